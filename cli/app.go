@@ -12,6 +12,7 @@ import (
 	"github.com/samber/lo"
 	"github.com/urfave/cli/v2"
 
+	"go.viam.com/rdk/ftdc/parser"
 	"go.viam.com/rdk/logging"
 )
 
@@ -1465,6 +1466,28 @@ Note: There is no progress meter while copying is in progress.
 						},
 					},
 				},
+				{
+					Name:            "capture-files",
+					Usage:           "check and repair data capture files copied off a machine",
+					UsageText:       createUsageText("data capture-files", nil, false, true),
+					HideHelpCommand: true,
+					Subcommands: []*cli.Command{
+						{
+							Name:      "verify",
+							Usage:     "report the capture files in a directory with corrupt readings or truncated by a power loss",
+							UsageText: createUsageText("data capture-files verify", nil, false, false, "<capture directory>"),
+							ArgsUsage: "<capture directory>",
+							Action:    createCommandWithT[emptyArgs](DataCaptureFilesVerifyAction),
+						},
+						{
+							Name:      "repair",
+							Usage:     "replace the damaged capture files in a directory with their intact readings",
+							UsageText: createUsageText("data capture-files repair", nil, false, false, "<capture directory>"),
+							ArgsUsage: "<capture directory>",
+							Action:    createCommandWithT[emptyArgs](DataCaptureFilesRepairAction),
+						},
+					},
+				},
 				{
 					Name:            "tag",
 					Usage:           "tag binary data by filter or ids",
@@ -2916,6 +2939,26 @@ Note: There is no progress meter while copying is in progress.
 							}...),
 							Action: createCommandWithT[robotsPartTunnelArgs](RobotsPartTunnelAction),
 						},
+						{
+							Name:            "jobs",
+							Usage:           "work with the jobs configured on a machine part",
+							UsageText:       createUsageText("machines part jobs", nil, false, true),
+							HideHelpCommand: true,
+							Subcommands: []*cli.Command{
+								{
+									Name:      "status",
+									Usage:     "show the recent runs of jobs on a machine part",
+									UsageText: createUsageText("machines part jobs status", []string{generalFlagPart}, true, false),
+									Flags: append(commonPartFlags, []cli.Flag{
+										&cli.StringSliceFlag{
+											Name:  jobsFlagJob,
+											Usage: "names of the jobs to show. shows all jobs if not provided",
+										},
+									}...),
+									Action: createCommandWithT[machinesPartJobsStatusArgs](MachinesPartJobsStatusAction),
+								},
+							},
+						},
 						{
 							Name: "motion",
 							Subcommands: []*cli.Command{
@@ -3944,15 +3987,51 @@ NOTES:
 		},
 		{
 			Name:  "parse-ftdc",
-			Usage: "parse an ftdc file and open a REPL with extra options",
+			Usage: "parse an ftdc file and open a REPL with extra options, or export it",
 			UsageText: createUsageText(
-				"ftdc-parse", []string{generalFlagPath}, false, false,
+				"parse-ftdc", []string{generalFlagPath}, true, false,
 			),
+			Description: `
+Without --format, plots the ftdc data with gnuplot and opens a REPL to explore it. Pass --path
+multiple times, e.g. once per machine part, to plot the same metrics of each path on one graph.
+With --format, writes the ftdc data to stdout or the --output file without plotting:
+  csv       one row per datapoint and one column per metric
+  ndjson    one JSON object per line and datapoint
+  line      InfluxDB line protocol, one point per datapoint
+  columnar  one JSON object with an array of values per metric
+Annotations of the ftdc rules that fired are exported too, except for csv.
+`,
 			Flags: []cli.Flag{
-				&cli.StringFlag{
+				&cli.StringSliceFlag{
 					Name:     generalFlagPath,
 					Required: true,
-					Usage:    "absolute file path to the ftdc file",
+					Usage:    "absolute file path to the ftdc file, or a directory of ftdc files. repeat to compare",
+				},
+				&cli.StringFlag{
+					Name:  logsFlagFormat,
+					Usage: "export format (csv, ndjson, line or columnar)",
+				},
+				&cli.StringFlag{
+					Name:        logsFlagOutputFile,
+					Usage:       "path to write the export to",
+					DefaultText: "stdout",
+				},
+				&cli.StringSliceFlag{
+					Name:  ftdcFlagMetrics,
+					Usage: "only export metrics matching these glob patterns (e.g., 'proc.viam-server.*')",
+				},
+				&cli.StringFlag{
+					Name:  generalFlagStart,
+					Usage: "ISO-8601 timestamp in RFC3339 format indicating the start of the exported interval",
+				},
+				&cli.StringFlag{
+					Name:  generalFlagEnd,
+					Usage: "ISO-8601 timestamp in RFC3339 format indicating the end of the exported interval",
+				},
+				&cli.StringFlag{
+					Name:        ftdcFlagMeasurement,
+					Usage:       "measurement name for line protocol exports",
+					DefaultText: parser.DefaultMeasurement,
 				},
 			},
 			Action: createCommandWithT[ftdcArgs](FTDCParseAction),
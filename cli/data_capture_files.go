@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"go.viam.com/rdk/data"
+)
+
+// DataCaptureFilesVerifyAction is the corresponding action for 'data capture-files verify'.
+func DataCaptureFilesVerifyAction(c *cli.Context, _ emptyArgs) error {
+	dir, err := getSingularArg(c)
+	if err != nil {
+		return err
+	}
+	var fileCount, damagedFileCount, unreadableFileCount int
+	err = walkCaptureFiles(dir, func(path string) {
+		fileCount++
+		report, err := data.VerifyCaptureFile(path)
+		if err != nil {
+			unreadableFileCount++
+			warningf(c.App.ErrWriter, "%s can't be read: %v", path, err)
+			return
+		}
+		if report.Damaged() {
+			damagedFileCount++
+			printCaptureFileReport(c, report)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	printf(c.App.Writer, "Verified %d capture files: %d damaged, %d unreadable", fileCount, damagedFileCount, unreadableFileCount)
+	if damagedFileCount > 0 || unreadableFileCount > 0 {
+		return errors.New("found damaged or unreadable capture files, run 'viam data capture-files repair' to salvage their intact readings")
+	}
+	return nil
+}
+
+// DataCaptureFilesRepairAction is the corresponding action for 'data capture-files repair'.
+func DataCaptureFilesRepairAction(c *cli.Context, _ emptyArgs) error {
+	dir, err := getSingularArg(c)
+	if err != nil {
+		return err
+	}
+	var fileCount, repairedFileCount, deletedFileCount, unreadableFileCount int
+	err = walkCaptureFiles(dir, func(path string) {
+		fileCount++
+		report, err := data.RepairCaptureFile(path)
+		if err != nil {
+			unreadableFileCount++
+			warningf(c.App.ErrWriter, "%s can't be repaired: %v", path, err)
+			return
+		}
+		switch {
+		case report.Path == "":
+			deletedFileCount++
+			printf(c.App.Writer, "Deleted %s: no intact readings", path)
+		case report.Damaged():
+			repairedFileCount++
+			printCaptureFileReport(c, report)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	printf(c.App.Writer, "Repaired %d of %d capture files, deleted %d without intact readings, %d unreadable",
+		repairedFileCount, fileCount, deletedFileCount, unreadableFileCount)
+	return nil
+}
+
+func printCaptureFileReport(c *cli.Context, report data.CaptureFileReport) {
+	printf(c.App.Writer, "%s: %d intact readings, %d corrupt readings, %d truncated bytes",
+		report.Path, report.Readings, report.CorruptReadings, report.TruncatedBytes)
+}
+
+// walkCaptureFiles calls fn with the path of each capture file in dir, which is walked before any
+// of them are repaired.
+func walkCaptureFiles(dir string, fn func(path string)) error {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ext := filepath.Ext(path); !d.IsDir() && (ext == data.CompletedCaptureFileExt || ext == data.InProgressCaptureFileExt) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to read capture directory %s", dir)
+	}
+	for _, path := range paths {
+		fn(path)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data"
+)
+
+func TestDataCaptureFiles(t *testing.T) {
+	captureDir := t.TempDir()
+	methodDir := filepath.Join(captureDir, "rdk_component_sensor", "sensor-1", "Readings")
+	test.That(t, os.MkdirAll(methodDir, 0o700), test.ShouldBeNil)
+
+	// A capture file which was truncated when the machine lost power.
+	md, _ := data.BuildCaptureMetadata(sensor.API, "sensor-1", "Readings", nil, nil, nil)
+	f, err := data.NewCaptureFile(methodDir, md)
+	test.That(t, err, test.ShouldBeNil)
+	readings, err := structpb.NewStruct(map[string]interface{}{"a": 1})
+	test.That(t, err, test.ShouldBeNil)
+	for i := 0; i < 2; i++ {
+		test.That(t, f.WriteNext(&v1.SensorData{Metadata: &v1.SensorMetadata{}, Data: &v1.SensorData_Struct{Struct: readings}}),
+			test.ShouldBeNil)
+	}
+	test.That(t, f.Flush(), test.ShouldBeNil)
+	test.That(t, os.Truncate(f.GetPath(), f.Size()-1), test.ShouldBeNil)
+	repairedPath := strings.TrimSuffix(f.GetPath(), data.InProgressCaptureFileExt) + data.CompletedCaptureFileExt
+
+	cCtx, _, out, _ := setup(nil, nil, nil, nil, "", captureDir)
+	err = DataCaptureFilesVerifyAction(cCtx, emptyArgs{})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, strings.Join(out.messages, ""), test.ShouldContainSubstring, "Verified 1 capture files: 1 damaged, 0 unreadable")
+
+	cCtx, _, out, _ = setup(nil, nil, nil, nil, "", captureDir)
+	test.That(t, DataCaptureFilesRepairAction(cCtx, emptyArgs{}), test.ShouldBeNil)
+	test.That(t, strings.Join(out.messages, ""), test.ShouldContainSubstring,
+		repairedPath+": 1 intact readings, 0 corrupt readings")
+	recovered, err := data.SensorDataFromCaptureFilePath(repairedPath)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, recovered, test.ShouldHaveLength, 1)
+
+	cCtx, _, out, _ = setup(nil, nil, nil, nil, "", captureDir)
+	test.That(t, DataCaptureFilesVerifyAction(cCtx, emptyArgs{}), test.ShouldBeNil)
+	test.That(t, strings.Join(out.messages, ""), test.ShouldContainSubstring, "Verified 1 capture files: 0 damaged, 0 unreadable")
+}
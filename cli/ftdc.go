@@ -1,17 +1,77 @@
 package cli
 
 import (
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
+	"go.uber.org/multierr"
 
 	"go.viam.com/rdk/ftdc/parser"
+	"go.viam.com/rdk/logging"
+)
+
+const (
+	ftdcFlagMetrics     = "metrics"
+	ftdcFlagMeasurement = "measurement"
 )
 
 type ftdcArgs struct {
-	Path string
+	Path        []string
+	Format      string
+	Output      string
+	Metrics     []string
+	Start       string
+	End         string
+	Measurement string
 }
 
-// FTDCParseAction is the cli action to parse an ftdc file.
+// FTDCParseAction is the cli action to parse an ftdc file. Without a format it plots the data
+// and opens a REPL, comparing the data of every path. Otherwise it exports the data in that
+// format.
 func FTDCParseAction(c *cli.Context, args ftdcArgs) error {
-	parser.LaunchREPL(args.Path)
+	if args.Format == "" {
+		parser.LaunchREPL(args.Path...)
+		return nil
+	}
+	if len(args.Path) != 1 {
+		return errors.New("exporting supports a single ftdc file or directory")
+	}
+
+	opts := parser.ExportOptions{
+		Format:      parser.ExportFormat(args.Format),
+		Metrics:     args.Metrics,
+		Measurement: args.Measurement,
+	}
+	var err error
+	if args.Start != "" {
+		if opts.Start, err = time.Parse(time.RFC3339, args.Start); err != nil {
+			return errors.Wrap(err, "could not parse start flag")
+		}
+	}
+	if args.End != "" {
+		if opts.End, err = time.Parse(time.RFC3339, args.End); err != nil {
+			return errors.Wrap(err, "could not parse end flag")
+		}
+	}
+
+	logger := logging.NewLogger("parser")
+	logger.SetLevel(logging.WARN)
+	if args.Output == "" {
+		return parser.Export(args.Path[0], c.App.Writer, opts, logger)
+	}
+
+	//nolint:gosec
+	outFile, err := os.Create(args.Output)
+	if err != nil {
+		return errors.Wrap(err, "could not create output file")
+	}
+	// Data may still be written when the file is closed, so a close error is an export error.
+	err = parser.Export(args.Path[0], outFile, opts, logger)
+	if err = multierr.Combine(err, errors.Wrap(outFile.Close(), "could not close output file")); err != nil {
+		return err
+	}
+	printf(c.App.Writer, "Exported FTDC data to %s", args.Output)
 	return nil
 }
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/robot/client"
+)
+
+const jobsFlagJob = "job"
+
+type machinesPartJobsStatusArgs struct {
+	Organization string
+	Location     string
+	Machine      string
+	Part         string
+	Job          []string
+}
+
+// MachinesPartJobsStatusAction prints the recent runs of the jobs configured on a machine part.
+func MachinesPartJobsStatusAction(c *cli.Context, args machinesPartJobsStatusArgs) error {
+	return withPartRobotClient(c, args.Organization, args.Location, args.Machine, args.Part,
+		func(ctx context.Context, robotClient *client.RobotClient) error {
+			machineStatus, err := robotClient.MachineStatus(ctx)
+			if err != nil {
+				return err
+			}
+			jobs := machineStatus.JobStatuses
+			for _, name := range args.Job {
+				if _, ok := jobs[name]; !ok {
+					return errors.Errorf("no runs of job %q found on this machine part", name)
+				}
+			}
+			if len(jobs) == 0 {
+				printf(c.App.Writer, "No jobs have run on this machine part")
+				return nil
+			}
+			names := make([]string, 0, len(jobs))
+			for name := range jobs {
+				if len(args.Job) == 0 || slices.Contains(args.Job, name) {
+					names = append(names, name)
+				}
+			}
+			slices.Sort(names)
+			for _, name := range names {
+				type jobRun struct {
+					start     time.Time
+					succeeded bool
+				}
+				runs := make([]jobRun, 0, len(jobs[name].RecentSuccessfulRuns)+len(jobs[name].RecentFailedRuns))
+				for _, start := range jobs[name].RecentSuccessfulRuns {
+					runs = append(runs, jobRun{start, true})
+				}
+				for _, start := range jobs[name].RecentFailedRuns {
+					runs = append(runs, jobRun{start, false})
+				}
+				// newest runs first, since that is what operators usually look for.
+				slices.SortFunc(runs, func(left, right jobRun) int {
+					return right.start.Compare(left.start)
+				})
+				printf(c.App.Writer, "%s (%d recent runs)", name, len(runs))
+				for _, run := range runs {
+					status := "succeeded"
+					if !run.succeeded {
+						status = "failed"
+					}
+					printf(c.App.Writer, "\t%s\t%s", run.start.Local().Format(time.RFC3339), status)
+				}
+			}
+			return nil
+		})
+}
+
+// withPartRobotClient connects to a machine part and calls f with a robot client for it.
+func withPartRobotClient(
+	c *cli.Context,
+	organization, location, machine, part string,
+	f func(ctx context.Context, robotClient *client.RobotClient) error,
+) error {
+	viamClient, err := newViamClient(c)
+	if err != nil {
+		return err
+	}
+
+	globalArgs, err := getGlobalArgs(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, fqdn, rpcOpts, err := viamClient.prepareDial(organization, location, machine, part, globalArgs.Debug)
+	if err != nil {
+		return err
+	}
+
+	logger := globalArgs.createLogger()
+
+	robotClient, err := viamClient.connectToRobot(ctx, fqdn, rpcOpts, globalArgs.Debug, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		utils.UncheckedError(robotClient.Close(ctx))
+	}()
+
+	return f(ctx, robotClient)
+}
@@ -14,6 +14,7 @@ import (
 
 	"go.viam.com/rdk/components/arm"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/spatialmath"
@@ -29,6 +30,12 @@ type operation struct {
 	targetInputs []float64
 	done         bool
 	stopped      bool
+
+	// A timed operation moves through its waypoints, reaching each at its time, relative to the start
+	// of the operation. Between waypoints the joints are interpolated linearly.
+	waypoints [][]float64
+	times     []time.Duration
+	elapsed   time.Duration
 }
 
 func (op operation) isMoving() bool {
@@ -175,6 +182,13 @@ func (sa *simulatedArm) updateForTime(now time.Time) {
 		return
 	}
 
+	if sa.operation.times != nil {
+		sa.operation.elapsed += now.Sub(sa.lastUpdated)
+		sa.lastUpdated = now
+		sa.updateTimedOperation()
+		return
+	}
+
 	// This will track if we need to set `done` to true. So long as even one joint is moving, this
 	// operation is not "done".
 	anyJointStillMoving := false
@@ -256,6 +270,55 @@ func (sa *simulatedArm) updateForTime(now time.Time) {
 	}
 }
 
+// updateTimedOperation moves the joints to where the timed operation is after its elapsed time. Its
+// caller must hold the mutex.
+func (sa *simulatedArm) updateTimedOperation() {
+	op := &sa.operation
+	last := len(op.times) - 1
+	if op.elapsed >= op.times[last] {
+		copy(sa.currInputs, op.targetInputs)
+		op.done = true
+		return
+	}
+
+	// The segment whose end waypoint has not been reached yet.
+	next := 1
+	for op.times[next] <= op.elapsed {
+		next++
+	}
+	from, to := op.waypoints[next-1], op.waypoints[next]
+	fraction := float64(op.elapsed-op.times[next-1]) / float64(op.times[next]-op.times[next-1])
+	for jointIdx := range sa.currInputs {
+		sa.currInputs[jointIdx] = from[jointIdx] + fraction*(to[jointIdx]-from[jointIdx])
+	}
+}
+
+// kinematicLimits returns the kinematic limits of the joints in radians. The velocity of each
+// joint is capped by `sa.speed`, and the velocity and acceleration by the move options.
+func (sa *simulatedArm) kinematicLimits(options *arm.MoveOptions) []referenceframe.KinematicLimits {
+	limits := make([]referenceframe.KinematicLimits, len(sa.currInputs))
+	if model, ok := sa.model.(motionplan.KinematicLimitsFrame); ok {
+		copy(limits, model.KinematicLimits())
+	}
+	capLimit := func(limit, maxLimit float64) float64 {
+		if maxLimit <= 0 {
+			return limit
+		}
+		if limit <= 0 {
+			return maxLimit
+		}
+		return math.Min(limit, maxLimit)
+	}
+	for jointIdx := range limits {
+		limits[jointIdx].Velocity = capLimit(limits[jointIdx].Velocity, sa.speed)
+		if options != nil {
+			limits[jointIdx].Velocity = capLimit(limits[jointIdx].Velocity, options.MaxVelRads)
+			limits[jointIdx].Acceleration = capLimit(limits[jointIdx].Acceleration, options.MaxAccRads)
+		}
+	}
+	return limits
+}
+
 func (sa *simulatedArm) EndPosition(
 	ctx context.Context, extra map[string]interface{},
 ) (spatialmath.Pose, error) {
@@ -302,8 +365,11 @@ func (sa *simulatedArm) MoveToJointPositions(
 	}
 	sa.mu.Unlock()
 
-	// An operation was "started". `MoveToJointPositions` blocks until the movement completes or is
-	// canceled.
+	return sa.waitForOperation(ctx)
+}
+
+// waitForOperation blocks until the started operation completes or is canceled.
+func (sa *simulatedArm) waitForOperation(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
@@ -338,19 +404,48 @@ func (sa *simulatedArm) MoveToJointPositions(
 	}
 }
 
+// MoveThroughJointPositions moves through the positions without stopping at them, reaching each at
+// the time the time-optimal parameterization of the kinematic limits of the joints prescribes.
 func (sa *simulatedArm) MoveThroughJointPositions(
 	ctx context.Context,
 	positions [][]referenceframe.Input,
-	_ *arm.MoveOptions,
+	options *arm.MoveOptions,
 	_ map[string]interface{},
 ) error {
+	if len(positions) == 0 {
+		return nil
+	}
 	for _, goal := range positions {
-		if err := sa.MoveToJointPositions(ctx, goal, nil); err != nil {
+		if err := arm.CheckDesiredJointPositions(ctx, sa, goal); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	current, err := sa.JointPositions(ctx, nil)
+	if err != nil {
+		return err
+	}
+	waypoints := append([][]float64{current}, positions...)
+	traj := make(motionplan.Trajectory, 0, len(waypoints))
+	for _, waypoint := range waypoints {
+		traj = append(traj, referenceframe.FrameSystemInputs{sa.Name().ShortName(): waypoint})
+	}
+	times, err := motionplan.TimeParameterize(traj, map[string][]referenceframe.KinematicLimits{
+		sa.Name().ShortName(): sa.kinematicLimits(options),
+	})
+	if err != nil {
+		return err
+	}
+
+	sa.mu.Lock()
+	sa.operation = operation{
+		targetInputs: positions[len(positions)-1],
+		waypoints:    waypoints,
+		times:        times,
+	}
+	sa.mu.Unlock()
+
+	return sa.waitForOperation(ctx)
 }
 
 func (sa *simulatedArm) GoToInputs(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
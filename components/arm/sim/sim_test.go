@@ -181,3 +181,57 @@ func TestTimeSimulation(t *testing.T) {
 	err = simArm.MoveToJointPositions(ctx, []float64{1, -2, 0, 0, 0, 0}, nil)
 	test.That(t, err, test.ShouldBeNil)
 }
+
+func TestMoveThroughJointPositions(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	resConf := resource.Config{
+		Name:  "arm",
+		API:   arm.API,
+		Model: Model,
+		ConvertedAttributes: &Config{
+			Model: "lite6",
+			Speed: 1.0, // radians per second
+		},
+	}
+
+	simArmI, err := NewArm(ctx, nil, resConf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	simArm := simArmI.(*simulatedArm)
+
+	// With an acceleration of 1 radian per second squared, moving the first joint by 2 radians takes
+	// one second accelerating, one second at `Speed` and one second slowing down. The arm passes the
+	// first position halfway, without stopping.
+	moveFuture := make(chan struct{})
+	go func() {
+		err := simArm.MoveThroughJointPositions(ctx, [][]float64{{1, 0, 0, 0, 0, 0}, {2, 0, 0, 0, 0, 0}},
+			&arm.MoveOptions{MaxAccRads: 1}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		close(moveFuture)
+	}()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		isMoving, err := simArm.IsMoving(ctx)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, isMoving, test.ShouldBeTrue)
+	})
+
+	clock := simArm.lastUpdated
+	clock = clock.Add(1500 * time.Millisecond)
+	simArm.updateForTime(clock)
+	currInputs, err := simArm.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, currInputs[0], test.ShouldAlmostEqual, 1)
+	test.That(t, simArm.operation.isMoving(), test.ShouldBeTrue)
+
+	clock = clock.Add(1500 * time.Millisecond)
+	simArm.updateForTime(clock)
+	currInputs, err = simArm.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, currInputs, test.ShouldResemble, []float64{2, 0, 0, 0, 0, 0})
+	select {
+	case <-moveFuture:
+	case <-time.After(time.Second):
+		t.Fatal("Background goroutine calling `MoveThroughJointPositions` has not returned.")
+	}
+}
@@ -3,6 +3,7 @@ package button
 
 import (
 	"context"
+	"sync"
 
 	pb "go.viam.com/api/component/button/v1"
 
@@ -80,3 +81,41 @@ func FromProvider(provider resource.Provider, name string) (Button, error) {
 func NamesFromRobot(r robot.Robot) []string {
 	return robot.NamesByAPI(r, API)
 }
+
+// pushSubscribers are the channels notified of the pushes of each button.
+var (
+	pushSubscribersMu sync.Mutex
+	pushSubscribers   = map[Button]map[chan<- struct{}]struct{}{}
+)
+
+// SubscribePushes notifies ch every time b is pushed through its gRPC service, which is the path
+// taken by clients, modules and jobs alike. A push is only sent if ch has room for it, so pushes
+// made while the subscriber is busy are coalesced. The returned function ends the subscription.
+func SubscribePushes(b Button, ch chan<- struct{}) func() {
+	pushSubscribersMu.Lock()
+	defer pushSubscribersMu.Unlock()
+	if pushSubscribers[b] == nil {
+		pushSubscribers[b] = map[chan<- struct{}]struct{}{}
+	}
+	pushSubscribers[b][ch] = struct{}{}
+	return func() {
+		pushSubscribersMu.Lock()
+		defer pushSubscribersMu.Unlock()
+		delete(pushSubscribers[b], ch)
+		if len(pushSubscribers[b]) == 0 {
+			delete(pushSubscribers, b)
+		}
+	}
+}
+
+// notifyPushed notifies the subscribers of b that it was pushed.
+func notifyPushed(b Button) {
+	pushSubscribersMu.Lock()
+	defer pushSubscribersMu.Unlock()
+	for ch := range pushSubscribers[b] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -30,7 +30,11 @@ func (s *serviceServer) Push(ctx context.Context, req *pb.PushRequest) (*pb.Push
 	if err != nil {
 		return nil, err
 	}
-	return &pb.PushResponse{}, button.Push(ctx, req.Extra.AsMap())
+	if err := button.Push(ctx, req.Extra.AsMap()); err != nil {
+		return nil, err
+	}
+	notifyPushed(button)
+	return &pb.PushResponse{}, nil
 }
 
 // DoCommand receives arbitrary commands.
@@ -72,6 +72,26 @@ func TestServer(t *testing.T) {
 		test.That(t, buttonPushed, test.ShouldEqual, testButtonName2)
 	})
 
+	t.Run("push subscribers", func(t *testing.T) {
+		pushes := make(chan struct{}, 1)
+		unsubscribe := button.SubscribePushes(injectButton, pushes)
+
+		_, err := buttonServer.Push(context.Background(), &pb.PushRequest{Name: testButtonName})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(pushes), test.ShouldEqual, 1)
+		<-pushes
+
+		// failed pushes and pushes of other buttons are not sent.
+		_, err = buttonServer.Push(context.Background(), &pb.PushRequest{Name: testButtonName2})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, len(pushes), test.ShouldEqual, 0)
+
+		unsubscribe()
+		_, err = buttonServer.Push(context.Background(), &pb.PushRequest{Name: testButtonName})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(pushes), test.ShouldEqual, 0)
+	})
+
 	t.Run("do command", func(t *testing.T) {
 		_, err := buttonServer.DoCommand(context.Background(), &pbcommon.DoCommandRequest{Name: missingButtonName})
 		test.That(t, err, test.ShouldNotBeNil)
@@ -21,6 +21,7 @@ import (
 	"go.viam.com/utils/pexec"
 	"go.viam.com/utils/rpc"
 
+	"go.viam.com/rdk/ftdc"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
@@ -44,6 +45,12 @@ type Config struct {
 	Jobs              []JobConfig
 	Tracing           TracingConfig
 
+	// FTDCRules are evaluated against the diagnostics collected by FTDC. A rule that fires logs a
+	// warning and annotates the FTDC data.
+	FTDCRules []ftdc.Rule
+	// FTDCStorage configures how the diagnostics collected by FTDC are kept on disk.
+	FTDCStorage *ftdc.StorageConfig
+
 	ConfigFilePath string
 
 	// AllowInsecureCreds is used to have all connections allow insecure
@@ -67,6 +74,10 @@ type Config struct {
 	// EnableWebProfile turns pprof http server in localhost. Defaults to false.
 	EnableWebProfile bool
 
+	// EnableWebMetrics serves the latest FTDC diagnostics in the OpenMetrics format at /metrics
+	// on the http server. Defaults to false.
+	EnableWebMetrics bool
+
 	// Revision contains the current revision of the config.
 	Revision string
 
@@ -132,12 +143,15 @@ type configData struct {
 	Auth                    AuthConfig                    `json:"auth"`
 	Debug                   bool                          `json:"debug,omitempty"`
 	EnableWebProfile        bool                          `json:"enable_web_profile"`
+	EnableWebMetrics        bool                          `json:"enable_web_metrics,omitempty"`
 	LogConfig               []logging.LoggerPatternConfig `json:"log,omitempty"`
 	Revision                string                        `json:"revision,omitempty"`
 	MaintenanceConfig       *MaintenanceConfig            `json:"maintenance,omitempty"`
 	PackagePath             string                        `json:"package_path,omitempty"`
 	DisableLogDeduplication bool                          `json:"disable_log_deduplication"`
 	Jobs                    []JobConfig                   `json:"jobs,omitempty"`
+	FTDCRules               []ftdc.Rule                   `json:"ftdc_rules,omitempty"`
+	FTDCStorage             *ftdc.StorageConfig           `json:"ftdc_storage,omitempty"`
 	Tracing                 TracingConfig                 `json:"tracing,omitempty"`
 }
 
@@ -183,6 +197,15 @@ func (c *Config) Ensure(fromCloud bool, logger logging.Logger) error {
 		}
 		seenJobs[c.Jobs[idx].Name] = struct{}{}
 	}
+	if err := ValidateJobTriggers("jobs", c.Jobs); err != nil {
+		logger.Errorw("Jobs config error; jobs in the cycle will not trigger follow-up jobs", "error", err.Error())
+	}
+	for idx := range c.FTDCRules {
+		if err := c.FTDCRules[idx].Validate(); err != nil {
+			logger.Errorw("FTDC rule config error; rule will be ignored",
+				"path", fmt.Sprintf("%s.%d", "ftdc_rules", idx), "name", c.FTDCRules[idx].Name, "error", err.Error())
+		}
+	}
 	seenModules := make(map[string]struct{})
 	for idx := range len(c.Modules) {
 		if err := c.Modules[idx].Validate(fmt.Sprintf("%s.%d", "modules", idx)); err != nil {
@@ -314,12 +337,15 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 	c.Auth = conf.Auth
 	c.Debug = conf.Debug
 	c.EnableWebProfile = conf.EnableWebProfile
+	c.EnableWebMetrics = conf.EnableWebMetrics
 	c.LogConfig = conf.LogConfig
 	c.Revision = conf.Revision
 	c.MaintenanceConfig = conf.MaintenanceConfig
 	c.PackagePath = conf.PackagePath
 	c.DisableLogDeduplication = conf.DisableLogDeduplication
 	c.Jobs = conf.Jobs
+	c.FTDCRules = conf.FTDCRules
+	c.FTDCStorage = conf.FTDCStorage
 	c.Tracing = conf.Tracing
 
 	return nil
@@ -346,12 +372,15 @@ func (c Config) MarshalJSON() ([]byte, error) {
 		Auth:                    c.Auth,
 		Debug:                   c.Debug,
 		EnableWebProfile:        c.EnableWebProfile,
+		EnableWebMetrics:        c.EnableWebMetrics,
 		LogConfig:               c.LogConfig,
 		Revision:                c.Revision,
 		MaintenanceConfig:       c.MaintenanceConfig,
 		PackagePath:             c.PackagePath,
 		DisableLogDeduplication: c.DisableLogDeduplication,
 		Jobs:                    c.Jobs,
+		FTDCRules:               c.FTDCRules,
+		FTDCStorage:             c.FTDCStorage,
 		Tracing:                 c.Tracing,
 	})
 }
@@ -1305,8 +1334,134 @@ type JobConfigData struct {
 	Method           string              `json:"method"`
 	Command          map[string]any      `json:"command,omitempty"`
 	LogConfiguration *resource.LogConfig `json:"log_configuration,omitempty"`
+
+	// Timeout bounds a single attempt of the job. It is a golang duration string.
+	Timeout string `json:"timeout,omitempty"`
+	// MaxRetries is the number of additional attempts made after a failed attempt.
+	MaxRetries int `json:"max_retries,omitempty"`
+	// RetryBackoff is the wait before the first retry; it doubles on every following retry.
+	RetryBackoff string `json:"retry_backoff,omitempty"`
+	// OverlapPolicy decides what happens when the job is due while a previous run is still
+	// in progress. See the JobOverlapPolicy constants.
+	OverlapPolicy JobOverlapPolicy `json:"overlap_policy,omitempty"`
+	// OnSuccess and OnFailure name other jobs that are triggered when this job finishes.
+	OnSuccess []string `json:"on_success,omitempty"`
+	OnFailure []string `json:"on_failure,omitempty"`
+	// Request is the JSON body of the gRPC request sent to Method. The resource name field of
+	// the request is filled in from Resource unless the body sets it. DoCommand jobs use
+	// Command instead.
+	Request map[string]any `json:"request,omitempty"`
+	// Capture, if set, writes the response of every successful run as tabular data into the
+	// data manager capture directory, where it is synced like any other captured data.
+	Capture *JobCaptureConfig `json:"capture,omitempty"`
+	// Trigger, if set, runs the job whenever an event happens on the machine. The schedule
+	// can then be left empty, in which case the job only runs on events.
+	Trigger *JobTriggerConfig `json:"trigger,omitempty"`
+}
+
+// JobCaptureConfig describes how the responses of a job are captured. Responses are written to
+// the capture directory of the data manager.
+type JobCaptureConfig struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+// JobTriggerType is the kind of event that triggers a job.
+type JobTriggerType string
+
+const (
+	// JobTriggerHealth triggers a job when a resource becomes healthy or unhealthy.
+	JobTriggerHealth JobTriggerType = "health"
+	// JobTriggerButtonPush triggers a job every time a button is pushed successfully through
+	// its API. Buttons do not report presses themselves, so calls made from inside a module to
+	// its own button do not trigger jobs.
+	JobTriggerButtonPush JobTriggerType = "button_push"
+	// JobTriggerDigitalInterrupt triggers a job on every tick of a board digital interrupt.
+	JobTriggerDigitalInterrupt JobTriggerType = "digital_interrupt"
+	// JobTriggerSensorThreshold triggers a job when a sensor reading crosses a threshold.
+	JobTriggerSensorThreshold JobTriggerType = "sensor_threshold"
+)
+
+// JobTriggerConfig describes the event that triggers a job.
+type JobTriggerConfig struct {
+	Type JobTriggerType `json:"type"`
+	// Resource is the resource to watch. It defaults to the resource of the job.
+	Resource string `json:"resource,omitempty"`
+	// State is "healthy" or "unhealthy" for health triggers. If it is empty, the job is
+	// triggered on both transitions.
+	State string `json:"state,omitempty"`
+	// Interrupt is the name of the digital interrupt of digital_interrupt triggers.
+	Interrupt string `json:"interrupt,omitempty"`
+	// Reading is the key of the sensor reading of sensor_threshold triggers. The job is
+	// triggered when the reading rises above Above or falls below Below.
+	Reading string   `json:"reading,omitempty"`
+	Above   *float64 `json:"above,omitempty"`
+	Below   *float64 `json:"below,omitempty"`
+	// PollInterval is how often the watched state is checked by health and sensor_threshold
+	// triggers. It is a golang duration string and defaults to 1s.
+	PollInterval string `json:"poll_interval,omitempty"`
+	// Debounce is the minimum time between two runs triggered by events. Events within that
+	// time of the last triggered run are dropped. It is a golang duration string.
+	Debounce string `json:"debounce,omitempty"`
+}
+
+// Validate checks that the trigger is complete.
+func (tc *JobTriggerConfig) Validate(path string) error {
+	switch tc.Type {
+	case JobTriggerHealth:
+		if tc.State != "" && tc.State != "healthy" && tc.State != "unhealthy" {
+			return resource.NewConfigValidationError(path,
+				errors.Errorf(`state must be "healthy" or "unhealthy", got %q`, tc.State))
+		}
+	case JobTriggerButtonPush:
+	case JobTriggerDigitalInterrupt:
+		if tc.Interrupt == "" {
+			return resource.NewConfigValidationFieldRequiredError(path, "interrupt")
+		}
+	case JobTriggerSensorThreshold:
+		if tc.Reading == "" {
+			return resource.NewConfigValidationFieldRequiredError(path, "reading")
+		}
+		if tc.Above == nil && tc.Below == nil {
+			return resource.NewConfigValidationError(path, errors.New("one of above or below is required"))
+		}
+	default:
+		return resource.NewConfigValidationError(path, errors.Errorf("unknown trigger type %q", tc.Type))
+	}
+	for _, field := range []struct{ name, value string }{
+		{"poll_interval", tc.PollInterval},
+		{"debounce", tc.Debounce},
+	} {
+		if field.value == "" {
+			continue
+		}
+		if t, err := time.ParseDuration(field.value); err != nil || t < 0 {
+			return resource.NewConfigValidationError(path,
+				errors.Errorf("%s must be a non-negative duration string, got %q", field.name, field.value))
+		}
+	}
+	return nil
 }
 
+// JobOverlapPolicy describes how a job behaves if it is due while a previous run is
+// still in progress.
+type JobOverlapPolicy string
+
+const (
+	// JobOverlapDefault keeps the scheduler defaults: cron jobs skip the overlapping run and
+	// duration jobs queue it.
+	JobOverlapDefault JobOverlapPolicy = ""
+	// JobOverlapSkip skips the new run if the previous one has not finished.
+	JobOverlapSkip JobOverlapPolicy = "skip"
+	// JobOverlapQueue waits for the previous run to finish and then starts the new one.
+	JobOverlapQueue JobOverlapPolicy = "queue"
+	// JobOverlapCancelPrevious cancels the previous run and starts the new one immediately.
+	JobOverlapCancelPrevious JobOverlapPolicy = "cancel_previous"
+)
+
+// JobScheduleManual is a schedule for jobs that never run on their own and are only
+// started by other jobs (see OnSuccess and OnFailure).
+const JobScheduleManual = "manual"
+
 // MarshalJSON marshals out this config.
 func (jc JobConfig) MarshalJSON() ([]byte, error) {
 	return json.Marshal(jc.JobConfigData)
@@ -1336,12 +1491,96 @@ func (jc *JobConfig) Validate(path string) error {
 	if jc.Resource == "" {
 		return resource.NewConfigValidationFieldRequiredError(path, "resource")
 	}
-	if jc.Schedule == "" {
+	if jc.Schedule == "" && jc.Trigger == nil {
 		return resource.NewConfigValidationFieldRequiredError(path, "schedule")
 	}
+	if jc.Trigger != nil {
+		if err := jc.Trigger.Validate(path + ".trigger"); err != nil {
+			return err
+		}
+	}
 	// At this point, the schedule could still be invalid (not a golang duration string or a
 	// cron expression). Such errors will be caught later, when the job manager will try to
 	// schedule the job and parse this field. The error will be displayed to the user.
+	if jc.Timeout != "" {
+		if t, err := time.ParseDuration(jc.Timeout); err != nil || t <= 0 {
+			return resource.NewConfigValidationError(path,
+				errors.Errorf("timeout must be a positive duration string, got %q", jc.Timeout))
+		}
+	}
+	if jc.MaxRetries < 0 {
+		return resource.NewConfigValidationError(path, errors.New("max_retries cannot be negative"))
+	}
+	if jc.RetryBackoff != "" {
+		if t, err := time.ParseDuration(jc.RetryBackoff); err != nil || t < 0 {
+			return resource.NewConfigValidationError(path,
+				errors.Errorf("retry_backoff must be a non-negative duration string, got %q", jc.RetryBackoff))
+		}
+	}
+	switch jc.OverlapPolicy {
+	case JobOverlapDefault, JobOverlapSkip, JobOverlapQueue, JobOverlapCancelPrevious:
+	default:
+		return resource.NewConfigValidationError(path,
+			errors.Errorf("unknown overlap_policy %q", jc.OverlapPolicy))
+	}
+	for _, next := range append(slices.Clone(jc.OnSuccess), jc.OnFailure...) {
+		if next == jc.Name {
+			return resource.NewConfigValidationError(path, errors.Errorf("job %q cannot trigger itself", jc.Name))
+		}
+	}
+	if jc.Request != nil && jc.Method == "DoCommand" {
+		return resource.NewConfigValidationError(path,
+			errors.New("request cannot be used with DoCommand jobs, use command instead"))
+	}
+	return nil
+}
+
+// JobsInTriggerCycles returns the sorted names of the jobs that can trigger themselves again
+// through the OnSuccess and OnFailure jobs of the jobs they trigger, e.g: "a" triggers "b",
+// which triggers "a". Such jobs would keep triggering each other forever.
+func JobsInTriggerCycles(jobs []JobConfig) []string {
+	triggers := make(map[string][]string, len(jobs))
+	for _, jc := range jobs {
+		triggers[jc.Name] = append(triggers[jc.Name], jc.OnSuccess...)
+		triggers[jc.Name] = append(triggers[jc.Name], jc.OnFailure...)
+	}
+
+	// Jobs are few, so search the jobs reachable from every job for the job itself.
+	reaches := func(from, to string) bool {
+		visited := make(map[string]struct{})
+		toVisit := slices.Clone(triggers[from])
+		for len(toVisit) > 0 {
+			next := toVisit[len(toVisit)-1]
+			toVisit = toVisit[:len(toVisit)-1]
+			if next == to {
+				return true
+			}
+			if _, ok := visited[next]; ok {
+				continue
+			}
+			visited[next] = struct{}{}
+			toVisit = append(toVisit, triggers[next]...)
+		}
+		return false
+	}
+
+	var cyclic []string
+	for name := range triggers {
+		if reaches(name, name) {
+			cyclic = append(cyclic, name)
+		}
+	}
+	slices.Sort(cyclic)
+	return cyclic
+}
+
+// ValidateJobTriggers checks that the OnSuccess and OnFailure jobs of jobs do not form a cycle.
+// See JobsInTriggerCycles.
+func ValidateJobTriggers(path string, jobs []JobConfig) error {
+	if cyclic := JobsInTriggerCycles(jobs); len(cyclic) > 0 {
+		return resource.NewConfigValidationError(path,
+			errors.Errorf("jobs %q trigger each other in a cycle through on_success and on_failure", cyclic))
+	}
 	return nil
 }
 
@@ -1198,6 +1198,7 @@ func TestJobsConfig(t *testing.T) {
 	errString := func(field string) string {
 		return fmt.Sprintf("Error validating, missing required field. Field: %q", field)
 	}
+	threshold := 30.0
 	jobsTests := []struct {
 		config               config.JobConfig
 		shouldFailValidation bool
@@ -1269,6 +1270,189 @@ func TestJobsConfig(t *testing.T) {
 			},
 			shouldFailValidation: false,
 		},
+		{
+			config: config.JobConfig{
+				config.JobConfigData{
+					Name:          "my_name",
+					Schedule:      config.JobScheduleManual,
+					Method:        "my_method",
+					Resource:      "my_resource",
+					Timeout:       "10s",
+					MaxRetries:    3,
+					RetryBackoff:  "500ms",
+					OverlapPolicy: config.JobOverlapCancelPrevious,
+					OnSuccess:     []string{"upload"},
+					OnFailure:     []string{"cleanup"},
+				},
+			},
+			shouldFailValidation: false,
+		},
+		{
+			config: config.JobConfig{
+				config.JobConfigData{
+					Name:     "my_name",
+					Schedule: "1m",
+					Method:   "my_method",
+					Resource: "my_resource",
+					Timeout:  "forever",
+				},
+			},
+			shouldFailValidation: true,
+			expRespErr:           "timeout must be a positive duration",
+		},
+		{
+			config: config.JobConfig{
+				config.JobConfigData{
+					Name:       "my_name",
+					Schedule:   "1m",
+					Method:     "my_method",
+					Resource:   "my_resource",
+					MaxRetries: -1,
+				},
+			},
+			shouldFailValidation: true,
+			expRespErr:           "max_retries cannot be negative",
+		},
+		{
+			config: config.JobConfig{
+				config.JobConfigData{
+					Name:          "my_name",
+					Schedule:      "1m",
+					Method:        "my_method",
+					Resource:      "my_resource",
+					OverlapPolicy: "sometimes",
+				},
+			},
+			shouldFailValidation: true,
+			expRespErr:           "unknown overlap_policy",
+		},
+		{
+			config: config.JobConfig{
+				config.JobConfigData{
+					Name:      "my_name",
+					Schedule:  "1m",
+					Method:    "my_method",
+					Resource:  "my_resource",
+					OnFailure: []string{"my_name"},
+				},
+			},
+			shouldFailValidation: true,
+			expRespErr:           "cannot trigger itself",
+		},
+		{
+			config: config.JobConfig{
+				config.JobConfigData{
+					Name:     "my_name",
+					Schedule: "1m",
+					Method:   "MoveToPosition",
+					Resource: "my_arm",
+					Request:  map[string]any{"to": map[string]any{"x": 1.0}},
+					Capture:  &config.JobCaptureConfig{Tags: []string{"jobs"}},
+				},
+			},
+			shouldFailValidation: false,
+		},
+		{
+			config: config.JobConfig{
+				config.JobConfigData{
+					Name:     "my_name",
+					Schedule: "1m",
+					Method:   "DoCommand",
+					Resource: "my_resource",
+					Request:  map[string]any{"command": "go"},
+				},
+			},
+			shouldFailValidation: true,
+			expRespErr:           "request cannot be used with DoCommand",
+		},
+		{
+			config: config.JobConfig{
+				config.JobConfigData{
+					Name:     "my_name",
+					Resource: "my_resource",
+					Method:   "DoCommand",
+					Trigger: &config.JobTriggerConfig{
+						Type:     config.JobTriggerSensorThreshold,
+						Resource: "my_sensor",
+						Reading:  "temperature",
+						Above:    &threshold,
+						Debounce: "10s",
+					},
+				},
+			},
+			shouldFailValidation: false,
+		},
+		{
+			config: config.JobConfig{
+				config.JobConfigData{
+					Name:     "my_name",
+					Resource: "my_resource",
+					Method:   "DoCommand",
+				},
+			},
+			shouldFailValidation: true,
+			expRespErr:           "schedule",
+		},
+		{
+			config: config.JobConfig{
+				config.JobConfigData{
+					Name:     "my_name",
+					Resource: "my_resource",
+					Method:   "DoCommand",
+					Trigger:  &config.JobTriggerConfig{Type: "on_a_whim"},
+				},
+			},
+			shouldFailValidation: true,
+			expRespErr:           "unknown trigger type",
+		},
+		{
+			config: config.JobConfig{
+				config.JobConfigData{
+					Name:     "my_name",
+					Resource: "my_resource",
+					Method:   "DoCommand",
+					Trigger:  &config.JobTriggerConfig{Type: config.JobTriggerSensorThreshold, Reading: "temperature"},
+				},
+			},
+			shouldFailValidation: true,
+			expRespErr:           "one of above or below is required",
+		},
+		{
+			config: config.JobConfig{
+				config.JobConfigData{
+					Name:     "my_name",
+					Resource: "my_resource",
+					Method:   "DoCommand",
+					Trigger:  &config.JobTriggerConfig{Type: config.JobTriggerDigitalInterrupt},
+				},
+			},
+			shouldFailValidation: true,
+			expRespErr:           "interrupt",
+		},
+		{
+			config: config.JobConfig{
+				config.JobConfigData{
+					Name:     "my_name",
+					Resource: "my_resource",
+					Method:   "DoCommand",
+					Trigger:  &config.JobTriggerConfig{Type: config.JobTriggerHealth, State: "meh"},
+				},
+			},
+			shouldFailValidation: true,
+			expRespErr:           "state must be",
+		},
+		{
+			config: config.JobConfig{
+				config.JobConfigData{
+					Name:     "my_name",
+					Resource: "my_resource",
+					Method:   "DoCommand",
+					Trigger:  &config.JobTriggerConfig{Type: config.JobTriggerButtonPush, Debounce: "soon"},
+				},
+			},
+			shouldFailValidation: true,
+			expRespErr:           "debounce must be a non-negative duration",
+		},
 	}
 
 	for _, jt := range jobsTests {
@@ -1282,6 +1466,53 @@ func TestJobsConfig(t *testing.T) {
 	}
 }
 
+func TestJobTriggerCycles(t *testing.T) {
+	job := func(name string, onSuccess, onFailure []string) config.JobConfig {
+		return config.JobConfig{config.JobConfigData{
+			Name:      name,
+			Schedule:  config.JobScheduleManual,
+			Method:    "DoCommand",
+			Resource:  "my_resource",
+			OnSuccess: onSuccess,
+			OnFailure: onFailure,
+		}}
+	}
+
+	// a chain of follow-up jobs, including unknown ones, is fine.
+	jobs := []config.JobConfig{
+		job("a", []string{"b"}, []string{"c"}),
+		job("b", []string{"c"}, nil),
+		job("c", []string{"not a job"}, nil),
+	}
+	test.That(t, config.JobsInTriggerCycles(jobs), test.ShouldBeEmpty)
+	test.That(t, config.ValidateJobTriggers("jobs", jobs), test.ShouldBeNil)
+
+	// "a" and "b" trigger each other, one on success and the other on failure.
+	jobs = []config.JobConfig{
+		job("a", []string{"b"}, nil),
+		job("b", nil, []string{"a"}),
+	}
+	test.That(t, config.JobsInTriggerCycles(jobs), test.ShouldResemble, []string{"a", "b"})
+	err := config.ValidateJobTriggers("jobs", jobs)
+	test.That(t, err, test.ShouldBeError)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `jobs ["a" "b"] trigger each other in a cycle`)
+
+	// "d" triggers the cycle of "a", "b" and "c" without being part of it.
+	jobs = []config.JobConfig{
+		job("a", []string{"b"}, nil),
+		job("b", []string{"c"}, nil),
+		job("c", nil, []string{"a"}),
+		job("d", []string{"a"}, []string{"a"}),
+	}
+	test.That(t, config.JobsInTriggerCycles(jobs), test.ShouldResemble, []string{"a", "b", "c"})
+
+	// a cycle is logged by Ensure, like other job config errors.
+	logger, logs := logging.NewObservedTestLogger(t)
+	cfg := config.Config{Jobs: jobs}
+	test.That(t, cfg.Ensure(false, logger), test.ShouldBeNil)
+	test.That(t, logs.FilterMessageSnippet("jobs in the cycle will not trigger follow-up jobs").Len(), test.ShouldEqual, 1)
+}
+
 func TestConfigRobotWebProfile(t *testing.T) {
 	logger := logging.NewTestLogger(t)
 	cfg, err := config.Read(context.Background(), "data/config_with_web_profile.json", logger, nil)
@@ -437,6 +437,10 @@ func diffNetworkingCfg(left, right *Config) bool {
 		return true
 	}
 
+	if !reflect.DeepEqual(left.EnableWebMetrics, right.EnableWebMetrics) {
+		return true
+	}
+
 	return false
 }
 
@@ -0,0 +1,228 @@
+package data
+
+import (
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Keys of the statistics an AggregatingBuffer replaces each numeric field of a reading with.
+const (
+	AggregateMinKey   = "min"
+	AggregateMaxKey   = "max"
+	AggregateMeanKey  = "mean"
+	AggregateCountKey = "count"
+)
+
+// AggregatingBuffer is a CaptureBufferedWriter which rolls tabular readings up into windows of a
+// fixed duration, and writes a single reading per window to its target. The reading of a window
+// has the shape of the readings it aggregates, with each numeric field replaced by its min, max,
+// mean and count over the window. Fields which are not numeric are dropped.
+//
+// The readings are also written, unchanged, to the raw target when there is one. Binary data is
+// written to the target unchanged.
+type AggregatingBuffer struct {
+	target CaptureBufferedWriter
+	// raw receives the readings before they are aggregated. It is nil when they are not kept.
+	raw    CaptureBufferedWriter
+	window time.Duration
+	clock  clock.Clock
+
+	mu sync.Mutex
+	// windowStart is the start of the window being aggregated. It is zero when no reading has been
+	// aggregated since the last window was written.
+	windowStart  time.Time
+	lastReceived time.Time
+	aggregate    *aggregateNode
+}
+
+// NewAggregatingBuffer returns a new AggregatingBuffer that writes a reading per window to target,
+// and the readings it aggregates to raw, unless raw is nil.
+func NewAggregatingBuffer(target, raw CaptureBufferedWriter, window time.Duration, clk clock.Clock) *AggregatingBuffer {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &AggregatingBuffer{
+		target: target,
+		raw:    raw,
+		window: window,
+		clock:  clk,
+	}
+}
+
+// WriteBinary writes the item to the target, binary data is not aggregated.
+func (b *AggregatingBuffer) WriteBinary(item *v1.SensorData, mimeType string) error {
+	return b.target.WriteBinary(item, mimeType)
+}
+
+// WriteTabular aggregates the item into the window it was requested in, and writes the previous
+// window to the target once the item is past it.
+func (b *AggregatingBuffer) WriteTabular(item *v1.SensorData) error {
+	if IsBinary(item) {
+		return errInvalidTabularSensorData
+	}
+	if b.raw != nil {
+		if err := b.raw.WriteTabular(item); err != nil {
+			return err
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	requested := b.clock.Now()
+	received := requested
+	if md := item.GetMetadata(); md.GetTimeRequested() != nil {
+		requested = md.GetTimeRequested().AsTime()
+		received = md.GetTimeReceived().AsTime()
+	}
+	windowStart := requested.Truncate(b.window)
+	// Readings requested before the window being aggregated, e.g: buffered by a pre-roll, are
+	// aggregated into it.
+	if !b.windowStart.IsZero() && windowStart.After(b.windowStart) {
+		if err := b.writeWindow(); err != nil {
+			return err
+		}
+	}
+	if b.windowStart.IsZero() {
+		b.windowStart = windowStart
+		b.aggregate = &aggregateNode{}
+	}
+	if received.After(b.lastReceived) {
+		b.lastReceived = received
+	}
+	b.aggregate.add(structpb.NewStructValue(item.GetStruct()))
+	return nil
+}
+
+// writeWindow writes the reading of the window being aggregated to the target.
+func (b *AggregatingBuffer) writeWindow() error {
+	aggregated := &v1.SensorData{
+		Metadata: &v1.SensorMetadata{
+			TimeRequested: timestamppb.New(b.windowStart),
+			TimeReceived:  timestamppb.New(b.lastReceived),
+		},
+		Data: &v1.SensorData_Struct{Struct: b.aggregate.toStruct()},
+	}
+	b.windowStart = time.Time{}
+	b.lastReceived = time.Time{}
+	b.aggregate = nil
+	return b.target.WriteTabular(aggregated)
+}
+
+// Flush writes the window being aggregated to the target if it has ended, and flushes the target
+// and the raw target. A window which has not ended is not written, so that it is not split into
+// several readings.
+func (b *AggregatingBuffer) Flush() error {
+	b.mu.Lock()
+	if !b.windowStart.IsZero() && !b.clock.Now().Before(b.windowStart.Add(b.window)) {
+		if err := b.writeWindow(); err != nil {
+			b.mu.Unlock()
+			return err
+		}
+	}
+	b.mu.Unlock()
+
+	if b.raw != nil {
+		if err := b.raw.Flush(); err != nil {
+			return err
+		}
+	}
+	return b.target.Flush()
+}
+
+// Close writes the window being aggregated to the target, even if it has not ended, and closes the
+// target and the raw target. Nothing may be written after Close.
+func (b *AggregatingBuffer) Close() error {
+	b.mu.Lock()
+	if !b.windowStart.IsZero() {
+		if err := b.writeWindow(); err != nil {
+			b.mu.Unlock()
+			return err
+		}
+	}
+	b.mu.Unlock()
+
+	if b.raw != nil {
+		if err := closeCaptureBufferedWriter(b.raw); err != nil {
+			return err
+		}
+	}
+	return closeCaptureBufferedWriter(b.target)
+}
+
+// Path returns the path of the target.
+func (b *AggregatingBuffer) Path() string {
+	return b.target.Path()
+}
+
+// aggregateNode aggregates the values of a field across readings. Numeric values are aggregated
+// into statistics, and the fields of struct values into child nodes.
+type aggregateNode struct {
+	count    int
+	min      float64
+	max      float64
+	sum      float64
+	children map[string]*aggregateNode
+}
+
+func (n *aggregateNode) add(value *structpb.Value) {
+	switch v := value.GetKind().(type) {
+	case *structpb.Value_NumberValue:
+		number := v.NumberValue
+		if n.count == 0 || number < n.min {
+			n.min = number
+		}
+		if n.count == 0 || number > n.max {
+			n.max = number
+		}
+		n.sum += number
+		n.count++
+	case *structpb.Value_StructValue:
+		for key, field := range v.StructValue.GetFields() {
+			if n.children == nil {
+				n.children = map[string]*aggregateNode{}
+			}
+			child, ok := n.children[key]
+			if !ok {
+				child = &aggregateNode{}
+				n.children[key] = child
+			}
+			child.add(field)
+		}
+	default:
+		// Strings, bools, lists and nulls can't be aggregated.
+	}
+}
+
+// toValue returns the statistics of a numeric field, or the aggregated fields of a struct. It
+// returns nil for fields which never had a numeric value, so that they are dropped.
+func (n *aggregateNode) toValue() *structpb.Value {
+	if n.count > 0 {
+		return structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+			AggregateMinKey:   structpb.NewNumberValue(n.min),
+			AggregateMaxKey:   structpb.NewNumberValue(n.max),
+			AggregateMeanKey:  structpb.NewNumberValue(n.sum / float64(n.count)),
+			AggregateCountKey: structpb.NewNumberValue(float64(n.count)),
+		}})
+	}
+	aggregated := n.toStruct()
+	if len(aggregated.GetFields()) == 0 {
+		return nil
+	}
+	return structpb.NewStructValue(aggregated)
+}
+
+func (n *aggregateNode) toStruct() *structpb.Struct {
+	fields := map[string]*structpb.Value{}
+	for key, child := range n.children {
+		if value := child.toValue(); value != nil {
+			fields[key] = value
+		}
+	}
+	return &structpb.Struct{Fields: fields}
+}
@@ -0,0 +1,118 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func readingAt(t *testing.T, requested time.Time, reading map[string]interface{}) *v1.SensorData {
+	t.Helper()
+	s, err := structpb.NewStruct(reading)
+	test.That(t, err, test.ShouldBeNil)
+	return &v1.SensorData{
+		Metadata: &v1.SensorMetadata{
+			TimeRequested: timestamppb.New(requested),
+			TimeReceived:  timestamppb.New(requested.Add(time.Millisecond)),
+		},
+		Data: &v1.SensorData_Struct{Struct: s},
+	}
+}
+
+func TestAggregatingBuffer(t *testing.T) {
+	start := time.Date(2024, 11, 18, 12, 0, 0, 0, time.UTC)
+
+	t.Run("writes a reading per window", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		target := &recordingBuffer{}
+		raw := &recordingBuffer{}
+		b := NewAggregatingBuffer(target, raw, time.Second, clk)
+
+		for i, x := range []float64{1, 5, 3} {
+			item := readingAt(t, start.Add(time.Duration(i)*100*time.Millisecond), map[string]interface{}{
+				"position": map[string]interface{}{"x": x},
+				"label":    "not aggregated",
+			})
+			test.That(t, b.WriteTabular(item), test.ShouldBeNil)
+		}
+		test.That(t, raw.tabular, test.ShouldHaveLength, 3)
+		test.That(t, target.tabular, test.ShouldBeEmpty)
+
+		// Flushing doesn't split a window that hasn't ended.
+		test.That(t, b.Flush(), test.ShouldBeNil)
+		test.That(t, target.tabular, test.ShouldBeEmpty)
+		test.That(t, target.flushed, test.ShouldEqual, 1)
+		test.That(t, raw.flushed, test.ShouldEqual, 1)
+
+		// A reading in the next window writes the previous one.
+		next := readingAt(t, start.Add(1500*time.Millisecond), map[string]interface{}{"position": map[string]interface{}{"x": 10}})
+		test.That(t, b.WriteTabular(next), test.ShouldBeNil)
+		test.That(t, target.tabular, test.ShouldHaveLength, 1)
+		aggregated := target.tabular[0]
+		test.That(t, aggregated.GetMetadata().GetTimeRequested().AsTime(), test.ShouldEqual, start)
+		test.That(t, aggregated.GetMetadata().GetTimeReceived().AsTime(), test.ShouldEqual, start.Add(201*time.Millisecond))
+		test.That(t, aggregated.GetStruct().AsMap(), test.ShouldResemble, map[string]interface{}{
+			"position": map[string]interface{}{
+				"x": map[string]interface{}{"min": 1.0, "max": 5.0, "mean": 3.0, "count": 3.0},
+			},
+		})
+
+		// Flushing writes a window that has ended.
+		clk.Set(start.Add(2 * time.Second))
+		test.That(t, b.Flush(), test.ShouldBeNil)
+		test.That(t, target.tabular, test.ShouldHaveLength, 2)
+		test.That(t, target.tabular[1].GetMetadata().GetTimeRequested().AsTime(), test.ShouldEqual, start.Add(time.Second))
+		test.That(t, target.tabular[1].GetStruct().AsMap(), test.ShouldResemble, map[string]interface{}{
+			"position": map[string]interface{}{
+				"x": map[string]interface{}{"min": 10.0, "max": 10.0, "mean": 10.0, "count": 1.0},
+			},
+		})
+	})
+
+	t.Run("aggregates late readings into the current window", func(t *testing.T) {
+		target := &recordingBuffer{}
+		b := NewAggregatingBuffer(target, nil, time.Second, clock.NewMock())
+
+		test.That(t, b.WriteTabular(readingAt(t, start.Add(time.Second), map[string]interface{}{"a": 1})), test.ShouldBeNil)
+		test.That(t, b.WriteTabular(readingAt(t, start, map[string]interface{}{"a": 3})), test.ShouldBeNil)
+		test.That(t, b.WriteTabular(readingAt(t, start.Add(2*time.Second), map[string]interface{}{"a": 5})), test.ShouldBeNil)
+		test.That(t, target.tabular, test.ShouldHaveLength, 1)
+		test.That(t, target.tabular[0].GetStruct().AsMap(), test.ShouldResemble, map[string]interface{}{
+			"a": map[string]interface{}{"min": 1.0, "max": 3.0, "mean": 2.0, "count": 2.0},
+		})
+	})
+
+	t.Run("closing writes the window being aggregated", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		target := &recordingBuffer{}
+		raw := &recordingBuffer{}
+		b := NewAggregatingBuffer(target, raw, time.Second, clk)
+
+		test.That(t, b.WriteTabular(readingAt(t, start, map[string]interface{}{"a": 1})), test.ShouldBeNil)
+		test.That(t, b.Close(), test.ShouldBeNil)
+		test.That(t, target.tabular, test.ShouldHaveLength, 1)
+		test.That(t, target.tabular[0].GetStruct().AsMap(), test.ShouldResemble, map[string]interface{}{
+			"a": map[string]interface{}{"min": 1.0, "max": 1.0, "mean": 1.0, "count": 1.0},
+		})
+		test.That(t, target.flushed, test.ShouldEqual, 1)
+		test.That(t, raw.flushed, test.ShouldEqual, 1)
+	})
+
+	t.Run("writes binary data unchanged", func(t *testing.T) {
+		target := &recordingBuffer{}
+		raw := &recordingBuffer{}
+		b := NewAggregatingBuffer(target, raw, time.Second, clock.NewMock())
+		test.That(t, b.WriteBinary(binarySensorData, "image/jpeg"), test.ShouldBeNil)
+		test.That(t, target.binary, test.ShouldHaveLength, 1)
+		test.That(t, raw.binary, test.ShouldBeEmpty)
+		test.That(t, b.WriteTabular(binarySensorData), test.ShouldBeError, errInvalidTabularSensorData)
+		test.That(t, b.Path(), test.ShouldEqual, "/recording")
+	})
+}
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"io"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -15,10 +16,21 @@ type CaptureBufferedWriter interface {
 	Path() string
 }
 
+// closeCaptureBufferedWriter flushes w for the last time. Writers which hold data back until more
+// is written, e.g: an AggregatingBuffer, implement io.Closer to write it out.
+func closeCaptureBufferedWriter(w CaptureBufferedWriter) error {
+	if closer, ok := w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return w.Flush()
+}
+
 // CaptureBuffer is a persistent queue of SensorData backed by a series of *data.CaptureFile.
 type CaptureBuffer struct {
-	Directory          string
-	MetaData           *v1.DataCaptureMetadata
+	Directory string
+	MetaData  *v1.DataCaptureMetadata
+	// FileVersion is the format version of the files written, CaptureFileV1 when zero.
+	FileVersion        int
 	nextFile           *CaptureFile
 	lock               sync.Mutex
 	maxCaptureFileSize int64
@@ -55,7 +67,7 @@ func (b *CaptureBuffer) WriteBinary(item *v1.SensorData, mimeType string) error
 
 	// assign mime type to DataCaptureMetadata
 	b.MetaData.MimeType = mimeType
-	binFile, err := NewCaptureFile(b.Directory, b.MetaData)
+	binFile, err := b.newCaptureFile()
 	if err != nil {
 		return err
 	}
@@ -85,7 +97,7 @@ func (b *CaptureBuffer) WriteTabular(item *v1.SensorData) error {
 	}
 
 	if b.nextFile == nil {
-		nextFile, err := NewCaptureFile(b.Directory, b.MetaData)
+		nextFile, err := b.newCaptureFile()
 		if err != nil {
 			return err
 		}
@@ -94,7 +106,7 @@ func (b *CaptureBuffer) WriteTabular(item *v1.SensorData) error {
 		if err := b.nextFile.Close(); err != nil {
 			return err
 		}
-		nextFile, err := NewCaptureFile(b.Directory, b.MetaData)
+		nextFile, err := b.newCaptureFile()
 		if err != nil {
 			return err
 		}
@@ -135,6 +147,14 @@ func (b *CaptureBuffer) Flush() error {
 	return nil
 }
 
+func (b *CaptureBuffer) newCaptureFile() (*CaptureFile, error) {
+	version := b.FileVersion
+	if version == 0 {
+		version = CaptureFileV1
+	}
+	return NewCaptureFileWithVersion(b.Directory, b.MetaData, version)
+}
+
 // Path returns the path to the directory containing the backing data capture files.
 func (b *CaptureBuffer) Path() string {
 	return b.Directory
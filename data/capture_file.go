@@ -2,6 +2,7 @@ package data
 
 import (
 	"bufio"
+	"bytes"
 	"io"
 	"os"
 	"path/filepath"
@@ -9,12 +10,12 @@ import (
 	"sync"
 	"time"
 
-	"github.com/matttproud/golang_protobuf_extensions/pbutil"
 	"github.com/pkg/errors"
 	v1 "go.viam.com/api/app/datasync/v1"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
 )
 
 // TODO Data-343: Reorganize this into a more standard interface/package, and add tests.
@@ -38,9 +39,25 @@ const (
 	filePathReservedChars = ":"
 )
 
+// ViamCaptureDotDir is the default directory for capturing and syncing data.
+var ViamCaptureDotDir = filepath.Join(utils.ViamDotDir, "capture")
+
+// Versions of the capture file format. Both are read, the version written is opted into.
+const (
+	// CaptureFileV1 files are length delimited messages, which every version of viam-server reads.
+	CaptureFileV1 = 1
+	// CaptureFileV2 files start with a header, and each message is followed by its checksum, so
+	// that corrupt readings are detected and skipped. Versions of viam-server from before V2 was
+	// added can't read V2 files, which are therefore only written when opted into.
+	CaptureFileV2 = 2
+)
+
 // CaptureFile is the data structure containing data captured by collectors. It is backed by a file on disk containing
 // length delimited protobuf messages, where the first message is the CaptureMetadata for the file, and ensuing
 // messages contain the captured data.
+//
+// CaptureFileV2 files start with a header, and each message is followed by its checksum, see `writeRecord`.
+// CaptureFileV1 files have neither.
 type CaptureFile struct {
 	path     string
 	lock     sync.Mutex
@@ -48,6 +65,8 @@ type CaptureFile struct {
 	writer   *bufio.Writer
 	size     int64
 	metadata *v1.DataCaptureMetadata
+	// checksummed is false for CaptureFileV1 files.
+	checksummed bool
 
 	initialReadOffset int64
 	readOffset        int64
@@ -64,11 +83,23 @@ func ReadCaptureFile(f *os.File) (*CaptureFile, error) {
 		return nil, err
 	}
 
+	checksummed, err := hasCaptureFileHeader(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the header of %s", f.Name())
+	}
+	var initOffset int64
+	if checksummed {
+		initOffset = int64(len(captureFileHeader))
+	}
+	if _, err := f.Seek(initOffset, io.SeekStart); err != nil {
+		return nil, err
+	}
 	md := &v1.DataCaptureMetadata{}
-	initOffset, err := pbutil.ReadDelimited(f, md)
+	read, err := readRecord(f, md, checksummed, finfo.Size()-initOffset)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to read DataCaptureMetadata from %s", f.Name())
 	}
+	initOffset += int64(read)
 
 	ret := CaptureFile{
 		path:              f.Name(),
@@ -76,16 +107,26 @@ func ReadCaptureFile(f *os.File) (*CaptureFile, error) {
 		writer:            bufio.NewWriter(f),
 		size:              finfo.Size(),
 		metadata:          md,
-		initialReadOffset: int64(initOffset),
-		readOffset:        int64(initOffset),
-		writeOffset:       int64(initOffset),
+		checksummed:       checksummed,
+		initialReadOffset: initOffset,
+		readOffset:        initOffset,
+		writeOffset:       initOffset,
 	}
 
 	return &ret, nil
 }
 
-// NewCaptureFile creates a new *CaptureFile with the specified md in the specified directory.
+// NewCaptureFile creates a new CaptureFileV1 *CaptureFile with the specified md in the specified directory.
 func NewCaptureFile(dir string, md *v1.DataCaptureMetadata) (*CaptureFile, error) {
+	return NewCaptureFileWithVersion(dir, md, CaptureFileV1)
+}
+
+// NewCaptureFileWithVersion creates a new *CaptureFile of the given format version with the specified md in the
+// specified directory.
+func NewCaptureFileWithVersion(dir string, md *v1.DataCaptureMetadata, version int) (*CaptureFile, error) {
+	if version != CaptureFileV1 && version != CaptureFileV2 {
+		return nil, errors.Errorf("unknown capture file version %d", version)
+	}
 	fileName := CaptureFilePathWithReplacedReservedChars(
 		filepath.Join(dir, getFileTimestampName()) + InProgressCaptureFileExt)
 	//nolint:gosec
@@ -94,8 +135,16 @@ func NewCaptureFile(dir string, md *v1.DataCaptureMetadata) (*CaptureFile, error
 		return nil, err
 	}
 
-	// Then write first metadata message to the file.
-	n, err := pbutil.WriteDelimited(f, md)
+	// Then write the header and the first metadata message to the file.
+	checksummed := version == CaptureFileV2
+	var header bytes.Buffer
+	if checksummed {
+		header.Write(captureFileHeader)
+	}
+	if _, err := writeRecord(&header, md, checksummed); err != nil {
+		return nil, err
+	}
+	n, err := f.Write(header.Bytes())
 	if err != nil {
 		return nil, err
 	}
@@ -104,6 +153,7 @@ func NewCaptureFile(dir string, md *v1.DataCaptureMetadata) (*CaptureFile, error
 		writer:            bufio.NewWriter(f),
 		file:              f,
 		size:              int64(n),
+		checksummed:       checksummed,
 		initialReadOffset: int64(n),
 		readOffset:        int64(n),
 		writeOffset:       int64(n),
@@ -115,7 +165,8 @@ func (f *CaptureFile) ReadMetadata() *v1.DataCaptureMetadata {
 	return f.metadata
 }
 
-// ReadNext returns the next SensorData reading.
+// ReadNext returns the next SensorData reading. It returns ErrCorruptCaptureRecord if the reading does not match
+// its checksum, in which case the following readings can still be read.
 func (f *CaptureFile) ReadNext() (*v1.SensorData, error) {
 	f.lock.Lock()
 	defer f.lock.Unlock()
@@ -128,7 +179,10 @@ func (f *CaptureFile) ReadNext() (*v1.SensorData, error) {
 		return nil, err
 	}
 	r := v1.SensorData{}
-	read, err := pbutil.ReadDelimited(f.file, &r)
+	read, err := readRecord(f.file, &r, f.checksummed, f.size-f.readOffset)
+	if errors.Is(err, ErrCorruptCaptureRecord) {
+		f.readOffset += int64(read)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -137,6 +191,31 @@ func (f *CaptureFile) ReadNext() (*v1.SensorData, error) {
 	return &r, nil
 }
 
+// ReadNextRaw is ReadNext without decoding the reading. It returns the SensorData reading as an
+// encoded protobuf message, such that readers can skip parts of it, like a large binary payload.
+func (f *CaptureFile) ReadNextRaw() ([]byte, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.writer.Flush(); err != nil {
+		return nil, err
+	}
+
+	if _, err := f.file.Seek(f.readOffset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	msg, read, err := readRecordBytes(f.file, f.checksummed, f.size-f.readOffset)
+	if errors.Is(err, ErrCorruptCaptureRecord) {
+		f.readOffset += int64(read)
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.readOffset += int64(read)
+
+	return msg, nil
+}
+
 // WriteNext writes the next SensorData reading.
 func (f *CaptureFile) WriteNext(data *v1.SensorData) error {
 	f.lock.Lock()
@@ -145,7 +224,7 @@ func (f *CaptureFile) WriteNext(data *v1.SensorData) error {
 	if _, err := f.file.Seek(f.writeOffset, 0); err != nil {
 		return err
 	}
-	n, err := pbutil.WriteDelimited(f.writer, data)
+	n, err := writeRecord(f.writer, data, f.checksummed)
 	if err != nil {
 		return err
 	}
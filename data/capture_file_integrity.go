@@ -0,0 +1,265 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// captureFileHeader starts CaptureFileV2 files, whose records have checksums. CaptureFileV1 files
+// start with the length of their metadata, which is never zero.
+var captureFileHeader = []byte{0x00, 'V', 'C', 'A', 'P', 0x01}
+
+// captureFileChecksumTable is the CRC-32C table of the record checksums.
+var captureFileChecksumTable = crc32.MakeTable(crc32.Castagnoli)
+
+const checksumSize = 4
+
+// ErrCorruptCaptureRecord is returned when reading a capture file record which does not match its
+// checksum.
+var ErrCorruptCaptureRecord = errors.New("capture file record does not match its checksum")
+
+// errInvalidCaptureRecordLength is returned when the length of a capture file record is not a
+// valid uvarint.
+var errInvalidCaptureRecordLength = errors.New("invalid capture file record length")
+
+// errCaptureRecordTooLarge is returned when the length of a capture file record runs past the end of
+// the file, so that its length, or the end of the file, is damaged.
+var errCaptureRecordTooLarge = errors.Wrap(io.ErrUnexpectedEOF, "capture file record runs past the end of the file")
+
+func hasCaptureFileHeader(f *os.File) (bool, error) {
+	header := make([]byte, len(captureFileHeader))
+	n, err := f.ReadAt(header, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	return bytes.Equal(header[:n], captureFileHeader), nil
+}
+
+// writeRecord writes a record of a capture file: the uvarint length of the message, the message,
+// and, for checksummed files, the little endian CRC-32C of the message. It returns the number of
+// bytes written.
+func writeRecord(w io.Writer, m proto.Message, checksummed bool) (int, error) {
+	msg, err := proto.Marshal(m)
+	if err != nil {
+		return 0, err
+	}
+	record := make([]byte, 0, binary.MaxVarintLen64+len(msg)+checksumSize)
+	record = binary.AppendUvarint(record, uint64(len(msg)))
+	record = append(record, msg...)
+	if checksummed {
+		record = binary.LittleEndian.AppendUint32(record, crc32.Checksum(msg, captureFileChecksumTable))
+	}
+	return w.Write(record)
+}
+
+// readRecord reads a record into m, and returns the number of bytes read. Records of files without
+// checksums are length delimited messages. A record of a checksummed file that does not match its
+// checksum returns ErrCorruptCaptureRecord, along with its size so that it can be skipped. remaining
+// bounds the size of the record, it is the number of bytes left in the file.
+func readRecord(r io.Reader, m proto.Message, checksummed bool, remaining int64) (int, error) {
+	msg, read, err := readRecordBytes(r, checksummed, remaining)
+	if err != nil {
+		return read, err
+	}
+	return read, proto.Unmarshal(msg, m)
+}
+
+// readRecordBytes is readRecord without decoding the message.
+func readRecordBytes(r io.Reader, checksummed bool, remaining int64) ([]byte, int, error) {
+	var header [binary.MaxVarintLen64]byte
+	headerSize := 0
+	for {
+		if headerSize == len(header) {
+			return nil, headerSize, errInvalidCaptureRecordLength
+		}
+		if _, err := io.ReadFull(r, header[headerSize:headerSize+1]); err != nil {
+			if headerSize > 0 && errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, headerSize, err
+		}
+		headerSize++
+		if header[headerSize-1] < 0x80 {
+			break
+		}
+	}
+	msgSize, _ := binary.Uvarint(header[:headerSize])
+	recordSize := msgSize
+	if checksummed {
+		recordSize += checksumSize
+	}
+	if recordSize > uint64(max(remaining-int64(headerSize), 0)) {
+		return nil, headerSize, errCaptureRecordTooLarge
+	}
+
+	record := make([]byte, recordSize)
+	n, err := io.ReadFull(r, record)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, headerSize + n, err
+	}
+	msg := record[:msgSize]
+	if checksummed && crc32.Checksum(msg, captureFileChecksumTable) != binary.LittleEndian.Uint32(record[msgSize:]) {
+		return nil, headerSize + n, ErrCorruptCaptureRecord
+	}
+	return msg, headerSize + n, nil
+}
+
+// CaptureFileReport describes the integrity of a capture file.
+type CaptureFileReport struct {
+	// Path is the path of the file. After a repair, it is the path of the repaired file, empty if
+	// the file was deleted as it had no intact readings.
+	Path string
+	// Checksummed is false for CaptureFileV1 files. Corrupt readings of those files can't be told
+	// apart from a truncated file.
+	Checksummed bool
+	// Readings is the number of intact readings.
+	Readings int
+	// CorruptReadings is the number of readings which do not match their checksum.
+	CorruptReadings int
+	// TruncatedBytes is the number of bytes at the end of the file which are not a complete
+	// reading, e.g: as the robot lost power while writing the file.
+	TruncatedBytes int64
+}
+
+// Damaged returns true if the file has corrupt readings, or is truncated.
+func (r CaptureFileReport) Damaged() bool {
+	return r.CorruptReadings > 0 || r.TruncatedBytes > 0
+}
+
+// VerifyCaptureFile reads every reading of the capture file at path. It returns an error if the
+// file isn't a capture file, or if its metadata can't be read.
+func VerifyCaptureFile(path string) (CaptureFileReport, error) {
+	_, report, err := scanCaptureFile(path, func(*v1.SensorData) error { return nil })
+	return report, err
+}
+
+// RepairCaptureFile salvages the intact readings of the capture file at path. A damaged file is
+// replaced by a file of the same version holding its intact readings, or deleted if it has none. Files which
+// are still in progress are marked as completed, so RepairCaptureFile must only be called on files
+// that are no longer being written to, e.g: those left behind when the robot lost power. It returns
+// an error if the file isn't a capture file, or if its metadata can't be read.
+func RepairCaptureFile(path string) (CaptureFileReport, error) {
+	completedPath := strings.TrimSuffix(path, filepath.Ext(path)) + CompletedCaptureFileExt
+	md, report, err := scanCaptureFile(path, func(*v1.SensorData) error { return nil })
+	if err != nil {
+		return report, err
+	}
+
+	if !report.Damaged() {
+		if path != completedPath {
+			if err := os.Rename(path, completedPath); err != nil {
+				return report, err
+			}
+		}
+		report.Path = completedPath
+		return report, nil
+	}
+
+	if report.Readings == 0 {
+		report.Path = ""
+		return report, os.Remove(path)
+	}
+
+	// The intact readings are written to an in progress file, so that they aren't synced if the
+	// repair is interrupted, and then marked as completed in place of the damaged file.
+	repairedPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".repaired" + InProgressCaptureFileExt
+	//nolint:gosec
+	repaired, err := os.OpenFile(repairedPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return report, err
+	}
+	w := bufio.NewWriter(repaired)
+	write := func(m proto.Message) error {
+		_, err := writeRecord(w, m, report.Checksummed)
+		return err
+	}
+	writeErr := func() error {
+		if report.Checksummed {
+			if _, err := w.Write(captureFileHeader); err != nil {
+				return err
+			}
+		}
+		if err := write(md); err != nil {
+			return err
+		}
+		_, _, err := scanCaptureFile(path, func(reading *v1.SensorData) error {
+			return write(reading)
+		})
+		if err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		return repaired.Sync()
+	}()
+	if err := repaired.Close(); err != nil && writeErr == nil {
+		writeErr = err
+	}
+	if writeErr != nil {
+		//nolint:errcheck
+		os.Remove(repairedPath)
+		return report, writeErr
+	}
+
+	if err := os.Remove(path); err != nil {
+		return report, err
+	}
+	if err := os.Rename(repairedPath, completedPath); err != nil {
+		return report, err
+	}
+	report.Path = completedPath
+	return report, nil
+}
+
+// scanCaptureFile calls fn with each intact reading of the capture file at path.
+func scanCaptureFile(path string, fn func(*v1.SensorData) error) (*v1.DataCaptureMetadata, CaptureFileReport, error) {
+	report := CaptureFileReport{Path: path}
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, report, err
+	}
+	// The file is only read. `CaptureFile.Close` would mark it as completed.
+	//nolint:errcheck
+	defer f.Close()
+
+	captureFile, err := ReadCaptureFile(f)
+	if err != nil {
+		return nil, report, err
+	}
+	report.Checksummed = captureFile.checksummed
+	for {
+		reading, err := captureFile.ReadNext()
+		switch {
+		case err == nil:
+			report.Readings++
+			if err := fn(reading); err != nil {
+				return nil, report, err
+			}
+		case errors.Is(err, io.EOF):
+			return captureFile.ReadMetadata(), report, nil
+		case errors.Is(err, ErrCorruptCaptureRecord):
+			report.CorruptReadings++
+		case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, errInvalidCaptureRecordLength), errors.Is(err, proto.Error):
+			// The rest of the file can't be read, be it truncated or corrupt.
+			report.TruncatedBytes = captureFile.size - captureFile.readOffset
+			return captureFile.ReadMetadata(), report, nil
+		default:
+			return nil, report, err
+		}
+	}
+}
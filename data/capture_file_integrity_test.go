@@ -0,0 +1,206 @@
+package data
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/resource"
+)
+
+func integrityTestReading(t *testing.T, value float64) *v1.SensorData {
+	t.Helper()
+	s, err := structpb.NewStruct(map[string]interface{}{"value": value})
+	test.That(t, err, test.ShouldBeNil)
+	return &v1.SensorData{Metadata: &v1.SensorMetadata{}, Data: &v1.SensorData_Struct{Struct: s}}
+}
+
+// writeIntegrityTestFile writes a completed capture file with a reading per value.
+func writeIntegrityTestFile(t *testing.T, dir string, values ...float64) string {
+	t.Helper()
+	md, _ := BuildCaptureMetadata(resource.APINamespaceRDK.WithComponentType("sensor"), "sensor-1", "Readings", nil, nil, nil)
+	f, err := NewCaptureFileWithVersion(dir, md, CaptureFileV2)
+	test.That(t, err, test.ShouldBeNil)
+	for _, value := range values {
+		test.That(t, f.WriteNext(integrityTestReading(t, value)), test.ShouldBeNil)
+	}
+	test.That(t, f.Close(), test.ShouldBeNil)
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+CompletedCaptureFileExt))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, paths, test.ShouldHaveLength, 1)
+	return paths[0]
+}
+
+func integrityTestValues(t *testing.T, path string) []float64 {
+	t.Helper()
+	readings, err := SensorDataFromCaptureFilePath(path)
+	test.That(t, err, test.ShouldBeNil)
+	var ret []float64
+	for _, reading := range readings {
+		ret = append(ret, reading.GetStruct().GetFields()["value"].GetNumberValue())
+	}
+	return ret
+}
+
+func TestCaptureFileIntegrity(t *testing.T) {
+	t.Run("intact files are not damaged", func(t *testing.T) {
+		path := writeIntegrityTestFile(t, t.TempDir(), 1, 2, 3)
+		report, err := VerifyCaptureFile(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, report, test.ShouldResemble, CaptureFileReport{Path: path, Checksummed: true, Readings: 3})
+		test.That(t, report.Damaged(), test.ShouldBeFalse)
+		test.That(t, integrityTestValues(t, path), test.ShouldResemble, []float64{1, 2, 3})
+	})
+
+	t.Run("corrupt readings are skipped and repaired", func(t *testing.T) {
+		path := writeIntegrityTestFile(t, t.TempDir(), 1, 2, 3)
+		contents, err := os.ReadFile(path)
+		test.That(t, err, test.ShouldBeNil)
+		// Flip a bit of the value of the second reading.
+		idx := bytes.LastIndex(contents, integrityTestValueBytes(t, 2))
+		test.That(t, idx, test.ShouldBeGreaterThan, 0)
+		contents[idx+len(integrityTestValueBytes(t, 2))-1] ^= 0x01
+		test.That(t, os.WriteFile(path, contents, 0o600), test.ShouldBeNil)
+
+		f, err := os.Open(path)
+		test.That(t, err, test.ShouldBeNil)
+		defer f.Close()
+		captureFile, err := ReadCaptureFile(f)
+		test.That(t, err, test.ShouldBeNil)
+		_, err = captureFile.ReadNext()
+		test.That(t, err, test.ShouldBeNil)
+		_, err = captureFile.ReadNext()
+		test.That(t, err, test.ShouldBeError, ErrCorruptCaptureRecord)
+		third, err := captureFile.ReadNext()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, third.GetStruct().GetFields()["value"].GetNumberValue(), test.ShouldEqual, 3)
+
+		report, err := VerifyCaptureFile(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, report, test.ShouldResemble, CaptureFileReport{Path: path, Checksummed: true, Readings: 2, CorruptReadings: 1})
+
+		report, err = RepairCaptureFile(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, report.Path, test.ShouldEqual, path)
+		test.That(t, integrityTestValues(t, path), test.ShouldResemble, []float64{1, 3})
+		report, err = VerifyCaptureFile(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, report.Damaged(), test.ShouldBeFalse)
+	})
+
+	t.Run("truncated in progress files are repaired and completed", func(t *testing.T) {
+		dir := t.TempDir()
+		completed := writeIntegrityTestFile(t, dir, 1, 2, 3)
+		info, err := os.Stat(completed)
+		test.That(t, err, test.ShouldBeNil)
+		inProgress := completed[:len(completed)-len(CompletedCaptureFileExt)] + InProgressCaptureFileExt
+		test.That(t, os.Rename(completed, inProgress), test.ShouldBeNil)
+		test.That(t, os.Truncate(inProgress, info.Size()-2), test.ShouldBeNil)
+
+		report, err := VerifyCaptureFile(inProgress)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, report.Readings, test.ShouldEqual, 2)
+		test.That(t, report.TruncatedBytes, test.ShouldBeGreaterThan, 0)
+
+		report, err = RepairCaptureFile(inProgress)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, report.Path, test.ShouldEqual, completed)
+		test.That(t, integrityTestValues(t, completed), test.ShouldResemble, []float64{1, 2})
+		_, err = os.Stat(inProgress)
+		test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+	})
+
+	t.Run("files without intact readings are deleted", func(t *testing.T) {
+		path := writeIntegrityTestFile(t, t.TempDir(), 1)
+		info, err := os.Stat(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, os.Truncate(path, info.Size()-1), test.ShouldBeNil)
+
+		report, err := RepairCaptureFile(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, report.Path, test.ShouldBeEmpty)
+		_, err = os.Stat(path)
+		test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+	})
+
+	t.Run("files without checksums are read and repaired", func(t *testing.T) {
+		// Files written before checksums were added are length delimited messages.
+		var contents bytes.Buffer
+		md, _ := BuildCaptureMetadata(resource.APINamespaceRDK.WithComponentType("sensor"), "sensor-1", "Readings", nil, nil, nil)
+		_, err := pbutil.WriteDelimited(&contents, md)
+		test.That(t, err, test.ShouldBeNil)
+		for _, value := range []float64{1, 2} {
+			_, err := pbutil.WriteDelimited(&contents, integrityTestReading(t, value))
+			test.That(t, err, test.ShouldBeNil)
+		}
+		path := filepath.Join(t.TempDir(), "old"+InProgressCaptureFileExt)
+		test.That(t, os.WriteFile(path, contents.Bytes()[:contents.Len()-1], 0o600), test.ShouldBeNil)
+
+		report, err := VerifyCaptureFile(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, report.Checksummed, test.ShouldBeFalse)
+		test.That(t, report.Readings, test.ShouldEqual, 1)
+		test.That(t, report.TruncatedBytes, test.ShouldBeGreaterThan, 0)
+
+		report, err = RepairCaptureFile(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, integrityTestValues(t, report.Path), test.ShouldResemble, []float64{1})
+		// Repaired files keep their version, so that they are read by older versions of viam-server.
+		report, err = VerifyCaptureFile(report.Path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, report.Checksummed, test.ShouldBeFalse)
+	})
+
+	t.Run("files are written without checksums unless opted into", func(t *testing.T) {
+		dir := t.TempDir()
+		md, _ := BuildCaptureMetadata(resource.APINamespaceRDK.WithComponentType("sensor"), "sensor-1", "Readings", nil, nil, nil)
+		f, err := NewCaptureFile(dir, md)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, f.WriteNext(integrityTestReading(t, 1)), test.ShouldBeNil)
+		test.That(t, f.Close(), test.ShouldBeNil)
+		paths, err := filepath.Glob(filepath.Join(dir, "*"+CompletedCaptureFileExt))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, paths, test.ShouldHaveLength, 1)
+
+		// Older versions of viam-server read length delimited messages.
+		contents, err := os.ReadFile(paths[0])
+		test.That(t, err, test.ShouldBeNil)
+		r := bytes.NewReader(contents)
+		var readMD v1.DataCaptureMetadata
+		_, err = pbutil.ReadDelimited(r, &readMD)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readMD.GetComponentName(), test.ShouldEqual, "sensor-1")
+		var reading v1.SensorData
+		_, err = pbutil.ReadDelimited(r, &reading)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, reading.GetStruct().GetFields()["value"].GetNumberValue(), test.ShouldEqual, 1)
+		test.That(t, r.Len(), test.ShouldEqual, 0)
+
+		_, err = NewCaptureFileWithVersion(dir, md, 3)
+		test.That(t, err, test.ShouldBeError, "unknown capture file version 3")
+	})
+
+	t.Run("files without metadata can't be repaired", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "empty"+InProgressCaptureFileExt)
+		test.That(t, os.WriteFile(path, nil, 0o600), test.ShouldBeNil)
+		_, err := RepairCaptureFile(path)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+// integrityTestValueBytes returns the encoding of the value of a reading written by
+// integrityTestReading.
+func integrityTestValueBytes(t *testing.T, value float64) []byte {
+	t.Helper()
+	var contents bytes.Buffer
+	_, err := writeRecord(&contents, structpb.NewNumberValue(value), true)
+	test.That(t, err, test.ShouldBeNil)
+	// Strip the length and the checksum of the record.
+	return contents.Bytes()[1 : contents.Len()-checksumSize]
+}
@@ -0,0 +1,108 @@
+package data
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"go.viam.com/utils"
+)
+
+// CaptureGroupTagPrefix prefixes the tag of the capture files of the members of a capture group.
+const CaptureGroupTagPrefix = "capture_group:"
+
+// CaptureGroupTag returns the tag of the capture files of the members of the named capture group.
+func CaptureGroupTag(name string) string {
+	return CaptureGroupTagPrefix + name
+}
+
+// CaptureGroup samples the collectors of several resource methods on one shared tick, rather than
+// on a ticker of their own, so that their readings are aligned: the readings captured on a tick
+// share the tick's time as their time requested.
+//
+// A collector that is still capturing when a tick happens misses that tick, rather than capturing
+// late, so that the readings of a tick are never further apart than the capture interval.
+type CaptureGroup struct {
+	name     string
+	interval time.Duration
+	clock    clock.Clock
+
+	mu          sync.Mutex
+	subscribers map[chan time.Time]struct{}
+
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+}
+
+// NewCaptureGroup returns a new CaptureGroup which ticks every interval until closed.
+func NewCaptureGroup(name string, interval time.Duration, clk clock.Clock) *CaptureGroup {
+	if clk == nil {
+		clk = clock.New()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &CaptureGroup{
+		name:        name,
+		interval:    interval,
+		clock:       clk,
+		subscribers: map[chan time.Time]struct{}{},
+		cancel:      cancel,
+	}
+	// The ticker must be created before returning, lest unittests advance a mock clock before the
+	// ticker makes its initial clock reading.
+	ticker := clk.Ticker(interval)
+	g.workers.Add(1)
+	utils.ManagedGo(func() { g.tick(ctx, ticker) }, g.workers.Done)
+	return g
+}
+
+// Name returns the name of the capture group.
+func (g *CaptureGroup) Name() string {
+	return g.name
+}
+
+// Interval returns the capture interval of the capture group.
+func (g *CaptureGroup) Interval() time.Duration {
+	return g.interval
+}
+
+// Close stops the ticks of the capture group.
+func (g *CaptureGroup) Close() {
+	g.cancel()
+	g.workers.Wait()
+}
+
+func (g *CaptureGroup) tick(ctx context.Context, ticker *clock.Ticker) {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case tick := <-ticker.C:
+			// All the members of a tick share its time, regardless of when they receive it.
+			g.mu.Lock()
+			for subscriber := range g.subscribers {
+				select {
+				case subscriber <- tick:
+				default:
+					// The subscriber is still capturing the previous tick.
+				}
+			}
+			g.mu.Unlock()
+		}
+	}
+}
+
+// subscribe returns the channel the ticks of the capture group are sent to, and a func to
+// unsubscribe.
+func (g *CaptureGroup) subscribe() (<-chan time.Time, func()) {
+	ticks := make(chan time.Time)
+	g.mu.Lock()
+	g.subscribers[ticks] = struct{}{}
+	g.mu.Unlock()
+	return ticks, func() {
+		g.mu.Lock()
+		delete(g.subscribers, ticks)
+		g.mu.Unlock()
+	}
+}
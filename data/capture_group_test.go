@@ -0,0 +1,82 @@
+package data
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestCaptureGroup(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	mockClock := clock.NewMock()
+	interval := 10 * time.Millisecond
+	group := NewCaptureGroup("teleop", interval, mockClock)
+	defer group.Close()
+	test.That(t, CaptureGroupTag(group.Name()), test.ShouldEqual, "capture_group:teleop")
+
+	var dirs []string
+	var targets []*signalingBuffer
+	var collectors []Collector
+	for _, name := range []string{"camera", "arm"} {
+		dir := t.TempDir()
+		target := newSignalingBuffer(ctx, dir)
+		c, err := NewCollector(structCapturer, CollectorParams{
+			DataType:      CaptureTypeTabular,
+			ComponentName: name,
+			// The interval of the members of a group is ignored.
+			Interval:   time.Hour,
+			Group:      group,
+			Target:     target,
+			QueueSize:  queueSize,
+			BufferSize: bufferSize,
+			Logger:     logging.NewTestLogger(t),
+			Clock:      mockClock,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		c.Collect()
+		dirs = append(dirs, dir)
+		targets = append(targets, target)
+		collectors = append(collectors, c)
+	}
+	// Give the collectors time to wait for the first tick, see TestSuccessfulWrite.
+	time.Sleep(10 * time.Millisecond)
+
+	var ticks []time.Time
+	for i := 0; i < 2; i++ {
+		mockClock.Add(interval)
+		ticks = append(ticks, mockClock.Now())
+		for _, target := range targets {
+			select {
+			case <-ctx.Done():
+				t.Fatalf("timed out waiting for data to be written")
+			case <-target.wrote:
+			}
+		}
+	}
+	for _, c := range collectors {
+		c.Close()
+	}
+
+	// The readings of a tick share its time, regardless of when they were captured.
+	for _, dir := range dirs {
+		var readingTimes []time.Time
+		for _, file := range getAllFiles(dir) {
+			readings, err := SensorDataFromCaptureFilePath(filepath.Join(dir, file.Name()))
+			test.That(t, err, test.ShouldBeNil)
+			for _, reading := range readings {
+				readingTimes = append(readingTimes, reading.GetMetadata().GetTimeRequested().AsTime())
+			}
+		}
+		test.That(t, len(readingTimes), test.ShouldEqual, len(ticks))
+		for i, tick := range ticks {
+			test.That(t, readingTimes[i].Equal(tick), test.ShouldBeTrue)
+		}
+	}
+}
@@ -53,6 +53,12 @@ type TabularDataBson struct {
 	Data          bson.M    `bson:"data"`
 }
 
+// TabularDataStore stores the tabular readings of collectors, e.g: an embedded database on the
+// machine.
+type TabularDataStore interface {
+	InsertTabularData(ctx context.Context, td TabularDataBson) error
+}
+
 // Collector collects data to some target.
 type Collector interface {
 	Close()
@@ -65,6 +71,7 @@ type collector struct {
 
 	captureResults  chan CaptureResult
 	mongoCollection *mongo.Collection
+	tabularStore    TabularDataStore
 	componentName   string
 	componentType   string
 	methodName      string
@@ -82,6 +89,8 @@ type collector struct {
 	target           CaptureBufferedWriter
 	lastLoggedErrors map[string]int64
 	dataType         CaptureType
+	// group, when set, ticks the collector instead of its own interval.
+	group *CaptureGroup
 }
 
 // Close closes the channels backing the Collector. It should always be called before disposing of a Collector to avoid
@@ -97,7 +106,11 @@ func (c *collector) Close() {
 	// `Wait` on them before acquiring the lock to avoid deadlock.
 	c.captureWorkers.Wait()
 
-	c.Flush()
+	c.lock.Lock()
+	if err := closeCaptureBufferedWriter(c.target); err != nil {
+		c.logger.Errorw("failed to close collector target", "error", err)
+	}
+	c.lock.Unlock()
 
 	close(c.captureErrors)
 	c.logRoutine.Wait()
@@ -136,7 +149,9 @@ func (c *collector) Collect() {
 // avoid wasting CPU on a thread that's idling for the vast majority of the time.
 // [0]: https://www.mail-archive.com/golang-nuts@googlegroups.com/msg46002.html
 func (c *collector) capture(started chan struct{}) {
-	if c.interval < sleepCaptureCutoff {
+	if c.group != nil {
+		c.groupBasedCapture(started)
+	} else if c.interval < sleepCaptureCutoff {
 		c.sleepBasedCapture(started)
 	} else {
 		c.tickerBasedCapture(started)
@@ -157,7 +172,7 @@ func (c *collector) sleepBasedCapture(started chan struct{}) {
 			return
 		}
 
-		c.getAndPushNextReading()
+		c.getAndPushNextReading(time.Time{})
 		next = next.Add(c.interval)
 		until = c.clock.Until(next)
 	}
@@ -177,7 +192,28 @@ func (c *collector) tickerBasedCapture(started chan struct{}) {
 		case <-c.cancelCtx.Done():
 			return
 		case <-ticker.C:
-			c.getAndPushNextReading()
+			c.getAndPushNextReading(time.Time{})
+		}
+	}
+}
+
+// groupBasedCapture captures on the ticks of the collector's capture group. The time requested of
+// the readings is the time of the tick, which the readings of the other members of the group share.
+func (c *collector) groupBasedCapture(started chan struct{}) {
+	ticks, unsubscribe := c.group.subscribe()
+	defer unsubscribe()
+
+	close(started)
+	for {
+		if err := c.cancelCtx.Err(); err != nil {
+			return
+		}
+
+		select {
+		case <-c.cancelCtx.Done():
+			return
+		case tick := <-ticks:
+			c.getAndPushNextReading(tick)
 		}
 	}
 }
@@ -201,7 +237,9 @@ func (c *collector) validateReadingType(t CaptureType) error {
 	}
 }
 
-func (c *collector) getAndPushNextReading() {
+// getAndPushNextReading captures a reading, tick is the time of the capture group tick it is
+// captured on, and is zero for collectors which aren't in a capture group.
+func (c *collector) getAndPushNextReading(tick time.Time) {
 	result, err := c.captureFunc(c.cancelCtx, c.params)
 
 	if c.cancelCtx.Err() != nil {
@@ -227,6 +265,10 @@ func (c *collector) getAndPushNextReading() {
 		return
 	}
 
+	if !tick.IsZero() {
+		result.TimeRequested = tick
+	}
+
 	select {
 	// If c.captureResults is full, c.captureResults <- a can block indefinitely.
 	// This additional select block allows cancel to
@@ -255,6 +297,7 @@ func NewCollector(captureFunc CaptureFunc, params CollectorParams) (Collector, e
 		componentType:    params.ComponentType,
 		methodName:       params.MethodName,
 		mongoCollection:  params.MongoCollection,
+		tabularStore:     params.TabularStore,
 		captureResults:   make(chan CaptureResult, params.QueueSize),
 		captureErrors:    make(chan error, params.QueueSize),
 		dataType:         params.DataType,
@@ -267,6 +310,7 @@ func NewCollector(captureFunc CaptureFunc, params CollectorParams) (Collector, e
 		target:           params.Target,
 		clock:            c,
 		lastLoggedErrors: make(map[string]int64, 0),
+		group:            params.Group,
 	}, nil
 }
 
@@ -312,16 +356,16 @@ func (c *collector) writeCaptureResults() {
 				return
 			}
 
-			c.maybeWriteToMongo(msg)
+			c.maybeWriteToTabularStores(msg)
 		}
 	}
 }
 
-// maybeWriteToMongo will write to the mongoCollection
-// if it is non-nil and the msg is tabular data
+// maybeWriteToTabularStores will write to the mongoCollection and the tabularStore
+// if they are non-nil and the msg is tabular data
 // logs errors on failure.
-func (c *collector) maybeWriteToMongo(msg CaptureResult) {
-	if c.mongoCollection == nil {
+func (c *collector) maybeWriteToTabularStores(msg CaptureResult) {
+	if c.mongoCollection == nil && c.tabularStore == nil {
 		return
 	}
 
@@ -349,8 +393,15 @@ func (c *collector) maybeWriteToMongo(msg CaptureResult) {
 		Data:          data,
 	}
 
-	if _, err := c.mongoCollection.InsertOne(c.cancelCtx, td); err != nil {
-		c.logger.Error(errors.Wrap(err, "failed to write to mongo"))
+	if c.mongoCollection != nil {
+		if _, err := c.mongoCollection.InsertOne(c.cancelCtx, td); err != nil {
+			c.logger.Error(errors.Wrap(err, "failed to write to mongo"))
+		}
+	}
+	if c.tabularStore != nil {
+		if err := c.tabularStore.InsertTabularData(c.cancelCtx, td); err != nil {
+			c.logger.Error(errors.Wrap(err, "failed to write to tabular data store"))
+		}
 	}
 }
 
@@ -252,6 +252,43 @@ func TestClose(t *testing.T) {
 	}
 }
 
+func TestCloseWritesAggregatedWindow(t *testing.T) {
+	l := logging.NewTestLogger(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	mockClock := clock.NewMock()
+	raw := newSignalingBuffer(ctx, t.TempDir())
+	target := &recordingBuffer{}
+	interval := time.Millisecond * 5
+
+	params := CollectorParams{
+		DataType:      CaptureTypeTabular,
+		ComponentName: "testComponent",
+		Interval:      interval,
+		MethodParams:  map[string]*anypb.Any{"name": fakeVal},
+		Target:        NewAggregatingBuffer(target, raw, time.Hour, mockClock),
+		QueueSize:     queueSize,
+		BufferSize:    bufferSize,
+		Logger:        l,
+		Clock:         mockClock,
+	}
+	c, err := NewCollector(structCapturer, params)
+	test.That(t, err, test.ShouldBeNil)
+
+	c.Collect()
+	mockClock.Add(interval)
+	select {
+	case <-ctx.Done():
+		t.Fatalf("timed out waiting for data to be written")
+	case <-raw.wrote:
+	}
+
+	// The window has not ended, closing writes it anyway.
+	c.Close()
+	test.That(t, target.tabular, test.ShouldHaveLength, 1)
+	test.That(t, target.flushed, test.ShouldEqual, 1)
+}
+
 // TestCtxCancelledNotLoggedAfterClose verifies that context cancelled errors are not logged if they occur after Close
 // has been called. The collector context is cancelled as part of Close, so we expect to see context cancelled errors
 // for any running capture routines.
@@ -0,0 +1,151 @@
+package data
+
+import (
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// PreRollBuffer is a CaptureBufferedWriter which keeps the data captured during the last pre-roll
+// in memory rather than writing it to its target. When triggered, the buffered data is written to
+// the target, as is the data captured until the post-roll elapses.
+type PreRollBuffer struct {
+	target   CaptureBufferedWriter
+	clock    clock.Clock
+	preRoll  time.Duration
+	postRoll time.Duration
+	// maxBufferedBytes bounds the memory used by the buffered data. The oldest data is dropped first.
+	// It is unbounded when zero.
+	maxBufferedBytes int
+
+	mu            sync.Mutex
+	buffered      []preRollItem
+	bufferedBytes int
+	persistUntil  time.Time
+}
+
+type preRollItem struct {
+	item       *v1.SensorData
+	mimeType   string
+	isBinary   bool
+	size       int
+	capturedAt time.Time
+}
+
+// NewPreRollBuffer returns a new PreRollBuffer that writes to target when triggered.
+func NewPreRollBuffer(
+	target CaptureBufferedWriter,
+	preRoll, postRoll time.Duration,
+	maxBufferedBytes int,
+	clk clock.Clock,
+) *PreRollBuffer {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &PreRollBuffer{
+		target:           target,
+		clock:            clk,
+		preRoll:          preRoll,
+		postRoll:         postRoll,
+		maxBufferedBytes: maxBufferedBytes,
+	}
+}
+
+// WriteBinary buffers the item, or writes it to the target during a post-roll.
+func (b *PreRollBuffer) WriteBinary(item *v1.SensorData, mimeType string) error {
+	if !IsBinary(item) {
+		return errInvalidBinarySensorData
+	}
+	return b.write(preRollItem{item: item, mimeType: mimeType, isBinary: true})
+}
+
+// WriteTabular buffers the item, or writes it to the target during a post-roll.
+func (b *PreRollBuffer) WriteTabular(item *v1.SensorData) error {
+	if IsBinary(item) {
+		return errInvalidTabularSensorData
+	}
+	return b.write(preRollItem{item: item})
+}
+
+func (b *PreRollBuffer) write(item preRollItem) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	if now.Before(b.persistUntil) {
+		return b.writeToTarget(item)
+	}
+
+	item.size = proto.Size(item.item)
+	item.capturedAt = now
+	b.buffered = append(b.buffered, item)
+	b.bufferedBytes += item.size
+	b.evict(now)
+	return nil
+}
+
+// evict drops the buffered items captured before the pre-roll, and the oldest items over the
+// memory bound.
+func (b *PreRollBuffer) evict(now time.Time) {
+	cutoff := now.Add(-b.preRoll)
+	evicted := 0
+	for _, item := range b.buffered {
+		overMaxBytes := b.maxBufferedBytes > 0 && b.bufferedBytes > b.maxBufferedBytes
+		if !item.capturedAt.Before(cutoff) && !overMaxBytes {
+			break
+		}
+		b.bufferedBytes -= item.size
+		evicted++
+	}
+	if evicted == 0 {
+		return
+	}
+	// Release the evicted items for garbage collection.
+	clear(b.buffered[:evicted])
+	b.buffered = b.buffered[evicted:]
+}
+
+// Trigger writes the buffered data to the target, and writes the data captured until the
+// post-roll elapses directly to the target. Triggering during a post-roll extends it.
+func (b *PreRollBuffer) Trigger() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if persistUntil := b.clock.Now().Add(b.postRoll); persistUntil.After(b.persistUntil) {
+		b.persistUntil = persistUntil
+	}
+	buffered := b.buffered
+	b.buffered = nil
+	b.bufferedBytes = 0
+	for _, item := range buffered {
+		if err := b.writeToTarget(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *PreRollBuffer) writeToTarget(item preRollItem) error {
+	if item.isBinary {
+		return b.target.WriteBinary(item.item, item.mimeType)
+	}
+	return b.target.WriteTabular(item.item)
+}
+
+// Flush flushes the data written to the target. Buffered data is not written.
+func (b *PreRollBuffer) Flush() error {
+	return b.target.Flush()
+}
+
+// Close closes the target. The data buffered for a trigger which never came is dropped.
+func (b *PreRollBuffer) Close() error {
+	return closeCaptureBufferedWriter(b.target)
+}
+
+// Path returns the path of the target.
+func (b *PreRollBuffer) Path() string {
+	return b.target.Path()
+}
@@ -0,0 +1,106 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/proto"
+)
+
+// recordingBuffer records the items written to it.
+type recordingBuffer struct {
+	tabular   []*v1.SensorData
+	binary    []*v1.SensorData
+	mimeTypes []string
+	flushed   int
+}
+
+func (b *recordingBuffer) WriteBinary(item *v1.SensorData, mimeType string) error {
+	b.binary = append(b.binary, item)
+	b.mimeTypes = append(b.mimeTypes, mimeType)
+	return nil
+}
+
+func (b *recordingBuffer) WriteTabular(item *v1.SensorData) error {
+	b.tabular = append(b.tabular, item)
+	return nil
+}
+
+func (b *recordingBuffer) Flush() error {
+	b.flushed++
+	return nil
+}
+
+func (b *recordingBuffer) Path() string {
+	return "/recording"
+}
+
+func TestPreRollBuffer(t *testing.T) {
+	t.Run("buffers data until triggered", func(t *testing.T) {
+		clk := clock.NewMock()
+		target := &recordingBuffer{}
+		b := NewPreRollBuffer(target, 2*time.Second, time.Second, 0, clk)
+
+		for i := 0; i < 4; i++ {
+			test.That(t, b.WriteTabular(structSensorData), test.ShouldBeNil)
+			clk.Add(time.Second)
+		}
+		test.That(t, b.WriteBinary(binarySensorData, "image/jpeg"), test.ShouldBeNil)
+		test.That(t, target.tabular, test.ShouldBeEmpty)
+		test.That(t, target.binary, test.ShouldBeEmpty)
+
+		// Flushing does not persist the buffered data.
+		test.That(t, b.Flush(), test.ShouldBeNil)
+		test.That(t, target.flushed, test.ShouldEqual, 1)
+		test.That(t, target.tabular, test.ShouldBeEmpty)
+
+		// Only the data captured during the last 2 seconds is written.
+		test.That(t, b.Trigger(), test.ShouldBeNil)
+		test.That(t, target.tabular, test.ShouldHaveLength, 2)
+		test.That(t, target.binary, test.ShouldHaveLength, 1)
+		test.That(t, target.mimeTypes, test.ShouldResemble, []string{"image/jpeg"})
+		test.That(t, b.Path(), test.ShouldEqual, "/recording")
+	})
+
+	t.Run("writes data directly during the post-roll", func(t *testing.T) {
+		clk := clock.NewMock()
+		target := &recordingBuffer{}
+		b := NewPreRollBuffer(target, time.Second, 2*time.Second, 0, clk)
+
+		test.That(t, b.Trigger(), test.ShouldBeNil)
+		test.That(t, b.WriteTabular(structSensorData), test.ShouldBeNil)
+		clk.Add(time.Second)
+		test.That(t, b.WriteTabular(structSensorData), test.ShouldBeNil)
+		test.That(t, target.tabular, test.ShouldHaveLength, 2)
+
+		// Triggering again extends the post-roll.
+		test.That(t, b.Trigger(), test.ShouldBeNil)
+		clk.Add(1500 * time.Millisecond)
+		test.That(t, b.WriteTabular(structSensorData), test.ShouldBeNil)
+		test.That(t, target.tabular, test.ShouldHaveLength, 3)
+
+		clk.Add(time.Second)
+		test.That(t, b.WriteTabular(structSensorData), test.ShouldBeNil)
+		test.That(t, target.tabular, test.ShouldHaveLength, 3)
+	})
+
+	t.Run("drops the oldest data over the max buffered bytes", func(t *testing.T) {
+		target := &recordingBuffer{}
+		b := NewPreRollBuffer(target, time.Minute, 0, 2*proto.Size(binarySensorData), clock.NewMock())
+
+		for i := 0; i < 5; i++ {
+			test.That(t, b.WriteBinary(binarySensorData, "image/jpeg"), test.ShouldBeNil)
+		}
+		test.That(t, b.Trigger(), test.ShouldBeNil)
+		test.That(t, target.binary, test.ShouldHaveLength, 2)
+	})
+
+	t.Run("rejects data of the wrong type", func(t *testing.T) {
+		b := NewPreRollBuffer(&recordingBuffer{}, time.Minute, 0, 0, clock.NewMock())
+		test.That(t, b.WriteBinary(structSensorData, ""), test.ShouldBeError, errInvalidBinarySensorData)
+		test.That(t, b.WriteTabular(binarySensorData), test.ShouldBeError, errInvalidTabularSensorData)
+	})
+}
@@ -24,12 +24,14 @@ type CollectorParams struct {
 	ComponentName   string
 	ComponentType   string
 	DataType        CaptureType
+	Group           *CaptureGroup
 	Interval        time.Duration
 	Logger          logging.Logger
 	MethodName      string
 	MethodParams    map[string]*anypb.Any
 	MongoCollection *mongo.Collection
 	QueueSize       int
+	TabularStore    TabularDataStore
 	Target          CaptureBufferedWriter
 }
 
@@ -10,8 +10,9 @@ import (
 func main() {
 	if len(os.Args) < 2 {
 		parser.NolintPrintln("Expected an FTDC filename. E.g: go run parser.go <path-to>/viam-server.ftdc")
+		parser.NolintPrintln("Pass multiple filenames or directories to compare them. E.g: go run parser.go <part-a-dir> <part-b-dir>")
 		return
 	}
 
-	parser.LaunchREPL(os.Args[1])
+	parser.LaunchREPL(os.Args[1:]...)
 }
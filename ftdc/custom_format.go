@@ -21,6 +21,8 @@ const (
 	epsilon = 1e-9
 	// nsInADay is the number of nanoseconds in a day.
 	nsInADay = 8.64e13
+	// annotationIdentifier is the first byte of an annotation document.
+	annotationIdentifier = 0x3
 )
 
 type schema struct {
@@ -376,12 +378,21 @@ func Parse(rawReader io.Reader) ([]FlatDatum, int64, error) {
 // ParseWithLogger parses with a logger for output. It returns a slice of flat datums and
 // the last timestamp that was read. The latter is useful for determining the timestamp of
 // the file boundary.
-func ParseWithLogger(rawReader io.Reader, logger logging.Logger) (
+func ParseWithLogger(rawReader io.Reader, logger logging.Logger) ([]FlatDatum, int64, error) {
+	ret, _, lastTimestampRead, err := ParseWithAnnotations(rawReader, logger)
+	return ret, lastTimestampRead, err
+}
+
+// ParseWithAnnotations is `ParseWithLogger` that additionally returns the annotations written by
+// FTDC rules, in the order they were written.
+func ParseWithAnnotations(rawReader io.Reader, logger logging.Logger) (
 	ret []FlatDatum,
+	annotations []Annotation,
 	lastTimestampRead int64,
 	retErr error,
 ) {
 	ret = make([]FlatDatum, 0)
+	annotations = make([]Annotation, 0)
 
 	// prevValues are the previous values used for producing the diff bits. This is overwritten when
 	// a new metrics reading is made. and nilled out when the schema changes.
@@ -390,6 +401,16 @@ func ParseWithLogger(rawReader io.Reader, logger logging.Logger) (
 	// bufio's Reader allows for peeking and potentially better control over how much data to read
 	// from disk at a time.
 	reader := bufio.NewReader(rawReader)
+
+	// Compressed FTDC files are a sequence of zstd compressed blocks holding FTDC documents. See
+	// `storage.go`.
+	reader, closeReader, err := maybeDecompress(reader)
+	if err != nil {
+		retErr = err
+		return
+	}
+	defer closeReader()
+
 	var schema *schema
 	for {
 		peek, err := reader.Peek(1)
@@ -427,6 +448,22 @@ func ParseWithLogger(rawReader io.Reader, logger logging.Logger) (
 			// We cannot diff against values from the old schema.
 			prevValues = nil
 			continue
+		} else if peek[0] == annotationIdentifier {
+			//nolint
+			//
+			// Consume the annotation byte. See the schema case for justifying the nolint.
+			_, _ = reader.ReadByte()
+
+			var annotation Annotation
+			annotation, reader, err = readAnnotation(reader)
+			if err != nil {
+				logger.Debugw("Error reading annotation", "error", err)
+				retErr = err
+				return
+			}
+			logger.Debugw("Annotation", "annotation", annotation)
+			annotations = append(annotations, annotation)
+			continue
 		} else if schema == nil {
 			retErr = errors.New("first byte of FTDC data must be the magic 0x1 representing a new schema")
 			return
@@ -550,6 +587,23 @@ func readSchema(reader *bufio.Reader) (*schema, *bufio.Reader) {
 	}, retReader
 }
 
+// readAnnotation expects to be positioned on the beginning of a json object and consumes bytes
+// until that object is complete. Like `readSchema`, it returns a new reader positioned on the first
+// byte of the next ftdc document.
+func readAnnotation(reader *bufio.Reader) (Annotation, *bufio.Reader, error) {
+	var annotation Annotation
+	decoder := json.NewDecoder(reader)
+	if err := decoder.Decode(&annotation); err != nil {
+		return annotation, reader, err
+	}
+
+	retReader := bufio.NewReader(io.MultiReader(decoder.Buffered(), reader))
+	if ch, err := retReader.ReadByte(); ch != '\n' || err != nil {
+		return annotation, retReader, errors.New("annotation is not followed by a newline")
+	}
+	return annotation, retReader, nil
+}
+
 // readDiffBits returns a list of integers that index into the `Schema` representing the set of
 // metrics that have changed. Note that the first byte of the input reader is "packed" with the
 // schema bit. Thus the first byte can represent 7 metrics and the remaining bytes can each
@@ -24,7 +24,7 @@
 // Using a pseudo EBNF notation, an FTDC file is:
 // FTDC = ftdc_doc*
 //
-// ftdc_doc = schema | metric
+// ftdc_doc = schema | metric | annotation
 //
 // schema =
 //
@@ -38,6 +38,11 @@
 //	time: int64 <Golang: `time.Now().Unix()`. Nanoseconds since the 1970 epoch.>
 //	values : float32*
 //
+// annotation =
+//
+//	annotation_identifier : 0x03 (a full byte of value 3)
+//	annotation : <object serialized as JSON, including a trailing \n(0xa)>
+//
 // Because a metric reading is not meaningful without a schema, a file will always start with a
 // schema document. The first byte of a schema document is 0x01 followed by a JSON list of strings
 // and a UNIX newline (0x0a). The JSON strings are "flattened" using a dot to concatenate the map
@@ -100,4 +105,19 @@
 //
 // A parser can read a single byte and look at the least significant bit to determine which path to
 // take.
+//
+// Annotations are written when a configured `Rule` fires, immediately after the metric reading that
+// fired it. Like the schema identifier, the annotation identifier has its least significant bit set
+// to 1, so it can never be mistaken for the first byte of a metric reading. The JSON object is an
+// `Annotation`, e.g:
+//
+// 0000 0011 {"time":123,"rule":"goroutine leak","kind":"value","metric":"proc.viam-server.Goroutines",...}\n
+// 7       0
+//
+// FTDC files may also be written compressed, see `StorageConfig`. A compressed file (`.ftdc.zst`)
+// is a sequence of zstd frames. Each frame compresses a block of the FTDC documents described
+// above, and a document may span two frames. Concatenating the decompressed frames gives an
+// uncompressed FTDC file. A parser recognizes a compressed file by the zstd magic number
+// (0x28 0xb5 0x2f 0xfd) it starts with, which cannot be mistaken for the schema document every
+// uncompressed file starts with.
 package ftdc
@@ -13,7 +13,6 @@ import (
 	"regexp"
 	"slices"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
@@ -21,6 +20,7 @@ import (
 	"go.viam.com/utils/rpc"
 
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils/diskusage"
 )
 
 // datum combines the `Stats` call to all registered `Statser`s at some "time". The hierarchy of
@@ -121,6 +121,27 @@ type FTDC struct {
 
 	uploader *uploader
 	logger   logging.Logger
+
+	// latestMu protects `latest`, the most recent datum handed to `writeDatum`, and
+	// `metricCollisions`, the metric names already logged as colliding. They are read when serving
+	// metrics over HTTP. See `openmetrics.go`.
+	latestMu         sync.Mutex
+	latest           *datum
+	metricCollisions map[string]struct{}
+
+	// rulesMu protects `rules`, which are set by `SetRules` and evaluated against every datum
+	// written. See `rules.go`.
+	rulesMu sync.Mutex
+	rules   []*ruleEvaluator
+
+	// storageMu protects `storage`, which is set by `SetStorage`. See `storage.go`.
+	storageMu sync.Mutex
+	storage   StorageConfig
+	// compressor is the `outputWriter` when the current output file is compressed. It is nil
+	// otherwise.
+	compressor *blockCompressor
+	// deleteMu serializes deleting old files between the `fileDeleter` and `Reclaim`.
+	deleteMu sync.Mutex
 }
 
 // New creates a new *FTDC. This FTDC object will write FTDC formatted files into the input
@@ -226,6 +247,9 @@ func (ftdc *FTDC) Start() {
 	if ftdc.uploader != nil {
 		ftdc.uploader.start()
 	}
+	if ftdc.ftdcDir != "" {
+		diskusage.RegisterReclaimer(ftdc)
+	}
 }
 
 func (ftdc *FTDC) statsReader(ctx context.Context) {
@@ -243,6 +267,9 @@ func (ftdc *FTDC) statsReader(ctx context.Context) {
 
 func (ftdc *FTDC) statsWriter() {
 	defer func() {
+		if ftdc.compressor != nil {
+			utils.UncheckedError(ftdc.compressor.flush())
+		}
 		if ftdc.currOutputFile != nil {
 			utils.UncheckedError(ftdc.currOutputFile.Close())
 		}
@@ -279,6 +306,8 @@ func (ftdc *FTDC) statsWriter() {
 // `<-ftdc.outputWorkerDone` to stop+wait for the `statsWriter`.
 func (ftdc *FTDC) StopAndJoin(ctx context.Context) {
 	ftdc.stopOnce.Do(func() {
+		diskusage.UnregisterReclaimer(ftdc)
+
 		// Only one caller should close the datum channel. And it should be the caller that called
 		// stop on the worker writing to the channel.
 		if ftdc.readStatsWorker != nil {
@@ -425,6 +454,7 @@ func walk(datum map[string]any, previousSchema *schema) (*schema, []float32, err
 }
 
 func (ftdc *FTDC) writeDatum(datum datum) error {
+	ftdc.setLatest(datum)
 	toWrite, err := ftdc.getWriter()
 	if err != nil {
 		return err
@@ -456,6 +486,13 @@ func (ftdc *FTDC) writeDatum(datum datum) error {
 	}
 	ftdc.prevFlatData = flatData
 
+	if err = ftdc.evaluateRules(datum.Time, ftdc.currSchema, flatData, toWrite); err != nil {
+		return err
+	}
+
+	if ftdc.compressor != nil {
+		return ftdc.compressor.maybeFlush()
+	}
 	return nil
 }
 
@@ -477,14 +514,21 @@ func (ftdc *FTDC) getWriter() (io.Writer, error) {
 	// In case that helps reading the following logic.
 
 	// If we have an active outputWriter and we have not exceeded our FTDC file rotation quota, we
-	// can just return.
-	if ftdc.outputWriter != nil && ftdc.bytesWrittenCounter.count < ftdc.maxFileSizeBytes {
+	// can just return. Unless compression was turned on or off since the file was created.
+	compress := ftdc.storageConfig().Compress
+	if ftdc.outputWriter != nil && ftdc.bytesWrittenCounter.count < ftdc.maxFileSizeBytes &&
+		compress == (ftdc.compressor != nil) {
 		return ftdc.outputWriter, nil
 	}
 
 	// If we're in the logic branch where we have exceeded our FTDC file rotation quota, we first
-	// close the `currOutputFile`.
+	// close the `currOutputFile`. Data still buffered for compression is written out first.
 	if ftdc.currOutputFile != nil {
+		if ftdc.compressor != nil {
+			if err := ftdc.compressor.flush(); err != nil {
+				ftdc.logger.Warnw("Error writing compressed FTDC block", "err", err)
+			}
+		}
 		utils.UncheckedError(ftdc.currOutputFile.Close())
 		if ftdc.uploader != nil {
 			// Dan: For now we only upload "completed" during the runtime of a viam-server. There's
@@ -508,15 +552,18 @@ func (ftdc *FTDC) getWriter() (io.Writer, error) {
 		}
 
 		now := time.Now().UTC()
+		// Filename example: viam-server-2024-10-04T18-42-02.ftdc
+		filename := fmt.Sprintf("viam-server-%d-%02d-%02dT%02d-%02d-%02dZ.ftdc",
+			now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second())
+		if compress {
+			filename += compressedFileSuffix
+		}
 		// lint wants 0o600 file permissions. We don't expect the unix user someone is ssh'ed in as
 		// to be on the same unix user as is running the viam-server process. Thus the file needs to
 		// be accessible by anyone.
 		//
 		//nolint:gosec
-		ftdc.currOutputFile, err = os.OpenFile(path.Join(ftdc.ftdcDir,
-			// Filename example: viam-server-2024-10-04T18-42-02.ftdc
-			fmt.Sprintf("viam-server-%d-%02d-%02dT%02d-%02d-%02dZ.ftdc",
-				now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second())),
+		ftdc.currOutputFile, err = os.OpenFile(path.Join(ftdc.ftdcDir, filename),
 			// Create a new file in read+write mode. `O_EXCL` is used to guarantee a new file is
 			// created. If the filename already exists, that flag changes the `os.OpenFile` behavior
 			// to return an error.
@@ -542,6 +589,16 @@ func (ftdc *FTDC) getWriter() (io.Writer, error) {
 	// disk.
 	ftdc.outputWriter = io.MultiWriter(&ftdc.bytesWrittenCounter, ftdc.currOutputFile)
 
+	// Compressed files are written in blocks. The bytes written counter counts the compressed
+	// bytes, such that files are rotated by their size on disk.
+	ftdc.compressor = nil
+	if compress {
+		if ftdc.compressor, err = newBlockCompressor(ftdc.outputWriter); err != nil {
+			return nil, err
+		}
+		ftdc.outputWriter = ftdc.compressor
+	}
+
 	// The schema was last persisted in the prior FTDC file. To ensure this file can be understood
 	// without it, we start it with a copy of the schema. We achieve this by erasing the
 	// `currSchema` value. Such that the caller/`writeDatum` will behave as if this is a "schema
@@ -567,10 +624,11 @@ func (ftdc *FTDC) fileDeleter() {
 	}
 }
 
-// fileTime pairs a file with a time value.
+// fileTime pairs a file with a time value and its size in bytes.
 type fileTime struct {
 	name string
 	time time.Time
+	size int64
 }
 
 func getFTDCFilesDescendingTimeOrder(ftdcDir string, logger logging.Logger) ([]fileTime, error) {
@@ -578,7 +636,7 @@ func getFTDCFilesDescendingTimeOrder(ftdcDir string, logger logging.Logger) ([]f
 
 	// Walk the `ftdcDir` and gather all of the found files into the captured `files` variable.
 	err := filepath.Walk(ftdcDir, filepath.WalkFunc(func(path string, info fs.FileInfo, walkErr error) error {
-		if !strings.HasSuffix(path, ".ftdc") {
+		if !IsFTDCFilename(path) {
 			return nil
 		}
 
@@ -588,9 +646,9 @@ func getFTDCFilesDescendingTimeOrder(ftdcDir string, logger logging.Logger) ([]f
 			return nil
 		}
 
-		parsedTime, err := parseTimeFromFilename(path)
+		parsedTime, err := ParseTimeFromFilename(path)
 		if err == nil {
-			files = append(files, fileTime{path, parsedTime})
+			files = append(files, fileTime{path, parsedTime, info.Size()})
 		} else {
 			logger.Warnw("Error parsing time from FTDC file", "filename", path)
 		}
@@ -614,23 +672,13 @@ func (ftdc *FTDC) checkAndDeleteOldFiles() error {
 		return err
 	}
 
-	if len(files) <= ftdc.maxNumFiles {
-		// We have yet to hit our file limit. Keep all of the files.
-		ftdc.logger.Debugw("Inside the budget for ftdc files", "numFiles", len(files), "maxNumFiles", ftdc.maxNumFiles)
-		return nil
-	}
-
-	slices.SortFunc(files, func(left, right fileTime) int {
-		return right.time.Compare(left.time)
-	})
-
 	// The files are conveniently in descending time order. If we, for example, have 30 files and we
-	// want to keep the newest 10, we delete the trailing 20 files.
-	for _, file := range files[ftdc.maxNumFiles:] {
-		ftdc.logger.Debugw("Deleting aged out FTDC file", "filename", file.name)
-		if err := os.Remove(file.name); err != nil {
-			ftdc.logger.Warnw("Error removing FTDC file", "filename", file.name)
-		}
+	// want to keep the newest 10, we delete the trailing 20 files. When the total size of the files
+	// is bounded, older files may be deleted to stay within it.
+	maxTotalBytes := ftdc.storageConfig().MaxTotalBytes
+	if freedBytes := ftdc.deleteFilesOverBudget(files, ftdc.maxNumFiles, maxTotalBytes); freedBytes == 0 {
+		ftdc.logger.Debugw("Inside the budget for ftdc files",
+			"numFiles", len(files), "maxNumFiles", ftdc.maxNumFiles, "maxTotalBytes", maxTotalBytes)
 	}
 
 	return nil
@@ -640,10 +688,13 @@ func (ftdc *FTDC) checkAndDeleteOldFiles() error {
 // deletion testing. Filename generation uses padding such that we can rely on there before 2/4
 // digits for every numeric value.
 //
-// Example filename: `countingBytesTest1228324349/viam-server-2024-11-18T20-37-01Z.ftdc`.
+// Example filename: `countingBytesTest1228324349/viam-server-2024-11-18T20-37-01Z.ftdc`. Compressed
+// files additionally end in `.zst`.
 var filenameTimeRe = regexp.MustCompile(`viam-server-(\d{4})-(\d{2})-(\d{2})T(\d{2})-(\d{2})-(\d{2})Z.ftdc`)
 
-func parseTimeFromFilename(path string) (time.Time, error) {
+// ParseTimeFromFilename returns the time encoded in the name of an FTDC file, which is when FTDC
+// started writing to it.
+func ParseTimeFromFilename(path string) (time.Time, error) {
 	allMatches := filenameTimeRe.FindAllStringSubmatch(path, -1)
 	if len(allMatches) != 1 || len(allMatches[0]) != 7 {
 		return time.Time{}, errors.New("filename did not match pattern")
@@ -352,7 +352,7 @@ func TestCountingBytes(t *testing.T) {
 }
 
 func TestParseTimeFromFile(t *testing.T) {
-	timeVal, err := parseTimeFromFilename("countingBytesTest1228324349/viam-server-2024-11-18T20-37-01Z.ftdc")
+	timeVal, err := ParseTimeFromFilename("countingBytesTest1228324349/viam-server-2024-11-18T20-37-01Z.ftdc")
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, timeVal.Year(), test.ShouldEqual, 2024)
 	test.That(t, timeVal.Month(), test.ShouldEqual, time.Month(11))
@@ -415,9 +415,9 @@ func TestFileDeletion(t *testing.T) {
 	slices.SortFunc(origFiles, func(left, right fs.FileInfo) int {
 		// Sort in descending order. After deletion, the "leftmost" files should remain. The
 		// "rightmost" should be removed.
-		leftTime, err := parseTimeFromFilename(left.Name())
+		leftTime, err := ParseTimeFromFilename(left.Name())
 		test.That(t, err, test.ShouldBeNil)
-		rightTime, err := parseTimeFromFilename(right.Name())
+		rightTime, err := ParseTimeFromFilename(right.Name())
 		test.That(t, err, test.ShouldBeNil)
 		return rightTime.Compare(leftTime)
 	})
@@ -432,9 +432,9 @@ func TestFileDeletion(t *testing.T) {
 	test.That(t, len(leftoverFiles), test.ShouldEqual, ftdc.maxNumFiles)
 	slices.SortFunc(leftoverFiles, func(left, right fs.FileInfo) int {
 		// Sort in descending order.
-		leftTime, err := parseTimeFromFilename(left.Name())
+		leftTime, err := ParseTimeFromFilename(left.Name())
 		test.That(t, err, test.ShouldBeNil)
-		rightTime, err := parseTimeFromFilename(right.Name())
+		rightTime, err := ParseTimeFromFilename(right.Name())
 		test.That(t, err, test.ShouldBeNil)
 		return rightTime.Compare(leftTime)
 	})
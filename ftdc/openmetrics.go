@@ -0,0 +1,184 @@
+package ftdc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// openMetricsContentType is the content type of the OpenMetrics text exposition format.
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// metricSample is a single line of the OpenMetrics exposition.
+type metricSample struct {
+	name string
+	// field is the flattened path of the reading within its `Statser`, before sanitizing.
+	field     string
+	subsystem string
+	value     float32
+	// collides is set when other readings of the subsystem have the same name after sanitizing.
+	collides bool
+}
+
+// setLatest remembers the most recently written datum so that it can be exposed by
+// `WriteOpenMetrics`.
+func (ftdc *FTDC) setLatest(datum datum) {
+	ftdc.latestMu.Lock()
+	ftdc.latest = &datum
+	ftdc.latestMu.Unlock()
+}
+
+// WriteOpenMetrics writes the latest datum in the OpenMetrics text format. Every reading is exposed
+// as a gauge. The metric name is the flattened path of the reading within its `Statser`, prefixed
+// with `viam_`, and the `Statser` name is the `subsystem` label. For example, the reading
+// `NumComponents` of the `resource_manager` subsystem is exposed as:
+//
+//	viam_NumComponents{subsystem="resource_manager"} 10 1700000000.5
+//
+// The readings of resources are also labeled with the `api` and `resource` name, e.g:
+//
+//	viam_Moving{subsystem="rdk:component:motor/foo",api="rdk:component:motor",resource="foo"} 1 1700000000.5
+//
+// Characters that are not valid in metric names, such as the `.` separating nested readings, are
+// replaced with `_`. Readings of a subsystem whose names collide once replaced, e.g. `a.b` and
+// `a_b`, are told apart by a `field` label holding the reading's path. It returns false if no datum
+// was written yet.
+func (ftdc *FTDC) WriteOpenMetrics(w io.Writer) (bool, error) {
+	ftdc.latestMu.Lock()
+	latest := ftdc.latest
+	ftdc.latestMu.Unlock()
+	if latest == nil {
+		return false, nil
+	}
+
+	samples := make([]metricSample, 0)
+	for subsystem, stats := range latest.Data {
+		fields, values, err := flatten(reflect.ValueOf(stats))
+		if err != nil {
+			// The same error stopped this `Statser` from being written out. Leave it out here too.
+			continue
+		}
+		for idx, field := range fields {
+			samples = append(samples, metricSample{
+				name:      "viam_" + sanitizeMetricName(field),
+				field:     field,
+				subsystem: subsystem,
+				value:     values[idx],
+			})
+		}
+	}
+	// OpenMetrics requires all samples of a metric to be grouped together.
+	slices.SortFunc(samples, func(left, right metricSample) int {
+		if c := strings.Compare(left.name, right.name); c != 0 {
+			return c
+		}
+		if c := strings.Compare(left.subsystem, right.subsystem); c != 0 {
+			return c
+		}
+		return strings.Compare(left.field, right.field)
+	})
+	for idx := 1; idx < len(samples); idx++ {
+		if samples[idx-1].name == samples[idx].name && samples[idx-1].subsystem == samples[idx].subsystem {
+			samples[idx-1].collides = true
+			samples[idx].collides = true
+		}
+	}
+	ftdc.logMetricCollisions(samples)
+
+	timestamp := strconv.FormatFloat(float64(latest.Time)/1e9, 'f', 3, 64)
+	out := bufio.NewWriter(w)
+	for idx, sample := range samples {
+		if idx == 0 || samples[idx-1].name != sample.name {
+			fmt.Fprintf(out, "# TYPE %s gauge\n", sample.name)
+		}
+		fmt.Fprintf(out, "%s{%s} %s %s\n",
+			sample.name,
+			sample.labels(),
+			strconv.FormatFloat(float64(sample.value), 'g', -1, 32),
+			timestamp)
+	}
+	fmt.Fprint(out, "# EOF\n")
+	return true, out.Flush()
+}
+
+// labels returns the labels of the sample, formatted for the exposition.
+func (sample *metricSample) labels() string {
+	labels := []string{"subsystem=\"" + escapeLabelValue(sample.subsystem) + "\""}
+	// Resources are registered under their resource name, e.g: `rdk:component:motor/foo`.
+	if api, name, ok := strings.Cut(sample.subsystem, "/"); ok && strings.Count(api, ":") == 2 {
+		labels = append(labels,
+			"api=\""+escapeLabelValue(api)+"\"",
+			"resource=\""+escapeLabelValue(name)+"\"")
+	}
+	if sample.collides {
+		labels = append(labels, "field=\""+escapeLabelValue(sample.field)+"\"")
+	}
+	return strings.Join(labels, ",")
+}
+
+// logMetricCollisions warns about readings whose names collide after sanitizing. Each collision is
+// only logged the first time it is seen, since metrics are scraped over and over.
+func (ftdc *FTDC) logMetricCollisions(samples []metricSample) {
+	ftdc.latestMu.Lock()
+	defer ftdc.latestMu.Unlock()
+	for _, sample := range samples {
+		if !sample.collides {
+			continue
+		}
+		key := sample.subsystem + "/" + sample.field
+		if _, logged := ftdc.metricCollisions[key]; logged {
+			continue
+		}
+		if ftdc.metricCollisions == nil {
+			ftdc.metricCollisions = make(map[string]struct{})
+		}
+		ftdc.metricCollisions[key] = struct{}{}
+		ftdc.logger.Warnw("Readings have the same metric name once sanitized, exposing them with a field label",
+			"subsystem", sample.subsystem, "metric", sample.name, "field", sample.field)
+	}
+}
+
+// OpenMetricsHandler returns an http.Handler that serves the latest datum in the OpenMetrics text
+// format. See `WriteOpenMetrics`.
+func (ftdc *FTDC) OpenMetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", openMetricsContentType)
+		// Render into a buffer first, such that a failure can still be reported with a status code.
+		var buf strings.Builder
+		written, err := ftdc.WriteOpenMetrics(&buf)
+		switch {
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		case !written:
+			http.Error(w, "no diagnostics data has been collected yet", http.StatusServiceUnavailable)
+		default:
+			if _, err := io.WriteString(w, buf.String()); err != nil {
+				ftdc.logger.Debugw("Error writing metrics response", "err", err)
+			}
+		}
+	})
+}
+
+// sanitizeMetricName replaces every character that is not allowed in an OpenMetrics metric name
+// with an underscore.
+func sanitizeMetricName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
@@ -0,0 +1,85 @@
+package ftdc
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestOpenMetrics(t *testing.T) {
+	logger, logs := logging.NewObservedTestLogger(t)
+	ftdc := NewWithWriter(bytes.NewBuffer(nil), logger.Sublogger("ftdc"))
+
+	handler := ftdc.OpenMetricsHandler()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	test.That(t, recorder.Code, test.ShouldEqual, http.StatusServiceUnavailable)
+
+	type nested struct {
+		Bytes int
+	}
+	ftdc.Add("rdk:component:motor/foo", &mockStatser{stats: struct {
+		Moving  bool
+		Current nested
+	}{true, nested{7}}})
+	ftdc.Add("proc.viam-server", &mockStatser{stats: struct {
+		Current nested
+	}{nested{1024}}})
+	ftdc.Add(`odd"name`, &mockStatser{stats: map[string]float32{"a.b-c": 0.5}})
+	// `a.b` and `a_b` are both exposed as `viam_a_b`.
+	ftdc.Add("collisions", &mockStatser{stats: map[string]float32{"a.b": 1, "a_b": 2}})
+
+	datum := ftdc.constructDatum()
+	datum.Time = 1_700_000_000_500_000_000
+	test.That(t, ftdc.writeDatum(datum), test.ShouldBeNil)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	test.That(t, recorder.Code, test.ShouldEqual, http.StatusOK)
+	test.That(t, recorder.Header().Get("Content-Type"), test.ShouldEqual, openMetricsContentType)
+	expected := []string{
+		"# TYPE viam_Current_Bytes gauge",
+		`viam_Current_Bytes{subsystem="proc.viam-server"} 1024 1700000000.500`,
+		`viam_Current_Bytes{subsystem="rdk:component:motor/foo",api="rdk:component:motor",resource="foo"} 7 1700000000.500`,
+		"# TYPE viam_Moving gauge",
+		`viam_Moving{subsystem="rdk:component:motor/foo",api="rdk:component:motor",resource="foo"} 1 1700000000.500`,
+		"# TYPE viam_a_b gauge",
+		`viam_a_b{subsystem="collisions",field="a.b"} 1 1700000000.500`,
+		`viam_a_b{subsystem="collisions",field="a_b"} 2 1700000000.500`,
+		"# TYPE viam_a_b_c gauge",
+		`viam_a_b_c{subsystem="odd\"name"} 0.5 1700000000.500`,
+		"# EOF",
+		"",
+	}
+	test.That(t, strings.Split(recorder.Body.String(), "\n"), test.ShouldResemble, expected)
+
+	// Collisions are logged once, not on every scrape.
+	var out bytes.Buffer
+	written, err := ftdc.WriteOpenMetrics(&out)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, written, test.ShouldBeTrue)
+	test.That(t, strings.Split(out.String(), "\n"), test.ShouldResemble, expected)
+	test.That(t, logs.FilterMessageSnippet("same metric name once sanitized").Len(), test.ShouldEqual, 2)
+}
+
+func TestSanitizeMetricName(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		expected string
+	}{
+		{"Goroutines", "Goroutines"},
+		{"Current.Bytes", "Current_Bytes"},
+		{"rx-bytes per/sec", "rx_bytes_per_sec"},
+		{"eth0:1", "eth0_1"},
+		{"héllo", "h_llo"},
+		{"", ""},
+	} {
+		test.That(t, sanitizeMetricName(tc.name), test.ShouldEqual, tc.expected)
+	}
+}
@@ -0,0 +1,418 @@
+package parser
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.viam.com/rdk/ftdc"
+	"go.viam.com/rdk/logging"
+)
+
+// ExportFormat is a non-interactive output format for FTDC data.
+type ExportFormat string
+
+// The supported export formats.
+const (
+	// ExportCSV writes one row per datum and one column per metric. Metrics missing from a datum,
+	// e.g. because the schema changed, are left empty. Annotations are not exported.
+	ExportCSV ExportFormat = "csv"
+	// ExportNDJSON writes one JSON object per line and datum. Annotations are written on their own
+	// line, after the datum that fired them. For example:
+	//   {"time":"2024-09-24T18:00:00Z","readings":{"proc.viam-server.Goroutines":1500}}
+	//   {"time":"2024-09-24T18:00:00Z","annotation":{"rule":"goroutine leak",...}}
+	ExportNDJSON ExportFormat = "ndjson"
+	// ExportLineProtocol writes one InfluxDB line protocol point per datum. Every metric is a
+	// field of the point. Annotations are points of the measurement suffixed with
+	// `AnnotationMeasurementSuffix`, tagged with the rule, kind and metric.
+	ExportLineProtocol ExportFormat = "line"
+	// ExportColumnarJSON writes a single JSON object with one array per column, in the spirit of
+	// columnar formats like Parquet. Metrics missing from a datum are null. Annotations are listed
+	// separately. For example:
+	//   {"time":["2024-09-24T18:00:00Z"],"metrics":{"proc.viam-server.UserCPUSecs":[1.5]},"annotations":[]}
+	ExportColumnarJSON ExportFormat = "columnar"
+)
+
+// ExportFormats lists every supported export format.
+var ExportFormats = []ExportFormat{ExportCSV, ExportNDJSON, ExportLineProtocol, ExportColumnarJSON}
+
+// DefaultMeasurement is the line protocol measurement used when `ExportOptions.Measurement` is
+// empty.
+const DefaultMeasurement = "viam_ftdc"
+
+// AnnotationMeasurementSuffix is appended to the line protocol measurement to get the measurement
+// of annotations.
+const AnnotationMeasurementSuffix = "_annotations"
+
+// ExportOptions select what FTDC data is exported and how.
+type ExportOptions struct {
+	Format ExportFormat
+	// Metrics are glob patterns of the metric names to export, e.g. `proc.viam-server.*`. A `*`
+	// matches any number of characters, including `.`, and a `?` matches a single character. All
+	// metrics are exported if empty.
+	Metrics []string
+	// Start and End bound the time range of the exported datums. The start is inclusive and the
+	// end exclusive. A zero value leaves that side of the range open.
+	Start time.Time
+	End   time.Time
+	// Measurement is the line protocol measurement name. Defaults to `DefaultMeasurement`.
+	Measurement string
+}
+
+// Export decodes the FTDC file, or every FTDC file in the directory, at ftdcPath and writes the
+// selected metrics to out in the requested format. Files with schema changes are exported with
+// the metrics of every schema. The annotations of rules that fired on a selected metric are
+// exported with them, except for CSV.
+func Export(ftdcPath string, out io.Writer, opts ExportOptions, logger logging.Logger) error {
+	if !slices.Contains(ExportFormats, opts.Format) {
+		return fmt.Errorf("unknown export format %q, expected one of %v", opts.Format, ExportFormats)
+	}
+	matchers, err := globsToRegexps(opts.Metrics)
+	if err != nil {
+		return err
+	}
+
+	data, _, annotations, err := getFTDCData(filepath.Clean(ftdcPath), logger)
+	if err != nil {
+		return err
+	}
+	data = selectData(data, matchers, opts.Start, opts.End)
+	annotations = selectAnnotations(annotations, matchers, opts.Start, opts.End)
+
+	buffered := bufio.NewWriter(out)
+	switch opts.Format {
+	case ExportCSV:
+		err = exportCSV(data, buffered)
+	case ExportNDJSON:
+		err = exportNDJSON(data, annotations, buffered)
+	case ExportColumnarJSON:
+		err = exportColumnarJSON(data, annotations, buffered)
+	case ExportLineProtocol:
+		measurement := opts.Measurement
+		if measurement == "" {
+			measurement = DefaultMeasurement
+		}
+		err = exportLineProtocol(data, annotations, measurement, buffered)
+	}
+	if err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+// globsToRegexps compiles metric name globs, ignoring empty ones.
+func globsToRegexps(globs []string) ([]*regexp.Regexp, error) {
+	ret := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		glob = strings.TrimSpace(glob)
+		if glob == "" {
+			continue
+		}
+		re, err := ftdc.CompileMetricGlob(glob)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, re)
+	}
+	return ret, nil
+}
+
+// metricMatcher returns whether a metric name matches any of the matchers. All metrics match when
+// there are no matchers.
+func metricMatcher(matchers []*regexp.Regexp) func(metricName string) bool {
+	// Metric names repeat in every datum. Cache whether each one matched.
+	matched := make(map[string]bool)
+	return func(metricName string) bool {
+		if len(matchers) == 0 {
+			return true
+		}
+		ret, ok := matched[metricName]
+		if !ok {
+			ret = slices.ContainsFunc(matchers, func(re *regexp.Regexp) bool {
+				return re.MatchString(metricName)
+			})
+			matched[metricName] = ret
+		}
+		return ret
+	}
+}
+
+// inTimeRange returns whether timeNanos is in [start, end). A zero start or end leaves that side
+// of the range open.
+func inTimeRange(timeNanos int64, start, end time.Time) bool {
+	if !start.IsZero() && timeNanos < start.UnixNano() {
+		return false
+	}
+	return end.IsZero() || timeNanos < end.UnixNano()
+}
+
+// selectData drops datums outside of [start, end) and readings that do not match any of the
+// matchers. Datums left without readings are dropped.
+func selectData(data []ftdc.FlatDatum, matchers []*regexp.Regexp, start, end time.Time) []ftdc.FlatDatum {
+	matches := metricMatcher(matchers)
+	ret := make([]ftdc.FlatDatum, 0, len(data))
+	for _, datum := range data {
+		if !inTimeRange(datum.Time, start, end) {
+			continue
+		}
+		readings := make([]ftdc.Reading, 0, len(datum.Readings))
+		for _, reading := range datum.Readings {
+			if matches(reading.MetricName) {
+				readings = append(readings, reading)
+			}
+		}
+		if len(readings) == 0 {
+			continue
+		}
+		ret = append(ret, ftdc.FlatDatum{Time: datum.Time, Readings: readings})
+	}
+	return ret
+}
+
+// selectAnnotations drops annotations outside of [start, end) and annotations of metrics that do
+// not match any of the matchers. The annotations left are sorted by time.
+func selectAnnotations(annotations []ftdc.Annotation, matchers []*regexp.Regexp, start, end time.Time) []ftdc.Annotation {
+	matches := metricMatcher(matchers)
+	ret := make([]ftdc.Annotation, 0, len(annotations))
+	for _, annotation := range annotations {
+		if inTimeRange(annotation.Time, start, end) && matches(annotation.Metric) {
+			ret = append(ret, annotation)
+		}
+	}
+	slices.SortStableFunc(ret, func(left, right ftdc.Annotation) int {
+		return cmp.Compare(left.Time, right.Time)
+	})
+	return ret
+}
+
+// writeInTimeOrder calls writeDatum for every datum and writeAnnotation for every annotation, in
+// time order. An annotation is written after the datum that fired it. Both data and annotations
+// must be sorted by time.
+func writeInTimeOrder(
+	data []ftdc.FlatDatum,
+	annotations []ftdc.Annotation,
+	writeDatum func(datum *ftdc.FlatDatum) error,
+	writeAnnotation func(annotation *ftdc.Annotation) error,
+) error {
+	for idx := range data {
+		for len(annotations) > 0 && annotations[0].Time < data[idx].Time {
+			if err := writeAnnotation(&annotations[0]); err != nil {
+				return err
+			}
+			annotations = annotations[1:]
+		}
+		if err := writeDatum(&data[idx]); err != nil {
+			return err
+		}
+	}
+	for idx := range annotations {
+		if err := writeAnnotation(&annotations[idx]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatValue(value float32) string {
+	return strconv.FormatFloat(float64(value), 'g', -1, 32)
+}
+
+func formatTime(datum *ftdc.FlatDatum) string {
+	return datum.ConvertedTime().Format(time.RFC3339Nano)
+}
+
+func formatAnnotationTime(annotation *ftdc.Annotation) string {
+	return time.Unix(0, annotation.Time).UTC().Format(time.RFC3339Nano)
+}
+
+// jsonFloat returns nil for values JSON cannot represent, NaN and infinities, such that they are
+// written as null.
+func jsonFloat(value float64) *float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+	return &value
+}
+
+// annotationJSON is an annotation as written by the JSON export formats.
+type annotationJSON struct {
+	Rule    string        `json:"rule"`
+	Kind    ftdc.RuleKind `json:"kind"`
+	Metric  string        `json:"metric"`
+	Value   *float64      `json:"value"`
+	Message string        `json:"message"`
+}
+
+type timedAnnotationJSON struct {
+	Time       string         `json:"time"`
+	Annotation annotationJSON `json:"annotation"`
+}
+
+func toAnnotationJSON(annotation *ftdc.Annotation) timedAnnotationJSON {
+	return timedAnnotationJSON{
+		Time: formatAnnotationTime(annotation),
+		Annotation: annotationJSON{
+			Rule:    annotation.Rule,
+			Kind:    annotation.Kind,
+			Metric:  annotation.Metric,
+			Value:   jsonFloat(annotation.Value),
+			Message: annotation.Message,
+		},
+	}
+}
+
+func exportCSV(data []ftdc.FlatDatum, out io.Writer) error {
+	// The columns are the union of the metrics of every schema in the data.
+	columnIdx := make(map[string]int)
+	for _, datum := range data {
+		for _, reading := range datum.Readings {
+			columnIdx[reading.MetricName] = 0
+		}
+	}
+	columns := make([]string, 0, len(columnIdx))
+	for metricName := range columnIdx {
+		columns = append(columns, metricName)
+	}
+	slices.Sort(columns)
+	for idx, metricName := range columns {
+		columnIdx[metricName] = idx
+	}
+
+	writer := csv.NewWriter(out)
+	if err := writer.Write(append([]string{"time"}, columns...)); err != nil {
+		return err
+	}
+	row := make([]string, len(columns)+1)
+	for _, datum := range data {
+		clear(row)
+		row[0] = formatTime(&datum)
+		for _, reading := range datum.Readings {
+			row[columnIdx[reading.MetricName]+1] = formatValue(reading.Value)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+type ndjsonDatum struct {
+	Time     string              `json:"time"`
+	Readings map[string]*float32 `json:"readings"`
+}
+
+func exportNDJSON(data []ftdc.FlatDatum, annotations []ftdc.Annotation, out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	writeDatum := func(datum *ftdc.FlatDatum) error {
+		toWrite := ndjsonDatum{
+			Time:     formatTime(datum),
+			Readings: make(map[string]*float32, len(datum.Readings)),
+		}
+		for _, reading := range datum.Readings {
+			// JSON cannot represent NaN or infinities. Write them as null.
+			if math.IsNaN(float64(reading.Value)) || math.IsInf(float64(reading.Value), 0) {
+				toWrite.Readings[reading.MetricName] = nil
+				continue
+			}
+			toWrite.Readings[reading.MetricName] = &reading.Value
+		}
+		return encoder.Encode(toWrite)
+	}
+	writeAnnotation := func(annotation *ftdc.Annotation) error {
+		return encoder.Encode(toAnnotationJSON(annotation))
+	}
+	return writeInTimeOrder(data, annotations, writeDatum, writeAnnotation)
+}
+
+type columnarDatums struct {
+	Time        []string              `json:"time"`
+	Metrics     map[string][]*float32 `json:"metrics"`
+	Annotations []timedAnnotationJSON `json:"annotations"`
+}
+
+func exportColumnarJSON(data []ftdc.FlatDatum, annotations []ftdc.Annotation, out io.Writer) error {
+	toWrite := columnarDatums{
+		Time:        make([]string, len(data)),
+		Metrics:     make(map[string][]*float32),
+		Annotations: make([]timedAnnotationJSON, len(annotations)),
+	}
+	for idx := range annotations {
+		toWrite.Annotations[idx] = toAnnotationJSON(&annotations[idx])
+	}
+	for idx, datum := range data {
+		toWrite.Time[idx] = formatTime(&datum)
+		for _, reading := range datum.Readings {
+			column, ok := toWrite.Metrics[reading.MetricName]
+			if !ok {
+				// Datums before the metric first appeared are missing it.
+				column = make([]*float32, len(data))
+				toWrite.Metrics[reading.MetricName] = column
+			}
+			// JSON cannot represent NaN or infinities. Write them as null.
+			if math.IsNaN(float64(reading.Value)) || math.IsInf(float64(reading.Value), 0) {
+				continue
+			}
+			column[idx] = &reading.Value
+		}
+	}
+	return json.NewEncoder(out).Encode(toWrite)
+}
+
+// lineProtocolEscaper escapes the characters with a special meaning in line protocol
+// measurements, tags and field keys.
+var lineProtocolEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`, " ", `\ `)
+
+// lineProtocolStringEscaper escapes the characters with a special meaning in line protocol string
+// field values.
+var lineProtocolStringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func exportLineProtocol(data []ftdc.FlatDatum, annotations []ftdc.Annotation, measurement string, out io.Writer) error {
+	annotationMeasurement := lineProtocolEscaper.Replace(measurement + AnnotationMeasurementSuffix)
+	measurement = lineProtocolEscaper.Replace(measurement)
+	writeDatum := func(datum *ftdc.FlatDatum) error {
+		fields := make([]string, 0, len(datum.Readings))
+		for _, reading := range datum.Readings {
+			// Line protocol cannot represent NaN or infinities. Leave those fields out.
+			if math.IsNaN(float64(reading.Value)) || math.IsInf(float64(reading.Value), 0) {
+				continue
+			}
+			fields = append(fields, lineProtocolEscaper.Replace(reading.MetricName)+"="+formatValue(reading.Value))
+		}
+		if len(fields) == 0 {
+			return nil
+		}
+		_, err := fmt.Fprintf(out, "%s %s %d\n", measurement, strings.Join(fields, ","), datum.Time)
+		return err
+	}
+	writeAnnotation := func(annotation *ftdc.Annotation) error {
+		// Tags cannot be empty. Leave empty ones out.
+		tags := ""
+		for _, tag := range [][2]string{
+			{"rule", annotation.Rule},
+			{"kind", string(annotation.Kind)},
+			{"metric", annotation.Metric},
+		} {
+			if tag[1] != "" {
+				tags += "," + tag[0] + "=" + lineProtocolEscaper.Replace(tag[1])
+			}
+		}
+		fields := `message="` + lineProtocolStringEscaper.Replace(annotation.Message) + `"`
+		if !math.IsNaN(annotation.Value) && !math.IsInf(annotation.Value, 0) {
+			fields += ",value=" + strconv.FormatFloat(annotation.Value, 'g', -1, 64)
+		}
+		_, err := fmt.Fprintf(out, "%s%s %s %d\n", annotationMeasurement, tags, fields, annotation.Time)
+		return err
+	}
+	return writeInTimeOrder(data, annotations, writeDatum, writeAnnotation)
+}
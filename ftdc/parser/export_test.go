@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/ftdc"
+)
+
+func TestExport(t *testing.T) {
+	start := time.Date(2024, 9, 24, 18, 0, 0, 0, time.UTC)
+	at := func(seconds int) int64 {
+		return start.Add(time.Duration(seconds) * time.Second).UnixNano()
+	}
+	// The schema changes after the first datum. `net.rx` appears and `proc.viam-server.Threads`
+	// goes away.
+	data := []ftdc.FlatDatum{
+		{Time: at(0), Readings: []ftdc.Reading{
+			{MetricName: "proc.viam-server.UserCPUSecs", Value: 1.5},
+			{MetricName: "proc.viam-server.Threads", Value: 10},
+		}},
+		{Time: at(1), Readings: []ftdc.Reading{
+			{MetricName: "proc.viam-server.UserCPUSecs", Value: 2},
+			{MetricName: "net.rx bytes", Value: float32(math.NaN())},
+		}},
+		{Time: at(2), Readings: []ftdc.Reading{
+			{MetricName: "proc.viam-server.UserCPUSecs", Value: 3},
+		}},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		var out bytes.Buffer
+		test.That(t, exportCSV(data, &out), test.ShouldBeNil)
+		test.That(t, strings.Split(out.String(), "\n"), test.ShouldResemble, []string{
+			"time,net.rx bytes,proc.viam-server.Threads,proc.viam-server.UserCPUSecs",
+			"2024-09-24T18:00:00Z,,10,1.5",
+			"2024-09-24T18:00:01Z,NaN,,2",
+			"2024-09-24T18:00:02Z,,,3",
+			"",
+		})
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		var out bytes.Buffer
+		test.That(t, exportNDJSON(data[:2], nil, &out), test.ShouldBeNil)
+		test.That(t, strings.Split(out.String(), "\n"), test.ShouldResemble, []string{
+			`{"time":"2024-09-24T18:00:00Z","readings":{"proc.viam-server.Threads":10,"proc.viam-server.UserCPUSecs":1.5}}`,
+			`{"time":"2024-09-24T18:00:01Z","readings":{"net.rx bytes":null,"proc.viam-server.UserCPUSecs":2}}`,
+			"",
+		})
+	})
+
+	t.Run("columnar json", func(t *testing.T) {
+		var out bytes.Buffer
+		test.That(t, exportColumnarJSON(data, nil, &out), test.ShouldBeNil)
+		test.That(t, out.String(), test.ShouldEqual,
+			`{"time":["2024-09-24T18:00:00Z","2024-09-24T18:00:01Z","2024-09-24T18:00:02Z"],"metrics":{`+
+				`"net.rx bytes":[null,null,null],"proc.viam-server.Threads":[10,null,null],"proc.viam-server.UserCPUSecs":[1.5,2,3]},"annotations":[]}`+"\n")
+	})
+
+	t.Run("line protocol", func(t *testing.T) {
+		var out bytes.Buffer
+		test.That(t, exportLineProtocol(data[:2], nil, "viam ftdc", &out), test.ShouldBeNil)
+		test.That(t, strings.Split(out.String(), "\n"), test.ShouldResemble, []string{
+			`viam\ ftdc proc.viam-server.UserCPUSecs=1.5,proc.viam-server.Threads=10 1727200800000000000`,
+			`viam\ ftdc proc.viam-server.UserCPUSecs=2 1727200801000000000`,
+			"",
+		})
+	})
+
+	// The first annotation was fired by the second datum. The second one fired after the last datum
+	// exported, e.g. because the export ends before the datum that fired it.
+	annotations := []ftdc.Annotation{
+		{
+			Time: at(1), Rule: "user cpu", Kind: ftdc.RuleValue, Metric: "proc.viam-server.UserCPUSecs",
+			Value: 2, Message: `above "1"`,
+		},
+		{Time: at(3), Rule: "rx rate", Kind: ftdc.RuleRate, Metric: "net.rx bytes", Value: math.Inf(1)},
+	}
+
+	t.Run("ndjson annotations", func(t *testing.T) {
+		var out bytes.Buffer
+		test.That(t, exportNDJSON(data[:2], annotations, &out), test.ShouldBeNil)
+		test.That(t, strings.Split(out.String(), "\n"), test.ShouldResemble, []string{
+			`{"time":"2024-09-24T18:00:00Z","readings":{"proc.viam-server.Threads":10,"proc.viam-server.UserCPUSecs":1.5}}`,
+			`{"time":"2024-09-24T18:00:01Z","readings":{"net.rx bytes":null,"proc.viam-server.UserCPUSecs":2}}`,
+			`{"time":"2024-09-24T18:00:01Z","annotation":{"rule":"user cpu","kind":"value",` +
+				`"metric":"proc.viam-server.UserCPUSecs","value":2,"message":"above \"1\""}}`,
+			`{"time":"2024-09-24T18:00:03Z","annotation":{"rule":"rx rate","kind":"rate",` +
+				`"metric":"net.rx bytes","value":null,"message":""}}`,
+			"",
+		})
+	})
+
+	t.Run("columnar json annotations", func(t *testing.T) {
+		var out bytes.Buffer
+		test.That(t, exportColumnarJSON(data[2:], annotations[:1], &out), test.ShouldBeNil)
+		test.That(t, out.String(), test.ShouldEqual,
+			`{"time":["2024-09-24T18:00:02Z"],"metrics":{"proc.viam-server.UserCPUSecs":[3]},"annotations":[`+
+				`{"time":"2024-09-24T18:00:01Z","annotation":{"rule":"user cpu","kind":"value",`+
+				`"metric":"proc.viam-server.UserCPUSecs","value":2,"message":"above \"1\""}}]}`+"\n")
+	})
+
+	t.Run("line protocol annotations", func(t *testing.T) {
+		var out bytes.Buffer
+		test.That(t, exportLineProtocol(data[:2], annotations, "viam ftdc", &out), test.ShouldBeNil)
+		test.That(t, strings.Split(out.String(), "\n"), test.ShouldResemble, []string{
+			`viam\ ftdc proc.viam-server.UserCPUSecs=1.5,proc.viam-server.Threads=10 1727200800000000000`,
+			`viam\ ftdc proc.viam-server.UserCPUSecs=2 1727200801000000000`,
+			`viam\ ftdc_annotations,rule=user\ cpu,kind=value,metric=proc.viam-server.UserCPUSecs ` +
+				`message="above \"1\"",value=2 1727200801000000000`,
+			`viam\ ftdc_annotations,rule=rx\ rate,kind=rate,metric=net.rx\ bytes message="" 1727200803000000000`,
+			"",
+		})
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		matchers, err := globsToRegexps([]string{"*Threads", " net.* "})
+		test.That(t, err, test.ShouldBeNil)
+		selected := selectData(data, matchers, time.Time{}, time.Time{})
+		test.That(t, selected, test.ShouldHaveLength, 2)
+		test.That(t, selected[0].Readings, test.ShouldResemble, []ftdc.Reading{{MetricName: "proc.viam-server.Threads", Value: 10}})
+		test.That(t, selected[1].Readings[0].MetricName, test.ShouldEqual, "net.rx bytes")
+
+		matchers, err = globsToRegexps([]string{"proc.viam-server.User?PUSecs"})
+		test.That(t, err, test.ShouldBeNil)
+		selected = selectData(data, matchers, start.Add(time.Second), start.Add(2*time.Second))
+		test.That(t, selected, test.ShouldResemble, []ftdc.FlatDatum{
+			{Time: at(1), Readings: []ftdc.Reading{{MetricName: "proc.viam-server.UserCPUSecs", Value: 2}}},
+		})
+		test.That(t, selectAnnotations(annotations, matchers, start.Add(time.Second), start.Add(2*time.Second)),
+			test.ShouldResemble, annotations[:1])
+		test.That(t, selectAnnotations(annotations, matchers, start.Add(2*time.Second), time.Time{}), test.ShouldBeEmpty)
+
+		// Annotations are sorted by time.
+		matchers, err = globsToRegexps(nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, selectAnnotations([]ftdc.Annotation{annotations[1], annotations[0]}, matchers, time.Time{}, time.Time{}),
+			test.ShouldResemble, annotations)
+	})
+}
@@ -24,16 +24,28 @@ import (
 	"go.viam.com/rdk/logging"
 )
 
-// graphInfo points to an OS file containing the data points for a single graph. We also record the
-// min/max values for scaling purposes when generating plots.
+// graphInfo describes a single graph. It points to one OS file per source containing the data
+// points of that source. We also record the min/max values across sources for scaling purposes when
+// generating plots.
 type graphInfo struct {
-	file   *os.File
+	series map[string]*seriesInfo
 	minVal float32
 	maxVal float32
+}
+
+// seriesInfo points to an OS file containing the data points of a single source for a graph.
+type seriesInfo struct {
+	file *os.File
 
 	prevVal float32
 }
 
+func (gi *graphInfo) close() {
+	for _, series := range gi.series {
+		utils.UncheckedErrorFunc(series.file.Close)
+	}
+}
+
 // gnuplotWriter organizes all of the output for `gnuplot` to create a graph from FTDC
 // data. Notably:
 //   - Each graph consists of all the readings for an individual metric. There is one file per metric
@@ -74,6 +86,29 @@ type gnuplotWriter struct {
 	// The earliest datapoint being plotted. This is used to help ensure every chart starts plotting
 	// from the same time.
 	firstTimeSecs int64
+
+	// sources are the names of the FTDC data being compared, in the order they were added. Each
+	// graph has one line per source. Lines are only labeled with their source when there are
+	// multiple sources.
+	sources []string
+	// source is the source whose data points are currently being added.
+	source string
+
+	// fileBoundariesAtSeconds will draw a yellow vertical line for each element. The items
+	// are expected to be in units of seconds since the epoch. These are to represent the
+	// ending timestamps of files in the case that the parser was run on a directory with
+	// multiple files.
+	fileBoundariesAtSeconds []int64
+
+	// annotations are written into the FTDC data by FTDC rules that fired. Each one draws a purple
+	// vertical line on the graph of the metric it is about.
+	annotations []sourceAnnotation
+}
+
+// sourceAnnotation is an annotation read from the FTDC data of a source.
+type sourceAnnotation struct {
+	ftdc.Annotation
+	source string
 }
 
 type kvPair[K, V any] struct {
@@ -118,11 +153,13 @@ type graphOptions struct {
 	// user (where the user would like to find correlations in other metrics.)
 	vertLinesAtSeconds []int64
 
-	// fileBoundariesAtSeconds will draw a yellow vertical line for each element. The items
-	// are expected to be in units of seconds since the epoch. These are to represent the
-	// ending timestamps of files in the case that the parser was run on a directory with
-	// multiple files.
-	fileBoundariesAtSeconds []int64
+	// alignStarts moves the data of every source to start at the same time as the source with the
+	// earliest data. This compares machines that were not running at the same time.
+	alignStarts bool
+
+	// sourceOffsets moves the data of individual sources by a fixed duration. E.g: to correct for
+	// clock skew between machines, or to line up an incident on two machines.
+	sourceOffsets map[string]time.Duration
 
 	// maxPoints is how many data points will actually be graphed for each plot. Too many data
 	// points can be distracting. The algorithm is to divide the min/max time in `maxPoints`
@@ -135,22 +172,16 @@ type graphOptions struct {
 	selectList *orderedmap.OrderedMap
 }
 
-// defaultGraphOptions returns a default set of graph options. It adds all but the last
-// passed-in file boundary timestamps to `vertLinesAtSeconds`, so that vertical lines will
-// be automatically rendered at file boundaries.
-func defaultGraphOptions(fileBoundaryTimestamps []int64) graphOptions {
-	for i := range fileBoundaryTimestamps {
-		fileBoundaryTimestamps[i] /= 1e9
-	}
-
+// defaultGraphOptions returns a default set of graph options.
+func defaultGraphOptions() graphOptions {
 	return graphOptions{
-		minTimeSeconds:          0,
-		maxTimeSeconds:          math.MaxInt64,
-		hideAllZeroes:           true,
-		vertLinesAtSeconds:      make([]int64, 0),
-		fileBoundariesAtSeconds: fileBoundaryTimestamps[:len(fileBoundaryTimestamps)-1],
-		maxPoints:               1000,
-		selectList:              orderedmap.New(),
+		minTimeSeconds:     0,
+		maxTimeSeconds:     math.MaxInt64,
+		hideAllZeroes:      true,
+		vertLinesAtSeconds: make([]int64, 0),
+		sourceOffsets:      make(map[string]time.Duration),
+		maxPoints:          1000,
+		selectList:         orderedmap.New(),
 	}
 }
 
@@ -276,11 +307,16 @@ func (gpw *gnuplotWriter) shouldIncludePoint(this, next *ftdc.FlatDatum) *ftdc.F
 	return this
 }
 
-// getGraphInfo returns the cached `graphInfo` object for a given metric, or creates a new
-// one. Returns whether a new one was created.
-func (gpw *gnuplotWriter) getGraphInfo(metricName string) (*graphInfo, bool) {
-	if gi, created := gpw.metricFiles[metricName]; created {
-		return gi, false
+// getGraphInfo returns the cached `graphInfo` object for a given metric and the `seriesInfo` of
+// the current source, or creates new ones. Returns whether a new `seriesInfo` was created.
+func (gpw *gnuplotWriter) getGraphInfo(metricName string) (*graphInfo, *seriesInfo, bool) {
+	gi, exists := gpw.metricFiles[metricName]
+	if !exists {
+		gi = &graphInfo{series: make(map[string]*seriesInfo)}
+		gpw.metricFiles[metricName] = gi
+	}
+	if si, exists := gi.series[gpw.source]; exists {
+		return gi, si, false
 	}
 
 	datafile, err := os.CreateTemp(gpw.tempdir, "")
@@ -288,12 +324,12 @@ func (gpw *gnuplotWriter) getGraphInfo(metricName string) (*graphInfo, bool) {
 		panic(err)
 	}
 
-	ret := &graphInfo{
+	si := &seriesInfo{
 		file: datafile,
 	}
-	gpw.metricFiles[metricName] = ret
+	gi.series[gpw.source] = si
 
-	return ret, true
+	return gi, si, true
 }
 
 func (gpw *gnuplotWriter) copyPreviousPoint(timeSeconds int64, metricName string) {
@@ -312,11 +348,15 @@ func (gpw *gnuplotWriter) copyPreviousPoint(timeSeconds int64, metricName string
 	if !exists {
 		return
 	}
+	si, exists := gi.series[gpw.source]
+	if !exists {
+		return
+	}
 
-	gpw.addPoint(timeSeconds, metricName, gi.prevVal)
+	gpw.addPoint(timeSeconds, metricName, si.prevVal)
 }
 
-func (gi *graphInfo) writeStartingDatapoints(firstTimeSecs, datapointTimeSecs int64) {
+func (si *seriesInfo) writeStartingDatapoints(firstTimeSecs, datapointTimeSecs int64) {
 	// For newly created files:
 	// - Ensure the first datapoint is at `firstTime`.
 	// - If the first datapoint is more than a second after `firstTime`, write a 0-value
@@ -326,11 +366,11 @@ func (gi *graphInfo) writeStartingDatapoints(firstTimeSecs, datapointTimeSecs in
 	// robot life. We want the graph to have nice spike up. Rather than a long slow rise from the
 	// beginning of time.
 	if datapointTimeSecs > firstTimeSecs {
-		writelnf(gi.file, "%v 0.0", firstTimeSecs)
+		writelnf(si.file, "%v 0.0", firstTimeSecs)
 	}
 
 	if datapointTimeSecs-1 > firstTimeSecs {
-		writelnf(gi.file, "%v 0.0", datapointTimeSecs-1)
+		writelnf(si.file, "%v 0.0", datapointTimeSecs-1)
 	}
 }
 
@@ -341,20 +381,20 @@ func (gpw *gnuplotWriter) addPoint(timeSeconds int64, metricName string, metricV
 
 	// While we're adding points, track the min/max values we saw. This can be used to better scale
 	// graphs. As we've found gnuplots auto scaling to be a bit clunky.
-	gi, newlyCreated := gpw.getGraphInfo(metricName)
+	gi, si, newlyCreated := gpw.getGraphInfo(metricName)
 	if newlyCreated {
 		startingTime := gpw.firstTimeSecs
 		if gpw.options.minTimeSeconds > startingTime {
 			startingTime = gpw.options.minTimeSeconds
 		}
 
-		gi.writeStartingDatapoints(startingTime, timeSeconds)
+		si.writeStartingDatapoints(startingTime, timeSeconds)
 	}
 
-	gi.prevVal = metricValue
+	si.prevVal = metricValue
 	gi.minVal = min(gi.minVal, metricValue)
 	gi.maxVal = max(gi.maxVal, metricValue)
-	writelnf(gi.file, "%v %.5f", timeSeconds, metricValue)
+	writelnf(si.file, "%v %.5f", timeSeconds, metricValue)
 }
 
 // ratioMetric describes which two FTDC metrics that should be combined to create a computed
@@ -657,8 +697,24 @@ func (gpw *gnuplotWriter) writeSinglePlot(metricName string, graphInfo *graphInf
 	//
 	//
 	// linestyle 7 is red, 6 is blue, lw is line-width (or weight) -- makes it thicker. The
-	// title is what's used in the legend.
-	writef(gnuFile, "plot '%v' using 1:2 with lines linestyle 7 lw 4 title '%v'", graphInfo.file.Name(), strings.ReplaceAll(metricName, "_", "\\_"))
+	// title is what's used in the legend. When comparing sources, each source gets its own line
+	// with a color from `sourceLinestyles` and the source name in the title.
+	plotted := 0
+	for idx, source := range gpw.sources {
+		series, exists := graphInfo.series[source]
+		if !exists {
+			continue
+		}
+		if plotted == 0 {
+			write(gnuFile, "plot ")
+		} else {
+			writeln(gnuFile, ",\\")
+			write(gnuFile, "\t")
+		}
+		plotted++
+		writef(gnuFile, "'%v' using 1:2 with lines linestyle %d lw 4 title '%v'",
+			series.file.Name(), sourceLinestyles[idx%len(sourceLinestyles)], gpw.title(source, metricName))
+	}
 
 	// "vertical lines" for events are rendered as another set of data points for a
 	// `plot`. Because the vertical lines are at the same x-value/time for each graph, we can
@@ -675,17 +731,44 @@ func (gpw *gnuplotWriter) writeSinglePlot(metricName string, graphInfo *graphInf
 	// File boundaries are the same as vertical lines above, but should be represented
 	// with yellow lines (5) instead of blue, and should not have titles, as many file
 	// boundaries can crowd out the actual metric's title.
-	for idx := range gpw.options.fileBoundariesAtSeconds {
+	for idx := range gpw.fileBoundariesAtSeconds {
 		writeln(gnuFile, ",\\")
 		writef(gnuFile,
 			"\t'%v' using 1:2 with lines linestyle 5 lw 4 notitle",
 			filepath.Join(gpw.tempdir, fmt.Sprintf("fb-%d.txt", idx)))
 	}
 
+	// Annotations are only drawn on the graph of the metric that fired the rule. Linestyle 1 is
+	// purple.
+	for idx, annotation := range gpw.annotations {
+		if annotation.Metric != metricName {
+			continue
+		}
+		writeln(gnuFile, ",\\")
+		writef(gnuFile,
+			"\t'%v' using 1:2 with lines linestyle 1 lw 4 title '%v'",
+			filepath.Join(gpw.tempdir, fmt.Sprintf("ann-%d.txt", idx)),
+			gpw.title(annotation.source, annotation.Rule))
+	}
+
 	// The trailing newline for the above calls to write out a single plot.
 	writeln(gnuFile, "")
 }
 
+// sourceLinestyles are the line colors of each source when comparing sources: red, green, light
+// blue, orange and black. Purple, yellow and blue are left for annotations, file boundaries and
+// events.
+var sourceLinestyles = []int{7, 2, 3, 4, 8}
+
+// title returns a legend title for a line. Lines are labeled with their source when comparing
+// sources.
+func (gpw *gnuplotWriter) title(source, name string) string {
+	if len(gpw.sources) > 1 {
+		name = fmt.Sprintf("%v: %v", source, name)
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(name, "_", "\\_"), "'", "''")
+}
+
 // Compile writes out all of the underlying files for gnuplot. And returns the "top-level" filename
 // that can be input to gnuplot. The returned filename is an absolute path.
 func (gpw *gnuplotWriter) CompileAndClose() string {
@@ -749,7 +832,7 @@ func (gpw *gnuplotWriter) CompileAndClose() string {
 
 		gpw.writeSinglePlot(metricName, graphInfo, gnuFile)
 
-		utils.UncheckedErrorFunc(graphInfo.file.Close)
+		graphInfo.close()
 	}
 
 	allZeroesHidden := 0
@@ -782,7 +865,7 @@ func (gpw *gnuplotWriter) CompileAndClose() string {
 		}
 		gpw.writeSinglePlot(metricName, graphInfo, gnuFile)
 
-		utils.UncheckedErrorFunc(graphInfo.file.Close)
+		graphInfo.close()
 	}
 	if allZeroesHidden > 0 {
 		NolintPrintln("Hid metrics that only had 0s for data. Cnt:", allZeroesHidden)
@@ -805,7 +888,7 @@ func (gpw *gnuplotWriter) CompileAndClose() string {
 	}
 
 	// Actually write out the `fb-<number>.txt` plots.
-	for idx, fileBoundaryX := range gpw.options.fileBoundariesAtSeconds {
+	for idx, fileBoundaryX := range gpw.fileBoundariesAtSeconds {
 		fileBoundaryFile, err := os.Create(filepath.Join(gpw.tempdir, fmt.Sprintf("fb-%v.txt", idx)))
 		defer utils.UncheckedErrorFunc(fileBoundaryFile.Close)
 		if err != nil {
@@ -817,6 +900,20 @@ func (gpw *gnuplotWriter) CompileAndClose() string {
 		writelnf(fileBoundaryFile, "%v %d", fileBoundaryX, maxY)
 	}
 
+	// Actually write out the `ann-<number>.txt` plots.
+	for idx, annotation := range gpw.annotations {
+		annotationFile, err := os.Create(filepath.Join(gpw.tempdir, fmt.Sprintf("ann-%v.txt", idx)))
+		defer utils.UncheckedErrorFunc(annotationFile.Close)
+		if err != nil {
+			panic(err)
+		}
+
+		annotationX := annotation.Time / 1e9
+		writelnf(annotationFile, "%v %d", annotationX, minY)
+		writelnf(annotationFile, "%v 0", annotationX)
+		writelnf(annotationFile, "%v %d", annotationX, maxY)
+	}
+
 	return gnuFile.Name()
 }
 
@@ -832,15 +929,16 @@ func parseStringAsTime(inp string) (time.Time, error) {
 	return goTime, nil
 }
 
-// getFTDCData returns a slice of FlatDatums from the path it was passed and a slice of
-// "last timestamps" representing file boundaries. If path leads to an .ftdc file, only
-// that file is parsed. If it leads to a directory, all .ftdc files in that directory will
-// get parsed and the combined slice of FlatDatums will get returned. The subdirectories
-// of that directory will NOT get explored.
-func getFTDCData(ftdcPath string, logger logging.Logger) ([]ftdc.FlatDatum, []int64, error) {
+// getFTDCData returns a slice of FlatDatums from the path it was passed, a slice of
+// "last timestamps" representing file boundaries and the annotations written by FTDC rules. If
+// path leads to an .ftdc file, only that file is parsed. If it leads to a directory, all .ftdc
+// files, compressed (.ftdc.zst) or not, in that directory will get parsed and the combined slice of FlatDatums will get
+// returned. The files are stitched together in the order of the time in their filename, which is
+// when FTDC started writing to them. The subdirectories of that directory will NOT get explored.
+func getFTDCData(ftdcPath string, logger logging.Logger) ([]ftdc.FlatDatum, []int64, []ftdc.Annotation, error) {
 	info, err := os.Stat(ftdcPath)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	// If path is not a directory, we can just open the file and get its datums.
@@ -848,25 +946,24 @@ func getFTDCData(ftdcPath string, logger logging.Logger) ([]ftdc.FlatDatum, []in
 		//nolint:gosec
 		ftdcFile, err := os.Open(ftdcPath)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		//nolint:errcheck
 		defer ftdcFile.Close()
 
-		flatDatums, lastTimestamp, err := ftdc.ParseWithLogger(ftdcFile, logger)
+		flatDatums, annotations, lastTimestamp, err := ftdc.ParseWithAnnotations(ftdcFile, logger)
 		logger.Debugw("File boundary found", "timestamp_ns", lastTimestamp)
-		return flatDatums, []int64{lastTimestamp}, err
+		return flatDatums, []int64{lastTimestamp}, annotations, err
 	}
 
-	// If path is a directory, we will walk it and get all of the FTDC datums.
-	flatDatums := make([]ftdc.FlatDatum, 0)
-	fileBoundaryTimestamps := make([]int64, 0)
+	// If path is a directory, we will walk it and get all of the FTDC files.
+	ftdcFiles := make([]string, 0)
 	err = filepath.WalkDir(ftdcPath, fs.WalkDirFunc(func(path string, d fs.DirEntry, walkErr error) error {
 		// For now, no recursive parsing.
 		if d.IsDir() && path != ftdcPath {
 			return filepath.SkipDir
 		}
-		if !strings.HasSuffix(path, ".ftdc") {
+		if !ftdc.IsFTDCFilename(path) {
 			return nil
 		}
 
@@ -874,42 +971,194 @@ func getFTDCData(ftdcPath string, logger logging.Logger) ([]ftdc.FlatDatum, []in
 			return walkErr
 		}
 
-		//nolint:gosec
-		ftdcReader, err := os.Open(path)
-		if err != nil {
-			return err
-		}
-		//nolint:errcheck
-		defer ftdcReader.Close()
+		ftdcFiles = append(ftdcFiles, path)
+		return nil
+	}))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sortFTDCFiles(ftdcFiles, logger)
 
-		ftdcData, lastTimestamp, err := ftdc.ParseWithLogger(ftdcReader, logger)
+	flatDatums := make([]ftdc.FlatDatum, 0)
+	fileBoundaryTimestamps := make([]int64, 0)
+	allAnnotations := make([]ftdc.Annotation, 0)
+	for _, path := range ftdcFiles {
+		ftdcData, annotations, lastTimestamp, err := parseFile(path, logger)
 		if err != nil {
 			logger.Warnw("Error getting ftdc data from file", "path", path, "err", err)
-			return nil
+			continue
 		}
 
 		flatDatums = append(flatDatums, ftdcData...)
+		allAnnotations = append(allAnnotations, annotations...)
 
 		// The last timestamp parsed is equivalent to a file boundary timestamp.
 		logger.Debugw("File boundary found", "timestamp_ns", lastTimestamp)
 		fileBoundaryTimestamps = append(fileBoundaryTimestamps, lastTimestamp)
+	}
 
-		return nil
-	}))
+	if len(flatDatums) < 1 {
+		return nil, nil, nil, errors.New("provided a directory with no FTDC files")
+	}
+
+	return flatDatums, fileBoundaryTimestamps, allAnnotations, nil
+}
+
+func parseFile(path string, logger logging.Logger) ([]ftdc.FlatDatum, []ftdc.Annotation, int64, error) {
+	//nolint:gosec
+	ftdcReader, err := os.Open(path)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, 0, err
 	}
+	//nolint:errcheck
+	defer ftdcReader.Close()
 
-	if len(flatDatums) < 1 {
-		return nil, nil, errors.New("provided a directory with no FTDC files")
+	return ftdc.ParseWithAnnotations(ftdcReader, logger)
+}
+
+// sortFTDCFiles sorts FTDC files by the time in their filename. Files without a time in their
+// filename, e.g: because they were renamed, are sorted by name after the others.
+func sortFTDCFiles(paths []string, logger logging.Logger) {
+	fileTimes := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		fileTime, err := ftdc.ParseTimeFromFilename(filepath.Base(path))
+		if err != nil {
+			logger.Debugw("FTDC filename has no time, sorting it by name", "path", path)
+			continue
+		}
+		fileTimes[path] = fileTime
 	}
 
-	return flatDatums, fileBoundaryTimestamps, nil
+	slices.SortStableFunc(paths, func(left, right string) int {
+		leftTime, leftOk := fileTimes[left]
+		rightTime, rightOk := fileTimes[right]
+		switch {
+		case leftOk && rightOk:
+			if c := leftTime.Compare(rightTime); c != 0 {
+				return c
+			}
+		case leftOk:
+			return -1
+		case rightOk:
+			return 1
+		}
+		return strings.Compare(left, right)
+	})
+}
+
+// ftdcSource is the FTDC data of a single file or directory. Multiple sources, e.g: one per
+// machine, can be graphed together to compare them.
+type ftdcSource struct {
+	name string
+	data []ftdc.FlatDatum
+	// fileBoundaryTimestamps are the last timestamps of each FTDC file in nanoseconds since the
+	// epoch.
+	fileBoundaryTimestamps []int64
+	annotations            []ftdc.Annotation
 }
 
-func renderPlot(data []ftdc.FlatDatum, graphOptions graphOptions, logger logging.Logger) *gnuplotWriter {
+// loadSources reads the FTDC data of every path. Sources are named after the last element of their
+// path, e.g: the part ID for a directory of FTDC files downloaded from a machine.
+func loadSources(ftdcPaths []string, logger logging.Logger) ([]*ftdcSource, error) {
+	if len(ftdcPaths) == 0 {
+		return nil, errors.New("no FTDC paths provided")
+	}
+
+	sources := make([]*ftdcSource, 0, len(ftdcPaths))
+	names := make(map[string]int, len(ftdcPaths))
+	for _, ftdcPath := range ftdcPaths {
+		ftdcPath = filepath.Clean(ftdcPath)
+		data, fileBoundaryTimestamps, annotations, err := getFTDCData(ftdcPath, logger)
+		if err != nil {
+			return nil, fmt.Errorf("error getting ftdc data from path %v: %w", ftdcPath, err)
+		}
+		if len(data) == 0 {
+			return nil, fmt.Errorf("no ftdc data in path %v", ftdcPath)
+		}
+
+		name := filepath.Base(ftdcPath)
+		names[name]++
+		if names[name] > 1 {
+			name = fmt.Sprintf("%v#%d", name, names[name])
+		}
+		sources = append(sources, &ftdcSource{
+			name:                   name,
+			data:                   data,
+			fileBoundaryTimestamps: fileBoundaryTimestamps,
+			annotations:            annotations,
+		})
+	}
+	return sources, nil
+}
+
+// sourceOffsets returns how far to move the data of each source in nanoseconds, as per the
+// alignment options.
+func sourceOffsets(sources []*ftdcSource, graphOptions graphOptions) []int64 {
+	earliest := int64(math.MaxInt64)
+	for _, source := range sources {
+		earliest = min(earliest, source.data[0].Time)
+	}
+
+	ret := make([]int64, len(sources))
+	for idx, source := range sources {
+		if graphOptions.alignStarts {
+			ret[idx] = earliest - source.data[0].Time
+		}
+		ret[idx] += graphOptions.sourceOffsets[source.name].Nanoseconds()
+	}
+	return ret
+}
+
+func shiftData(data []ftdc.FlatDatum, offsetNanos int64) []ftdc.FlatDatum {
+	if offsetNanos == 0 {
+		return data
+	}
+
+	ret := make([]ftdc.FlatDatum, len(data))
+	for idx, datum := range data {
+		ret[idx] = ftdc.FlatDatum{Time: datum.Time + offsetNanos, Readings: datum.Readings}
+	}
+	return ret
+}
+
+func renderPlot(sources []*ftdcSource, graphOptions graphOptions, logger logging.Logger) *gnuplotWriter {
+	offsets := sourceOffsets(sources, graphOptions)
+	shifted := make([][]ftdc.FlatDatum, len(sources))
+	numDatapoints := 0
+	minTime, maxTime := int64(math.MaxInt64), int64(math.MinInt64)
+	for idx, source := range sources {
+		data := shiftData(source.data, offsets[idx])
+		shifted[idx] = data
+		numDatapoints = max(numDatapoints, len(data))
+		minTime = min(minTime, data[0].Time)
+		maxTime = max(maxTime, data[len(data)-1].Time)
+	}
+
+	gpw := newGnuPlotWriter(graphOptions, numDatapoints, minTime, maxTime)
+	for idx, source := range sources {
+		gpw.addSource(source.name, shifted[idx], logger)
+
+		// Draw lines at all but the last file boundary, which is the end of the data.
+		for _, boundary := range source.fileBoundaryTimestamps[:len(source.fileBoundaryTimestamps)-1] {
+			gpw.fileBoundariesAtSeconds = append(gpw.fileBoundariesAtSeconds, (boundary+offsets[idx])/1e9)
+		}
+		for _, annotation := range source.annotations {
+			annotation.Time += offsets[idx]
+			gpw.annotations = append(gpw.annotations, sourceAnnotation{annotation, source.name})
+		}
+	}
+
+	gpw.Render()
+	return gpw
+}
+
+// addSource adds the data points of a source to the graphs.
+func (gpw *gnuplotWriter) addSource(name string, data []ftdc.FlatDatum, logger logging.Logger) {
+	gpw.source = name
+	gpw.sources = append(gpw.sources, name)
+	gpw.shouldIncludePointStorage.nextTimeIdx = 0
+
 	deferredValues := make([]map[string]*ratioReading, 0)
-	gpw := newGnuPlotWriter(graphOptions, len(data), data[0].Time, data[len(data)-1].Time)
 	for idx := 0; idx < len(data)-1; idx++ {
 		thisDatum, nextDatum := data[idx], data[idx+1]
 		if pt := gpw.shouldIncludePoint(&thisDatum, &nextDatum); pt != nil {
@@ -922,27 +1171,35 @@ func renderPlot(data []ftdc.FlatDatum, graphOptions graphOptions, logger logging
 	}
 
 	gpw.writeDeferredValues(deferredValues, logger)
-
-	gpw.Render()
-	return gpw
 }
 
-// LaunchREPL opens an ftdc file or directory, plots it, and runs a cli for it.
-func LaunchREPL(ftdcFilepath string) {
+// LaunchREPL opens ftdc files or directories, plots them, and runs a cli for them. Each path is
+// a source, e.g: the FTDC data of one machine. The same metric of every source is plotted on one
+// graph to compare them.
+func LaunchREPL(ftdcFilepaths ...string) {
 	logger := logging.NewLogger("parser")
-	data, fileBoundaryTimestamps, err := getFTDCData(filepath.Clean(ftdcFilepath), logger)
+	sources, err := loadSources(ftdcFilepaths, logger)
 	if err != nil {
-		NolintPrintln("Error getting ftdc data from path:", ftdcFilepath, "Err:", err)
-		NolintPrintln(`Expected an FTDC filename or a directory. E.g: go run main.go
+		NolintPrintln("Error getting ftdc data. Err:", err)
+		NolintPrintln(`Expected FTDC filenames or directories. E.g: go run main.go
 		<path-to>/viam-server.ftdc or a directory with .ftdc files`)
 		return
 	}
 
 	stdinReader := bufio.NewReader(os.Stdin)
 
-	graphOptions := defaultGraphOptions(fileBoundaryTimestamps)
+	for _, source := range sources {
+		if len(sources) > 1 {
+			NolintPrintln("Source:", source.name, "From:", source.data[0].ConvertedTime(), "To:", source.data[len(source.data)-1].ConvertedTime())
+		}
+		for _, annotation := range source.annotations {
+			NolintPrintln("Rule fired:", source.name, time.Unix(0, annotation.Time).UTC(), annotation.Rule, annotation.Metric, annotation.Message)
+		}
+	}
+
+	graphOptions := defaultGraphOptions()
 
-	gpw := renderPlot(data, graphOptions, logger)
+	gpw := renderPlot(sources, graphOptions, logger)
 	for {
 		render := true
 
@@ -990,6 +1247,17 @@ func LaunchREPL(ftdcFilepath string) {
 			NolintPrintln("hide zeroes")
 			NolintPrintln("-  Generate graphs omitting plots with all zeroes.")
 			NolintPrintln()
+			NolintPrintln("align start")
+			NolintPrintln("-  When comparing sources, move the data of every source to start at the same time as the earliest one.")
+			NolintPrintln()
+			NolintPrintln("align none")
+			NolintPrintln("-  Plot every source at the time it was recorded. This is the default.")
+			NolintPrintln()
+			NolintPrintln("shift <source> <duration>")
+			NolintPrintln("-  Move the data of a source by a duration, e.g. to correct for clock skew between machines.")
+			NolintPrintln("-  E.g: shift viam-server-2024-09-24T18-00-00Z.ftdc -1m30s")
+			NolintPrintln("-       shift part-a 0s")
+			NolintPrintln()
 			NolintPrintln("`quit` or Ctrl-d to exit")
 		case strings.HasPrefix(cmd, "range "):
 			pieces := strings.SplitN(cmd, " ", 3)
@@ -1095,6 +1363,31 @@ func LaunchREPL(ftdcFilepath string) {
 		case cmd == "hide zeroes":
 			graphOptions.hideAllZeroes = true
 			NolintPrintln("Generating graphs omitting plots with all zeroes")
+		case cmd == "align start":
+			graphOptions.alignStarts = true
+			NolintPrintln("Aligning the start of every source")
+		case cmd == "align none":
+			graphOptions.alignStarts = false
+			NolintPrintln("Plotting every source at the time it was recorded")
+		case strings.HasPrefix(cmd, "shift "):
+			pieces := strings.Fields(cmd)
+			if len(pieces) != 3 {
+				NolintPrintln("Expected a source and a duration. E.g: shift part-a -1m30s")
+				render = false
+				break
+			}
+			offset, err := time.ParseDuration(pieces[2])
+			if err != nil {
+				NolintPrintln("Error parsing duration:", pieces[2], "Err:", err)
+				render = false
+				break
+			}
+			if !slices.ContainsFunc(sources, func(source *ftdcSource) bool { return source.name == pieces[1] }) {
+				NolintPrintln("Unknown source:", pieces[1])
+				render = false
+				break
+			}
+			graphOptions.sourceOffsets[pieces[1]] = offset
 		case len(cmd) == 0:
 			render = false
 		default:
@@ -1103,7 +1396,7 @@ func LaunchREPL(ftdcFilepath string) {
 		}
 
 		if render {
-			gpw = renderPlot(data, graphOptions, logger)
+			gpw = renderPlot(sources, graphOptions, logger)
 		}
 	}
 }
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/ftdc"
+	"go.viam.com/rdk/logging"
+)
+
+func TestSortFTDCFiles(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	files := []string{
+		"part/viam-server-2024-11-18T20-37-01Z.ftdc",
+		"part/renamed.ftdc",
+		"part/viam-server-2024-11-18T09-00-00Z.ftdc",
+		"part/old.ftdc",
+		"part/viam-server-2024-11-19T00-00-00Z.ftdc",
+	}
+	sortFTDCFiles(files, logger)
+	test.That(t, files, test.ShouldResemble, []string{
+		"part/viam-server-2024-11-18T09-00-00Z.ftdc",
+		"part/viam-server-2024-11-18T20-37-01Z.ftdc",
+		"part/viam-server-2024-11-19T00-00-00Z.ftdc",
+		"part/old.ftdc",
+		"part/renamed.ftdc",
+	})
+}
+
+func TestSourceOffsets(t *testing.T) {
+	start := time.Date(2024, 9, 24, 18, 0, 0, 0, time.UTC)
+	dataAt := func(offsets ...time.Duration) []ftdc.FlatDatum {
+		ret := make([]ftdc.FlatDatum, 0, len(offsets))
+		for _, offset := range offsets {
+			ret = append(ret, ftdc.FlatDatum{Time: start.Add(offset).UnixNano()})
+		}
+		return ret
+	}
+	sources := []*ftdcSource{
+		{name: "healthy", data: dataAt(time.Hour, time.Hour+time.Second)},
+		{name: "broken", data: dataAt(0, time.Second)},
+	}
+
+	graphOptions := defaultGraphOptions()
+	test.That(t, sourceOffsets(sources, graphOptions), test.ShouldResemble, []int64{0, 0})
+
+	graphOptions.alignStarts = true
+	test.That(t, sourceOffsets(sources, graphOptions), test.ShouldResemble, []int64{-time.Hour.Nanoseconds(), 0})
+
+	graphOptions.sourceOffsets["broken"] = -2 * time.Second
+	offsets := sourceOffsets(sources, graphOptions)
+	test.That(t, offsets, test.ShouldResemble, []int64{-time.Hour.Nanoseconds(), -2 * time.Second.Nanoseconds()})
+
+	shifted := shiftData(sources[0].data, offsets[0])
+	test.That(t, shifted, test.ShouldResemble, dataAt(0, time.Second))
+	// The source data is left as is, such that alignment can be changed again.
+	test.That(t, sources[0].data, test.ShouldResemble, dataAt(time.Hour, time.Hour+time.Second))
+}
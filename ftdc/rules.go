@@ -0,0 +1,329 @@
+package ftdc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RuleKind describes what value of a metric a `Rule` compares against its thresholds.
+type RuleKind string
+
+// The supported rule kinds.
+const (
+	// RuleValue compares the current value of the metric.
+	RuleValue RuleKind = "value"
+	// RuleRate compares how much the metric changed per second over the rule window. E.g: to catch
+	// memory growing steadily.
+	RuleRate RuleKind = "rate"
+	// RuleErrorRate compares the fraction of requests that failed over the rule window. It applies
+	// to the request counts of the web service, e.g: `web.motor1.MotorService/SetPower`, which are
+	// paired with an `errorCnt` metric.
+	RuleErrorRate RuleKind = "error_rate"
+)
+
+// defaultRuleWindow is the window of rate and error rate rules without a configured window.
+const defaultRuleWindow = time.Minute
+
+// errorCountSuffix is the suffix of the web service metrics counting failed requests.
+const errorCountSuffix = ".errorCnt"
+
+// Rule is evaluated against every datum FTDC writes. When one of the metrics it matches crosses a
+// threshold, FTDC logs a warning and writes an `Annotation` into the FTDC data. The rule fires
+// again only after the metric went back within its thresholds.
+type Rule struct {
+	Name string `json:"name"`
+	// Metric is a glob pattern for the flattened metric names the rule applies to, e.g:
+	// `proc.viam-server.*Goroutines`. A `*` matches any number of characters, including dots, and
+	// a `?` matches a single character.
+	Metric string   `json:"metric"`
+	Kind   RuleKind `json:"kind,omitempty"`
+	Above  *float64 `json:"above,omitempty"`
+	Below  *float64 `json:"below,omitempty"`
+	// Window is the duration rate and error rate rules are computed over. Defaults to one minute.
+	Window string `json:"window,omitempty"`
+}
+
+// Validate returns an error if the rule cannot be evaluated.
+func (rule *Rule) Validate() error {
+	if rule.Name == "" {
+		return errors.New("rule name is required")
+	}
+	if rule.Metric == "" {
+		return errors.New("rule metric is required")
+	}
+	if _, err := CompileMetricGlob(rule.Metric); err != nil {
+		return err
+	}
+	switch rule.Kind {
+	case "", RuleValue, RuleRate, RuleErrorRate:
+	default:
+		return fmt.Errorf("unknown rule kind %q, expected one of %q, %q or %q", rule.Kind, RuleValue, RuleRate, RuleErrorRate)
+	}
+	if rule.Above == nil && rule.Below == nil {
+		return errors.New("rule must set above, below or both")
+	}
+	if rule.Window != "" {
+		window, err := time.ParseDuration(rule.Window)
+		if err != nil {
+			return fmt.Errorf("invalid rule window: %w", err)
+		}
+		if window <= 0 {
+			return errors.New("rule window must be positive")
+		}
+	}
+	return nil
+}
+
+// CompileMetricGlob compiles a metric name glob pattern into an anchored regular expression. A `*`
+// matches any number of characters, including the dots separating nested metrics, and a `?`
+// matches a single character.
+func CompileMetricGlob(glob string) (*regexp.Regexp, error) {
+	pattern := regexp.QuoteMeta(glob)
+	pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+	pattern = strings.ReplaceAll(pattern, `\?`, ".")
+	re, err := regexp.Compile("^" + pattern + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid metric glob %q: %w", glob, err)
+	}
+	return re, nil
+}
+
+// Annotation marks the moment a `Rule` fired. Annotations are written into the FTDC data after
+// the datum that fired them, such that parsers can show them alongside the metrics.
+type Annotation struct {
+	// Time is the time of the datum that fired the rule, in nanoseconds since the epoch.
+	Time   int64    `json:"time"`
+	Rule   string   `json:"rule"`
+	Kind   RuleKind `json:"kind"`
+	Metric string   `json:"metric"`
+	// Value is the value the rule compared, e.g: the rate for rate rules.
+	Value   float64 `json:"value"`
+	Message string  `json:"message"`
+}
+
+// writeAnnotation writes an annotation document. See `doc.go` for the file format.
+func writeAnnotation(annotation Annotation, output io.Writer) error {
+	if _, err := output.Write([]byte{annotationIdentifier}); err != nil {
+		return fmt.Errorf("Error writing annotation byte: %w", err)
+	}
+	if err := json.NewEncoder(output).Encode(annotation); err != nil {
+		return fmt.Errorf("Error writing annotation: %w", err)
+	}
+	return nil
+}
+
+// SetRules replaces the rules evaluated against future datums. Invalid rules are logged and
+// skipped.
+func (ftdc *FTDC) SetRules(rules []Rule) {
+	evaluators := make([]*ruleEvaluator, 0, len(rules))
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			ftdc.logger.Warnw("Ignoring invalid FTDC rule", "name", rule.Name, "err", err)
+			continue
+		}
+		evaluators = append(evaluators, newRuleEvaluator(rule))
+	}
+
+	ftdc.rulesMu.Lock()
+	ftdc.rules = evaluators
+	ftdc.rulesMu.Unlock()
+}
+
+// evaluateRules evaluates every rule against the flattened data just written and writes an
+// annotation for each rule that fired.
+func (ftdc *FTDC) evaluateRules(timeNanos int64, schema *schema, values []float32, output io.Writer) error {
+	ftdc.rulesMu.Lock()
+	defer ftdc.rulesMu.Unlock()
+
+	for _, evaluator := range ftdc.rules {
+		for _, annotation := range evaluator.evaluate(timeNanos, schema, values) {
+			ftdc.logger.Warnw("FTDC rule fired",
+				"rule", annotation.Rule,
+				"kind", annotation.Kind,
+				"metric", annotation.Metric,
+				"value", annotation.Value,
+				"message", annotation.Message)
+			if err := writeAnnotation(annotation, output); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ruleSample is a reading of the metric(s) a rule watches.
+type ruleSample struct {
+	timeNanos int64
+	value     float64
+	// errors is the matching error count for error rate rules.
+	errors float64
+}
+
+// metricState is what a rule tracks for a single metric it applies to.
+type metricState struct {
+	name string
+	// valueIdx and errorsIdx index into the flattened values of the current schema.
+	valueIdx  int
+	errorsIdx int
+	// samples holds the readings within the rule window, plus the last one before it. It is only
+	// used by rate and error rate rules.
+	samples []ruleSample
+	firing  bool
+}
+
+type ruleEvaluator struct {
+	rule   Rule
+	kind   RuleKind
+	glob   *regexp.Regexp
+	window time.Duration
+
+	// schema is the schema `metrics` were matched against. Metrics are matched again when the schema
+	// changes.
+	schema  *schema
+	metrics []*metricState
+}
+
+func newRuleEvaluator(rule Rule) *ruleEvaluator {
+	// `Validate` has checked the glob and window.
+	glob, _ := CompileMetricGlob(rule.Metric)
+	window := defaultRuleWindow
+	if rule.Window != "" {
+		window, _ = time.ParseDuration(rule.Window)
+	}
+	kind := rule.Kind
+	if kind == "" {
+		kind = RuleValue
+	}
+	return &ruleEvaluator{
+		rule:   rule,
+		kind:   kind,
+		glob:   glob,
+		window: window,
+	}
+}
+
+// matchMetrics finds the metrics of the schema the rule applies to. The state of metrics that are
+// still present is kept.
+func (re *ruleEvaluator) matchMetrics(schema *schema) {
+	previous := make(map[string]*metricState, len(re.metrics))
+	for _, metric := range re.metrics {
+		previous[metric.name] = metric
+	}
+	fieldIdx := make(map[string]int, len(schema.fieldOrder))
+	for idx, field := range schema.fieldOrder {
+		fieldIdx[field] = idx
+	}
+
+	re.schema = schema
+	re.metrics = nil
+	for idx, field := range schema.fieldOrder {
+		if !re.glob.MatchString(field) {
+			continue
+		}
+		errorsIdx := -1
+		if re.kind == RuleErrorRate {
+			var ok bool
+			if errorsIdx, ok = fieldIdx[field+errorCountSuffix]; !ok {
+				// Only request counts have a matching error count.
+				continue
+			}
+		}
+		metric, ok := previous[field]
+		if !ok {
+			metric = &metricState{name: field}
+		}
+		metric.valueIdx = idx
+		metric.errorsIdx = errorsIdx
+		re.metrics = append(re.metrics, metric)
+	}
+}
+
+// evaluate returns an annotation for every metric that crossed a threshold with this datum.
+func (re *ruleEvaluator) evaluate(timeNanos int64, schema *schema, values []float32) []Annotation {
+	if schema != re.schema {
+		re.matchMetrics(schema)
+	}
+
+	var ret []Annotation
+	for _, metric := range re.metrics {
+		sample := ruleSample{timeNanos: timeNanos, value: float64(values[metric.valueIdx])}
+		if metric.errorsIdx >= 0 {
+			sample.errors = float64(values[metric.errorsIdx])
+		}
+
+		value, ok := re.observe(metric, sample)
+		if !ok {
+			continue
+		}
+		crossed := (re.rule.Above != nil && value > *re.rule.Above) || (re.rule.Below != nil && value < *re.rule.Below)
+		switch {
+		case crossed && !metric.firing:
+			metric.firing = true
+			ret = append(ret, Annotation{
+				Time:    timeNanos,
+				Rule:    re.rule.Name,
+				Kind:    re.kind,
+				Metric:  metric.name,
+				Value:   value,
+				Message: re.describe(value),
+			})
+		case !crossed:
+			metric.firing = false
+		}
+	}
+	return ret
+}
+
+// observe records a sample of the metric and returns the value the rule compares. It returns false
+// when rate and error rate rules have not seen a full window of samples, or no requests were made
+// within the window.
+func (re *ruleEvaluator) observe(metric *metricState, sample ruleSample) (float64, bool) {
+	if re.kind == RuleValue {
+		return sample.value, true
+	}
+
+	metric.samples = append(metric.samples, sample)
+	windowStart := sample.timeNanos - re.window.Nanoseconds()
+	for len(metric.samples) > 1 && metric.samples[1].timeNanos <= windowStart {
+		metric.samples = metric.samples[1:]
+	}
+	oldest := metric.samples[0]
+	if oldest.timeNanos > windowStart {
+		return 0, false
+	}
+
+	if re.kind == RuleRate {
+		elapsed := time.Duration(sample.timeNanos - oldest.timeNanos).Seconds()
+		return (sample.value - oldest.value) / elapsed, true
+	}
+	requests := sample.value - oldest.value
+	if requests <= 0 {
+		return 0, false
+	}
+	return (sample.errors - oldest.errors) / requests, true
+}
+
+func (re *ruleEvaluator) describe(value float64) string {
+	var what string
+	switch re.kind {
+	case RuleRate:
+		what = fmt.Sprintf("changed by %g per second over %v", value, re.window)
+	case RuleErrorRate:
+		what = fmt.Sprintf("had %g%% of requests fail over %v", value*100, re.window)
+	default:
+		what = fmt.Sprintf("is %g", value)
+	}
+	var bounds []string
+	if re.rule.Above != nil {
+		bounds = append(bounds, fmt.Sprintf("above %g", *re.rule.Above))
+	}
+	if re.rule.Below != nil {
+		bounds = append(bounds, fmt.Sprintf("below %g", *re.rule.Below))
+	}
+	return fmt.Sprintf("%s, outside of the threshold (%s)", what, strings.Join(bounds, " or "))
+}
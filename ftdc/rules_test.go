@@ -0,0 +1,115 @@
+package ftdc
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestRules(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	ftdcData := bytes.NewBuffer(nil)
+	ftdc := NewWithWriter(ftdcData, logger.Sublogger("ftdc"))
+
+	goroutines, rss := 10.0, 100.0
+	ftdc.SetRules([]Rule{
+		{Name: "goroutine leak", Metric: "runtime.Gor*", Above: &goroutines},
+		{Name: "rss growth", Metric: "proc.viam-server.RssMB", Kind: RuleRate, Above: &rss, Window: "2s"},
+		{Name: "invalid", Metric: "runtime.Goroutines"},
+	})
+
+	type runtimeStats struct {
+		Goroutines int
+	}
+	type procStats struct {
+		RssMB float64
+	}
+	runtimeStatser := &mockStatser{}
+	procStatser := &mockStatser{}
+	ftdc.Add("runtime", runtimeStatser)
+	ftdc.Add("proc.viam-server", procStatser)
+
+	start := time.Now()
+	writeAt := func(seconds, goroutines int, rssMB float64) {
+		runtimeStatser.stats = runtimeStats{Goroutines: goroutines}
+		procStatser.stats = procStats{RssMB: rssMB}
+		datum := ftdc.constructDatum()
+		datum.Time = start.Add(time.Duration(seconds) * time.Second).UnixNano()
+		test.That(t, ftdc.writeDatum(datum), test.ShouldBeNil)
+	}
+	writeAt(0, 5, 100)
+	// The goroutine rule fires once while the count stays above the threshold. The rss rate rule
+	// needs a full window of data before it can fire.
+	writeAt(1, 11, 200)
+	writeAt(2, 12, 210)
+	// RSS grew by 110 MB/s over the last two seconds. The first full window only saw 55 MB/s.
+	writeAt(3, 5, 420)
+	// The goroutine count went back under the threshold in the previous datum, so the rule fires again.
+	writeAt(4, 20, 430)
+
+	datums, annotations, _, err := ParseWithAnnotations(ftdcData, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, datums, test.ShouldHaveLength, 5)
+	test.That(t, annotations, test.ShouldHaveLength, 3)
+
+	test.That(t, annotations[0].Rule, test.ShouldEqual, "goroutine leak")
+	test.That(t, annotations[0].Metric, test.ShouldEqual, "runtime.Goroutines")
+	test.That(t, annotations[0].Kind, test.ShouldEqual, RuleValue)
+	test.That(t, annotations[0].Value, test.ShouldEqual, 11)
+	test.That(t, annotations[0].Time, test.ShouldEqual, datums[1].Time)
+
+	test.That(t, annotations[1].Rule, test.ShouldEqual, "rss growth")
+	test.That(t, annotations[1].Metric, test.ShouldEqual, "proc.viam-server.RssMB")
+	test.That(t, annotations[1].Value, test.ShouldEqual, 110)
+	test.That(t, annotations[1].Time, test.ShouldEqual, datums[3].Time)
+
+	test.That(t, annotations[2].Rule, test.ShouldEqual, "goroutine leak")
+	test.That(t, annotations[2].Value, test.ShouldEqual, 20)
+	test.That(t, annotations[2].Time, test.ShouldEqual, datums[4].Time)
+
+	// Annotations do not disturb the diffing of metric readings around them.
+	test.That(t, datums[4].Readings, test.ShouldResemble, []Reading{
+		{MetricName: "runtime.Goroutines", Value: 20},
+		{MetricName: "proc.viam-server.RssMB", Value: 430},
+	})
+}
+
+func TestErrorRateRule(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	ftdcData := bytes.NewBuffer(nil)
+	ftdc := NewWithWriter(ftdcData, logger.Sublogger("ftdc"))
+
+	errorRate := 0.5
+	ftdc.SetRules([]Rule{
+		{Name: "motor errors", Metric: "web.motor1.*", Kind: RuleErrorRate, Above: &errorRate, Window: "1s"},
+	})
+
+	// The shape of the web service request counter stats.
+	counts := map[string]int64{}
+	ftdc.Add("web", &mockStatser{stats: counts})
+
+	start := time.Now()
+	writeAt := func(seconds int, requests, errors int64) {
+		counts["motor1.MotorService/SetPower"] = requests
+		counts["motor1.MotorService/SetPower.errorCnt"] = errors
+		counts["motor1.MotorService/SetPower.timeSpent"] = 0
+		datum := ftdc.constructDatum()
+		datum.Time = start.Add(time.Duration(seconds) * time.Second).UnixNano()
+		test.That(t, ftdc.writeDatum(datum), test.ShouldBeNil)
+	}
+	writeAt(0, 10, 0)
+	writeAt(1, 20, 1)
+	writeAt(2, 30, 9)
+
+	_, annotations, _, err := ParseWithAnnotations(ftdcData, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, annotations, test.ShouldHaveLength, 1)
+	test.That(t, annotations[0].Metric, test.ShouldEqual, "web.motor1.MotorService/SetPower")
+	test.That(t, annotations[0].Value, test.ShouldAlmostEqual, 0.8)
+}
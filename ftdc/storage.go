@@ -0,0 +1,188 @@
+package ftdc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// StorageConfig configures how FTDC files are kept on disk.
+type StorageConfig struct {
+	// Compress writes new FTDC files as a sequence of zstd compressed blocks. Compressed files use
+	// the `.ftdc.zst` extension and are read by the same parser as uncompressed files.
+	Compress bool `json:"compress,omitempty"`
+	// MaxTotalBytes bounds the disk space used by FTDC files. The oldest files are deleted first.
+	// Zero, or less, only bounds the number of files.
+	MaxTotalBytes int64 `json:"max_total_bytes,omitempty"`
+}
+
+// compressedFileSuffix is appended to the name of FTDC files holding compressed blocks.
+const compressedFileSuffix = ".zst"
+
+// A block is compressed and written out when it holds `compressedBlockBytes` of FTDC data, or its
+// oldest data is `compressedBlockAge` old. Uncompressed data is lost if viam-server crashes. The age
+// limit matches how often FTDC files are fsync'ed.
+const (
+	compressedBlockBytes = 64 * 1024
+	compressedBlockAge   = 30 * time.Second
+)
+
+// diskPressureNumFiles is the number of FTDC files kept when the disk is full. See `Reclaim`.
+const diskPressureNumFiles = 2
+
+// zstdMagic is the first four bytes of a zstd frame.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// IsFTDCFilename returns whether the path names an FTDC file, compressed or not.
+func IsFTDCFilename(path string) bool {
+	return strings.HasSuffix(path, ".ftdc") || strings.HasSuffix(path, ".ftdc"+compressedFileSuffix)
+}
+
+// SetStorage replaces how FTDC files are kept on disk. A change to compression takes effect with the
+// next datum, which is written to a new file. A nil config restores the defaults.
+func (ftdc *FTDC) SetStorage(config *StorageConfig) {
+	ftdc.storageMu.Lock()
+	defer ftdc.storageMu.Unlock()
+	if config == nil {
+		ftdc.storage = StorageConfig{}
+		return
+	}
+	ftdc.storage = *config
+}
+
+func (ftdc *FTDC) storageConfig() StorageConfig {
+	ftdc.storageMu.Lock()
+	defer ftdc.storageMu.Unlock()
+	return ftdc.storage
+}
+
+// ReclaimDir returns the directory FTDC writes files to. It implements `diskusage.Reclaimer`.
+func (ftdc *FTDC) ReclaimDir() string {
+	return ftdc.ftdcDir
+}
+
+// Reclaim deletes all but the newest FTDC files. It is called by the data manager when the disk is
+// full, before it deletes captured data. It implements `diskusage.Reclaimer`.
+func (ftdc *FTDC) Reclaim(ctx context.Context) (int64, error) {
+	files, err := getFTDCFilesDescendingTimeOrder(ftdc.ftdcDir, ftdc.logger)
+	if err != nil {
+		return 0, err
+	}
+
+	freedBytes := ftdc.deleteFilesOverBudget(files, diskPressureNumFiles, 0)
+	if freedBytes > 0 {
+		ftdc.logger.Infow("Deleted FTDC files to free up disk space", "freedBytes", freedBytes)
+	}
+	return freedBytes, nil
+}
+
+// deleteFilesOverBudget deletes the files, sorted in descending time order, once there are more
+// than `maxNumFiles` of them or they take up more than `maxTotalBytes`. A `maxTotalBytes` of zero,
+// or less, is unbounded. The newest file, which FTDC may still be writing to, is always kept. It returns the
+// number of bytes freed.
+func (ftdc *FTDC) deleteFilesOverBudget(files []fileTime, maxNumFiles int, maxTotalBytes int64) int64 {
+	ftdc.deleteMu.Lock()
+	defer ftdc.deleteMu.Unlock()
+
+	var totalBytes, freedBytes int64
+	for idx, file := range files {
+		totalBytes += file.size
+		if idx == 0 || (idx < maxNumFiles && (maxTotalBytes <= 0 || totalBytes <= maxTotalBytes)) {
+			continue
+		}
+
+		// Once a file is over budget, so are all of the older ones.
+		for _, file := range files[idx:] {
+			ftdc.logger.Debugw("Deleting aged out FTDC file", "filename", file.name)
+			if err := os.Remove(file.name); err != nil {
+				ftdc.logger.Warnw("Error removing FTDC file", "filename", file.name, "err", err)
+				continue
+			}
+			freedBytes += file.size
+		}
+		break
+	}
+	return freedBytes
+}
+
+// blockCompressor buffers FTDC bytes and writes them to `output` as independent zstd frames. A
+// compressed FTDC file is a sequence of such frames. Because every frame is complete on its own, a
+// file cut short by a crash is readable up to the last frame that was fully written.
+type blockCompressor struct {
+	output  io.Writer
+	encoder *zstd.Encoder
+
+	buf          []byte
+	bufStartTime time.Time
+}
+
+func newBlockCompressor(output io.Writer) (*blockCompressor, error) {
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &blockCompressor{output: output, encoder: encoder}, nil
+}
+
+func (bc *blockCompressor) Write(p []byte) (int, error) {
+	if len(bc.buf) == 0 {
+		bc.bufStartTime = time.Now()
+	}
+	bc.buf = append(bc.buf, p...)
+	return len(p), nil
+}
+
+// maybeFlush writes out the buffered block if it is large or old enough.
+func (bc *blockCompressor) maybeFlush() error {
+	if len(bc.buf) < compressedBlockBytes && time.Since(bc.bufStartTime) < compressedBlockAge {
+		return nil
+	}
+	return bc.flush()
+}
+
+// flush compresses the buffered bytes into a frame and writes it out.
+func (bc *blockCompressor) flush() error {
+	if len(bc.buf) == 0 {
+		return nil
+	}
+	_, err := bc.output.Write(bc.encoder.EncodeAll(bc.buf, nil))
+	bc.buf = bc.buf[:0]
+	return err
+}
+
+// maybeDecompress returns a reader of the decompressed FTDC data if `reader` is positioned at a
+// compressed block. Otherwise `reader` is returned as is. The returned close function must be
+// called when done reading.
+func maybeDecompress(reader *bufio.Reader) (*bufio.Reader, func(), error) {
+	magic, err := reader.Peek(len(zstdMagic))
+	if err != nil || !bytes.Equal(magic, zstdMagic) {
+		return reader, func() {}, nil
+	}
+
+	decoder, err := zstd.NewReader(reader, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, nil, err
+	}
+	return bufio.NewReader(truncatedBlockReader{decoder}), decoder.Close, nil
+}
+
+// truncatedBlockReader ends the decompressed data at a block cut short, e.g: by a crash while it
+// was written.
+type truncatedBlockReader struct {
+	reader io.Reader
+}
+
+func (tbr truncatedBlockReader) Read(p []byte) (int, error) {
+	n, err := tbr.reader.Read(p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	return n, err
+}
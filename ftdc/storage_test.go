@@ -0,0 +1,143 @@
+package ftdc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestCompressedFile(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	ftdc := New(t.TempDir(), logger.Sublogger("ftdc"))
+	ftdc.SetStorage(&StorageConfig{Compress: true})
+
+	foo := &foo{}
+	ftdc.Add("foo", foo)
+	writeDatums := func(from, to int) {
+		for cnt := from; cnt < to; cnt++ {
+			foo.x = cnt
+			foo.y = 2 * cnt
+			datum := ftdc.constructDatum()
+			datum.Time = int64(cnt)
+			test.That(t, ftdc.writeDatum(datum), test.ShouldBeNil)
+		}
+	}
+
+	// Write two blocks of 100 datums each.
+	writeDatums(0, 100)
+	test.That(t, ftdc.compressor, test.ShouldNotBeNil)
+	test.That(t, ftdc.compressor.flush(), test.ShouldBeNil)
+	firstBlockBytes := ftdc.bytesWrittenCounter.count
+	writeDatums(100, 200)
+	test.That(t, ftdc.compressor.flush(), test.ShouldBeNil)
+
+	filename := ftdc.currOutputFile.Name()
+	test.That(t, strings.HasSuffix(filename, ".ftdc.zst"), test.ShouldBeTrue)
+	test.That(t, IsFTDCFilename(filename), test.ShouldBeTrue)
+	contents, err := os.ReadFile(filename)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, int64(len(contents)), test.ShouldEqual, ftdc.bytesWrittenCounter.count)
+
+	datums, _, err := ParseWithLogger(bytes.NewReader(contents), logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, datums, test.ShouldHaveLength, 200)
+	test.That(t, datums[199].Time, test.ShouldEqual, 199)
+	test.That(t, datums[199].Readings, test.ShouldResemble, []Reading{
+		{MetricName: "foo.X", Value: 199},
+		{MetricName: "foo.Y", Value: 398},
+	})
+
+	// A file cut short while writing a block is readable up to the previous block.
+	datums, _, err = ParseWithLogger(bytes.NewReader(contents[:len(contents)-3]), logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, datums, test.ShouldHaveLength, 100)
+	datums, _, err = ParseWithLogger(bytes.NewReader(contents[:firstBlockBytes]), logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, datums, test.ShouldHaveLength, 100)
+
+	// Turning compression off starts a new, uncompressed, file. Use a new directory to avoid a
+	// filename collision, filenames only have second resolution.
+	ftdc.ftdcDir = t.TempDir()
+	ftdc.SetStorage(nil)
+	writeDatums(200, 210)
+	test.That(t, ftdc.compressor, test.ShouldBeNil)
+	test.That(t, strings.HasSuffix(ftdc.currOutputFile.Name(), ".ftdc"), test.ShouldBeTrue)
+
+	// The compressed file was completed before switching files.
+	completed, err := os.ReadFile(filename)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, completed, test.ShouldResemble, contents)
+}
+
+func TestDeleteFilesOverBudget(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	ftdcDir := t.TempDir()
+	ftdc := New(ftdcDir, logger.Sublogger("ftdc"))
+	ftdc.maxNumFiles = 4
+
+	// Files from oldest to newest, with their size in bytes.
+	writeFiles := func() {
+		for idx, size := range []int{400, 300, 200, 100, 100} {
+			name := filepath.Join(ftdcDir, fmt.Sprintf("viam-server-2024-11-18T20-37-%02dZ.ftdc", idx))
+			if idx%2 == 1 {
+				name += compressedFileSuffix
+			}
+			test.That(t, os.WriteFile(name, make([]byte, size), 0o644), test.ShouldBeNil)
+		}
+	}
+	remainingFiles := func() []string {
+		files, err := getFTDCFilesDescendingTimeOrder(ftdcDir, logger)
+		test.That(t, err, test.ShouldBeNil)
+		var ret []string
+		for _, file := range files {
+			ret = append(ret, filepath.Base(file.name))
+		}
+		return ret
+	}
+
+	// Only the number of files is bounded.
+	writeFiles()
+	test.That(t, ftdc.checkAndDeleteOldFiles(), test.ShouldBeNil)
+	test.That(t, remainingFiles(), test.ShouldResemble, []string{
+		"viam-server-2024-11-18T20-37-04Z.ftdc",
+		"viam-server-2024-11-18T20-37-03Z.ftdc.zst",
+		"viam-server-2024-11-18T20-37-02Z.ftdc",
+		"viam-server-2024-11-18T20-37-01Z.ftdc.zst",
+	})
+
+	// The newest files within 450 bytes are kept.
+	writeFiles()
+	ftdc.SetStorage(&StorageConfig{MaxTotalBytes: 450})
+	test.That(t, ftdc.checkAndDeleteOldFiles(), test.ShouldBeNil)
+	test.That(t, remainingFiles(), test.ShouldResemble, []string{
+		"viam-server-2024-11-18T20-37-04Z.ftdc",
+		"viam-server-2024-11-18T20-37-03Z.ftdc.zst",
+		"viam-server-2024-11-18T20-37-02Z.ftdc",
+	})
+
+	// The newest file is kept, even if it alone is over the budget.
+	ftdc.SetStorage(&StorageConfig{MaxTotalBytes: 50})
+	test.That(t, ftdc.checkAndDeleteOldFiles(), test.ShouldBeNil)
+	test.That(t, remainingFiles(), test.ShouldResemble, []string{"viam-server-2024-11-18T20-37-04Z.ftdc"})
+
+	// When the disk is full, only the newest files are kept.
+	writeFiles()
+	ftdc.SetStorage(nil)
+	freedBytes, err := ftdc.Reclaim(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, freedBytes, test.ShouldEqual, 900)
+	test.That(t, remainingFiles(), test.ShouldResemble, []string{
+		"viam-server-2024-11-18T20-37-04Z.ftdc",
+		"viam-server-2024-11-18T20-37-03Z.ftdc.zst",
+	})
+}
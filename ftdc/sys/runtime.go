@@ -0,0 +1,55 @@
+package sys
+
+import (
+	"runtime/metrics"
+
+	"go.viam.com/rdk/ftdc"
+)
+
+type runtimeStats struct {
+	Goroutines  int
+	HeapAllocMB float64
+	HeapObjects uint64
+	NumGC       uint32
+}
+
+// The runtime metrics read for `runtimeStats`. Unlike `runtime.ReadMemStats`, reading them does not
+// stop the world.
+const (
+	goroutinesMetric  = "/sched/goroutines:goroutines"
+	heapAllocMetric   = "/memory/classes/heap/objects:bytes"
+	heapObjectsMetric = "/gc/heap/objects:objects"
+	numGCMetric       = "/gc/cycles/total:gc-cycles"
+)
+
+type runtimeStatser struct{}
+
+// NewRuntimeStatser returns an ftdc statser for the Go runtime of the current process, e.g: the
+// number of goroutines.
+func NewRuntimeStatser() ftdc.Statser {
+	return runtimeStatser{}
+}
+
+func (runtimeStatser) Stats() any {
+	samples := []metrics.Sample{
+		{Name: goroutinesMetric},
+		{Name: heapAllocMetric},
+		{Name: heapObjectsMetric},
+		{Name: numGCMetric},
+	}
+	metrics.Read(samples)
+
+	// A metric this version of Go does not support reads as `KindBad`. Leave it at zero.
+	values := make(map[string]uint64, len(samples))
+	for _, sample := range samples {
+		if sample.Value.Kind() == metrics.KindUint64 {
+			values[sample.Name] = sample.Value.Uint64()
+		}
+	}
+	return runtimeStats{
+		Goroutines:  int(values[goroutinesMetric]),
+		HeapAllocMB: float64(values[heapAllocMetric]) / 1_000_000,
+		HeapObjects: values[heapObjectsMetric],
+		NumGC:       uint32(values[numGCMetric]),
+	}
+}
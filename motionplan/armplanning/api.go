@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/pkg/errors"
@@ -47,6 +48,8 @@ type PlanRequest struct {
 	Constraints *motionplan.Constraints `json:"constraints"`
 	// Other more granular parameters for the plan used to move the robot.
 	PlannerOptions *PlannerOptions `json:"planner_options"`
+	// The precomputed roadmap searched by the prm algorithm.
+	Roadmap *Roadmap `json:"-"`
 
 	myTestOptions testOptions
 }
@@ -134,6 +137,31 @@ func (req *PlanRequest) validatePlanRequest() error {
 		}
 	}
 
+	if req.PlannerOptions.CartesianPath != nil {
+		if err := req.PlannerOptions.CartesianPath.validate(req.Goals, req.FrameSystem); err != nil {
+			return err
+		}
+	}
+
+	if err := req.PlannerOptions.Algorithm.validate(); err != nil {
+		return err
+	}
+	usesRoadmap := req.PlannerOptions.Algorithm == PRMAlgorithm
+	if req.PlannerOptions.PlannerRace != nil {
+		if err := req.PlannerOptions.PlannerRace.validate(); err != nil {
+			return err
+		}
+		usesRoadmap = usesRoadmap || slices.Contains(req.PlannerOptions.PlannerRace.Algorithms, PRMAlgorithm)
+	}
+	if usesRoadmap {
+		if req.Roadmap == nil {
+			return errors.New("the prm algorithm needs a roadmap")
+		}
+		if err := req.Roadmap.CheckValid(req.FrameSystem, req.WorldState); err != nil {
+			return err
+		}
+	}
+
 	if req.Constraints == nil {
 		req.Constraints = &motionplan.Constraints{}
 	}
@@ -180,6 +208,11 @@ type PlanMeta struct {
 	Partial        bool
 	PartialError   error
 	GoalsProcessed int
+	// CacheHit is whether the plan was reused from a PlanCache.
+	CacheHit bool
+
+	// The index of the trajectory step at which each goal is reached.
+	goalSteps []int
 }
 
 // PlanMotion plans a motion from a provided plan request.
@@ -212,12 +245,19 @@ func PlanMotion(ctx context.Context, parentLogger logging.Logger, request *PlanR
 		return nil, meta, errors.New("must populate start state configuration")
 	}
 
-	sfPlanner, err := newPlanManager(ctx, logger, request, meta)
-	if err != nil {
-		return nil, meta, err
+	var trajAsInps []*referenceframe.LinearInputs
+	var goalsProcessed int
+	var err error
+	if request.PlannerOptions.PlannerRace != nil {
+		trajAsInps, goalsProcessed, err = racePlanners(ctx, logger, request, meta)
+	} else {
+		var sfPlanner *planManager
+		sfPlanner, err = newPlanManager(ctx, logger, request, meta)
+		if err != nil {
+			return nil, meta, err
+		}
+		trajAsInps, goalsProcessed, err = sfPlanner.planMultiWaypoint(ctx)
 	}
-
-	trajAsInps, goalsProcessed, err := sfPlanner.planMultiWaypoint(ctx)
 	if err != nil {
 		if request.PlannerOptions.ReturnPartialPlan {
 			meta.Partial = true
@@ -0,0 +1,320 @@
+package armplanning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/utils/trace"
+
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/motionplan/ik"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	// Number of gradient descents IK makes for each pose along a Cartesian path.
+	cartesianIKAttempts = 3
+
+	// The maximum percent of a joints range of motion IK may move it between poses along a Cartesian path, lest it flip to
+	// another IK branch.
+	cartesianFrameStep = 0.05
+)
+
+// CartesianPathOptions make the planner move the frames of goal poses along Cartesian paths from their current poses, rather
+// than along any path it finds: along straight lines, through consecutive goals along polylines, or along circular arcs. The
+// path is sampled every Resolution mm/degrees, and IK is solved for each sample starting from the solution for the previous
+// one, such that the planner reports exactly where the path becomes infeasible. Goals given as configurations are still
+// planned in joint space.
+type CartesianPathOptions struct {
+	// Max deviation of the frames from the path, in mm. Defaults to 0.5 mm when zero.
+	LineToleranceMm float64 `json:"line_tolerance_mm"`
+
+	// Max deviation of the orientation of the frames from the path, in degrees. Defaults to 0.5 degrees when zero.
+	OrientationToleranceDegs float64 `json:"orientation_tolerance_degs"`
+
+	// The path of the frames to the goal at the same index is the circular arc through the given via poses, rather than a
+	// straight line, as in the circular moves of robot controllers. The orientation along an arc is interpolated between the
+	// start and goal orientations.
+	ArcVias []referenceframe.FrameSystemPoses `json:"arc_vias"`
+}
+
+func (opts *CartesianPathOptions) validate(goals []*PlanState, fs *referenceframe.FrameSystem) error {
+	if opts.LineToleranceMm < 0 {
+		return errors.New("cartesian_path line_tolerance_mm can't be negative")
+	}
+	if opts.OrientationToleranceDegs < 0 {
+		return errors.New("cartesian_path orientation_tolerance_degs can't be negative")
+	}
+	if len(opts.ArcVias) > len(goals) {
+		return fmt.Errorf("cartesian_path has arc vias for %d goals, but there are %d goals", len(opts.ArcVias), len(goals))
+	}
+	for i, vias := range opts.ArcVias {
+		for fName, pif := range vias {
+			if _, ok := goals[i].poses[fName]; !ok {
+				return fmt.Errorf("cartesian_path arc via of frame %s has no goal pose for goal %d", fName, i)
+			}
+			if fs.Frame(pif.Parent()) == nil {
+				return referenceframe.NewParentFrameMissingError(fName, pif.Parent())
+			}
+		}
+	}
+	return nil
+}
+
+func (opts *CartesianPathOptions) tolerances() (float64, float64) {
+	lineTolerance := opts.LineToleranceMm
+	if lineTolerance == 0 {
+		lineTolerance = defaultCartesianLineToleranceMm
+	}
+	orientationTolerance := opts.OrientationToleranceDegs
+	if orientationTolerance == 0 {
+		orientationTolerance = defaultCartesianOrientationToleranceDegs
+	}
+	return lineTolerance, orientationTolerance
+}
+
+// CartesianPathError reports where the Cartesian path to a goal becomes infeasible.
+type CartesianPathError struct {
+	// Goal is the index of the goal whose path is infeasible.
+	Goal int
+	// Fraction is how far along the path to the goal, from 0 to 1, the first pose which can't be reached is.
+	Fraction float64
+	// Poses are the poses of the frames, in the world frame, which can't be reached.
+	Poses referenceframe.FrameSystemPoses
+	// Err is why the poses can't be reached.
+	Err error
+}
+
+func (e *CartesianPathError) Error() string {
+	return fmt.Sprintf("cartesian path to goal %d is infeasible %.1f%% along: %v", e.Goal, 100*e.Fraction, e.Err)
+}
+
+func (e *CartesianPathError) Unwrap() error {
+	return e.Err
+}
+
+// cartesianSegment is the path of a frame from its start pose to its goal pose, both in the world frame, along either a
+// straight line or a circular arc.
+type cartesianSegment struct {
+	start, goal spatialmath.Pose
+
+	// A circular arc starts at center + radius*axis1, and sweeps the given angle towards axis2.
+	arc          bool
+	center       r3.Vector
+	axis1, axis2 r3.Vector
+	radius       float64
+	sweep        float64
+}
+
+// newCartesianSegment returns the straight line from start to goal, or the circular arc through via when it isn't nil.
+func newCartesianSegment(start, goal, via spatialmath.Pose) (*cartesianSegment, error) {
+	segment := &cartesianSegment{start: start, goal: goal}
+	if via == nil {
+		return segment, nil
+	}
+
+	// The center of the circle through three points, relative to the first one, is
+	// (|u|²w - |w|²u) × (u × w) / 2|u × w|².
+	u, w := via.Point().Sub(start.Point()), goal.Point().Sub(start.Point())
+	normal := u.Cross(w)
+	if normal.Norm2() <= 1e-12*u.Norm2()*w.Norm2() {
+		return nil, errors.New("arc via position is collinear with the start and goal positions")
+	}
+	segment.arc = true
+	segment.center = start.Point().Add(w.Mul(u.Norm2()).Sub(u.Mul(w.Norm2())).Cross(normal).Mul(1 / (2 * normal.Norm2())))
+
+	// Going from the start, through the via to the goal is counterclockwise about the normal.
+	fromCenter := start.Point().Sub(segment.center)
+	segment.radius = fromCenter.Norm()
+	segment.axis1 = fromCenter.Normalize()
+	segment.axis2 = normal.Normalize().Cross(segment.axis1)
+	toGoal := goal.Point().Sub(segment.center)
+	segment.sweep = math.Atan2(toGoal.Dot(segment.axis2), toGoal.Dot(segment.axis1))
+	if segment.sweep <= 0 {
+		segment.sweep += 2 * math.Pi
+	}
+	return segment, nil
+}
+
+// poseAt returns the pose the given fraction along the segment.
+func (s *cartesianSegment) poseAt(by float64) spatialmath.Pose {
+	pose := spatialmath.Interpolate(s.start, s.goal, by)
+	if !s.arc {
+		return pose
+	}
+	angle := by * s.sweep
+	point := s.center.Add(s.axis1.Mul(s.radius * math.Cos(angle))).Add(s.axis2.Mul(s.radius * math.Sin(angle)))
+	return spatialmath.NewPose(point, pose.Orientation())
+}
+
+// stepCount returns the number of steps which sample the segment every resolution mm/degrees.
+func (s *cartesianSegment) stepCount(resolution float64) int {
+	length := s.start.Point().Distance(s.goal.Point())
+	if s.arc {
+		length = s.radius * s.sweep
+	}
+	rotation := utils.RadToDeg(spatialmath.OrientationBetween(s.start.Orientation(), s.goal.Orientation()).AxisAngles().Theta)
+	return max(1, int(math.Ceil(math.Max(length, math.Abs(rotation))/resolution)))
+}
+
+// planCartesian plans the motion of the frames of the goal poses along their Cartesian paths from the start configuration.
+// It returns the steps up to the last reachable pose along the paths, even when they become infeasible.
+func (pm *planManager) planCartesian(
+	ctx context.Context,
+	goalIdx int,
+	start *referenceframe.LinearInputs,
+	goal referenceframe.FrameSystemPoses,
+) ([]*referenceframe.LinearInputs, error) {
+	ctx, span := trace.StartSpan(ctx, "planCartesian")
+	defer span.End()
+	opts := pm.request.PlannerOptions.CartesianPath
+
+	psc, err := newPlanSegmentContext(ctx, pm.pc, start, goal)
+	if err != nil {
+		return nil, err
+	}
+	vias := referenceframe.FrameSystemPoses{}
+	if goalIdx < len(opts.ArcVias) {
+		vias, err = translateGoalsToWorldPosition(pm.pc.fs, start, opts.ArcVias[goalIdx])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resolution := pm.request.PlannerOptions.Resolution
+	if resolution <= 0 {
+		resolution = defaultResolution
+	}
+	segments := map[string]*cartesianSegment{}
+	numSteps := 1
+	for frame, goalPIF := range psc.goal {
+		var via spatialmath.Pose
+		if viaPIF, ok := vias[frame]; ok {
+			via = viaPIF.Pose()
+		}
+		segment, err := newCartesianSegment(psc.startPoses[frame].Pose(), goalPIF.Pose(), via)
+		if err != nil {
+			return nil, fmt.Errorf("cartesian path of frame %s: %w", frame, err)
+		}
+		segments[frame] = segment
+		numSteps = max(numSteps, segment.stepCount(resolution))
+	}
+	pm.logger.Debugf("cartesian path to goal %d has %d steps", goalIdx, numSteps)
+
+	solver, err := ik.CreateNloptSolver(pm.logger.Sublogger("ik"), cartesianIKAttempts, false, true, time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	// Frames which don't move towards the goal keep their inputs.
+	_, nonmoving := psc.motionChains.framesFilteredByMovingAndNonmoving()
+	frameSteps := []float64{}
+	for _, frameName := range pm.pc.lis.FrameNamesInOrder() {
+		frameStep := cartesianFrameStep
+		if slices.Contains(nonmoving, frameName) {
+			frameStep = 0
+		}
+		for range pm.pc.fs.Frame(frameName).DoF() {
+			frameSteps = append(frameSteps, frameStep)
+		}
+	}
+
+	steps := []*referenceframe.LinearInputs{}
+	prev := start
+	for i := 1; i <= numSteps; i++ {
+		by := float64(i) / float64(numSteps)
+		target := referenceframe.FrameSystemPoses{}
+		for frame, segment := range segments {
+			target[frame] = referenceframe.NewPoseInFrame(referenceframe.World, segment.poseAt(by))
+		}
+
+		next, err := pm.solveCartesianStep(ctx, psc, solver, prev, target, frameSteps)
+		if err != nil {
+			return steps, &CartesianPathError{Goal: goalIdx, Fraction: by, Poses: target, Err: err}
+		}
+		steps = append(steps, next)
+		prev = next
+	}
+	return steps, nil
+}
+
+// solveCartesianStep solves IK for the target poses starting from the previous configuration, and returns the closest
+// solution within the tolerances of the path which can be moved to from the previous configuration.
+func (pm *planManager) solveCartesianStep(
+	ctx context.Context,
+	psc *planSegmentContext,
+	solver *ik.NloptIK,
+	prev *referenceframe.LinearInputs,
+	target referenceframe.FrameSystemPoses,
+	frameSteps []float64,
+) (*referenceframe.LinearInputs, error) {
+	seed := prev.GetLinearizedInputs()
+	var totalAttempts atomic.Int32
+	solutions, _, err := ik.DoSolve(ctx, solver, &totalAttempts,
+		pm.pc.linearizeFSmetric(pm.pc.planOpts.getGoalMetric(target)),
+		[][]float64{seed}, [][]referenceframe.Limit{ik.ComputeAdjustLimitsArray(seed, pm.pc.lis.GetLimits(), frameSteps)})
+	if err != nil {
+		return nil, err
+	}
+
+	lineTolerance, orientationTolerance := pm.request.PlannerOptions.CartesianPath.tolerances()
+	var best *referenceframe.LinearInputs
+	bestDistance := math.Inf(1)
+	var deviationErr error
+	for _, solution := range solutions {
+		step, err := pm.pc.lis.FloatsToInputs(solution)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkCartesianDeviation(pm.pc.fs, step, target, lineTolerance, orientationTolerance); err != nil {
+			deviationErr = err
+			continue
+		}
+		distance := pm.pc.configurationDistanceFunc(&motionplan.SegmentFS{StartConfiguration: prev, EndConfiguration: step})
+		if distance < bestDistance {
+			best, bestDistance = step, distance
+		}
+	}
+	if best == nil {
+		return nil, deviationErr
+	}
+
+	if err := psc.checkPath(ctx, prev, best, true); err != nil {
+		return nil, err
+	}
+	return best, nil
+}
+
+// checkCartesianDeviation returns an error when the frames deviate from their target poses by more than the tolerances.
+func checkCartesianDeviation(
+	fs *referenceframe.FrameSystem,
+	step *referenceframe.LinearInputs,
+	target referenceframe.FrameSystemPoses,
+	lineTolerance, orientationTolerance float64,
+) error {
+	for frame, targetPIF := range target {
+		tf, err := fs.Transform(step, referenceframe.NewZeroPoseInFrame(frame), referenceframe.World)
+		if err != nil {
+			return err
+		}
+		pose := tf.(*referenceframe.PoseInFrame).Pose()
+		if distance := pose.Point().Distance(targetPIF.Pose().Point()); distance > lineTolerance {
+			return fmt.Errorf("frame %s deviates %.2f mm from its path, more than the line tolerance of %.2f mm",
+				frame, distance, lineTolerance)
+		}
+		rotation := utils.RadToDeg(spatialmath.OrientationBetween(pose.Orientation(), targetPIF.Pose().Orientation()).AxisAngles().Theta)
+		if math.Abs(rotation) > orientationTolerance {
+			return fmt.Errorf("frame %s deviates %.2f degrees from its path, more than the orientation tolerance of %.2f degrees",
+				frame, math.Abs(rotation), orientationTolerance)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package armplanning
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+func TestCartesianSegment(t *testing.T) {
+	start := spatialmath.NewPoseFromPoint(r3.Vector{X: 100})
+	goal := spatialmath.NewPoseFromPoint(r3.Vector{X: -100})
+
+	line, err := newCartesianSegment(start, goal, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.R3VectorAlmostEqual(line.poseAt(0.25).Point(), r3.Vector{X: 50}, 1e-9), test.ShouldBeTrue)
+	test.That(t, line.stepCount(2), test.ShouldEqual, 100)
+
+	arc, err := newCartesianSegment(start, goal, spatialmath.NewPoseFromPoint(r3.Vector{Y: 100}))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.R3VectorAlmostEqual(arc.center, r3.Vector{}, 1e-9), test.ShouldBeTrue)
+	test.That(t, spatialmath.R3VectorAlmostEqual(arc.poseAt(0.5).Point(), r3.Vector{Y: 100}, 1e-9), test.ShouldBeTrue)
+	test.That(t, spatialmath.R3VectorAlmostEqual(arc.poseAt(1).Point(), r3.Vector{X: -100}, 1e-9), test.ShouldBeTrue)
+	test.That(t, arc.stepCount(2), test.ShouldEqual, int(math.Ceil(100*math.Pi/2)))
+
+	// An arc through a via on the far side of the circle sweeps the long way around.
+	arc, err = newCartesianSegment(start, spatialmath.NewPoseFromPoint(r3.Vector{Y: 100}), spatialmath.NewPoseFromPoint(r3.Vector{Y: -100}))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, arc.sweep, test.ShouldAlmostEqual, 3*math.Pi/2)
+
+	_, err = newCartesianSegment(start, goal, spatialmath.NewPoseFromPoint(r3.Vector{}))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "collinear")
+}
+
+func TestPlanCartesian(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	armModel, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/xarm6.json"), "xarm6")
+	test.That(t, err, test.ShouldBeNil)
+	fs := referenceframe.NewEmptyFrameSystem("")
+	test.That(t, fs.AddFrame(armModel, fs.World()), test.ShouldBeNil)
+
+	startConfig := referenceframe.FrameSystemInputs{"xarm6": {0, -0.3, -0.8, 0, 1.1, 0}}
+	startPoses, err := startConfig.ComputePoses(fs)
+	test.That(t, err, test.ShouldBeNil)
+	startPose := startPoses["xarm6"].Pose()
+	offsetPose := func(offset r3.Vector) referenceframe.FrameSystemPoses {
+		return referenceframe.FrameSystemPoses{
+			"xarm6": referenceframe.NewPoseInFrame(referenceframe.World,
+				spatialmath.NewPose(startPose.Point().Add(offset), startPose.Orientation())),
+		}
+	}
+	request := func(cartesianPath *CartesianPathOptions, goals ...referenceframe.FrameSystemPoses) *PlanRequest {
+		opts := NewBasicPlannerOptions()
+		opts.CartesianPath = cartesianPath
+		req := &PlanRequest{
+			FrameSystem:    fs,
+			StartState:     NewPlanState(nil, startConfig),
+			PlannerOptions: opts,
+		}
+		for _, goal := range goals {
+			req.Goals = append(req.Goals, NewPlanState(goal, nil))
+		}
+		return req
+	}
+	pathPoints := func(t *testing.T, req *PlanRequest) []r3.Vector {
+		t.Helper()
+		plan, _, err := PlanMotion(context.Background(), logger, req)
+		test.That(t, err, test.ShouldBeNil)
+		poses, err := plan.Path().GetFramePoses("xarm6")
+		test.That(t, err, test.ShouldBeNil)
+		points := []r3.Vector{}
+		for _, pose := range poses {
+			points = append(points, pose.Point().Sub(startPose.Point()))
+		}
+		return points
+	}
+
+	t.Run("polyline", func(t *testing.T) {
+		points := pathPoints(t, request(&CartesianPathOptions{},
+			offsetPose(r3.Vector{X: 40}), offsetPose(r3.Vector{X: 40, Y: 40})))
+		// The path is sampled about every 2 mm along each leg.
+		test.That(t, len(points), test.ShouldBeBetweenOrEqual, 41, 42)
+		for _, point := range points {
+			onFirstLeg := math.Hypot(point.Y, point.Z) <= defaultCartesianLineToleranceMm
+			onSecondLeg := math.Hypot(point.X-40, point.Z) <= defaultCartesianLineToleranceMm
+			test.That(t, onFirstLeg || onSecondLeg, test.ShouldBeTrue)
+		}
+		test.That(t, spatialmath.R3VectorAlmostEqual(points[len(points)-1], r3.Vector{X: 40, Y: 40}, defaultCartesianLineToleranceMm), test.ShouldBeTrue)
+	})
+
+	t.Run("arc", func(t *testing.T) {
+		points := pathPoints(t, request(&CartesianPathOptions{ArcVias: []referenceframe.FrameSystemPoses{offsetPose(r3.Vector{X: 25, Y: 25})}},
+			offsetPose(r3.Vector{X: 50})))
+		test.That(t, len(points), test.ShouldBeGreaterThan, 2)
+		center := r3.Vector{X: 25}
+		for _, point := range points {
+			test.That(t, math.Abs(point.Distance(center)-25), test.ShouldBeLessThanOrEqualTo, defaultCartesianLineToleranceMm)
+			test.That(t, math.Abs(point.Z), test.ShouldBeLessThanOrEqualTo, defaultCartesianLineToleranceMm)
+		}
+	})
+
+	t.Run("reports where the path becomes infeasible", func(t *testing.T) {
+		req := request(&CartesianPathOptions{}, offsetPose(r3.Vector{Z: 2000}))
+		req.PlannerOptions.ReturnPartialPlan = true
+		plan, meta, err := PlanMotion(context.Background(), logger, req)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, meta.Partial, test.ShouldBeTrue)
+
+		var pathErr *CartesianPathError
+		test.That(t, errors.As(meta.PartialError, &pathErr), test.ShouldBeTrue)
+		test.That(t, pathErr.Goal, test.ShouldEqual, 0)
+		test.That(t, pathErr.Fraction, test.ShouldBeBetween, 0, 1)
+		// The plan reaches the pose just before the infeasible one.
+		test.That(t, len(plan.Trajectory()), test.ShouldEqual, int(math.Round(pathErr.Fraction*1000)))
+	})
+
+	t.Run("validates arc vias", func(t *testing.T) {
+		_, _, err := PlanMotion(context.Background(), logger, request(&CartesianPathOptions{
+			ArcVias: []referenceframe.FrameSystemPoses{offsetPose(r3.Vector{X: 25}), offsetPose(r3.Vector{X: 25})},
+		}, offsetPose(r3.Vector{X: 50})))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "arc vias for 2 goals")
+	})
+}
@@ -1,7 +1,9 @@
 package armplanning
 
 import (
+	"cmp"
 	"math"
+	"slices"
 
 	"go.viam.com/rdk/motionplan"
 )
@@ -28,3 +30,25 @@ func nearestNeighbor(seed *node, tree rrtMap, nodeDistanceFunc NodeDistanceMetri
 	}
 	return best
 }
+
+// kNearestNeighbors returns the indices of the up to k nodes of candidates nearest to seed, nearest first. The seed
+// itself is skipped if it is a candidate.
+func kNearestNeighbors(seed *node, candidates []*node, k int, nodeDistanceFunc NodeDistanceMetric) []int {
+	type neighbor struct {
+		idx  int
+		dist float64
+	}
+	neighbors := make([]neighbor, 0, len(candidates))
+	for i, candidate := range candidates {
+		if candidate != seed {
+			neighbors = append(neighbors, neighbor{i, nodeDistanceFunc(seed, candidate)})
+		}
+	}
+	slices.SortFunc(neighbors, func(a, b neighbor) int { return cmp.Compare(a.dist, b.dist) })
+
+	nearest := make([]int, 0, k)
+	for _, n := range neighbors[:min(k, len(neighbors))] {
+		nearest = append(nearest, n.idx)
+	}
+	return nearest
+}
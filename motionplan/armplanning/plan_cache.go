@@ -0,0 +1,221 @@
+package armplanning
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"go.viam.com/utils/trace"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+)
+
+// Width of the buckets start configurations are rounded to in the keys of a PlanCache, in radians or mm.
+const defaultPlanCacheStartBucketSize = 0.01
+
+// PlanCache reuses the plans of identical requests, such as the same pick and place moves made over and over again.
+//
+// Plans are keyed by the hash of the frame system, the start configuration rounded to buckets, the goals, the
+// constraints, the planner options, and the names of the obstacles and the transforms of the world state. As obstacles
+// with the same names may have moved, a cached plan is only reused once its whole trajectory, starting from the actual
+// start configuration, is checked against the constraints and the current obstacles of the request. Plans which fail
+// the check are evicted and planned again.
+type PlanCache struct {
+	maxEntries      int
+	startBucketSize float64
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	lru     list.List // of *planCacheEntry, most recently used first
+}
+
+type planCacheEntry struct {
+	key [sha256.Size]byte
+
+	// The trajectory of the cached plan, and the index of the step at which each goal is reached.
+	traj      motionplan.Trajectory
+	goalSteps []int
+}
+
+// planCacheKey holds what a plan depends on, and is serialized to JSON and hashed to key a PlanCache.
+type planCacheKey struct {
+	FrameSystem    int                           `json:"frame_system"`
+	StartBuckets   map[string][]int64            `json:"start_buckets"`
+	Goals          []*PlanState                  `json:"goals"`
+	Constraints    *motionplan.Constraints       `json:"constraints"`
+	PlannerOptions *PlannerOptions               `json:"planner_options"`
+	ObstacleNames  []string                      `json:"obstacle_names"`
+	Transforms     []*referenceframe.LinkInFrame `json:"transforms"`
+}
+
+// NewPlanCache returns a PlanCache which holds up to maxEntries plans, evicting the least recently used ones. Start
+// configurations within the same startBucketSize radians or mm wide buckets share plans, defaulting to 0.01 when zero.
+func NewPlanCache(maxEntries int, startBucketSize float64) (*PlanCache, error) {
+	if maxEntries <= 0 {
+		return nil, fmt.Errorf("plan cache must hold at least 1 plan, not %d", maxEntries)
+	}
+	if startBucketSize < 0 {
+		return nil, fmt.Errorf("plan cache start bucket size can't be negative, got %v", startBucketSize)
+	}
+	if startBucketSize == 0 {
+		startBucketSize = defaultPlanCacheStartBucketSize
+	}
+	return &PlanCache{
+		maxEntries:      maxEntries,
+		startBucketSize: startBucketSize,
+		entries:         map[[sha256.Size]byte]*list.Element{},
+	}, nil
+}
+
+// PlanMotion plans a motion like the PlanMotion function, reusing the plan of an identical earlier request when it is
+// still valid. PlanMeta.CacheHit reports whether it was. Partial plans aren't cached.
+func (c *PlanCache) PlanMotion(ctx context.Context, parentLogger logging.Logger, request *PlanRequest) (motionplan.Plan, *PlanMeta, error) {
+	ctx, span := trace.StartSpan(ctx, "PlanCache.PlanMotion")
+	defer span.End()
+	logger := parentLogger.Sublogger("mp")
+
+	start := time.Now()
+	if err := request.validatePlanRequest(); err != nil {
+		return nil, &PlanMeta{}, err
+	}
+	key, err := c.key(request)
+	if err != nil {
+		return nil, &PlanMeta{}, err
+	}
+
+	if entry := c.get(key); entry != nil {
+		plan, err := reuseCachedPlan(ctx, logger, request, entry)
+		if err == nil {
+			logger.CDebugf(ctx, "reusing cached plan with %d steps", len(entry.traj))
+			return plan, &PlanMeta{Duration: time.Since(start), GoalsProcessed: len(request.Goals), CacheHit: true}, nil
+		}
+		logger.CInfof(ctx, "cached plan is no longer valid, planning again: %v", err)
+		c.remove(key)
+	}
+
+	plan, meta, err := PlanMotion(ctx, parentLogger, request)
+	if err != nil || meta.Partial {
+		return plan, meta, err
+	}
+	c.put(&planCacheEntry{key: key, traj: plan.Trajectory(), goalSteps: meta.goalSteps})
+	return plan, meta, nil
+}
+
+// Len returns the number of plans in the cache.
+func (c *PlanCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *PlanCache) key(request *PlanRequest) ([sha256.Size]byte, error) {
+	key := planCacheKey{
+		FrameSystem:    request.FrameSystem.Hash(),
+		StartBuckets:   map[string][]int64{},
+		Goals:          request.Goals,
+		Constraints:    request.Constraints,
+		PlannerOptions: request.PlannerOptions,
+		Transforms:     request.WorldState.Transforms(),
+	}
+	for frame, inputs := range request.StartState.Configuration() {
+		buckets := make([]int64, len(inputs))
+		for i, input := range inputs {
+			buckets[i] = int64(math.Round(input / c.startBucketSize))
+		}
+		key.StartBuckets[frame] = buckets
+	}
+	for name := range request.WorldState.ObstacleNames() {
+		key.ObstacleNames = append(key.ObstacleNames, name)
+	}
+	slices.Sort(key.ObstacleNames)
+
+	data, err := json.Marshal(key)
+	if err != nil {
+		return [sha256.Size]byte{}, fmt.Errorf("cannot serialize plan cache key: %w", err)
+	}
+	return sha256.Sum256(data), nil
+}
+
+func (c *PlanCache) get(key [sha256.Size]byte) *planCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*planCacheEntry)
+}
+
+func (c *PlanCache) put(entry *planCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[entry.key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*planCacheEntry).key)
+	}
+}
+
+func (c *PlanCache) remove(key [sha256.Size]byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+// reuseCachedPlan returns the cached plan starting from the start configuration of the request, once it has checked
+// the plan against the constraints and obstacles of the request.
+func reuseCachedPlan(ctx context.Context, logger logging.Logger, request *PlanRequest, entry *planCacheEntry) (motionplan.Plan, error) {
+	ctx, span := trace.StartSpan(ctx, "reuseCachedPlan")
+	defer span.End()
+	if len(entry.goalSteps) != len(request.Goals) {
+		return nil, fmt.Errorf("cached plan reaches %d goals, but there are %d goals", len(entry.goalSteps), len(request.Goals))
+	}
+
+	traj := make([]*referenceframe.LinearInputs, len(entry.traj))
+	traj[0] = request.StartState.LinearConfiguration()
+	for i := 1; i < len(traj); i++ {
+		traj[i] = entry.traj[i].ToLinearInputs()
+	}
+
+	pm, err := newPlanManager(ctx, logger, request, &PlanMeta{})
+	if err != nil {
+		return nil, err
+	}
+	from := 0
+	for i, goal := range request.Goals {
+		goalPoses, err := goal.ComputePoses(ctx, request.FrameSystem)
+		if err != nil {
+			return nil, err
+		}
+		psc, err := newPlanSegmentContext(ctx, pm.pc, traj[from], goalPoses)
+		if err != nil {
+			return nil, err
+		}
+		for step := from; step < entry.goalSteps[i]; step++ {
+			if err := psc.checkPath(ctx, traj[step], traj[step+1], true); err != nil {
+				return nil, fmt.Errorf("step %d of cached plan: %w", step+1, err)
+			}
+		}
+		from = entry.goalSteps[i]
+	}
+
+	return motionplan.NewSimplePlanFromTrajectory(traj, request.FrameSystem)
+}
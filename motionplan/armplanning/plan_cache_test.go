@@ -0,0 +1,96 @@
+package armplanning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+func TestPlanCache(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	armModel, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/xarm6.json"), "xarm6")
+	test.That(t, err, test.ShouldBeNil)
+	fs := referenceframe.NewEmptyFrameSystem("")
+	test.That(t, fs.AddFrame(armModel, fs.World()), test.ShouldBeNil)
+
+	startConfig := []referenceframe.Input{0, -0.3, -0.8, 0, 1.1, 0}
+	startPoses, err := referenceframe.FrameSystemInputs{"xarm6": startConfig}.ComputePoses(fs)
+	test.That(t, err, test.ShouldBeNil)
+	goal := referenceframe.FrameSystemPoses{"xarm6": referenceframe.NewPoseInFrame(referenceframe.World,
+		spatialmath.NewPose(startPoses["xarm6"].Pose().Point().Add(r3.Vector{X: 50, Y: 100}), startPoses["xarm6"].Pose().Orientation()))}
+	worldState := func(obstaclePoint r3.Vector) *referenceframe.WorldState {
+		box, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(obstaclePoint), r3.Vector{X: 50, Y: 50, Z: 50}, "box")
+		test.That(t, err, test.ShouldBeNil)
+		ws, err := referenceframe.NewWorldState(
+			[]*referenceframe.GeometriesInFrame{referenceframe.NewGeometriesInFrame(referenceframe.World, []spatialmath.Geometry{box})}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		return ws
+	}
+	request := func(start []referenceframe.Input, ws *referenceframe.WorldState) *PlanRequest {
+		return &PlanRequest{
+			FrameSystem: fs,
+			Goals:       []*PlanState{NewPlanState(goal, nil)},
+			StartState:  NewPlanState(nil, referenceframe.FrameSystemInputs{"xarm6": start}),
+			WorldState:  ws,
+		}
+	}
+
+	_, err = NewPlanCache(0, 0)
+	test.That(t, err, test.ShouldNotBeNil)
+	cache, err := NewPlanCache(2, 0)
+	test.That(t, err, test.ShouldBeNil)
+
+	farAway := worldState(r3.Vector{X: -1000, Y: -1000})
+	plan, meta, err := cache.PlanMotion(ctx, logger, request(startConfig, farAway))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, meta.CacheHit, test.ShouldBeFalse)
+	test.That(t, cache.Len(), test.ShouldEqual, 1)
+
+	t.Run("identical requests reuse the plan", func(t *testing.T) {
+		cached, meta, err := cache.PlanMotion(ctx, logger, request(startConfig, farAway))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, meta.CacheHit, test.ShouldBeTrue)
+		test.That(t, cached.Trajectory(), test.ShouldResemble, plan.Trajectory())
+	})
+
+	t.Run("start configurations in the same bucket reuse the plan from the actual start", func(t *testing.T) {
+		nearby := []referenceframe.Input{0.001, -0.3, -0.8, 0, 1.1, 0}
+		cached, meta, err := cache.PlanMotion(ctx, logger, request(nearby, farAway))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, meta.CacheHit, test.ShouldBeTrue)
+		test.That(t, cached.Trajectory()[0]["xarm6"], test.ShouldResemble, nearby)
+		test.That(t, cached.Trajectory()[1:], test.ShouldResemble, plan.Trajectory()[1:])
+	})
+
+	t.Run("cached plans are checked against the current obstacles", func(t *testing.T) {
+		req := request(startConfig, worldState(goal["xarm6"].Pose().Point()))
+		test.That(t, req.validatePlanRequest(), test.ShouldBeNil)
+		key, err := cache.key(req)
+		test.That(t, err, test.ShouldBeNil)
+		entry := cache.get(key)
+		test.That(t, entry, test.ShouldNotBeNil)
+		_, err = reuseCachedPlan(ctx, logger, req, entry)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "obstacle constraint")
+	})
+
+	t.Run("least recently used plans are evicted", func(t *testing.T) {
+		for _, start := range [][]referenceframe.Input{{0.1, -0.3, -0.8, 0, 1.1, 0}, {0.2, -0.3, -0.8, 0, 1.1, 0}} {
+			_, meta, err := cache.PlanMotion(ctx, logger, request(start, farAway))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, meta.CacheHit, test.ShouldBeFalse)
+		}
+		test.That(t, cache.Len(), test.ShouldEqual, 2)
+		_, meta, err := cache.PlanMotion(ctx, logger, request(startConfig, farAway))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, meta.CacheHit, test.ShouldBeFalse)
+	})
+}
@@ -2,6 +2,7 @@ package armplanning
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -79,6 +80,13 @@ func (pm *planManager) planMultiWaypoint(ctx context.Context) ([]*referenceframe
 				return linearTraj, i, err
 			}
 			linearTraj = append(linearTraj, newTraj...)
+		} else if pm.request.PlannerOptions.CartesianPath != nil {
+			newTraj, err := pm.planCartesian(ctx, i, linearTraj[len(linearTraj)-1], to)
+			// The steps up to where the path becomes infeasible are kept for ReturnPartialPlan.
+			linearTraj = append(linearTraj, newTraj...)
+			if err != nil {
+				return linearTraj, i, err
+			}
 		} else {
 			subGoals, cbirrtAllowed, err := pm.generateWaypoints(ctx, start, to)
 			if err != nil {
@@ -105,6 +113,7 @@ func (pm *planManager) planMultiWaypoint(ctx context.Context) ([]*referenceframe
 				linearTraj = append(linearTraj, newTraj...)
 			}
 		}
+		pm.pc.planMeta.goalSteps = append(pm.pc.planMeta.goalSteps, len(linearTraj)-1)
 		start = to
 	}
 
@@ -152,6 +161,16 @@ func (pm *planManager) planToDirectJoints(
 	if err != nil {
 		return nil, fmt.Errorf("want to go to specific joint config but it is invalid: %w", err)
 	}
+	switch pm.request.PlannerOptions.Algorithm {
+	case DirectIKAlgorithm:
+		return nil, errors.New("direct path to specific joint config is blocked, and the direct_ik algorithm doesn't search for another")
+	case PRMAlgorithm:
+		steps, err := pm.planRoadmap(ctx, psc, []*referenceframe.LinearInputs{fullConfig})
+		if err != nil {
+			return nil, err
+		}
+		return smoothPath(ctx, psc, steps)
+	}
 
 	pathPlanner, err := newCBiRRTMotionPlanner(ctx, pm.pc, psc, pm.logger.Sublogger("cbirrt"))
 	if err != nil {
@@ -208,6 +227,20 @@ func (pm *planManager) planSingleGoal(
 	if !cbirrtAllowed {
 		return nil, fmt.Errorf("linear with cbirrt not allowed and no direct solutions found")
 	}
+	switch pm.request.PlannerOptions.Algorithm {
+	case DirectIKAlgorithm:
+		return nil, errors.New("no direct solutions found with the direct_ik algorithm")
+	case PRMAlgorithm:
+		goals := make([]*referenceframe.LinearInputs, 0, len(planSeed.maps.goalMap))
+		for goal := range planSeed.maps.goalMap {
+			goals = append(goals, goal.inputs)
+		}
+		steps, err := pm.planRoadmap(ctx, psc, goals)
+		if err != nil {
+			return nil, err
+		}
+		return smoothPath(ctx, psc, steps)
+	}
 
 	pm.logger.Debugf("initRRTSolutions goalMap size: %d", len(planSeed.maps.goalMap))
 	pathPlanner, err := newCBiRRTMotionPlanner(ctx, pm.pc, psc, pm.logger.Sublogger("cbirrt"))
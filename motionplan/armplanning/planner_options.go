@@ -44,6 +44,10 @@ const (
 	defaultIterBeforeRand = 50
 
 	defaultOptimalityMultiple = 3.0
+
+	// Max deviation of the frames from a Cartesian path, in mm and degrees.
+	defaultCartesianLineToleranceMm          = 0.5
+	defaultCartesianOrientationToleranceDegs = 0.5
 )
 
 var defaultNumThreads = utils.MinInt(runtime.NumCPU()/2, 10)
@@ -128,6 +132,15 @@ type PlannerOptions struct {
 
 	// Setting indicating that all mesh geometries should be converted into octrees.
 	MeshesAsOctrees bool `json:"meshes_as_octrees"`
+
+	// If set, the frames of goal poses move along Cartesian paths to them, see CartesianPathOptions.
+	CartesianPath *CartesianPathOptions `json:"cartesian_path"`
+
+	// The algorithm planning the motion to goals, see PlannerAlgorithm. Defaults to cbirrt when empty.
+	Algorithm PlannerAlgorithm `json:"algorithm"`
+
+	// If set, several planning attempts run concurrently, see PlannerRaceOptions.
+	PlannerRace *PlannerRaceOptions `json:"planner_race"`
 }
 
 // NewPlannerOptionsFromExtra returns basic default settings updated by overridden parameters
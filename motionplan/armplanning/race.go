@@ -0,0 +1,163 @@
+package armplanning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+	"go.viam.com/utils/trace"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+)
+
+// Number of concurrent planning attempts a planner race makes by default.
+const defaultRaceAttempts = 4
+
+// PlannerAlgorithm is a string enum indicating which algorithm plans the motion to goal poses and configurations.
+type PlannerAlgorithm string
+
+const (
+	// CBiRRTAlgorithm moves directly to an IK solution of the goal when it can, and otherwise searches for a path to one
+	// with cBiRRT. It is the default.
+	CBiRRTAlgorithm PlannerAlgorithm = "cbirrt"
+	// DirectIKAlgorithm only moves directly to an IK solution of the goal, interpolating in configuration space, and fails
+	// when no such move is valid. It is fast, but fails around obstacles.
+	DirectIKAlgorithm PlannerAlgorithm = "direct_ik"
+	// PRMAlgorithm moves directly to an IK solution of the goal when it can, and otherwise searches the precomputed
+	// Roadmap of the request for a path to one, see Roadmap.
+	PRMAlgorithm PlannerAlgorithm = "prm"
+)
+
+func (a PlannerAlgorithm) validate() error {
+	switch a {
+	case "", CBiRRTAlgorithm, DirectIKAlgorithm, PRMAlgorithm:
+		return nil
+	default:
+		return fmt.Errorf("unknown planner algorithm %q", a)
+	}
+}
+
+// PlannerRaceOptions make the planner run several planning attempts concurrently, trading CPU for latency and
+// reliability. Each attempt uses a different random seed, counting up from RandomSeed, and the next algorithm of
+// Algorithms in turn.
+type PlannerRaceOptions struct {
+	// Number of concurrent attempts. Defaults to 4 when zero.
+	Attempts int `json:"attempts"`
+
+	// The algorithms of the attempts. Defaults to the Algorithm of the planner options when empty.
+	Algorithms []PlannerAlgorithm `json:"algorithms"`
+
+	// If set, all attempts run until they finish or time out, and the plan with the lowest cost, as evaluated by the
+	// ConfigurationDistanceMetric, is returned. Otherwise the first valid plan is returned, and the other attempts are
+	// canceled.
+	LowestCost bool `json:"lowest_cost"`
+}
+
+func (opts *PlannerRaceOptions) validate() error {
+	if opts.Attempts < 0 {
+		return errors.New("planner_race attempts can't be negative")
+	}
+	for _, algorithm := range opts.Algorithms {
+		if err := algorithm.validate(); err != nil {
+			return fmt.Errorf("planner_race: %w", err)
+		}
+	}
+	return nil
+}
+
+type raceResult struct {
+	attempt        int
+	traj           []*referenceframe.LinearInputs
+	goalsProcessed int
+	meta           *PlanMeta
+	err            error
+}
+
+// racePlanners runs the attempts of a planner race, and returns the trajectory of the winning attempt like
+// planMultiWaypoint. When no attempt succeeds, it returns the trajectory of the one which processed the most goals, for
+// ReturnPartialPlan, with the errors of all attempts.
+func racePlanners(ctx context.Context, logger logging.Logger, request *PlanRequest, meta *PlanMeta) (
+	[]*referenceframe.LinearInputs, int, error,
+) {
+	ctx, span := trace.StartSpan(ctx, "racePlanners")
+	defer span.End()
+	opts := request.PlannerOptions.PlannerRace
+	attempts := opts.Attempts
+	if attempts == 0 {
+		attempts = defaultRaceAttempts
+	}
+	algorithms := opts.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = []PlannerAlgorithm{request.PlannerOptions.Algorithm}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan raceResult, attempts)
+	for i := range attempts {
+		attemptOpts := *request.PlannerOptions
+		attemptOpts.RandomSeed += i
+		attemptOpts.Algorithm = algorithms[i%len(algorithms)]
+		attemptOpts.PlannerRace = nil
+		attemptRequest := *request
+		attemptRequest.PlannerOptions = &attemptOpts
+		// PlanStates cache their linearized configuration, which planning mutates, so each attempt has its own.
+		attemptRequest.StartState = NewPlanState(request.StartState.poses, request.StartState.structuredConfiguration)
+		attemptRequest.Goals = make([]*PlanState, len(request.Goals))
+		for j, goal := range request.Goals {
+			attemptRequest.Goals[j] = NewPlanState(goal.poses, goal.structuredConfiguration)
+		}
+		attemptLogger := logger.Sublogger(fmt.Sprintf("attempt-%d", i))
+
+		utils.PanicCapturingGo(func() {
+			result := raceResult{attempt: i, meta: &PlanMeta{}}
+			defer func() { results <- result }()
+			pm, err := newPlanManager(ctx, attemptLogger, &attemptRequest, result.meta)
+			if err != nil {
+				result.err = err
+				return
+			}
+			result.traj, result.goalsProcessed, result.err = pm.planMultiWaypoint(ctx)
+		})
+	}
+
+	distanceFunc := motionplan.GetConfigurationDistanceFunc(request.PlannerOptions.ConfigurationDistanceMetric)
+	var best *raceResult
+	var bestCost float64
+	var errs error
+	// Every result is received, such that no attempt outlives the race.
+	for range attempts {
+		result := <-results
+		if result.err != nil {
+			logger.Debugf("planner race attempt %d with algorithm %s failed: %v", result.attempt, algorithms[result.attempt%len(algorithms)],
+				result.err)
+			errs = multierr.Combine(errs, fmt.Errorf("attempt %d: %w", result.attempt, result.err))
+			if best == nil || best.err != nil && result.goalsProcessed > best.goalsProcessed {
+				best = &result
+			}
+			continue
+		}
+
+		cost := motionplan.TrajectoryFromLinearInputs(result.traj).EvaluateCost(distanceFunc)
+		logger.Debugf("planner race attempt %d with algorithm %s found a plan with cost %.3f", result.attempt,
+			algorithms[result.attempt%len(algorithms)], cost)
+		if best == nil || best.err != nil || opts.LowestCost && cost < bestCost {
+			best, bestCost = &result, cost
+		}
+		if !opts.LowestCost {
+			cancel()
+		}
+	}
+
+	meta.goalSteps = best.meta.goalSteps
+	if best.err != nil {
+		return best.traj, best.goalsProcessed, errs
+	}
+	logger.Infof("planner race attempt %d with algorithm %s won with cost %.3f", best.attempt,
+		algorithms[best.attempt%len(algorithms)], bestCost)
+	return best.traj, best.goalsProcessed, nil
+}
@@ -0,0 +1,66 @@
+package armplanning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+func TestRacePlanners(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	armModel, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/xarm6.json"), "xarm6")
+	test.That(t, err, test.ShouldBeNil)
+	fs := referenceframe.NewEmptyFrameSystem("")
+	test.That(t, fs.AddFrame(armModel, fs.World()), test.ShouldBeNil)
+
+	startConfig := []referenceframe.Input{0, -0.3, -0.8, 0, 1.1, 0}
+	startPoses, err := referenceframe.FrameSystemInputs{"xarm6": startConfig}.ComputePoses(fs)
+	test.That(t, err, test.ShouldBeNil)
+	goal := referenceframe.FrameSystemPoses{"xarm6": referenceframe.NewPoseInFrame(referenceframe.World,
+		spatialmath.NewPose(startPoses["xarm6"].Pose().Point().Add(r3.Vector{X: 50, Y: 100}), startPoses["xarm6"].Pose().Orientation()))}
+	request := func(race *PlannerRaceOptions) *PlanRequest {
+		opts := NewBasicPlannerOptions()
+		opts.PlannerRace = race
+		return &PlanRequest{
+			FrameSystem:    fs,
+			Goals:          []*PlanState{NewPlanState(goal, nil)},
+			StartState:     NewPlanState(nil, referenceframe.FrameSystemInputs{"xarm6": startConfig}),
+			PlannerOptions: opts,
+		}
+	}
+	checkPlan := func(t *testing.T, race *PlannerRaceOptions) {
+		t.Helper()
+		plan, meta, err := PlanMotion(ctx, logger, request(race))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, meta.GoalsProcessed, test.ShouldEqual, 1)
+		test.That(t, meta.goalSteps, test.ShouldResemble, []int{len(plan.Trajectory()) - 1})
+		test.That(t, plan.Trajectory()[0]["xarm6"], test.ShouldResemble, startConfig)
+		poses, err := plan.Trajectory()[len(plan.Trajectory())-1].ComputePoses(fs)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, spatialmath.PoseAlmostCoincidentEps(poses["xarm6"].Pose(), goal["xarm6"].Pose(), 1), test.ShouldBeTrue)
+	}
+
+	t.Run("first valid plan", func(t *testing.T) {
+		checkPlan(t, &PlannerRaceOptions{Attempts: 3, Algorithms: []PlannerAlgorithm{CBiRRTAlgorithm, DirectIKAlgorithm}})
+	})
+
+	t.Run("lowest cost plan", func(t *testing.T) {
+		checkPlan(t, &PlannerRaceOptions{Algorithms: []PlannerAlgorithm{DirectIKAlgorithm}, LowestCost: true})
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, _, err := PlanMotion(ctx, logger, request(&PlannerRaceOptions{Attempts: -1}))
+		test.That(t, err, test.ShouldNotBeNil)
+		_, _, err = PlanMotion(ctx, logger, request(&PlannerRaceOptions{Algorithms: []PlannerAlgorithm{"rrt*"}}))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "unknown planner algorithm")
+	})
+}
@@ -0,0 +1,347 @@
+package armplanning
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+	"go.viam.com/utils/trace"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+)
+
+const (
+	// Number of collision-free configurations a roadmap is built with by default.
+	defaultRoadmapNodes = 500
+	// Number of nearest nodes each node of a roadmap tries to connect to by default.
+	defaultRoadmapNeighbors = 10
+	// Number of configurations sampled per roadmap node before building the roadmap gives up.
+	maxRoadmapSamplesPerNode = 100
+	// Number of times a roadmap query searches again after finding an edge of its path blocked.
+	maxRoadmapSearches = 10
+)
+
+// RoadmapOptions are the parameters a Roadmap is built with.
+type RoadmapOptions struct {
+	// Number of collision-free configurations in the roadmap. Defaults to 500 when zero.
+	Nodes int `json:"nodes"`
+
+	// Number of nearest nodes each node tries to connect to, and which the start and goals of queries try to connect
+	// to. Defaults to 10 when zero.
+	Neighbors int `json:"neighbors"`
+}
+
+// Roadmap is a probabilistic roadmap (PRM): a graph of collision-free configurations connected by collision-free
+// straight lines in configuration space. It is built once, offline, for a workcell whose obstacles don't move, and
+// answers requests with the prm algorithm by connecting their start and goals to the graph and searching it for the
+// shortest path.
+//
+// A roadmap is only valid for the frame system and the world state it was built for. Requests with either changed
+// fail, and the roadmap must be built again. Roadmaps are serialized to JSON, such that robots can boot with them.
+type Roadmap struct {
+	FrameSystemHash int `json:"frame_system_hash"`
+	WorldStateHash  int `json:"world_state_hash"`
+	Neighbors       int `json:"neighbors"`
+
+	// The inputs of the frames moving along the roadmap. The frames which don't move keep the inputs of the start of
+	// each request.
+	Nodes []referenceframe.FrameSystemInputs `json:"nodes"`
+	// The indices of the nodes each node is connected to.
+	Edges [][]int `json:"edges"`
+}
+
+// BuildRoadmap builds a Roadmap for the frame system and world state of request. The frames of the goals of request are
+// the frames moving along the roadmap, while the goal poses themselves are ignored. The start configuration of request
+// is the reference which collisions allowed between frames are determined from, and gives the inputs of frames which
+// don't move. Nodes and edges must satisfy the constraints of request, and the random seed of its planner options is
+// used to sample nodes.
+func BuildRoadmap(ctx context.Context, logger logging.Logger, request *PlanRequest, opts *RoadmapOptions) (*Roadmap, error) {
+	ctx, span := trace.StartSpan(ctx, "BuildRoadmap")
+	defer span.End()
+	if err := request.validatePlanRequest(); err != nil {
+		return nil, err
+	}
+	if len(request.Goals) == 0 {
+		return nil, errors.New("roadmap request must have a goal naming the frames which move")
+	}
+	numNodes, numNeighbors := defaultRoadmapNodes, defaultRoadmapNeighbors
+	if opts != nil && opts.Nodes != 0 {
+		numNodes = opts.Nodes
+	}
+	if opts != nil && opts.Neighbors != 0 {
+		numNeighbors = opts.Neighbors
+	}
+	if numNodes < 0 || numNeighbors < 0 {
+		return nil, fmt.Errorf("roadmap nodes and neighbors can't be negative, got %d and %d", numNodes, numNeighbors)
+	}
+
+	pm, err := newPlanManager(ctx, logger, request, &PlanMeta{})
+	if err != nil {
+		return nil, err
+	}
+	goalPoses, err := request.Goals[0].ComputePoses(ctx, request.FrameSystem)
+	if err != nil {
+		return nil, err
+	}
+	reference := request.StartState.LinearConfiguration()
+	psc, err := newPlanSegmentContext(ctx, pm.pc, reference, goalPoses)
+	if err != nil {
+		return nil, err
+	}
+	moving, _ := psc.motionChains.framesFilteredByMovingAndNonmoving()
+
+	nodes := make([]*node, 0, numNodes)
+	nodeInputs := make([]referenceframe.FrameSystemInputs, 0, numNodes)
+	for samples := 0; len(nodes) < numNodes; samples++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if samples >= numNodes*maxRoadmapSamplesPerNode {
+			return nil, fmt.Errorf("only found %d collision-free configurations for the roadmap after %d samples", len(nodes), samples)
+		}
+		inputs := reference.Copy()
+		movingInputs := referenceframe.FrameSystemInputs{}
+		for name := range reference.Keys() {
+			if f := request.FrameSystem.Frame(name); slices.Contains(moving, name) && f != nil && len(f.DoF()) > 0 {
+				movingInputs[name] = referenceframe.RandomFrameInputs(f, pm.pc.randseed)
+				inputs.Put(name, movingInputs[name])
+			}
+		}
+		if _, err := psc.checker.CheckStateFSConstraints(ctx, &motionplan.StateFS{Configuration: inputs, FS: request.FrameSystem}); err != nil {
+			continue
+		}
+		nodes = append(nodes, newConfigurationNode(inputs))
+		nodeInputs = append(nodeInputs, movingInputs)
+	}
+	logger.Debugf("sampled %d roadmap nodes", len(nodes))
+
+	rm := &Roadmap{
+		FrameSystemHash: request.FrameSystem.Hash(),
+		WorldStateHash:  request.WorldState.Hash(),
+		Neighbors:       numNeighbors,
+		Nodes:           nodeInputs,
+		Edges:           make([][]int, len(nodes)),
+	}
+	distanceFunc := roadmapDistanceFunc(pm.pc)
+	checked := map[[2]int]bool{}
+	for i, n := range nodes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for _, j := range kNearestNeighbors(n, nodes, numNeighbors, distanceFunc) {
+			// Nodes which are among each other's nearest neighbors are only checked once.
+			pair := [2]int{min(i, j), max(i, j)}
+			if checked[pair] {
+				continue
+			}
+			checked[pair] = true
+			if err := psc.checkPath(ctx, n.inputs, nodes[j].inputs, false); err != nil {
+				continue
+			}
+			rm.Edges[i] = append(rm.Edges[i], j)
+			rm.Edges[j] = append(rm.Edges[j], i)
+		}
+	}
+	logger.Infof("built roadmap with %d nodes from %d checked edges", len(nodes), len(checked))
+	return rm, nil
+}
+
+// ReadRoadmapFromFile reads a Roadmap from a json file.
+func ReadRoadmapFromFile(fileName string) (*Roadmap, error) {
+	f, err := os.Open(fileName) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	defer utils.UncheckedErrorFunc(f.Close)
+
+	rm := &Roadmap{}
+	if err := json.NewDecoder(f).Decode(rm); err != nil {
+		return nil, err
+	}
+	if len(rm.Edges) != len(rm.Nodes) {
+		return nil, fmt.Errorf("roadmap has %d nodes but edges for %d", len(rm.Nodes), len(rm.Edges))
+	}
+	return rm, nil
+}
+
+// WriteToFile writes a roadmap to a .json file.
+func (rm *Roadmap) WriteToFile(fileName string) error {
+	data, err := json.Marshal(rm)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filepath.Clean(fileName), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		return multierr.Combine(err, file.Close())
+	}
+	return file.Close()
+}
+
+// CheckValid returns an error if the roadmap wasn't built for the given frame system and world state.
+func (rm *Roadmap) CheckValid(fs *referenceframe.FrameSystem, worldState *referenceframe.WorldState) error {
+	if rm.FrameSystemHash != fs.Hash() {
+		return errors.New("roadmap was built for a different frame system, and must be built again")
+	}
+	if rm.WorldStateHash != worldState.Hash() {
+		return errors.New("roadmap was built for a different world state, and must be built again")
+	}
+	return nil
+}
+
+func roadmapDistanceFunc(pc *planContext) NodeDistanceMetric {
+	return func(a, b *node) float64 {
+		return pc.configurationDistanceFunc(&motionplan.SegmentFS{StartConfiguration: a.inputs, EndConfiguration: b.inputs})
+	}
+}
+
+// planRoadmap plans a path from the start of psc to any of goals through the roadmap of the request. Like the paths of
+// cBiRRT, the returned path starts with the start configuration.
+func (pm *planManager) planRoadmap(
+	ctx context.Context, psc *planSegmentContext, goals []*referenceframe.LinearInputs,
+) ([]*referenceframe.LinearInputs, error) {
+	ctx, span := trace.StartSpan(ctx, "planRoadmap")
+	defer span.End()
+	rm := pm.request.Roadmap
+	distanceFunc := roadmapDistanceFunc(pm.pc)
+
+	// The roadmap nodes are followed by the start and the goals, which are connected to their nearest roadmap nodes.
+	nodes := make([]*node, 0, len(rm.Nodes)+1+len(goals))
+	for _, movingInputs := range rm.Nodes {
+		inputs := psc.start.Copy()
+		for name, frameInputs := range movingInputs {
+			inputs.Put(name, frameInputs)
+		}
+		nodes = append(nodes, newConfigurationNode(inputs))
+	}
+	roadmapNodes := nodes
+	startIdx := len(nodes)
+	nodes = append(nodes, newConfigurationNode(psc.start))
+	edges := make([][]int, len(nodes)+len(goals))
+	copy(edges, rm.Edges)
+	connect := func(idx int, checkFinal bool) {
+		for _, j := range kNearestNeighbors(nodes[idx], roadmapNodes, rm.Neighbors, distanceFunc) {
+			from, to := nodes[idx], nodes[j]
+			if checkFinal {
+				from, to = to, from
+			}
+			if psc.checkPath(ctx, from.inputs, to.inputs, checkFinal) == nil {
+				// Roadmap edges are extended into new slices, as the edges of the roadmap are shared between queries.
+				edges[idx] = append(edges[idx], j)
+				edges[j] = append(slices.Clip(edges[j]), idx)
+			}
+		}
+	}
+	connect(startIdx, false)
+	isGoal := make([]bool, len(edges))
+	for _, goal := range goals {
+		nodes = append(nodes, newConfigurationNode(goal))
+		isGoal[len(nodes)-1] = true
+		connect(len(nodes)-1, true)
+	}
+
+	blocked := map[[2]int]bool{}
+	for range maxRoadmapSearches {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		path := shortestRoadmapPath(nodes, edges, blocked, startIdx, isGoal, distanceFunc)
+		if path == nil {
+			return nil, errors.New("no path through the roadmap connects the start and goal")
+		}
+
+		// Edges between roadmap nodes were checked when the roadmap was built, but are checked again against the
+		// constraints of this request, and blocked edges searched around.
+		steps := []*referenceframe.LinearInputs{psc.start}
+		valid := true
+		for i := 1; i < len(path); i++ {
+			a, b := path[i-1], path[i]
+			if a < len(roadmapNodes) && b < len(roadmapNodes) {
+				if err := psc.checkPath(ctx, nodes[a].inputs, nodes[b].inputs, false); err != nil {
+					pm.logger.Debugf("roadmap edge %d-%d is blocked: %v", a, b, err)
+					blocked[[2]int{a, b}], blocked[[2]int{b, a}] = true, true
+					valid = false
+					break
+				}
+			}
+			steps = append(steps, nodes[b].inputs)
+		}
+		if valid {
+			pm.logger.Debugf("found a path through %d roadmap nodes", len(path)-2)
+			return steps, nil
+		}
+	}
+	return nil, fmt.Errorf("roadmap paths were blocked %d times", maxRoadmapSearches)
+}
+
+// shortestRoadmapPath returns the indices of the nodes of the shortest path from start to any goal, using Dijkstra's
+// algorithm, or nil if there is none.
+func shortestRoadmapPath(
+	nodes []*node, edges [][]int, blocked map[[2]int]bool, start int, isGoal []bool, distanceFunc NodeDistanceMetric,
+) []int {
+	dist := make([]float64, len(nodes))
+	for i := range dist {
+		dist[i] = math.Inf(1)
+	}
+	prev := make([]int, len(nodes))
+	dist[start] = 0
+	queue := &roadmapQueue{{idx: start}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(roadmapQueueItem)
+		if item.dist > dist[item.idx] {
+			continue
+		}
+		if isGoal[item.idx] {
+			path := []int{item.idx}
+			for idx := item.idx; idx != start; idx = prev[idx] {
+				path = append(path, prev[idx])
+			}
+			slices.Reverse(path)
+			return path
+		}
+		for _, j := range edges[item.idx] {
+			if blocked[[2]int{item.idx, j}] {
+				continue
+			}
+			if d := item.dist + distanceFunc(nodes[item.idx], nodes[j]); d < dist[j] {
+				dist[j], prev[j] = d, item.idx
+				heap.Push(queue, roadmapQueueItem{idx: j, dist: d})
+			}
+		}
+	}
+	return nil
+}
+
+type roadmapQueueItem struct {
+	idx  int
+	dist float64
+}
+
+// roadmapQueue is a min-heap of nodes by their distance from the start.
+type roadmapQueue []roadmapQueueItem
+
+func (q roadmapQueue) Len() int           { return len(q) }
+func (q roadmapQueue) Less(i, j int) bool { return q[i].dist < q[j].dist }
+func (q roadmapQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *roadmapQueue) Push(x any) { *q = append(*q, x.(roadmapQueueItem)) }
+
+func (q *roadmapQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,148 @@
+package armplanning
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+func TestRoadmap(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	armModel, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/xarm6.json"), "xarm6")
+	test.That(t, err, test.ShouldBeNil)
+	fs := referenceframe.NewEmptyFrameSystem("")
+	test.That(t, fs.AddFrame(armModel, fs.World()), test.ShouldBeNil)
+
+	startConfig := []referenceframe.Input{0, -0.3, -0.8, 0, 1.1, 0}
+	goalConfig := []referenceframe.Input{1.5, -0.3, -0.8, 0, 1.1, 0}
+	// The box is where the arm passes halfway through rotating its waist from the start to the goal.
+	midPoses, err := referenceframe.FrameSystemInputs{"xarm6": {0.75, -0.3, -0.8, 0, 1.1, 0}}.ComputePoses(fs)
+	test.That(t, err, test.ShouldBeNil)
+	box, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(midPoses["xarm6"].Pose().Point()), r3.Vector{X: 60, Y: 60, Z: 60}, "box")
+	test.That(t, err, test.ShouldBeNil)
+	worldState, err := referenceframe.NewWorldState(
+		[]*referenceframe.GeometriesInFrame{referenceframe.NewGeometriesInFrame(referenceframe.World, []spatialmath.Geometry{box})}, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	request := func(goal []referenceframe.Input) *PlanRequest {
+		return &PlanRequest{
+			FrameSystem: fs,
+			Goals:       []*PlanState{NewPlanState(nil, referenceframe.FrameSystemInputs{"xarm6": goal})},
+			StartState:  NewPlanState(nil, referenceframe.FrameSystemInputs{"xarm6": startConfig}),
+			WorldState:  worldState,
+		}
+	}
+
+	rm, err := BuildRoadmap(ctx, logger, request(startConfig), &RoadmapOptions{Nodes: 150, Neighbors: 8})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(rm.Nodes), test.ShouldEqual, 150)
+	test.That(t, len(rm.Edges), test.ShouldEqual, 150)
+	for _, inputs := range rm.Nodes {
+		test.That(t, inputs, test.ShouldContainKey, "xarm6")
+	}
+	for i, edges := range rm.Edges {
+		for _, j := range edges {
+			test.That(t, rm.Edges[j], test.ShouldContain, i)
+		}
+	}
+
+	t.Run("serialization", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "roadmap.json")
+		test.That(t, rm.WriteToFile(fileName), test.ShouldBeNil)
+		read, err := ReadRoadmapFromFile(fileName)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, read, test.ShouldResemble, rm)
+	})
+
+	t.Run("paths around obstacles", func(t *testing.T) {
+		req := request(goalConfig)
+		req.PlannerOptions = NewBasicPlannerOptions()
+		req.PlannerOptions.Algorithm = PRMAlgorithm
+		req.Roadmap = rm
+		plan, _, err := PlanMotion(ctx, logger, req)
+		test.That(t, err, test.ShouldBeNil)
+		traj := plan.Trajectory()
+		test.That(t, len(traj), test.ShouldBeGreaterThan, 2)
+		test.That(t, traj[len(traj)-1]["xarm6"], test.ShouldResemble, goalConfig)
+
+		pm, err := newPlanManager(ctx, logger, req, &PlanMeta{})
+		test.That(t, err, test.ShouldBeNil)
+		goalPoses, err := req.Goals[0].ComputePoses(ctx, fs)
+		test.That(t, err, test.ShouldBeNil)
+		psc, err := newPlanSegmentContext(ctx, pm.pc, req.StartState.LinearConfiguration(), goalPoses)
+		test.That(t, err, test.ShouldBeNil)
+		for i := 1; i < len(traj); i++ {
+			test.That(t, psc.checkPath(ctx, traj[i-1].ToLinearInputs(), traj[i].ToLinearInputs(), true), test.ShouldBeNil)
+		}
+	})
+
+	t.Run("frames which don't move keep the start inputs", func(t *testing.T) {
+		railFS := referenceframe.NewEmptyFrameSystem("")
+		test.That(t, railFS.AddFrame(armModel, railFS.World()), test.ShouldBeNil)
+		rail, err := referenceframe.NewTranslationalFrame("rail", r3.Vector{X: 1}, referenceframe.Limit{Min: -1000, Max: 1000})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, railFS.AddFrame(rail, railFS.World()), test.ShouldBeNil)
+		goalPoses, err := referenceframe.FrameSystemInputs{"xarm6": goalConfig, "rail": {0}}.ComputePoses(railFS)
+		test.That(t, err, test.ShouldBeNil)
+		railRequest := func(railInputs []referenceframe.Input) *PlanRequest {
+			return &PlanRequest{
+				FrameSystem: railFS,
+				Goals:       []*PlanState{NewPlanState(referenceframe.FrameSystemPoses{"xarm6": goalPoses["xarm6"]}, nil)},
+				StartState:  NewPlanState(nil, referenceframe.FrameSystemInputs{"xarm6": startConfig, "rail": railInputs}),
+				WorldState:  worldState,
+			}
+		}
+
+		railRM, err := BuildRoadmap(ctx, logger, railRequest([]referenceframe.Input{0}), &RoadmapOptions{Nodes: 80, Neighbors: 8})
+		test.That(t, err, test.ShouldBeNil)
+		for _, inputs := range railRM.Nodes {
+			test.That(t, inputs, test.ShouldNotContainKey, "rail")
+		}
+
+		req := railRequest([]referenceframe.Input{300})
+		req.PlannerOptions = NewBasicPlannerOptions()
+		req.PlannerOptions.Algorithm = PRMAlgorithm
+		req.Roadmap = railRM
+		plan, _, err := PlanMotion(ctx, logger, req)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(plan.Trajectory()), test.ShouldBeGreaterThan, 2)
+		for _, step := range plan.Trajectory() {
+			test.That(t, step["rail"], test.ShouldResemble, []referenceframe.Input{300})
+		}
+	})
+
+	t.Run("changed world states invalidate the roadmap", func(t *testing.T) {
+		req := request(goalConfig)
+		req.PlannerOptions = NewBasicPlannerOptions()
+		req.PlannerOptions.Algorithm = PRMAlgorithm
+		req.Roadmap = rm
+		req.WorldState = referenceframe.NewEmptyWorldState()
+		_, _, err := PlanMotion(ctx, logger, req)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "different world state")
+
+		req.Roadmap = nil
+		_, _, err = PlanMotion(ctx, logger, req)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "needs a roadmap")
+	})
+
+	t.Run("planner race", func(t *testing.T) {
+		req := request(goalConfig)
+		req.PlannerOptions = NewBasicPlannerOptions()
+		req.PlannerOptions.PlannerRace = &PlannerRaceOptions{Attempts: 2, Algorithms: []PlannerAlgorithm{PRMAlgorithm, DirectIKAlgorithm}}
+		req.Roadmap = rm
+		plan, _, err := PlanMotion(ctx, logger, req)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, plan.Trajectory()[len(plan.Trajectory())-1]["xarm6"], test.ShouldResemble, goalConfig)
+	})
+}
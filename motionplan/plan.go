@@ -4,6 +4,7 @@ package motionplan
 import (
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/golang/geo/r3"
 	geo "github.com/kellydunn/golang-geo"
@@ -38,10 +39,26 @@ type Plan interface {
 	Trajectory() Trajectory
 }
 
+// TimedPlan is implemented by Plans which know the time at which each step of their Trajectory is reached
+// when it is followed as fast as the kinematic limits of its frames allow.
+type TimedPlan interface {
+	Plan
+	Timestamps() []time.Duration
+}
+
+// PlanTimestamps returns the Timestamps of a Plan implementing TimedPlan, or nil when they are not known.
+func PlanTimestamps(plan Plan) []time.Duration {
+	if timed, ok := plan.(TimedPlan); ok {
+		return timed.Timestamps()
+	}
+	return nil
+}
+
 // SimplePlan is a simple implementation of Plan.
 type SimplePlan struct {
-	path Path
-	traj Trajectory
+	path       Path
+	traj       Trajectory
+	timestamps []time.Duration
 }
 
 // NewSimplePlan instantiates a new Plan from a Path and Trajectory.
@@ -76,7 +93,13 @@ func NewSimplePlanFromTrajectory(
 		path = append(path, poseMap)
 	}
 
-	return &SimplePlan{path: path, traj: TrajectoryFromLinearInputs(trajAsInputs)}, nil
+	traj := TrajectoryFromLinearInputs(trajAsInputs)
+	// Plans whose frames lack velocity limits have no timestamps.
+	timestamps, err := TimeParameterize(traj, FrameSystemKinematicLimits(fs))
+	if err != nil {
+		timestamps = nil
+	}
+	return &SimplePlan{path: path, traj: traj, timestamps: timestamps}, nil
 }
 
 // NewGeoPlan returns a Plan containing a Path with GPS coordinates smuggled into the Pose struct. Each GPS point is created using:
@@ -96,7 +119,9 @@ func NewGeoPlan(plan Plan, pt *geo.Point) Plan {
 		}
 		newPath = append(newPath, newStep)
 	}
-	return NewSimplePlan(newPath, plan.Trajectory())
+	geoPlan := NewSimplePlan(newPath, plan.Trajectory())
+	geoPlan.timestamps = PlanTimestamps(plan)
+	return geoPlan
 }
 
 // ExecutionState describes a plan and a particular state along it.
@@ -111,6 +136,12 @@ func (plan *SimplePlan) Trajectory() Trajectory {
 	return plan.traj
 }
 
+// Timestamps returns the time, relative to the start of the Plan, at which each step of its Trajectory is
+// reached, or nil when it is not known.
+func (plan *SimplePlan) Timestamps() []time.Duration {
+	return plan.timestamps
+}
+
 // GetFramePoses returns a slice of poses a given frame should visit in the course of the Path.
 func (path Path) GetFramePoses(frameName string) ([]spatialmath.Pose, error) {
 	poses := []spatialmath.Pose{}
@@ -0,0 +1,291 @@
+package motionplan
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"go.viam.com/rdk/referenceframe"
+)
+
+// bisectionIterations bounds the bisections of velocities, which halve the error each iteration.
+const bisectionIterations = 64
+
+// KinematicLimitsFrame is a Frame which knows the kinematic limits of its DoF.
+type KinematicLimitsFrame interface {
+	referenceframe.Frame
+	KinematicLimits() []referenceframe.KinematicLimits
+}
+
+// FrameSystemKinematicLimits returns the kinematic limits of the frames of the frame system which
+// know them, by frame name.
+func FrameSystemKinematicLimits(fs *referenceframe.FrameSystem) map[string][]referenceframe.KinematicLimits {
+	limits := map[string][]referenceframe.KinematicLimits{}
+	for _, name := range fs.FrameNames() {
+		if frame, ok := fs.Frame(name).(KinematicLimitsFrame); ok {
+			limits[name] = frame.KinematicLimits()
+		}
+	}
+	return limits
+}
+
+// TimeParameterize returns the time, relative to the first waypoint, at which each waypoint of the
+// trajectory is reached when the trajectory is followed as fast as the kinematic limits of its
+// frames allow, starting and ending at rest.
+//
+// The trajectory is followed along the straight lines between its waypoints, in the space of the
+// inputs of all its frames. Along each line the path velocity is the largest one the velocity
+// limits allow, which is reached and left with S-curve profiles bounded by the acceleration and
+// jerk limits. At a waypoint the direction of the path changes, the path velocity there is bounded
+// such that blending the two lines over half the length of the shorter one respects the
+// acceleration limits.
+//
+// Each DoF which moves must have a velocity limit, while acceleration and jerk limits which are
+// zero are unbounded.
+func TimeParameterize(traj Trajectory, limits map[string][]referenceframe.KinematicLimits) ([]time.Duration, error) {
+	if len(traj) == 0 {
+		return []time.Duration{}, nil
+	}
+	waypoints, dofLimits, err := flattenTrajectory(traj, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := make([]pathSegment, len(waypoints)-1)
+	for i := range segments {
+		segments[i], err = newPathSegment(waypoints[i], waypoints[i+1], dofLimits)
+		if err != nil {
+			return nil, fmt.Errorf("segment %d of trajectory: %w", i, err)
+		}
+	}
+
+	// The largest path velocity at each waypoint.
+	velocities := make([]float64, len(waypoints))
+	for i := 1; i < len(waypoints)-1; i++ {
+		velocities[i] = math.Min(segments[i-1].maxVelocity, segments[i].maxVelocity)
+		velocities[i] = math.Min(velocities[i], cornerVelocity(segments[i-1], segments[i], dofLimits))
+	}
+	// The velocities must be reachable from the previous waypoint, and must leave enough distance to
+	// slow down for the next.
+	for i, segment := range segments {
+		velocities[i+1] = math.Min(velocities[i+1], segment.reachableVelocity(velocities[i]))
+	}
+	for i := len(segments) - 1; i >= 0; i-- {
+		velocities[i] = math.Min(velocities[i], segments[i].reachableVelocity(velocities[i+1]))
+	}
+
+	timestamps := make([]time.Duration, len(waypoints))
+	var elapsed float64
+	for i, segment := range segments {
+		elapsed += segment.duration(velocities[i], velocities[i+1])
+		timestamps[i+1] = time.Duration(elapsed * float64(time.Second))
+	}
+	return timestamps, nil
+}
+
+// flattenTrajectory returns the waypoints of the trajectory in the space of the inputs of all its
+// frames, in the order of their names, and the kinematic limits of each input. Frames missing from
+// a waypoint keep their previous inputs, or their first inputs before they appear.
+func flattenTrajectory(
+	traj Trajectory, limits map[string][]referenceframe.KinematicLimits,
+) ([][]float64, []referenceframe.KinematicLimits, error) {
+	frameNames := []string{}
+	for _, step := range traj {
+		for name := range step {
+			if !slices.Contains(frameNames, name) {
+				frameNames = append(frameNames, name)
+			}
+		}
+	}
+	slices.Sort(frameNames)
+
+	offsets, dofs := map[string]int{}, map[string]int{}
+	first := []float64{}
+	dofLimits := []referenceframe.KinematicLimits{}
+	for _, name := range frameNames {
+		for _, step := range traj {
+			if inputs, ok := step[name]; ok {
+				dofs[name] = len(inputs)
+				first = append(first, inputs...)
+				break
+			}
+		}
+		offsets[name] = len(dofLimits)
+		frameLimits := limits[name]
+		if len(frameLimits) != 0 && len(frameLimits) != dofs[name] {
+			return nil, nil, fmt.Errorf("frame %s has %d kinematic limits for %d inputs", name, len(frameLimits), dofs[name])
+		}
+		for i := range dofs[name] {
+			if len(frameLimits) == 0 {
+				dofLimits = append(dofLimits, referenceframe.KinematicLimits{})
+			} else {
+				dofLimits = append(dofLimits, frameLimits[i])
+			}
+		}
+	}
+
+	waypoints := make([][]float64, len(traj))
+	for i, step := range traj {
+		if i == 0 {
+			waypoints[i] = first
+		} else {
+			waypoints[i] = slices.Clone(waypoints[i-1])
+		}
+		for name, inputs := range step {
+			if len(inputs) != dofs[name] {
+				return nil, nil, fmt.Errorf("frame %s has %d inputs in step %d of trajectory, expected %d", name, len(inputs), i, dofs[name])
+			}
+			copy(waypoints[i][offsets[name]:], inputs)
+		}
+	}
+	return waypoints, dofLimits, nil
+}
+
+// pathSegment is the straight line between two waypoints, along which the DoF move at velocities
+// proportional to their direction.
+type pathSegment struct {
+	length    float64
+	direction []float64
+
+	// The limits of the path velocity and its derivatives, zero limits are unbounded.
+	maxVelocity     float64
+	maxAcceleration float64
+	maxJerk         float64
+}
+
+func newPathSegment(from, to []float64, limits []referenceframe.KinematicLimits) (pathSegment, error) {
+	segment := pathSegment{direction: make([]float64, len(from)), maxVelocity: math.Inf(1)}
+	for i := range from {
+		segment.length += (to[i] - from[i]) * (to[i] - from[i])
+	}
+	segment.length = math.Sqrt(segment.length)
+	if segment.length == 0 {
+		return segment, nil
+	}
+
+	segment.maxAcceleration, segment.maxJerk = math.Inf(1), math.Inf(1)
+	for i := range from {
+		segment.direction[i] = (to[i] - from[i]) / segment.length
+		component := math.Abs(segment.direction[i])
+		if component == 0 {
+			continue
+		}
+		if limits[i].Velocity <= 0 {
+			return pathSegment{}, fmt.Errorf("input %d moves but has no velocity limit", i)
+		}
+		segment.maxVelocity = math.Min(segment.maxVelocity, limits[i].Velocity/component)
+		if limits[i].Acceleration > 0 {
+			segment.maxAcceleration = math.Min(segment.maxAcceleration, limits[i].Acceleration/component)
+		}
+		if limits[i].Jerk > 0 {
+			segment.maxJerk = math.Min(segment.maxJerk, limits[i].Jerk/component)
+		}
+	}
+	if math.IsInf(segment.maxAcceleration, 1) {
+		segment.maxAcceleration = 0
+	}
+	if math.IsInf(segment.maxJerk, 1) {
+		segment.maxJerk = 0
+	}
+	return segment, nil
+}
+
+// rampDuration returns how long changing the path velocity by delta takes, with an S-curve profile
+// which is symmetric such that the average velocity during the change is the average of the
+// velocities before and after it.
+func (segment pathSegment) rampDuration(delta float64) float64 {
+	a, j := segment.maxAcceleration, segment.maxJerk
+	switch {
+	case delta <= 0 || segment.instantRamps():
+		return 0
+	case j == 0:
+		return delta / a
+	case a == 0 || delta*j < a*a:
+		// The acceleration never reaches its limit.
+		return 2 * math.Sqrt(delta/j)
+	default:
+		return delta/a + a/j
+	}
+}
+
+// instantRamps returns whether the path velocity can change instantly, when neither its
+// acceleration nor its jerk is bounded.
+func (segment pathSegment) instantRamps() bool {
+	return segment.maxAcceleration == 0 && segment.maxJerk == 0
+}
+
+// rampLength returns the distance travelled while changing the path velocity between from and to.
+func (segment pathSegment) rampLength(from, to float64) float64 {
+	return (from + to) / 2 * segment.rampDuration(math.Abs(to-from))
+}
+
+// reachableVelocity returns the largest path velocity, up to the velocity limit, which can be
+// reached over the length of the segment from the given path velocity.
+func (segment pathSegment) reachableVelocity(from float64) float64 {
+	if segment.length == 0 {
+		return from
+	}
+	if segment.instantRamps() || segment.rampLength(from, segment.maxVelocity) <= segment.length {
+		return segment.maxVelocity
+	}
+	low, high := from, segment.maxVelocity
+	for range bisectionIterations {
+		mid := (low + high) / 2
+		if segment.rampLength(from, mid) <= segment.length {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return low
+}
+
+// duration returns how long traversing the segment takes between the given path velocities, which
+// are reachable from each other, accelerating to the largest path velocity from which it can slow
+// down in time.
+func (segment pathSegment) duration(from, to float64) float64 {
+	if segment.length == 0 {
+		return 0
+	}
+	rampsLength := func(peak float64) float64 {
+		return segment.rampLength(from, peak) + segment.rampLength(peak, to)
+	}
+	peak := segment.maxVelocity
+	if !segment.instantRamps() && rampsLength(peak) > segment.length {
+		low, high := math.Max(from, to), segment.maxVelocity
+		for range bisectionIterations {
+			mid := (low + high) / 2
+			if rampsLength(mid) <= segment.length {
+				low = mid
+			} else {
+				high = mid
+			}
+		}
+		peak = low
+	}
+	if peak <= 0 {
+		return 0
+	}
+	cruise := math.Max(segment.length-rampsLength(peak), 0) / peak
+	return segment.rampDuration(peak-from) + segment.rampDuration(peak-to) + cruise
+}
+
+// cornerVelocity returns the largest path velocity at the waypoint between two segments such that
+// blending their directions over half the length of the shorter segment respects the acceleration
+// limits of the DoF.
+func cornerVelocity(before, after pathSegment, limits []referenceframe.KinematicLimits) float64 {
+	if before.length == 0 || after.length == 0 {
+		return 0
+	}
+	blendLength := math.Min(before.length, after.length) / 2
+	velocity := math.Inf(1)
+	for i := range limits {
+		change := math.Abs(after.direction[i] - before.direction[i])
+		if change == 0 || limits[i].Acceleration <= 0 {
+			continue
+		}
+		velocity = math.Min(velocity, math.Sqrt(limits[i].Acceleration*blendLength/change))
+	}
+	return velocity
+}
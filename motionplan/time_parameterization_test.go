@@ -0,0 +1,112 @@
+package motionplan
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/referenceframe"
+)
+
+func durationSeconds(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func TestTimeParameterize(t *testing.T) {
+	line := Trajectory{
+		{"arm": {0}},
+		{"arm": {2}},
+	}
+
+	t.Run("velocity limits only", func(t *testing.T) {
+		timestamps, err := TimeParameterize(line, map[string][]referenceframe.KinematicLimits{"arm": {{Velocity: 1}}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, timestamps, test.ShouldResemble, []time.Duration{0, 2 * time.Second})
+	})
+
+	t.Run("acceleration limits too short to cruise", func(t *testing.T) {
+		timestamps, err := TimeParameterize(line, map[string][]referenceframe.KinematicLimits{
+			"arm": {{Velocity: 10, Acceleration: 1}},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		// Accelerates to sqrt(2) over the first half of the line, and slows down over the second.
+		test.That(t, timestamps[1], test.ShouldAlmostEqual, durationSeconds(2*math.Sqrt2), time.Microsecond)
+	})
+
+	t.Run("acceleration limits", func(t *testing.T) {
+		timestamps, err := TimeParameterize(line, map[string][]referenceframe.KinematicLimits{
+			"arm": {{Velocity: 1, Acceleration: 1}},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		// One second accelerating over 0.5, one second cruising over 1 and one second slowing down.
+		test.That(t, timestamps[1], test.ShouldAlmostEqual, 3*time.Second, time.Microsecond)
+	})
+
+	t.Run("jerk limits", func(t *testing.T) {
+		timestamps, err := TimeParameterize(Trajectory{{"arm": {0}}, {"arm": {4}}}, map[string][]referenceframe.KinematicLimits{
+			"arm": {{Velocity: 1, Acceleration: 1, Jerk: 1}},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		// Two seconds accelerating over 1, two seconds cruising over 2 and two seconds slowing down.
+		test.That(t, timestamps[1], test.ShouldAlmostEqual, 6*time.Second, time.Microsecond)
+	})
+
+	t.Run("slowest joint", func(t *testing.T) {
+		timestamps, err := TimeParameterize(Trajectory{{"arm": {0, 0}}, {"arm": {1, 4}}}, map[string][]referenceframe.KinematicLimits{
+			"arm": {{Velocity: 1}, {Velocity: 2}},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, timestamps[1], test.ShouldAlmostEqual, 2*time.Second, time.Microsecond)
+	})
+
+	t.Run("collinear waypoints are passed without slowing down", func(t *testing.T) {
+		limits := map[string][]referenceframe.KinematicLimits{"arm": {{Velocity: 1, Acceleration: 1}}}
+		timestamps, err := TimeParameterize(Trajectory{{"arm": {0}}, {"arm": {1}}, {"arm": {2}}}, limits)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, timestamps[2], test.ShouldAlmostEqual, 3*time.Second, time.Microsecond)
+		test.That(t, timestamps[1], test.ShouldAlmostEqual, 1500*time.Millisecond, time.Microsecond)
+	})
+
+	t.Run("corners slow down", func(t *testing.T) {
+		limits := map[string][]referenceframe.KinematicLimits{"arm": {{Velocity: 1, Acceleration: 0.5}, {Velocity: 1, Acceleration: 0.5}}}
+		straight, err := TimeParameterize(Trajectory{{"arm": {0, 0}}, {"arm": {2, 0}}, {"arm": {4, 0}}}, limits)
+		test.That(t, err, test.ShouldBeNil)
+		corner, err := TimeParameterize(Trajectory{{"arm": {0, 0}}, {"arm": {2, 0}}, {"arm": {2, 2}}}, limits)
+		test.That(t, err, test.ShouldBeNil)
+		// Going straight takes 6 seconds, while stopping at the corner would take 8.
+		test.That(t, straight[2], test.ShouldAlmostEqual, 6*time.Second, time.Microsecond)
+		test.That(t, corner[2], test.ShouldBeGreaterThan, straight[2])
+		test.That(t, corner[2], test.ShouldBeLessThan, 8*time.Second)
+	})
+
+	t.Run("frames without velocity limits", func(t *testing.T) {
+		_, err := TimeParameterize(line, map[string][]referenceframe.KinematicLimits{})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "has no velocity limit")
+
+		// Frames which don't move need no limits.
+		timestamps, err := TimeParameterize(Trajectory{{"arm": {0}, "gripper": {1}}, {"arm": {2}, "gripper": {1}}},
+			map[string][]referenceframe.KinematicLimits{"arm": {{Velocity: 1}}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, timestamps, test.ShouldResemble, []time.Duration{0, 2 * time.Second})
+	})
+}
+
+// untimedPlan is a Plan which doesn't implement TimedPlan.
+type untimedPlan struct {
+	traj Trajectory
+}
+
+func (p untimedPlan) Path() Path { return nil }
+
+func (p untimedPlan) Trajectory() Trajectory { return p.traj }
+
+func TestPlanTimestamps(t *testing.T) {
+	timestamps := []time.Duration{0, time.Second}
+	timed := &SimplePlan{traj: Trajectory{{"arm": {0}}, {"arm": {1}}}, timestamps: timestamps}
+	test.That(t, PlanTimestamps(timed), test.ShouldResemble, timestamps)
+	test.That(t, PlanTimestamps(untimedPlan{timed.traj}), test.ShouldBeNil)
+	test.That(t, PlanTimestamps(NewSimplePlan(nil, nil)), test.ShouldBeNil)
+}
@@ -29,6 +29,15 @@ type Limit struct {
 	Max float64
 }
 
+// KinematicLimits bounds how fast a degree of freedom of a Frame moves, in the units of its inputs
+// per second, i.e: radians for revolute joints and mm for prismatic joints. Limits which are zero
+// are unbounded.
+type KinematicLimits struct {
+	Velocity     float64
+	Acceleration float64
+	Jerk         float64
+}
+
 // Range gives the range of the limit.
 func (l *Limit) Range() float64 {
 	return l.Max - l.Min
@@ -37,6 +37,10 @@ type JointConfig struct {
 	Max      float64                 `json:"max"`                // in mm or degs
 	Min      float64                 `json:"min"`                // in mm or degs
 	Geometry *spatial.GeometryConfig `json:"geometry,omitempty"` // only valid for prismatic/translational joints
+	// Kinematic limits, which are unbounded when zero.
+	MaxVelocity     float64 `json:"max_velocity,omitempty"`     // in mm/s or degs/s
+	MaxAcceleration float64 `json:"max_acceleration,omitempty"` // in mm/s^2 or degs/s^2
+	MaxJerk         float64 `json:"max_jerk,omitempty"`         // in mm/s^3 or degs/s^3
 }
 
 // DHParamConfig is a revolute and static frame combined in a set of Denavit Hartenberg parameters.
@@ -49,6 +53,10 @@ type DHParamConfig struct {
 	Max      float64                 `json:"max"` // in mm or degs
 	Min      float64                 `json:"min"` // in mm or degs
 	Geometry *spatial.GeometryConfig `json:"geometry,omitempty"`
+	// Kinematic limits of the joint, which are unbounded when zero.
+	MaxVelocity     float64 `json:"max_velocity,omitempty"`     // in degs/s
+	MaxAcceleration float64 `json:"max_acceleration,omitempty"` // in degs/s^2
+	MaxJerk         float64 `json:"max_jerk,omitempty"`         // in degs/s^3
 }
 
 // NewLinkConfig constructs a config from a Frame.
@@ -139,6 +147,28 @@ func (cfg *JointConfig) ToFrame() (Frame, error) {
 	}
 }
 
+// KinematicLimits returns the kinematic limits of the joint in the units of its inputs.
+func (cfg *JointConfig) KinematicLimits() KinematicLimits {
+	limits := KinematicLimits{Velocity: cfg.MaxVelocity, Acceleration: cfg.MaxAcceleration, Jerk: cfg.MaxJerk}
+	if cfg.Type == RevoluteJoint {
+		limits = KinematicLimits{
+			Velocity:     utils.DegToRad(limits.Velocity),
+			Acceleration: utils.DegToRad(limits.Acceleration),
+			Jerk:         utils.DegToRad(limits.Jerk),
+		}
+	}
+	return limits
+}
+
+// KinematicLimits returns the kinematic limits of the joint in radians.
+func (cfg *DHParamConfig) KinematicLimits() KinematicLimits {
+	return KinematicLimits{
+		Velocity:     utils.DegToRad(cfg.MaxVelocity),
+		Acceleration: utils.DegToRad(cfg.MaxAcceleration),
+		Jerk:         utils.DegToRad(cfg.MaxJerk),
+	}
+}
+
 // ToDHFrames converts a DHParamConfig into a joint frame and a link frame.
 func (cfg *DHParamConfig) ToDHFrames() (Frame, Frame, error) {
 	jointID := cfg.ID + "_j"
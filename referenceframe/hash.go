@@ -1,5 +1,7 @@
 package referenceframe
 
+import "go.viam.com/rdk/spatialmath"
+
 // Hash returns a hash value for this frame system.
 func (sfs *FrameSystem) Hash() int {
 	hash := len(sfs.frames) * 1000
@@ -13,6 +15,25 @@ func (sfs *FrameSystem) Hash() int {
 	return hash
 }
 
+// Hash returns a hash value for this world state, covering its obstacles and transforms.
+func (ws *WorldState) Hash() int {
+	hash := 0
+	for _, gf := range ws.Obstacles() {
+		for _, g := range gf.Geometries() {
+			// + is important, as obstacles may be given in any order, while * ties each geometry to its name
+			hash += (hashString(gf.Parent()) + hashString(g.Label())) * g.Hash()
+		}
+	}
+	for _, lif := range ws.Transforms() {
+		linkHash := spatialmath.HashPose(lif.Pose())
+		if lif.Geometry() != nil {
+			linkHash += lif.Geometry().Hash()
+		}
+		hash += (hashString(lif.Name()) + hashString(lif.Parent())) * linkHash
+	}
+	return hash
+}
+
 func hashString(s string) int {
 	hash := 0
 	for idx, c := range s {
@@ -256,6 +256,27 @@ func (m *SimpleModel) MoveableFrameNames() []string {
 	return names
 }
 
+// KinematicLimits returns the kinematic limits of each DoF of the model, in the order of DoF(), as
+// specified by the joints of its model config. The limits of models without a config are unbounded.
+func (m *SimpleModel) KinematicLimits() []KinematicLimits {
+	limits := make([]KinematicLimits, 0, len(m.DoF()))
+	byFrame := map[string]KinematicLimits{}
+	if m.modelConfig != nil {
+		for i := range m.modelConfig.Joints {
+			byFrame[m.modelConfig.Joints[i].ID] = m.modelConfig.Joints[i].KinematicLimits()
+		}
+		for i := range m.modelConfig.DHParams {
+			byFrame[m.modelConfig.DHParams[i].ID+"_j"] = m.modelConfig.DHParams[i].KinematicLimits()
+		}
+	}
+	for _, name := range m.MoveableFrameNames() {
+		for range m.internalFS.Frame(name).DoF() {
+			limits = append(limits, byFrame[name])
+		}
+	}
+	return limits
+}
+
 // framesInOrder returns the Frame objects in schema order.
 func (m *SimpleModel) framesInOrder() []Frame {
 	if m.internalFS == nil || m.inputSchema == nil {
@@ -2,6 +2,7 @@ package referenceframe
 
 import (
 	"encoding/json"
+	"math"
 	"testing"
 
 	"github.com/golang/geo/r3"
@@ -93,6 +94,41 @@ func TestLimitsParsing(t *testing.T) {
 	test.That(t, simpleModelDeserialized.DoF()[0], test.ShouldResemble, Limit{0, 1})
 }
 
+func TestKinematicLimitsParsing(t *testing.T) {
+	jsonData := []byte(`{
+		"name": "limited",
+		"links": [
+			{"id": "base", "parent": "world"},
+			{"id": "tcp", "parent": "slider", "translation": {"x": 0, "y": 0, "z": 10}}
+		],
+		"joints": [
+			{"id": "waist", "type": "revolute", "parent": "base", "axis": {"x": 0, "y": 0, "z": 1}, "min": -180, "max": 180,
+				"max_velocity": 90, "max_acceleration": 180, "max_jerk": 360},
+			{"id": "slider", "type": "prismatic", "parent": "waist", "axis": {"x": 1, "y": 0, "z": 0}, "min": 0, "max": 500,
+				"max_velocity": 250}
+		]
+	}`)
+	model, err := UnmarshalModelJSON(jsonData, "")
+	test.That(t, err, test.ShouldBeNil)
+	smodel, ok := model.(*SimpleModel)
+	test.That(t, ok, test.ShouldBeTrue)
+
+	// Revolute limits are converted to radians, prismatic limits remain in mm.
+	limits := smodel.KinematicLimits()
+	test.That(t, len(limits), test.ShouldEqual, 2)
+	test.That(t, limits[0].Velocity, test.ShouldAlmostEqual, math.Pi/2)
+	test.That(t, limits[0].Acceleration, test.ShouldAlmostEqual, math.Pi)
+	test.That(t, limits[0].Jerk, test.ShouldAlmostEqual, 2*math.Pi)
+	test.That(t, limits[1], test.ShouldResemble, KinematicLimits{Velocity: 250})
+
+	// The limits persist when roundtripping through JSON serialization.
+	data, err := smodel.MarshalJSON()
+	test.That(t, err, test.ShouldBeNil)
+	simpleModelDeserialized := new(SimpleModel)
+	test.That(t, simpleModelDeserialized.UnmarshalJSON(data), test.ShouldBeNil)
+	test.That(t, simpleModelDeserialized.KinematicLimits(), test.ShouldResemble, limits)
+}
+
 // Tests that yml files are properly parsed and correctly loaded into the model
 // Should not need to actually test the contained rotation/translation values
 // since that will be caught by tests to the actual kinematics
@@ -158,6 +158,13 @@ func UnmarshalModelXML(xmlData []byte, modelName string, meshMap map[string]*com
 			default:
 				return nil, err
 			}
+			if jointElem.Limit != nil && jointElem.Limit.Velocity > 0 {
+				if jointElem.Type == PrismaticJoint {
+					thisJoint.MaxVelocity = utils.MetersToMM(jointElem.Limit.Velocity)
+				} else {
+					thisJoint.MaxVelocity = utils.RadToDeg(jointElem.Limit.Velocity)
+				}
+			}
 			joints = append(joints, thisJoint)
 
 			// Generate child link translation and orientation data, which is held by this joint per the URDF design
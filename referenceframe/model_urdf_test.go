@@ -113,6 +113,11 @@ func TestParseURDFFile(t *testing.T) {
 	modelGeo, err := model.Geometries(make([]Input, len(model.DoF())))
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, len(modelGeo.Geometries()), test.ShouldEqual, 5) // notably we only have 5 geometries for this model
+	// The velocity limits of the joints are parsed, other kinematic limits are not in URDF.
+	for _, limits := range model.KinematicLimits() {
+		test.That(t, limits.Velocity, test.ShouldAlmostEqual, 3.141592)
+		test.That(t, limits.Acceleration, test.ShouldEqual, 0)
+	}
 
 	// Test naming of a URDF to something other than the robot's name element
 	u, err = ParseModelXMLFile(utils.ResolveFile("referenceframe/testfiles/ur5e.urdf"), "foo")
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/golang/geo/r3"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"go.viam.com/test"
 
@@ -78,3 +79,25 @@ func TestString(t *testing.T) {
 
 	test.That(t, fmt.Sprint(ws), test.ShouldEqual, testTable.Render())
 }
+
+func TestWorldStateHash(t *testing.T) {
+	sphere := func(name string, x float64) spatialmath.Geometry {
+		s, err := spatialmath.NewSphere(spatialmath.NewPoseFromPoint(r3.Vector{X: x}), 10, name)
+		test.That(t, err, test.ShouldBeNil)
+		return s
+	}
+	worldState := func(geometries ...spatialmath.Geometry) *WorldState {
+		ws, err := NewWorldState([]*GeometriesInFrame{NewGeometriesInFrame(World, geometries)}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		return ws
+	}
+
+	ws := worldState(sphere("foo", 0), sphere("bar", 100))
+	test.That(t, ws.Hash(), test.ShouldEqual, worldState(sphere("bar", 100), sphere("foo", 0)).Hash())
+	test.That(t, ws.Hash(), test.ShouldNotEqual, worldState(sphere("foo", 100), sphere("bar", 0)).Hash())
+	test.That(t, ws.Hash(), test.ShouldNotEqual, worldState(sphere("foo", 0), sphere("bar", 101)).Hash())
+	test.That(t, ws.Hash(), test.ShouldNotEqual, worldState(sphere("foo", 0)).Hash())
+
+	var nilWorldState *WorldState
+	test.That(t, nilWorldState.Hash(), test.ShouldEqual, NewEmptyWorldState().Hash())
+}
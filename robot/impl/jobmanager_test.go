@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 	"sync/atomic"
@@ -35,6 +37,7 @@ import (
 	"go.viam.com/rdk/components/servo"
 	sw "go.viam.com/rdk/components/switch"
 	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/ml"
 	"go.viam.com/rdk/referenceframe"
@@ -319,6 +322,784 @@ func TestJobManagerHistory(t *testing.T) {
 	})
 }
 
+func TestJobManagerRetriesTimeoutsAndChaining(t *testing.T) {
+	logger, logs := logging.NewObservedTestLogger(t)
+
+	var flakyCalls, successFollowUps, failureFollowUps atomic.Int32
+
+	model := resource.DefaultModelFamily.WithModel("fakesensorRetries")
+	injectSensor := inject.NewSensor("fakesensorRetries")
+	injectSensor.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		switch cmd["command"] {
+		case "flaky":
+			// every run fails twice before succeeding on the last retry.
+			if flakyCalls.Add(1)%3 != 0 {
+				return nil, errors.New("flaky")
+			}
+			return nil, nil
+		case "hang":
+			// hangs until the attempt times out and is cancelled.
+			<-ctx.Done()
+			return nil, ctx.Err()
+		case "after success":
+			successFollowUps.Add(1)
+		case "after failure":
+			failureFollowUps.Add(1)
+		}
+		return nil, nil
+	}
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (sensor.Sensor, error) {
+			return injectSensor, nil
+		}})
+
+	cfg := &config.Config{
+		Components: []resource.Config{
+			{
+				Model: model,
+				Name:  "sensor",
+				API:   sensor.API,
+			},
+		},
+		Jobs: []config.JobConfig{
+			{
+				config.JobConfigData{
+					Name:         "flaky",
+					Schedule:     "300ms",
+					Resource:     "sensor",
+					Method:       "DoCommand",
+					Command:      map[string]any{"command": "flaky"},
+					MaxRetries:   2,
+					RetryBackoff: "10ms",
+					OnSuccess:    []string{"success follow-up"},
+					OnFailure:    []string{"failure follow-up"},
+				},
+			},
+			{
+				config.JobConfigData{
+					Name:          "hang",
+					Schedule:      "300ms",
+					Resource:      "sensor",
+					Method:        "DoCommand",
+					Command:       map[string]any{"command": "hang"},
+					Timeout:       "100ms",
+					OverlapPolicy: config.JobOverlapSkip,
+					OnFailure:     []string{"failure follow-up"},
+				},
+			},
+			{
+				config.JobConfigData{
+					Name:     "success follow-up",
+					Schedule: config.JobScheduleManual,
+					Resource: "sensor",
+					Method:   "DoCommand",
+					Command:  map[string]any{"command": "after success"},
+				},
+			},
+			{
+				config.JobConfigData{
+					Name:     "failure follow-up",
+					Schedule: config.JobScheduleManual,
+					Resource: "sensor",
+					Method:   "DoCommand",
+					Command:  map[string]any{"command": "after failure"},
+				},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	lr := setupLocalRobot(t, ctx, cfg, logger)
+
+	testutils.WaitForAssertionWithSleep(t, time.Second, 5, func(tb testing.TB) {
+		tb.Helper()
+		ms, err := lr.MachineStatus(ctx)
+		test.That(tb, err, test.ShouldBeNil)
+
+		// retries hide the flaky failures, so only successes are recorded.
+		flaky, ok := ms.JobStatuses["flaky"]
+		test.That(tb, ok, test.ShouldBeTrue)
+		test.That(tb, len(flaky.RecentSuccessfulRuns), test.ShouldBeGreaterThan, 0)
+		test.That(tb, len(flaky.RecentFailedRuns), test.ShouldEqual, 0)
+		test.That(tb, flakyCalls.Load(), test.ShouldBeGreaterThanOrEqualTo, 3)
+		test.That(tb, successFollowUps.Load(), test.ShouldBeGreaterThan, 0)
+
+		// the hung job keeps getting timed out instead of blocking forever.
+		hang, ok := ms.JobStatuses["hang"]
+		test.That(tb, ok, test.ShouldBeTrue)
+		test.That(tb, len(hang.RecentFailedRuns), test.ShouldBeGreaterThan, 1)
+		test.That(tb, logs.FilterMessage("Job timed out").Len(), test.ShouldBeGreaterThan, 1)
+		test.That(tb, failureFollowUps.Load(), test.ShouldBeGreaterThan, 0)
+	})
+}
+
+func TestJobManagerTriggerCycle(t *testing.T) {
+	logger, logs := logging.NewObservedTestLogger(t)
+
+	var pings, pongs atomic.Int32
+	model := resource.DefaultModelFamily.WithModel("fakesensorTriggerCycle")
+	injectSensor := inject.NewSensor("fakesensorTriggerCycle")
+	injectSensor.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		switch cmd["command"] {
+		case "ping":
+			pings.Add(1)
+		case "pong":
+			pongs.Add(1)
+		}
+		return nil, nil
+	}
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (sensor.Sensor, error) {
+			return injectSensor, nil
+		}})
+
+	cfg := &config.Config{
+		Components: []resource.Config{
+			{
+				Model: model,
+				Name:  "sensor",
+				API:   sensor.API,
+			},
+		},
+		Jobs: []config.JobConfig{
+			{
+				config.JobConfigData{
+					Name:      "ping",
+					Schedule:  "100ms",
+					Resource:  "sensor",
+					Method:    "DoCommand",
+					Command:   map[string]any{"command": "ping"},
+					OnSuccess: []string{"pong"},
+				},
+			},
+			{
+				config.JobConfigData{
+					Name:      "pong",
+					Schedule:  config.JobScheduleManual,
+					Resource:  "sensor",
+					Method:    "DoCommand",
+					Command:   map[string]any{"command": "pong"},
+					OnSuccess: []string{"ping"},
+				},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	lr := setupLocalRobot(t, ctx, cfg, logger)
+
+	// the jobs would trigger each other forever, so neither triggers its follow-up job.
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, pings.Load(), test.ShouldBeGreaterThan, 2)
+		test.That(tb, logs.FilterMessage("Not triggering follow-up jobs of a job in a trigger cycle").Len(),
+			test.ShouldBeGreaterThan, 0)
+	})
+	test.That(t, pongs.Load(), test.ShouldEqual, 0)
+
+	// once the cycle is broken, the follow-up job is triggered again.
+	newCfg := *cfg
+	newCfg.Jobs = slices.Clone(cfg.Jobs)
+	newCfg.Jobs[1].OnSuccess = nil
+	lr.Reconfigure(ctx, &newCfg)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, pongs.Load(), test.ShouldBeGreaterThan, 0)
+	})
+}
+
+func TestJobManagerPersistedHistory(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	homeDir := t.TempDir()
+
+	cfg := &config.Config{
+		Components: []resource.Config{
+			{
+				Model: resource.DefaultModelFamily.WithModel("fake"),
+				Name:  "sensor",
+				API:   sensor.API,
+			},
+		},
+		Jobs: []config.JobConfig{
+			{
+				config.JobConfigData{
+					Name:     "readings",
+					Schedule: "200ms",
+					Resource: "sensor",
+					Method:   "GetReadings",
+				},
+			},
+			{
+				config.JobConfigData{
+					Name:     "unknown method",
+					Schedule: "200ms",
+					Resource: "sensor",
+					Method:   "DoCommand",
+					Command:  map[string]any{"command": "does not exist"},
+				},
+			},
+		},
+	}
+
+	lr, err := New(ctx, cfg, nil, logger, WithViamHomeDir(homeDir))
+	test.That(t, err, test.ShouldBeNil)
+
+	testutils.WaitForAssertionWithSleep(t, time.Second, 5, func(tb testing.TB) {
+		tb.Helper()
+		jobs, err := lr.JobStatus(ctx)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, len(jobs), test.ShouldEqual, 2)
+
+		readings := jobs["readings"]
+		failures := jobs["unknown method"]
+		test.That(tb, len(readings), test.ShouldBeGreaterThan, 0)
+		test.That(tb, len(failures), test.ShouldBeGreaterThan, 0)
+		if len(readings) == 0 || len(failures) == 0 {
+			return
+		}
+		test.That(tb, readings[0].Succeeded(), test.ShouldBeTrue)
+		test.That(tb, readings[0].Response, test.ShouldContainSubstring, "readings")
+		test.That(tb, readings[0].Start.IsZero(), test.ShouldBeFalse)
+		test.That(tb, failures[0].Succeeded(), test.ShouldBeFalse)
+		test.That(tb, failures[0].Error, test.ShouldNotBeEmpty)
+	})
+
+	jobs, err := lr.JobStatus(ctx, "readings")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(jobs), test.ShouldEqual, 1)
+	_, err = lr.JobStatus(ctx, "not a job")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	test.That(t, lr.Close(ctx), test.ShouldBeNil)
+	before, err := lr.JobStatus(ctx, "readings")
+	test.That(t, err, test.ShouldBeNil)
+
+	// a restarted robot without any jobs configured still knows about the previous runs.
+	lr, err = New(ctx, &config.Config{}, nil, logger, WithViamHomeDir(homeDir))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, lr.Close(ctx), test.ShouldBeNil)
+	}()
+	after, err := lr.JobStatus(ctx, "readings")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(after["readings"]), test.ShouldEqual, len(before["readings"]))
+	for i, run := range after["readings"] {
+		test.That(t, run.Start.Equal(before["readings"][i].Start), test.ShouldBeTrue)
+		test.That(t, run.Duration, test.ShouldEqual, before["readings"][i].Duration)
+		test.That(t, run.Response, test.ShouldEqual, before["readings"][i].Response)
+	}
+
+	ms, err := lr.MachineStatus(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(ms.JobStatuses["readings"].RecentSuccessfulRuns), test.ShouldBeGreaterThan, 0)
+	test.That(t, len(ms.JobStatuses["unknown method"].RecentFailedRuns), test.ShouldBeGreaterThan, 0)
+}
+
+func TestJobManagerRunPauseResume(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	var hourlyRuns, tickerRuns atomic.Int32
+	model := resource.DefaultModelFamily.WithModel("fakesensorRunPause")
+	injectSensor := inject.NewSensor("fakesensorRunPause")
+	injectSensor.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		switch cmd["command"] {
+		case "hourly":
+			hourlyRuns.Add(1)
+		case "ticker":
+			tickerRuns.Add(1)
+		}
+		return nil, nil
+	}
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (sensor.Sensor, error) {
+			return injectSensor, nil
+		}})
+
+	cfg := &config.Config{
+		Components: []resource.Config{
+			{
+				Model: model,
+				Name:  "sensor",
+				API:   sensor.API,
+			},
+		},
+		Jobs: []config.JobConfig{
+			{
+				config.JobConfigData{
+					Name:     "hourly",
+					Schedule: "1h",
+					Resource: "sensor",
+					Method:   "DoCommand",
+					Command:  map[string]any{"command": "hourly"},
+				},
+			},
+			{
+				config.JobConfigData{
+					Name:     "ticker",
+					Schedule: "100ms",
+					Resource: "sensor",
+					Method:   "DoCommand",
+					Command:  map[string]any{"command": "ticker"},
+				},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	lr := setupLocalRobot(t, ctx, cfg, logger)
+
+	// the hourly job only runs when asked to, and the run is recorded in its history.
+	test.That(t, lr.RunJobNow(ctx, "hourly"), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, hourlyRuns.Load(), test.ShouldEqual, 1)
+		jobs, err := lr.JobStatus(ctx, "hourly")
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, len(jobs["hourly"]), test.ShouldEqual, 1)
+	})
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, tickerRuns.Load(), test.ShouldBeGreaterThan, 1)
+	})
+	test.That(t, lr.PauseJob(ctx, "ticker"), test.ShouldBeNil)
+	// give a run that was already in flight a chance to finish.
+	time.Sleep(200 * time.Millisecond)
+	paused := tickerRuns.Load()
+	time.Sleep(500 * time.Millisecond)
+	test.That(t, tickerRuns.Load(), test.ShouldEqual, paused)
+
+	// paused jobs can still be run on demand.
+	test.That(t, lr.RunJobNow(ctx, "ticker"), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, tickerRuns.Load(), test.ShouldEqual, paused+1)
+	})
+
+	test.That(t, lr.ResumeJob(ctx, "ticker"), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, tickerRuns.Load(), test.ShouldBeGreaterThan, paused+2)
+	})
+
+	test.That(t, lr.RunJobNow(ctx, "not a job"), test.ShouldNotBeNil)
+	test.That(t, lr.PauseJob(ctx, "not a job"), test.ShouldNotBeNil)
+	test.That(t, lr.ResumeJob(ctx, "not a job"), test.ShouldNotBeNil)
+}
+
+// captureDirDataManager is a data manager that reports the directory it captures to.
+type captureDirDataManager struct {
+	*inject.DataManagerService
+	captureDir string
+}
+
+func (dm *captureDirDataManager) CaptureDir() string {
+	return dm.captureDir
+}
+
+func TestJobManagerRequestBodyAndCapture(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	captureDir := t.TempDir()
+
+	var power atomic.Value
+	motorModel := resource.DefaultModelFamily.WithModel("fakemotorRequest")
+	injectMotor := inject.NewMotor("fakemotorRequest")
+	injectMotor.SetPowerFunc = func(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+		power.Store(powerPct)
+		return nil
+	}
+	resource.RegisterComponent(
+		motor.API,
+		motorModel,
+		resource.Registration[motor.Motor, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (motor.Motor, error) {
+			return injectMotor, nil
+		}})
+
+	sensorModel := resource.DefaultModelFamily.WithModel("fakesensorCapture")
+	injectSensor := inject.NewSensor("sensor")
+	injectSensor.ReadingsFunc = func(ctx context.Context, extra map[string]any) (map[string]any, error) {
+		return map[string]any{"temperature": 21.5}, nil
+	}
+	resource.RegisterComponent(
+		sensor.API,
+		sensorModel,
+		resource.Registration[sensor.Sensor, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (sensor.Sensor, error) {
+			return injectSensor, nil
+		}})
+
+	dataManagerModel := resource.DefaultModelFamily.WithModel("fakedatamanagerCapture")
+	injectDataManager := &captureDirDataManager{
+		DataManagerService: inject.NewDataManagerService("data_manager"),
+		captureDir:         captureDir,
+	}
+	injectDataManager.CloseFunc = func(ctx context.Context) error {
+		return nil
+	}
+	resource.RegisterService(
+		datamanager.API,
+		dataManagerModel,
+		resource.Registration[datamanager.Service, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (datamanager.Service, error) {
+			return injectDataManager, nil
+		}})
+	defer resource.Deregister(datamanager.API, dataManagerModel)
+
+	cfg := &config.Config{
+		Components: []resource.Config{
+			{
+				Model: motorModel,
+				Name:  "motor",
+				API:   motor.API,
+			},
+			{
+				Model: sensorModel,
+				Name:  "sensor",
+				API:   sensor.API,
+			},
+		},
+		// job responses are captured to the capture directory of the data manager.
+		Services: []resource.Config{
+			{
+				Model: dataManagerModel,
+				Name:  "data_manager",
+				API:   datamanager.API,
+			},
+		},
+		Jobs: []config.JobConfig{
+			{
+				config.JobConfigData{
+					Name:     "half power",
+					Schedule: config.JobScheduleManual,
+					Resource: "motor",
+					Method:   "SetPower",
+					Request:  map[string]any{"power_pct": 0.5},
+				},
+			},
+			{
+				config.JobConfigData{
+					Name:     "capture readings",
+					Schedule: "100ms",
+					Resource: "sensor",
+					Method:   "GetReadings",
+					Capture:  &config.JobCaptureConfig{Tags: []string{"jobs"}},
+				},
+			},
+		},
+	}
+
+	lr, err := New(ctx, cfg, nil, logger, WithViamHomeDir(t.TempDir()))
+	test.That(t, err, test.ShouldBeNil)
+
+	// the request body is sent along with the resource name.
+	test.That(t, lr.RunJobNow(ctx, "half power"), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, power.Load(), test.ShouldEqual, 0.5)
+	})
+
+	targetDir := data.CaptureFilePathWithReplacedReservedChars(
+		filepath.Join(captureDir, sensor.API.String(), "sensor", "GetReadings"))
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		files, err := os.ReadDir(targetDir)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, len(files), test.ShouldBeGreaterThan, 0)
+	})
+
+	// closing the robot completes the capture file so that it can be synced.
+	test.That(t, lr.Close(ctx), test.ShouldBeNil)
+	files, err := filepath.Glob(filepath.Join(targetDir, "*"+data.CompletedCaptureFileExt))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(files), test.ShouldEqual, 1)
+
+	//nolint:gosec
+	f, err := os.Open(files[0])
+	test.That(t, err, test.ShouldBeNil)
+	defer utils.UncheckedErrorFunc(f.Close)
+	captureFile, err := data.ReadCaptureFile(f)
+	test.That(t, err, test.ShouldBeNil)
+	md := captureFile.ReadMetadata()
+	test.That(t, md.GetComponentName(), test.ShouldEqual, "sensor")
+	test.That(t, md.GetMethodName(), test.ShouldEqual, "GetReadings")
+	test.That(t, md.GetTags(), test.ShouldResemble, []string{"jobs"})
+
+	items, err := data.SensorDataFromCaptureFile(captureFile)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(items), test.ShouldBeGreaterThan, 0)
+	readings := items[0].GetStruct().GetFields()["readings"].GetStructValue().AsMap()
+	test.That(t, readings["temperature"], test.ShouldEqual, 21.5)
+}
+
+func TestJobManagerEventTriggers(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	var pushRuns, tickRuns, thresholdRuns, unhealthyRuns atomic.Int32
+	targetModel := resource.DefaultModelFamily.WithModel("fakesensorTriggerTarget")
+	target := inject.NewSensor("target")
+	target.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		switch cmd["command"] {
+		case "push":
+			pushRuns.Add(1)
+		case "tick":
+			tickRuns.Add(1)
+		case "threshold":
+			thresholdRuns.Add(1)
+		case "unhealthy":
+			unhealthyRuns.Add(1)
+		}
+		return nil, nil
+	}
+	resource.RegisterComponent(
+		sensor.API,
+		targetModel,
+		resource.Registration[sensor.Sensor, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (sensor.Sensor, error) {
+			return target, nil
+		}})
+
+	var temperature atomic.Value
+	temperature.Store(20.0)
+	thermometerModel := resource.DefaultModelFamily.WithModel("fakesensorTriggerThermometer")
+	thermometer := inject.NewSensor("thermometer")
+	thermometer.ReadingsFunc = func(ctx context.Context, extra map[string]any) (map[string]any, error) {
+		return map[string]any{"temperature": temperature.Load()}, nil
+	}
+	resource.RegisterComponent(
+		sensor.API,
+		thermometerModel,
+		resource.Registration[sensor.Sensor, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (sensor.Sensor, error) {
+			if conf.Attributes.Bool("fail", false) {
+				return nil, errors.New("thermometer broke")
+			}
+			// rebuild on reconfiguration, so that the constructor can fail.
+			return struct {
+				*inject.Sensor
+				resource.AlwaysRebuild
+			}{Sensor: thermometer}, nil
+		}})
+
+	buttonModel := resource.DefaultModelFamily.WithModel("fakebuttonTrigger")
+	injectButton := inject.NewButton("button")
+	injectButton.PushFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		return nil
+	}
+	injectButton.CloseFunc = func(ctx context.Context) error {
+		return nil
+	}
+	resource.RegisterComponent(
+		button.API,
+		buttonModel,
+		resource.Registration[button.Button, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (button.Button, error) {
+			return injectButton, nil
+		}})
+
+	ticks := make(chan struct{})
+	boardModel := resource.DefaultModelFamily.WithModel("fakeboardTrigger")
+	injectBoard := inject.NewBoard("board")
+	interrupt := &inject.DigitalInterrupt{NameFunc: func() string { return "di" }}
+	injectBoard.DigitalInterruptByNameFunc = func(name string) (board.DigitalInterrupt, error) {
+		if name != "di" {
+			return nil, errors.New("unknown interrupt")
+		}
+		return interrupt, nil
+	}
+	injectBoard.StreamTicksFunc = func(
+		ctx context.Context, interrupts []board.DigitalInterrupt, ch chan board.Tick, extra map[string]interface{},
+	) error {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticks:
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case ch <- board.Tick{Name: "di", High: true}:
+				}
+			}
+		}()
+		return nil
+	}
+	resource.RegisterComponent(
+		board.API,
+		boardModel,
+		resource.Registration[board.Board, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (board.Board, error) {
+			return injectBoard, nil
+		}})
+
+	above := 30.0
+	cfg := &config.Config{
+		Components: []resource.Config{
+			{Model: targetModel, Name: "target", API: sensor.API},
+			{Model: thermometerModel, Name: "thermometer", API: sensor.API},
+			{Model: buttonModel, Name: "button", API: button.API},
+			{Model: boardModel, Name: "board", API: board.API},
+		},
+		Jobs: []config.JobConfig{
+			{
+				config.JobConfigData{
+					Name:     "on push",
+					Resource: "target",
+					Method:   "DoCommand",
+					Command:  map[string]any{"command": "push"},
+					Trigger: &config.JobTriggerConfig{
+						Type:     config.JobTriggerButtonPush,
+						Resource: "button",
+					},
+				},
+			},
+			{
+				config.JobConfigData{
+					Name:     "on tick",
+					Resource: "target",
+					Method:   "DoCommand",
+					Command:  map[string]any{"command": "tick"},
+					Trigger: &config.JobTriggerConfig{
+						Type:      config.JobTriggerDigitalInterrupt,
+						Resource:  "board",
+						Interrupt: "di",
+					},
+				},
+			},
+			{
+				config.JobConfigData{
+					Name:     "on threshold",
+					Resource: "target",
+					Method:   "DoCommand",
+					Command:  map[string]any{"command": "threshold"},
+					Trigger: &config.JobTriggerConfig{
+						Type:         config.JobTriggerSensorThreshold,
+						Resource:     "thermometer",
+						Reading:      "temperature",
+						Above:        &above,
+						PollInterval: "50ms",
+						Debounce:     "1h",
+					},
+				},
+			},
+			{
+				config.JobConfigData{
+					Name:     "on unhealthy",
+					Resource: "target",
+					Method:   "DoCommand",
+					Command:  map[string]any{"command": "unhealthy"},
+					Trigger: &config.JobTriggerConfig{
+						Type:         config.JobTriggerHealth,
+						Resource:     "thermometer",
+						State:        "unhealthy",
+						PollInterval: "50ms",
+					},
+				},
+			},
+		},
+	}
+
+	lr := setupLocalRobot(t, ctx, cfg, logger)
+	o, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	test.That(t, lr.StartWeb(ctx, o), test.ShouldBeNil)
+	robotClient, err := rclient.New(ctx, addr, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer robotClient.Close(ctx)
+
+	// event-only jobs do not run on their own.
+	time.Sleep(300 * time.Millisecond)
+	test.That(t, pushRuns.Load()+tickRuns.Load()+thresholdRuns.Load()+unhealthyRuns.Load(), test.ShouldEqual, 0)
+
+	clientButton, err := button.FromProvider(robotClient, "button")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, clientButton.Push(ctx, nil), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, pushRuns.Load(), test.ShouldEqual, 1)
+	})
+
+	ticks <- struct{}{}
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, tickRuns.Load(), test.ShouldEqual, 1)
+	})
+
+	// crossing the threshold triggers the job, but crossing it again right away is debounced.
+	temperature.Store(40.0)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, thresholdRuns.Load(), test.ShouldEqual, 1)
+	})
+	temperature.Store(20.0)
+	time.Sleep(200 * time.Millisecond)
+	temperature.Store(40.0)
+	time.Sleep(200 * time.Millisecond)
+	test.That(t, thresholdRuns.Load(), test.ShouldEqual, 1)
+
+	broken := *cfg
+	broken.Components = slices.Clone(cfg.Components)
+	broken.Components[1].Attributes = rutils.AttributeMap{"fail": true}
+	lr.Reconfigure(ctx, &broken)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, unhealthyRuns.Load(), test.ShouldEqual, 1)
+	})
+}
+
 // Test continuous mode, include switching to and from.
 func TestJobContinuousSchedule(t *testing.T) {
 	t.Parallel()
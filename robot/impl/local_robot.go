@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"slices"
 	"sort"
 	"strings"
@@ -58,6 +59,7 @@ import (
 	"go.viam.com/rdk/robot/packages"
 	"go.viam.com/rdk/robot/web"
 	weboptions "go.viam.com/rdk/robot/web/options"
+	"go.viam.com/rdk/services/datamanager"
 	"go.viam.com/rdk/session"
 	"go.viam.com/rdk/utils"
 )
@@ -466,6 +468,7 @@ func newWithResources(
 		if statser, err := sys.NewNetUsageStatser(); err == nil {
 			ftdcWorker.Add("net", statser)
 		}
+		ftdcWorker.Add("runtime", sys.NewRuntimeStatser())
 	}
 
 	homeDir := utils.ViamDotDir
@@ -545,7 +548,11 @@ func newWithResources(
 
 	// we assume these never appear in our configs and as such will not be removed from the
 	// resource graph
-	r.webSvc = web.New(r, logger, rOpts.webOptions...)
+	webOptions := rOpts.webOptions
+	if r.ftdc != nil {
+		webOptions = append(slices.Clone(webOptions), web.WithMetricsHandler(r.ftdc.OpenMetricsHandler()))
+	}
+	r.webSvc = web.New(r, logger, webOptions...)
 	if r.ftdc != nil {
 		r.ftdc.Add("web", r.webSvc.RequestCounter())
 	}
@@ -634,7 +641,30 @@ func newWithResources(
 		return r.ResourceByName(match)
 	}
 
-	jobManager, err := jobmanager.New(ctx, logger, getResource, r.webSvc.ModuleAddresses())
+	// getCaptureDir is passed in to the jobmanager to capture job responses where the data
+	// manager captures data, such that they are synced with it.
+	getCaptureDir := func() string {
+		for _, name := range r.manager.AllNonCollidingResourceNames() {
+			if name.API != datamanager.API || name.ContainsRemoteNames() {
+				continue
+			}
+			res, err := r.ResourceByName(name)
+			if err != nil {
+				continue
+			}
+			if dm, ok := res.(jobmanager.CaptureDirer); ok {
+				return dm.CaptureDir()
+			}
+		}
+		return ""
+	}
+
+	jobHistoryDir := filepath.Join(homeDir, "jobs", partID)
+	eventSources := jobmanager.EventSources{
+		ResourceStatuses: r.manager.resources.Status,
+	}
+	jobManager, err := jobmanager.New(
+		ctx, logger, getResource, getCaptureDir, r.webSvc.ModuleAddresses(), jobHistoryDir, eventSources)
 	if err != nil {
 		r.logger.CErrorw(ctx, "Job manager failed to start", "error", err)
 	}
@@ -1603,6 +1633,13 @@ func (r *localRobot) reconfigure(ctx context.Context, newConfig *config.Config,
 		return
 	}
 
+	if r.ftdc != nil && !reflect.DeepEqual(existingConfig.FTDCRules, newConfig.FTDCRules) {
+		r.ftdc.SetRules(newConfig.FTDCRules)
+	}
+	if r.ftdc != nil && !reflect.DeepEqual(existingConfig.FTDCStorage, newConfig.FTDCStorage) {
+		r.ftdc.SetStorage(newConfig.FTDCStorage)
+	}
+
 	if existingConfig.Revision != newConfig.Revision {
 		revision := diff.NewRevision()
 		for _, res := range diff.UnmodifiedResources {
@@ -1988,6 +2025,52 @@ func (r *localRobot) MachineStatus(ctx context.Context) (robot.MachineStatus, er
 	return result, nil
 }
 
+// JobStatus returns the recent runs of the named jobs, or of every job if no names are given.
+func (r *localRobot) JobStatus(ctx context.Context, names ...string) (map[string][]robot.JobRun, error) {
+	if r.jobManager == nil {
+		return nil, errors.New("job manager is not running")
+	}
+	result := make(map[string][]robot.JobRun)
+	if len(names) == 0 {
+		for jobName, jobHistory := range r.jobManager.JobHistories.Range {
+			result[jobName] = jobHistory.Runs()
+		}
+		return result, nil
+	}
+	for _, name := range names {
+		jobHistory, ok := r.jobManager.JobHistories.Load(name)
+		if !ok {
+			return nil, errors.Errorf("no job named %q", name)
+		}
+		result[name] = jobHistory.Runs()
+	}
+	return result, nil
+}
+
+// RunJobNow runs a configured job once, outside of its schedule.
+func (r *localRobot) RunJobNow(ctx context.Context, name string) error {
+	if r.jobManager == nil {
+		return errors.New("job manager is not running")
+	}
+	return r.jobManager.RunJobNow(name)
+}
+
+// PauseJob stops a configured job from running on its schedule until it is resumed.
+func (r *localRobot) PauseJob(ctx context.Context, name string) error {
+	if r.jobManager == nil {
+		return errors.New("job manager is not running")
+	}
+	return r.jobManager.PauseJob(name)
+}
+
+// ResumeJob puts a paused job back on its schedule.
+func (r *localRobot) ResumeJob(ctx context.Context, name string) error {
+	if r.jobManager == nil {
+		return errors.New("job manager is not running")
+	}
+	return r.jobManager.ResumeJob(name)
+}
+
 // Version returns version information about the robot.
 func (r *localRobot) Version(ctx context.Context) (robot.VersionResponse, error) {
 	return robot.Version, nil
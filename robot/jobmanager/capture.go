@@ -0,0 +1,117 @@
+package jobmanager
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	datasyncpb "go.viam.com/api/app/datasync/v1"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/resource"
+)
+
+// maxCaptureFileSize is the size after which a new capture file is started. It matches the
+// default of the data manager.
+const maxCaptureFileSize = int64(256 * 1024)
+
+// CaptureDirer is implemented by data managers that capture data to a directory, e.g: the builtin
+// data manager.
+type CaptureDirer interface {
+	CaptureDir() string
+}
+
+// jobCapture writes the responses of a job into the data manager capture directory, laid
+// out the same way as the files of data manager collectors so that they are synced with them.
+type jobCapture struct {
+	// captureDir returns the capture directory of the data manager, or an empty string if
+	// there is none.
+	captureDir func() string
+	method     string
+	tags       []string
+
+	mu sync.Mutex
+	// buffer is created on the first write, since the API of the resource is needed to build
+	// its metadata. It is replaced if the job resource or the capture directory changes.
+	buffer     *data.CaptureBuffer
+	bufferName resource.Name
+	bufferDir  string
+}
+
+func newJobCapture(jc config.JobConfig, captureDir func() string) *jobCapture {
+	return &jobCapture{
+		captureDir: captureDir,
+		method:     jc.Method,
+		tags:       jc.Capture.Tags,
+	}
+}
+
+// write appends the response of a successful run of the job on res to the capture buffer.
+func (c *jobCapture) write(res resource.Resource, requested, received time.Time, response map[string]any) error {
+	ts := data.Timestamps{TimeRequested: requested, TimeReceived: received}
+	var result data.CaptureResult
+	var err error
+	if c.method == "DoCommand" {
+		result, err = data.NewTabularCaptureResultDoCommand(ts, response)
+	} else {
+		result, err = data.NewTabularCaptureResult(ts, response)
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not convert job response to tabular data")
+	}
+
+	dir := c.captureDir()
+	if dir == "" {
+		dir = data.ViamCaptureDotDir
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.buffer == nil || c.bufferName != res.Name() || c.bufferDir != dir {
+		if err := c.flushLocked(); err != nil {
+			return err
+		}
+		name := res.Name()
+		// job responses are always written as tabular data, even for methods that data
+		// manager collectors capture as binary data.
+		md := &datasyncpb.DataCaptureMetadata{
+			ComponentType: name.API.String(),
+			ComponentName: name.ShortName(),
+			MethodName:    c.method,
+			Type:          data.CaptureTypeTabular.ToProto(),
+			FileExtension: data.ExtDat,
+			Tags:          c.tags,
+		}
+		targetDir := data.CaptureFilePathWithReplacedReservedChars(
+			filepath.Join(dir, name.API.String(), name.ShortName(), c.method))
+		if err := os.MkdirAll(targetDir, 0o700); err != nil {
+			return errors.Wrapf(err, "failed to create target directory %s with 700 file permissions", targetDir)
+		}
+		c.buffer = data.NewCaptureBuffer(targetDir, md, maxCaptureFileSize)
+		c.bufferName = name
+		c.bufferDir = dir
+	}
+	for _, item := range result.ToProto() {
+		if err := c.buffer.WriteTabular(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flush marks the capture file in progress as complete, making it eligible for sync.
+func (c *jobCapture) flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.flushLocked()
+}
+
+func (c *jobCapture) flushLocked() error {
+	if c.buffer == nil {
+		return nil
+	}
+	return c.buffer.Flush()
+}
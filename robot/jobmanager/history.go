@@ -0,0 +1,137 @@
+package jobmanager
+
+import (
+	"container/ring"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.viam.com/rdk/robot"
+)
+
+const (
+	// runHistoryLength is the number of detailed runs kept (and persisted) per job.
+	runHistoryLength = 50
+	// maxResponseLength bounds the size of a job response kept in its run record.
+	maxResponseLength = 1024
+	// historyFileName is the name of the file the job histories are persisted to, inside of
+	// the history directory passed to New.
+	historyFileName = "job_history.json"
+	// historyFlushInterval is how often new runs are written to disk.
+	historyFlushInterval = 10 * time.Second
+)
+
+func newJobHistory() *JobHistory {
+	return &JobHistory{
+		successTimes: ring.New(historyLength),
+		failureTimes: ring.New(historyLength),
+	}
+}
+
+// AddRun records a finished run of the job, overwriting the earliest run if the history is
+// full. The end of the run is also recorded as a success or failure timestamp.
+func (jh *JobHistory) AddRun(run robot.JobRun) {
+	run.Response = truncateResponse(run.Response)
+	jh.runsMu.Lock()
+	jh.runs = append(jh.runs, run)
+	if len(jh.runs) > runHistoryLength {
+		jh.runs = jh.runs[len(jh.runs)-runHistoryLength:]
+	}
+	jh.runsMu.Unlock()
+
+	if run.Error == "" {
+		jh.AddSuccess(run.Start.Add(run.Duration))
+	} else {
+		jh.AddFailure(run.Start.Add(run.Duration))
+	}
+}
+
+// truncateResponse cuts a response down to maxResponseLength bytes, on a rune boundary.
+func truncateResponse(response string) string {
+	if len(response) <= maxResponseLength {
+		return response
+	}
+	cut := maxResponseLength
+	for cut > 0 && !utf8.RuneStart(response[cut]) {
+		cut--
+	}
+	return response[:cut] + "..."
+}
+
+// Runs returns the last runHistoryLength runs of the job, oldest first.
+func (jh *JobHistory) Runs() []robot.JobRun {
+	jh.runsMu.Lock()
+	defer jh.runsMu.Unlock()
+	runs := make([]robot.JobRun, len(jh.runs))
+	copy(runs, jh.runs)
+	return runs
+}
+
+// historyStore persists the runs of every job to a single JSON file.
+type historyStore struct {
+	path string
+
+	mu    sync.Mutex
+	dirty bool
+}
+
+func newHistoryStore(dir string) (*historyStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &historyStore{path: filepath.Join(dir, historyFileName)}, nil
+}
+
+// load reads the persisted runs. A missing file is not an error.
+func (hs *historyStore) load() (map[string][]robot.JobRun, error) {
+	data, err := os.ReadFile(hs.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	runs := map[string][]robot.JobRun{}
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// markDirty notes that a job history changed since the last flush.
+func (hs *historyStore) markDirty() {
+	hs.mu.Lock()
+	hs.dirty = true
+	hs.mu.Unlock()
+}
+
+// flush writes the runs of all histories to disk if any of them changed since the last
+// flush. The file is replaced atomically so a crash never leaves a partial history behind.
+func (hs *historyStore) flush(histories func(yield func(string, *JobHistory) bool)) error {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if !hs.dirty {
+		return nil
+	}
+	runs := map[string][]robot.JobRun{}
+	for name, jh := range histories {
+		runs[name] = jh.Runs()
+	}
+	data, err := json.Marshal(runs)
+	if err != nil {
+		return err
+	}
+	tmpPath := hs.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, hs.path); err != nil {
+		return err
+	}
+	hs.dirty = false
+	return nil
+}
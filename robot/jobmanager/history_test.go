@@ -0,0 +1,21 @@
+package jobmanager
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/robot"
+)
+
+func TestJobHistoryTruncatesResponses(t *testing.T) {
+	jh := newJobHistory()
+	// Each "é" is two bytes, so byte maxResponseLength falls in the middle of one.
+	jh.AddRun(robot.JobRun{Response: "a" + strings.Repeat("é", maxResponseLength)})
+	runs := jh.Runs()
+	test.That(t, runs, test.ShouldHaveLength, 1)
+	test.That(t, utf8.ValidString(runs[0].Response), test.ShouldBeTrue)
+	test.That(t, runs[0].Response, test.ShouldEqual, "a"+strings.Repeat("é", (maxResponseLength-2)/2)+"...")
+}
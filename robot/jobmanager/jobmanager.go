@@ -7,7 +7,9 @@ import (
 	"container/ring"
 	"context"
 	"encoding/json"
+	"maps"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -28,6 +30,7 @@ import (
 	"go.viam.com/rdk/grpc"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
 	rutils "go.viam.com/rdk/utils"
 	"go.viam.com/rdk/utils/ssync"
 )
@@ -39,22 +42,50 @@ const (
 	// the job manager will be looking for.
 	componentServiceIndex int = 2
 	historyLength         int = 10
+
+	// defaultRetryBackoff is the wait before the first retry of a failed job if the job
+	// config does not specify one. The wait doubles on every retry up to maxRetryBackoff.
+	defaultRetryBackoff = time.Second
+	maxRetryBackoff     = time.Minute
+	// manualJobDelay parks "manual" jobs on the scheduler far enough in the future that
+	// they only ever run when triggered.
+	manualJobDelay = 100 * 365 * 24 * time.Hour
 )
 
 // JobManager keeps track of the currently scheduled jobs and updates the schedule with
 // respect to the "jobs" part of the config.
 type JobManager struct {
-	scheduler     gocron.Scheduler
-	logger        logging.Logger
-	getResource   func(resource string) (resource.Resource, error)
-	namesToJobIDs map[string]uuid.UUID
-	ctx           context.Context
-	conn          rpc.ClientConn
-	isClosed      bool
-	closeMutex    sync.Mutex
+	scheduler   gocron.Scheduler
+	logger      logging.Logger
+	getResource func(resource string) (resource.Resource, error)
+	// getCaptureDir returns the capture directory of the data manager. See New.
+	getCaptureDir func() string
+	eventSources  EventSources
+	// jobsMu guards namesToJobIDs, jobConfigs, pausedJobs, triggerCancels and cyclicJobs, and
+	// serializes changes to the scheduler.
+	jobsMu         sync.Mutex
+	namesToJobIDs  map[string]uuid.UUID
+	jobConfigs     map[string]config.JobConfig
+	pausedJobs     map[string]struct{}
+	triggerCancels map[string]context.CancelFunc
+	// cyclicJobs are the jobs that could trigger themselves through their follow-up jobs. They
+	// do not trigger follow-up jobs. See config.JobsInTriggerCycles.
+	cyclicJobs map[string]struct{}
+	ctx        context.Context
+	conn       rpc.ClientConn
+	isClosed   bool
+	closeMutex sync.Mutex
+
+	// history is nil if job histories are not persisted.
+	history                 *historyStore
+	workersCtx              context.Context
+	cancelBackgroundWorkers context.CancelFunc
+	activeBackgroundWorkers sync.WaitGroup
 
 	NumJobHistories atomic.Int32
 	JobHistories    ssync.Map[string, *JobHistory]
+	// captures holds the capture buffers of the jobs that capture their responses.
+	captures ssync.Map[string, *jobCapture]
 }
 
 // JobHistory records historical metadata about a job.
@@ -63,6 +94,8 @@ type JobHistory struct {
 	successTimes   *ring.Ring
 	failureTimesMu sync.Mutex
 	failureTimes   *ring.Ring
+	runsMu         sync.Mutex
+	runs           []robot.JobRun
 }
 
 // Successes returns timestamps of the last historyLength number successfully completed jobs.
@@ -111,12 +144,19 @@ func (jh *JobHistory) AddFailure(time time.Time) {
 
 // New sets up the context and grpcConn that is used in scheduled jobs. The actual
 // scheduler is initialized and automatically started. Any jobs added to the config will
-// then immediately get scheduled according to their "Schedule" field.
+// then immediately get scheduled according to their "Schedule" field. If historyDir is
+// not empty, the runs of every job are persisted there and restored on the next start.
+// Event triggers of jobs watch the robot through eventSources. Captured job responses are
+// written to the directory returned by getCaptureDir, the capture directory of the data
+// manager, or to the default capture directory if it returns an empty string.
 func New(
 	robotContext context.Context,
 	logger logging.Logger,
 	getResource func(string) (resource.Resource, error),
+	getCaptureDir func() string,
 	parentAddr config.ParentSockAddrs,
+	historyDir string,
+	eventSources EventSources,
 ) (*JobManager, error) {
 	jobLogger := logger.Sublogger("job_manager")
 
@@ -144,18 +184,88 @@ func New(
 	}
 
 	jm := &JobManager{
-		logger:        jobLogger,
-		scheduler:     scheduler,
-		getResource:   getResource,
-		namesToJobIDs: make(map[string]uuid.UUID),
-		ctx:           robotContext,
-		conn:          conn,
+		logger:         jobLogger,
+		scheduler:      scheduler,
+		getResource:    getResource,
+		getCaptureDir:  getCaptureDir,
+		eventSources:   eventSources,
+		namesToJobIDs:  make(map[string]uuid.UUID),
+		jobConfigs:     make(map[string]config.JobConfig),
+		pausedJobs:     make(map[string]struct{}),
+		triggerCancels: make(map[string]context.CancelFunc),
+		ctx:            robotContext,
+		conn:           conn,
+	}
+
+	if historyDir != "" {
+		jm.restoreHistory(historyDir)
 	}
+	workersCtx, cancel := context.WithCancel(context.Background())
+	jm.workersCtx = workersCtx
+	jm.cancelBackgroundWorkers = cancel
+	jm.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		ticker := time.NewTicker(historyFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-workersCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			jm.flushHistory()
+			jm.flushCaptures()
+		}
+	}, jm.activeBackgroundWorkers.Done)
 
 	jm.scheduler.Start()
 	return jm, nil
 }
 
+// restoreHistory sets up the history store and loads the runs persisted by a previous
+// JobManager. Failures are logged; the JobManager then runs without persisted history.
+func (jm *JobManager) restoreHistory(historyDir string) {
+	history, err := newHistoryStore(historyDir)
+	if err != nil {
+		jm.logger.CWarnw(jm.ctx, "Could not set up job history directory", "dir", historyDir, "error", err.Error())
+		return
+	}
+	jm.history = history
+	runs, err := history.load()
+	if err != nil {
+		jm.logger.CWarnw(jm.ctx, "Could not load persisted job history", "dir", historyDir, "error", err.Error())
+		return
+	}
+	for name, jobRuns := range runs {
+		jh := newJobHistory()
+		for _, run := range jobRuns {
+			jh.AddRun(run)
+		}
+		jm.JobHistories.Store(name, jh)
+		jm.NumJobHistories.Add(1)
+	}
+}
+
+// flushHistory writes the job histories to disk if they are persisted.
+func (jm *JobManager) flushHistory() {
+	if jm.history == nil {
+		return
+	}
+	if err := jm.history.flush(jm.JobHistories.Range); err != nil {
+		jm.logger.CWarnw(jm.ctx, "Could not persist job history", "error", err.Error())
+	}
+}
+
+// flushCaptures completes the capture files of all jobs that capture their responses, so that
+// the data manager can sync them.
+func (jm *JobManager) flushCaptures() {
+	for name, capture := range jm.captures.Range {
+		if err := capture.flush(); err != nil {
+			jm.logger.CWarnw(jm.ctx, "Could not flush captured job responses", "name", name, "error", err.Error())
+		}
+	}
+}
+
 // Close attempts to close the grpcConn of the job scheduler and shuts it down. It is
 // not possible to restart the job scheduler after calling Shutdown().
 func (jm *JobManager) Close() error {
@@ -168,7 +278,12 @@ func (jm *JobManager) Close() error {
 	jm.isClosed = true
 	jm.logger.CInfo(jm.ctx, "JobManager is shutting down.")
 	utils.UncheckedError(jm.conn.Close())
-	return jm.scheduler.Shutdown()
+	err := jm.scheduler.Shutdown()
+	jm.cancelBackgroundWorkers()
+	jm.activeBackgroundWorkers.Wait()
+	jm.flushHistory()
+	jm.flushCaptures()
+	return err
 }
 
 // createDescriptorSourceAndgRPCMethod sets up a DescriptorSource for grpc translations
@@ -216,39 +331,44 @@ func (jm *JobManager) createJobFunction(jc config.JobConfig, continuous bool) fu
 	// deduplication for job loggers.
 	jobLogger.NeverDeduplicate()
 
-	// using jm.ctx so we interrupt only if JM is shutting down. When changing schedule, let existing jobs complete instead of interrupting.
-	jobFunc := func(_ context.Context) error {
+	jobFunc := func(ctx context.Context) (map[string]any, error) {
 		res, err := jm.getResource(jc.Resource)
 		if err != nil {
-			jobLogger.CWarnw(jm.ctx, "Could not get resource", "error", err.Error())
-			return err
+			jobLogger.CWarnw(ctx, "Could not get resource", "error", err.Error())
+			return nil, err
 		}
 		if jc.Method == "DoCommand" {
-			jobLogger.CDebugw(jm.ctx, "Job triggered", "name", jc.Name)
-			// unlike below InvokeRPC, if DoCommand panics there is no recover
-			response, err := res.DoCommand(jm.ctx, jc.Command)
+			jobLogger.CDebugw(ctx, "Job triggered", "name", jc.Name)
+			// unlike below InvokeRPC, a DoCommand panic is only recovered by runAttempt.
+			response, err := res.DoCommand(ctx, jc.Command)
 			if err != nil {
-				jobLogger.CWarnw(jm.ctx, "Job failed", "error", err.Error())
-				return err
+				jobLogger.CWarnw(ctx, "Job failed", "error", err.Error())
+				return nil, err
 			}
-			jobLogger.CDebugw(jm.ctx, "Job succeeded", "name", jc.Name, "response", response)
-			return nil
+			jobLogger.CDebugw(ctx, "Job succeeded", "name", jc.Name, "response", response)
+			return response, nil
 		}
 
 		descSource, grpcService, grpcMethod, err := jm.createDescriptorSourceAndgRPCMethod(res, jc.Method)
 		if err != nil {
-			jobLogger.CWarnw(jm.ctx, "grpc setup failed", "error", err)
-			return err
+			jobLogger.CWarnw(ctx, "grpc setup failed", "error", err)
+			return nil, err
 		}
 
+		// the resource name is filled in unless the configured request body already names the
+		// resource.
 		gRPCArgument := resource.GetResourceNameOverride(grpcService, grpcMethod)
-		argumentMap := map[string]string{
-			gRPCArgument: jc.Resource,
+		argumentMap := maps.Clone(jc.Request)
+		if argumentMap == nil {
+			argumentMap = map[string]any{}
+		}
+		if _, ok := argumentMap[gRPCArgument]; !ok {
+			argumentMap[gRPCArgument] = jc.Resource
 		}
 		argumentBytes, err := json.Marshal(argumentMap)
 		if err != nil {
-			jobLogger.CWarnw(jm.ctx, "could not serialize gRPC method arguments", "error", err.Error())
-			return err
+			jobLogger.CWarnw(ctx, "could not serialize gRPC method arguments", "error", err.Error())
+			return nil, err
 		}
 		options := grpcurl.FormatOptions{
 			EmitJSONDefaultFields: true,
@@ -261,8 +381,8 @@ func (jm *JobManager) createJobFunction(jc config.JobConfig, continuous bool) fu
 			bytes.NewBuffer(argumentBytes),
 			options)
 		if err != nil {
-			jobLogger.CWarnw(jm.ctx, "could not create parser and formatter for grpc requests", "error", err.Error())
-			return err
+			jobLogger.CWarnw(ctx, "could not create parser and formatter for grpc requests", "error", err.Error())
+			return nil, err
 		}
 
 		buffer := bytes.NewBuffer(make([]byte, 0))
@@ -271,26 +391,130 @@ func (jm *JobManager) createJobFunction(jc config.JobConfig, continuous bool) fu
 			Formatter:      formatter,
 			VerbosityLevel: 0,
 		}
-		jobLogger.CDebugw(jm.ctx, "Job triggered", "name", jc.Name)
+		jobLogger.CDebugw(ctx, "Job triggered", "name", jc.Name)
 		grpcMethodCombined := grpcService + "." + grpcMethod
-		err = grpcurl.InvokeRPC(jm.ctx, descSource, jm.conn, grpcMethodCombined, nil, h, rf.Next)
+		err = grpcurl.InvokeRPC(ctx, descSource, jm.conn, grpcMethodCombined, nil, h, rf.Next)
 		if err != nil {
-			jobLogger.CWarnw(jm.ctx, "Job failed", "name", jc.Name, "error", err.Error())
-			return err
+			jobLogger.CWarnw(ctx, "Job failed", "name", jc.Name, "error", err.Error())
+			return nil, err
 		} else if h.Status != nil && h.Status.Err() != nil {
 			// if job panics, it seems to be captured here.
-			jobLogger.CWarnw(jm.ctx, "Job failed", "name", jc.Name, "error", h.Status.Err())
-			return h.Status.Err()
+			jobLogger.CWarnw(ctx, "Job failed", "name", jc.Name, "error", h.Status.Err())
+			return nil, h.Status.Err()
 		}
 		response := map[string]any{}
 		err = json.Unmarshal(buffer.Bytes(), &response)
 		if err != nil {
-			jobLogger.CWarnw(jm.ctx, "Unmarshalling grpc response failed with error", "name", jc.Name,
+			jobLogger.CWarnw(ctx, "Unmarshalling grpc response failed with error", "name", jc.Name,
 				"error", err.Error())
-			return err
+			return nil, err
+		}
+		jobLogger.CDebugw(ctx, "Job succeeded", "name", jc.Name, "response", response)
+		return response, nil
+	}
+
+	timeout, _ := time.ParseDuration(jc.Timeout)
+	backoff := defaultRetryBackoff
+	if jc.RetryBackoff != "" {
+		backoff, _ = time.ParseDuration(jc.RetryBackoff)
+	}
+
+	// runAttempt runs the job once, giving up on it once the timeout passes. The job
+	// function is run by a worker such that a timed out attempt is cancelled and waited
+	// for, instead of being left running in the background.
+	type result struct {
+		response map[string]any
+		err      error
+	}
+	runAttempt := func(ctx context.Context) (map[string]any, error) {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		resultCh := make(chan result, 1)
+		worker := utils.NewStoppableWorkers(ctx)
+		defer worker.Stop()
+		worker.Add(func(ctx context.Context) {
+			defer func() {
+				if r := recover(); r != nil {
+					resultCh <- result{err: errors.Errorf("job %q panicked: %v", jc.Name, r)}
+				}
+			}()
+			response, err := jobFunc(ctx)
+			resultCh <- result{response, err}
+		})
+		select {
+		case res := <-resultCh:
+			return res.response, res.err
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				jobLogger.CWarnw(jm.ctx, "Job timed out", "name", jc.Name, "timeout", timeout)
+				return nil, errors.Errorf("job %q timed out after %v", jc.Name, timeout)
+			}
+			return nil, ctx.Err()
 		}
-		jobLogger.CDebugw(jm.ctx, "Job succeeded", "name", jc.Name, "response", response)
-		return nil
+	}
+
+	runWithRetries := func(ctx context.Context) (map[string]any, error) {
+		wait := backoff
+		var response map[string]any
+		var err error
+		for attempt := 0; attempt <= jc.MaxRetries; attempt++ {
+			if attempt > 0 {
+				jobLogger.CInfow(jm.ctx, "Retrying job", "name", jc.Name, "attempt", attempt, "backoff", wait)
+				select {
+				case <-ctx.Done():
+					return nil, err
+				case <-time.After(wait):
+				}
+				wait = min(2*wait, maxRetryBackoff)
+			}
+			if response, err = runAttempt(ctx); err == nil {
+				return response, nil
+			}
+		}
+		return nil, err
+	}
+
+	var capture *jobCapture
+	if jc.Capture != nil {
+		capture = newJobCapture(jc, jm.getCaptureDir)
+		jm.captures.Store(jc.Name, capture)
+	}
+	captureResponse := func(start time.Time, response map[string]any) {
+		res, err := jm.getResource(jc.Resource)
+		if err == nil {
+			err = capture.write(res, start, time.Now(), response)
+		}
+		// a run that outlives its job (e.g. after a config change) completes its own file,
+		// since the job manager no longer flushes it.
+		if current, ok := jm.captures.Load(jc.Name); err == nil && (!ok || current != capture) {
+			err = capture.flush()
+		}
+		if err != nil {
+			jobLogger.CWarnw(jm.ctx, "Could not capture job response", "name", jc.Name, "error", err.Error())
+		}
+	}
+
+	// Runs are derived from jm.ctx so we interrupt only if JM is shutting down. When changing
+	// schedule, let existing jobs complete instead of interrupting. The cancel_previous
+	// overlap policy is the exception: a new run cancels the one still in progress.
+	var runningMu sync.Mutex
+	var cancelRunning context.CancelFunc
+	startRun := func() (context.Context, context.CancelFunc) {
+		runCtx, cancel := context.WithCancel(jm.ctx)
+		if jc.OverlapPolicy != config.JobOverlapCancelPrevious {
+			return runCtx, cancel
+		}
+		runningMu.Lock()
+		defer runningMu.Unlock()
+		if cancelRunning != nil {
+			jobLogger.CDebugw(jm.ctx, "Cancelling previous run of job", "name", jc.Name)
+			cancelRunning()
+		}
+		cancelRunning = cancel
+		return runCtx, cancel
 	}
 
 	return func(ctx context.Context) error {
@@ -305,16 +529,25 @@ func (jm *JobManager) createJobFunction(jc config.JobConfig, continuous bool) fu
 				return err
 			default:
 			}
-			err = jobFunc(ctx)
-			now := time.Now()
+			runCtx, cancel := startRun()
+			start := time.Now()
+			var response map[string]any
+			response, err = runWithRetries(runCtx)
+			cancel()
+			if err == nil && capture != nil {
+				captureResponse(start, response)
+			}
 			if jh, ok := jm.JobHistories.Load(jc.Name); ok {
-				if err != nil {
-					// this includes captured panics (from InvokeRPC).
-					jh.AddFailure(now)
-				} else {
-					jh.AddSuccess(now)
+				jh.AddRun(newJobRun(start, response, err))
+				if jm.history != nil {
+					jm.history.markDirty()
 				}
 			}
+			if err != nil {
+				jm.triggerJobs(jc.Name, jc.OnFailure, jobLogger)
+			} else {
+				jm.triggerJobs(jc.Name, jc.OnSuccess, jobLogger)
+			}
 			if !continuous {
 				return err
 			}
@@ -322,7 +555,106 @@ func (jm *JobManager) createJobFunction(jc config.JobConfig, continuous bool) fu
 	}
 }
 
-// removeJob removes the job from the scheduler and clears the internal map entry.
+// newJobRun creates the record of a run that started at start and just finished.
+func newJobRun(start time.Time, response map[string]any, err error) robot.JobRun {
+	run := robot.JobRun{Start: start, Duration: time.Since(start)}
+	if err != nil {
+		// this includes captured panics (from InvokeRPC).
+		run.Error = err.Error()
+	} else if response != nil {
+		if data, err := json.Marshal(response); err == nil {
+			run.Response = string(data)
+		}
+	}
+	return run
+}
+
+// triggerJobs runs the named follow-up jobs of a finished job. Follow-up jobs respect their
+// own overlap policy.
+func (jm *JobManager) triggerJobs(from string, names []string, logger logging.Logger) {
+	if len(names) == 0 {
+		return
+	}
+	jm.jobsMu.Lock()
+	_, cyclic := jm.cyclicJobs[from]
+	jm.jobsMu.Unlock()
+	if cyclic {
+		logger.CWarnw(jm.ctx, "Not triggering follow-up jobs of a job in a trigger cycle", "name", from, "follow_ups", names)
+		return
+	}
+	for _, name := range names {
+		logger.CDebugw(jm.ctx, "Triggering follow-up job", "name", from, "follow_up", name)
+		if err := jm.RunJobNow(name); err != nil {
+			logger.CWarnw(jm.ctx, "Could not trigger follow-up job", "name", from, "follow_up", name, "error", err.Error())
+		}
+	}
+}
+
+// RunJobNow runs a scheduled job once, outside of its regular schedule. The run respects the
+// overlap policy of the job and is recorded in its history like any other run.
+func (jm *JobManager) RunJobNow(name string) error {
+	jm.jobsMu.Lock()
+	jobID, ok := jm.namesToJobIDs[name]
+	jm.jobsMu.Unlock()
+	if !ok {
+		return errors.Errorf("no job named %q is scheduled", name)
+	}
+	for _, j := range jm.scheduler.Jobs() {
+		if j.ID() == jobID {
+			return j.RunNow()
+		}
+	}
+	return errors.Errorf("no job named %q is scheduled", name)
+}
+
+// PauseJob stops a job from running on its schedule until ResumeJob is called. A paused job
+// can still be run with RunJobNow. Jobs stay paused when their config is modified, but not
+// across restarts.
+func (jm *JobManager) PauseJob(name string) error {
+	jm.jobsMu.Lock()
+	defer jm.jobsMu.Unlock()
+	jc, ok := jm.jobConfigs[name]
+	if !ok {
+		return errors.Errorf("no job named %q is scheduled", name)
+	}
+	if _, paused := jm.pausedJobs[name]; paused {
+		return nil
+	}
+	jm.logger.CInfow(jm.ctx, "Pausing job", "name", name)
+	jm.pausedJobs[name] = struct{}{}
+	jm.removeJob(name, false)
+	jm.scheduleJob(jc, false)
+	return nil
+}
+
+// ResumeJob puts a job paused by PauseJob back on its schedule.
+func (jm *JobManager) ResumeJob(name string) error {
+	jm.jobsMu.Lock()
+	defer jm.jobsMu.Unlock()
+	jc, ok := jm.jobConfigs[name]
+	if !ok {
+		return errors.Errorf("no job named %q is scheduled", name)
+	}
+	if _, paused := jm.pausedJobs[name]; !paused {
+		return nil
+	}
+	jm.logger.CInfow(jm.ctx, "Resuming job", "name", name)
+	delete(jm.pausedJobs, name)
+	jm.removeJob(name, false)
+	jm.scheduleJob(jc, false)
+	return nil
+}
+
+// IsPaused returns whether the named job is paused.
+func (jm *JobManager) IsPaused(name string) bool {
+	jm.jobsMu.Lock()
+	defer jm.jobsMu.Unlock()
+	_, paused := jm.pausedJobs[name]
+	return paused
+}
+
+// removeJob removes the job from the scheduler and clears the internal map entries. It must
+// be called with jobsMu held.
 func (jm *JobManager) removeJob(name string, verbose bool) {
 	jobID := jm.namesToJobIDs[name]
 	if verbose {
@@ -333,20 +665,29 @@ func (jm *JobManager) removeJob(name string, verbose bool) {
 		jm.logger.CWarnw(jm.ctx, "Removing the job failed", "error", err.Error())
 	}
 	delete(jm.namesToJobIDs, name)
+	delete(jm.jobConfigs, name)
+	jm.stopTrigger(name)
+	if capture, ok := jm.captures.LoadAndDelete(name); ok {
+		if err := capture.flush(); err != nil {
+			jm.logger.CWarnw(jm.ctx, "Could not flush captured job responses", "name", name, "error", err.Error())
+		}
+	}
 }
 
 // scheduleJob validates the job config and attempts to put a new job on the scheduler
-// queue. If an error happens, it is logged, and the job is not scheduled.
+// queue. If an error happens, it is logged, and the job is not scheduled. It must be called
+// with jobsMu held.
 func (jm *JobManager) scheduleJob(jc config.JobConfig, verbose bool) {
 	if err := jc.Validate(""); err != nil {
 		jm.logger.CWarnw(jm.ctx, "Job failed to validate", "name", jc.Name, "error", err.Error())
 		return
 	}
 
+	_, paused := jm.pausedJobs[jc.Name]
 	var continuous bool
 	var jobDefinition gocron.JobDefinition
 	var jobOptions []gocron.JobOption
-	if strings.ToLower(jc.Schedule) == "continuous" {
+	if strings.ToLower(jc.Schedule) == "continuous" && !paused {
 		continuous = true
 		// used with WithIntervalFromCompletion: if job unexpectedly exits, try to restart later.
 		// since we capture panics, this is largely unused, but helps reduce scheduler overhead.
@@ -386,15 +727,30 @@ func (jm *JobManager) scheduleJob(jc config.JobConfig, verbose bool) {
 
 		// It is also important to note that DURATION jobs start relative to when they were
 		// queued on the job scheduler, while CRON jobs are tied to the physical clock.
-		t, err := time.ParseDuration(jc.Schedule)
-		if err != nil {
+		var limitMode gocron.LimitMode = gocron.LimitModeWait
+		if paused || jc.Schedule == "" || strings.ToLower(jc.Schedule) == config.JobScheduleManual {
+			// manual, paused and event-only jobs are only ever started by RunJobNow.
+			jobDefinition = gocron.OneTimeJob(gocron.OneTimeJobStartDateTime(time.Now().Add(manualJobDelay)))
+		} else if t, err := time.ParseDuration(jc.Schedule); err != nil {
 			// TODO(RSDK-12757): exit if cron job is also invalid. Currently it's stored as an invalid string and validated at NewJob call.
 			withSeconds := len(strings.Split(jc.Schedule, " ")) >= 6
 			jobDefinition = gocron.CronJob(jc.Schedule, withSeconds)
-			jobOptions = append(jobOptions, gocron.WithSingletonMode(gocron.LimitModeReschedule))
+			limitMode = gocron.LimitModeReschedule
 		} else {
 			jobDefinition = gocron.DurationJob(t)
-			jobOptions = append(jobOptions, gocron.WithSingletonMode(gocron.LimitModeWait))
+		}
+
+		// The overlap policy overrides the defaults above. Jobs that cancel their previous run
+		// are not singletons: the job function itself stops the run in progress.
+		switch jc.OverlapPolicy {
+		case config.JobOverlapSkip:
+			limitMode = gocron.LimitModeReschedule
+		case config.JobOverlapQueue:
+			limitMode = gocron.LimitModeWait
+		case config.JobOverlapCancelPrevious, config.JobOverlapDefault:
+		}
+		if jc.OverlapPolicy != config.JobOverlapCancelPrevious {
+			jobOptions = append(jobOptions, gocron.WithSingletonMode(limitMode))
 		}
 	}
 
@@ -405,10 +761,7 @@ func (jm *JobManager) scheduleJob(jc config.JobConfig, verbose bool) {
 	jobLogger := jm.logger.Sublogger(jc.Name)
 
 	if _, ok := jm.JobHistories.Load(jc.Name); !ok {
-		jm.JobHistories.Store(jc.Name, &JobHistory{
-			successTimes: ring.New(historyLength),
-			failureTimes: ring.New(historyLength),
-		})
+		jm.JobHistories.Store(jc.Name, newJobHistory())
 		jm.NumJobHistories.Add(1)
 	}
 
@@ -429,13 +782,20 @@ func (jm *JobManager) scheduleJob(jc config.JobConfig, verbose bool) {
 	}
 
 	jm.namesToJobIDs[jc.Name] = jobID
+	jm.jobConfigs[jc.Name] = jc
+	if jc.Trigger != nil && !paused {
+		jm.startTrigger(jc, jobLogger)
+	}
 }
 
 // UpdateJobs is called when the "jobs" part of the config gets updated. It updates
 // scheduled jobs based on the Removed/Added/Modified parts of the diff.
 func (jm *JobManager) UpdateJobs(diff *config.Diff) {
+	jm.jobsMu.Lock()
+	defer jm.jobsMu.Unlock()
 	for _, jc := range diff.Removed.Jobs {
 		jm.removeJob(jc.Name, true)
+		delete(jm.pausedJobs, jc.Name)
 	}
 	for _, jc := range diff.Modified.Jobs {
 		jm.logger.CInfow(jm.ctx, "Job modified", "name", jc.Name)
@@ -445,4 +805,9 @@ func (jm *JobManager) UpdateJobs(diff *config.Diff) {
 	for _, jc := range diff.Added.Jobs {
 		jm.scheduleJob(jc, true)
 	}
+
+	jm.cyclicJobs = make(map[string]struct{})
+	for _, name := range config.JobsInTriggerCycles(slices.Collect(maps.Values(jm.jobConfigs))) {
+		jm.cyclicJobs[name] = struct{}{}
+	}
 }
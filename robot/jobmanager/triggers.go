@@ -0,0 +1,320 @@
+package jobmanager
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/button"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+const (
+	defaultTriggerPollInterval = time.Second
+	// triggerRestartDelay is the wait before a digital interrupt or button push trigger
+	// subscribes to the events of its resource again after the subscription failed.
+	triggerRestartDelay = time.Second
+)
+
+// EventSources give the JobManager access to the state of the robot that job triggers watch.
+// Triggers that need a missing source never fire.
+type EventSources struct {
+	// ResourceStatuses returns the status of every resource in the resource graph.
+	ResourceStatuses func() []resource.NodeStatus
+}
+
+// trigger watches for the event configured on a job and runs the job when it happens.
+type trigger struct {
+	jm           *JobManager
+	jobName      string
+	cfg          config.JobTriggerConfig
+	resourceName string
+	pollInterval time.Duration
+	debounce     time.Duration
+	logger       logging.Logger
+
+	// lastFired and lastErr are only accessed by the goroutine running the trigger.
+	lastFired time.Time
+	lastErr   string
+}
+
+// startTrigger starts watching for the trigger event of a job in the background. It must be
+// called with jobsMu held.
+func (jm *JobManager) startTrigger(jc config.JobConfig, logger logging.Logger) {
+	if jm.workersCtx.Err() != nil {
+		return
+	}
+	t := &trigger{
+		jm:           jm,
+		jobName:      jc.Name,
+		cfg:          *jc.Trigger,
+		resourceName: jc.Trigger.Resource,
+		pollInterval: defaultTriggerPollInterval,
+		logger:       logger,
+	}
+	if t.resourceName == "" {
+		t.resourceName = jc.Resource
+	}
+	if jc.Trigger.PollInterval != "" {
+		if d, _ := time.ParseDuration(jc.Trigger.PollInterval); d > 0 {
+			t.pollInterval = d
+		}
+	}
+	t.debounce, _ = time.ParseDuration(jc.Trigger.Debounce)
+
+	ctx, cancel := context.WithCancel(jm.workersCtx)
+	jm.triggerCancels[jc.Name] = cancel
+	jm.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		t.run(ctx)
+	}, jm.activeBackgroundWorkers.Done)
+}
+
+// stopTrigger stops watching for the trigger event of a job. It must be called with jobsMu
+// held. It does not wait for the trigger to stop, since a firing trigger needs jobsMu to run
+// its job.
+func (jm *JobManager) stopTrigger(name string) {
+	if cancel, ok := jm.triggerCancels[name]; ok {
+		cancel()
+		delete(jm.triggerCancels, name)
+	}
+}
+
+func (t *trigger) run(ctx context.Context) {
+	t.logger.CDebugw(ctx, "Watching for job trigger", "name", t.jobName, "type", t.cfg.Type, "resource", t.resourceName)
+	switch t.cfg.Type {
+	case config.JobTriggerHealth:
+		t.poll(ctx, t.healthCheck())
+	case config.JobTriggerButtonPush:
+		t.resubscribe(ctx, t.watchPushes)
+	case config.JobTriggerSensorThreshold:
+		t.poll(ctx, t.thresholdCheck())
+	case config.JobTriggerDigitalInterrupt:
+		t.resubscribe(ctx, t.streamTicks)
+	}
+}
+
+// fire runs the job unless it was triggered less than the debounce time ago.
+func (t *trigger) fire(ctx context.Context) {
+	now := time.Now()
+	if t.debounce > 0 && !t.lastFired.IsZero() && now.Sub(t.lastFired) < t.debounce {
+		t.logger.CDebugw(ctx, "Dropping debounced job trigger", "name", t.jobName)
+		return
+	}
+	t.lastFired = now
+	t.logger.CDebugw(ctx, "Job triggered by event", "name", t.jobName, "type", t.cfg.Type)
+	if err := t.jm.RunJobNow(t.jobName); err != nil {
+		t.logger.CWarnw(ctx, "Could not run triggered job", "name", t.jobName, "error", err.Error())
+	}
+}
+
+// warn logs an error of the trigger, unless it is the same as the previous one. Watched
+// resources are polled often, so a resource that stays broken would flood the logs otherwise.
+func (t *trigger) warn(ctx context.Context, err error) {
+	if err == nil {
+		t.lastErr = ""
+		return
+	}
+	if err.Error() == t.lastErr {
+		return
+	}
+	t.lastErr = err.Error()
+	t.logger.CWarnw(ctx, "Job trigger failed", "name", t.jobName, "type", t.cfg.Type, "error", t.lastErr)
+}
+
+// poll calls check every poll interval and fires the trigger when check reports an event.
+func (t *trigger) poll(ctx context.Context, check func(ctx context.Context) (bool, error)) {
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+	for {
+		happened, err := check(ctx)
+		t.warn(ctx, err)
+		if happened {
+			t.fire(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// healthCheck reports transitions of the watched resource between the ready and unhealthy
+// states. Transient states, like a resource being reconfigured, are not transitions.
+func (t *trigger) healthCheck() func(ctx context.Context) (bool, error) {
+	var healthy, seen bool
+	return func(ctx context.Context) (bool, error) {
+		if t.jm.eventSources.ResourceStatuses == nil {
+			return false, errors.New("resource statuses are not available")
+		}
+		for _, status := range t.jm.eventSources.ResourceStatuses() {
+			if status.Name.ShortName() != t.resourceName {
+				continue
+			}
+			var nowHealthy bool
+			switch status.State {
+			case resource.NodeStateReady:
+				nowHealthy = true
+			case resource.NodeStateUnhealthy:
+				nowHealthy = false
+			default:
+				return false, nil
+			}
+			changed := seen && nowHealthy != healthy
+			healthy, seen = nowHealthy, true
+			if !changed {
+				return false, nil
+			}
+			return t.cfg.State == "" || (t.cfg.State == "healthy") == healthy, nil
+		}
+		return false, nil
+	}
+}
+
+// thresholdCheck reports whether the watched sensor reading crossed one of its thresholds
+// since the last check.
+func (t *trigger) thresholdCheck() func(ctx context.Context) (bool, error) {
+	var last float64
+	var seen bool
+	return func(ctx context.Context) (bool, error) {
+		res, err := t.jm.getResource(t.resourceName)
+		if err != nil {
+			return false, err
+		}
+		sensor, ok := res.(resource.Sensor)
+		if !ok {
+			return false, errors.Errorf("resource %q does not have readings", t.resourceName)
+		}
+		readings, err := sensor.Readings(ctx, nil)
+		if err != nil {
+			return false, err
+		}
+		value, err := readingAsFloat(readings[t.cfg.Reading])
+		if err != nil {
+			return false, errors.Wrapf(err, "reading %q", t.cfg.Reading)
+		}
+		crossed := seen &&
+			((t.cfg.Above != nil && last <= *t.cfg.Above && value > *t.cfg.Above) ||
+				(t.cfg.Below != nil && last >= *t.cfg.Below && value < *t.cfg.Below))
+		last, seen = value, true
+		return crossed, nil
+	}
+}
+
+func readingAsFloat(reading any) (float64, error) {
+	switch v := reading.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case nil:
+		return 0, errors.New("missing from sensor readings")
+	default:
+		return 0, errors.Errorf("expected a number, got %T", reading)
+	}
+}
+
+// resubscribe runs watch, which fires the trigger on the events of the watched resource, and
+// runs it again when the resource is reconfigured or the subscription fails.
+func (t *trigger) resubscribe(ctx context.Context, watch func(ctx context.Context) error) {
+	for {
+		err := watch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		t.warn(ctx, err)
+		if !utils.SelectContextOrWait(ctx, triggerRestartDelay) {
+			return
+		}
+	}
+}
+
+// streamTicks fires the trigger on every tick of the watched digital interrupt.
+func (t *trigger) streamTicks(ctx context.Context) error {
+	res, err := t.jm.getResource(t.resourceName)
+	if err != nil {
+		return err
+	}
+	b, ok := res.(board.Board)
+	if !ok {
+		return errors.Errorf("resource %q is not a board", t.resourceName)
+	}
+	interrupt, err := b.DigitalInterruptByName(t.cfg.Interrupt)
+	if err != nil {
+		return err
+	}
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ticks := make(chan board.Tick)
+	if err := b.StreamTicks(streamCtx, []board.DigitalInterrupt{interrupt}, ticks, nil); err != nil {
+		return err
+	}
+	t.warn(ctx, nil)
+
+	// the stream stays attached to the board it was started on, so check that the board was
+	// not replaced by a reconfiguration.
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticks:
+			t.fire(ctx)
+		case <-ticker.C:
+			if current, err := t.jm.getResource(t.resourceName); err != nil || current != res {
+				return errors.Errorf("board %q changed, subscribing to its ticks again", t.resourceName)
+			}
+		}
+	}
+}
+
+// watchPushes fires the trigger every time the watched button is pushed.
+func (t *trigger) watchPushes(ctx context.Context) error {
+	res, err := t.jm.getResource(t.resourceName)
+	if err != nil {
+		return err
+	}
+	b, ok := res.(button.Button)
+	if !ok {
+		return errors.Errorf("resource %q is not a button", t.resourceName)
+	}
+	pushes := make(chan struct{}, 1)
+	defer button.SubscribePushes(b, pushes)()
+	t.warn(ctx, nil)
+
+	// pushes are tracked per button, so check that the button was not replaced by a
+	// reconfiguration.
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-pushes:
+			t.fire(ctx)
+		case <-ticker.C:
+			if current, err := t.jm.getResource(t.resourceName); err != nil || current != res {
+				return errors.Errorf("button %q changed, subscribing to its pushes again", t.resourceName)
+			}
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package robot
+
+import "time"
+
+// JobRun describes a single run of a JobManager job.
+type JobRun struct {
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	// Error is empty if the run succeeded.
+	Error string `json:"error,omitempty"`
+	// Response is the (possibly truncated) JSON response of the job's method.
+	Response string `json:"response,omitempty"`
+}
+
+// Succeeded returns whether the run completed without an error.
+func (jr JobRun) Succeeded() bool {
+	return jr.Error == ""
+}
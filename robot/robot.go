@@ -196,6 +196,19 @@ type LocalRobot interface {
 
 	// WriteTraceMessages writes trace spans to any configured exporters.
 	WriteTraceMessages(context.Context, []*otlpv1.ResourceSpans) error
+
+	// JobStatus returns the recent runs of the named jobs, or of every job if no names are
+	// given. Runs are ordered oldest first.
+	JobStatus(ctx context.Context, names ...string) (map[string][]JobRun, error)
+
+	// RunJobNow runs a configured job once, outside of its schedule.
+	RunJobNow(ctx context.Context, name string) error
+
+	// PauseJob stops a configured job from running on its schedule until it is resumed.
+	PauseJob(ctx context.Context, name string) error
+
+	// ResumeJob puts a paused job back on its schedule.
+	ResumeJob(ctx context.Context, name string) error
 }
 
 // A RemoteRobot is a Robot that was created through a connection.
@@ -19,6 +19,10 @@ type Options struct {
 	// Pprof turns on the pprof profiler accessible at /debug
 	Pprof bool
 
+	// Metrics turns on the OpenMetrics endpoint accessible at /metrics. It serves the latest
+	// diagnostics collected by FTDC.
+	Metrics bool
+
 	// StaticHost is a url to use for static assets, like app.viam.com
 	StaticHost string
 
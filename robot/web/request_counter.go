@@ -159,6 +159,15 @@ func (rc *RequestCounter) postRequestIncrement(key string, timeSpent time.Durati
 	}
 }
 
+// Count returns how many requests were counted under the given key. Keys are formatted like
+// those reported by Stats, e.g: `motor-foo.MotorService/IsMoving`.
+func (rc *RequestCounter) Count(key string) int64 {
+	if stats, ok := rc.requestKeyToStats.Load(key); ok {
+		return stats.count.Load()
+	}
+	return 0
+}
+
 // Stats satisfies the ftdc.Statser interface and will return a copy of the counters.
 func (rc *RequestCounter) Stats() any {
 	ret := make(map[string]int64)
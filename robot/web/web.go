@@ -806,6 +806,14 @@ func (svc *webService) initMux(options weboptions.Options) *goji.Mux {
 		mux.HandleFunc(pat.New("/debug/pprof/trace"), pprof.Trace)
 	}
 
+	if options.Metrics {
+		if svc.opts.metricsHandler != nil {
+			mux.Handle(pat.New("/metrics"), svc.opts.metricsHandler)
+		} else {
+			svc.logger.Warn("metrics requested but diagnostics collection (FTDC) is disabled, not serving /metrics")
+		}
+	}
+
 	// serve resource graph visualization
 	// TODO: hide behind option
 	// TODO: accept params to display different formats
@@ -4,6 +4,7 @@ package web
 
 import (
 	"context"
+	"net/http"
 
 	"go.viam.com/rdk/resource"
 	"go.viam.com/utils/rpc"
@@ -24,4 +25,7 @@ func (svc *webService) initStreamServer(_ context.Context, _ rpc.Server) error {
 }
 
 // stub for missing gostream
-type options struct{}
+type options struct {
+	// metricsHandler serves diagnostics in the OpenMetrics format when metrics are enabled.
+	metricsHandler http.Handler
+}
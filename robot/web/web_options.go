@@ -1,5 +1,7 @@
 package web
 
+import "net/http"
+
 // Option configures how we set up the web service.
 // Cribbed from https://github.com/grpc/grpc-go/blob/aff571cc86e6e7e740130dbbb32a9741558db805/dialoptions.go#L41
 type Option interface {
@@ -21,3 +23,11 @@ func newFuncOption(f func(*options)) *funcOption {
 		f: f,
 	}
 }
+
+// WithMetricsHandler returns an Option which sets the handler serving /metrics when
+// metrics are enabled in the web options.
+func WithMetricsHandler(handler http.Handler) Option {
+	return newFuncOption(func(o *options) {
+		o.metricsHandler = handler
+	})
+}
@@ -2,12 +2,19 @@
 
 package web
 
-import "go.viam.com/rdk/gostream"
+import (
+	"net/http"
+
+	"go.viam.com/rdk/gostream"
+)
 
 // options configures a web service.
 type options struct {
 	// streamConfig is used to enable audio/video streaming over WebRTC.
 	streamConfig *gostream.StreamConfig
+
+	// metricsHandler serves diagnostics in the OpenMetrics format when metrics are enabled.
+	metricsHandler http.Handler
 }
 
 // WithStreamConfig returns an Option which sets the streamConfig
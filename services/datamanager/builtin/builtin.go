@@ -88,8 +88,13 @@ type builtIn struct {
 	capture            *capture.Capture
 	sync               *datasync.Sync
 	diskSummaryTracker *diskSummaryTracker
+	// captureDir is where data capture writes files to. It is read by `DoCommand` queries.
+	captureDir string
 
 	captureControlPoller *goutils.StoppableWorkers
+	preRollTriggerPoller *goutils.StoppableWorkers
+	// recoveryWorkers recover the capture files left behind by a crash, see `recoverCaptureFiles`.
+	recoveryWorkers *goutils.StoppableWorkers
 }
 
 // New returns a new builtin data manager service for the given robot.
@@ -123,6 +128,7 @@ func New(
 		capture:            capture,
 		sync:               sync,
 		diskSummaryTracker: diskSummaryTracker,
+		recoveryWorkers:    goutils.NewBackgroundStoppableWorkers(),
 	}
 
 	if err := svc.Reconfigure(ctx, deps, conf); err != nil {
@@ -137,6 +143,8 @@ func (b *builtIn) Close(ctx context.Context) error {
 	defer b.logger.Info("Close END")
 
 	b.stopCaptureControlPoller()
+	b.stopPreRollTriggerPoller()
+	b.recoveryWorkers.Stop()
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	b.diskSummaryTracker.close()
@@ -159,6 +167,27 @@ func (b *builtIn) Sync(ctx context.Context, extra map[string]interface{}) error
 	return b.sync.Sync(ctx, extra)
 }
 
+// DoCommand supports the following commands:
+//   - `DoQuery` returns readings captured to disk, see `runQuery`.
+//   - `DoTriggerCapture` triggers pre-roll capture methods, see `runTriggerCapture`.
+func (b *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if req, ok := cmd[DoQuery]; ok {
+		return b.runQuery(ctx, req)
+	}
+	if req, ok := cmd[DoTriggerCapture]; ok {
+		return b.runTriggerCapture(req)
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
+// CaptureDir returns the directory data capture writes files to. Jobs capture their responses
+// into it too.
+func (b *builtIn) CaptureDir() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.captureDir
+}
+
 // Reconfigure updates the data manager service when the config has changed.
 // At time of writing Reconfigure only returns an error in one of the following unrecoverable error cases:
 //  1. There is some static (aka compile time) error which we currently are only able to detected at runtime:
@@ -207,11 +236,20 @@ func (b *builtIn) Reconfigure(ctx context.Context, deps resource.Dependencies, c
 	}
 
 	syncSensor, syncSensorEnabled := syncSensorFromDeps(c.SelectiveSyncerName, deps, b.logger)
-	syncConfig := c.syncConfig(syncSensor, syncSensorEnabled, b.logger)
+	syncConfig := c.syncConfig(
+		syncSensor,
+		syncSensorEnabled,
+		lookupSyncDestinationsByCaptureMethod(conf),
+		lookupRetentionPoliciesByCaptureMethod(conf, b.logger),
+		meteredNetworkDetectorFromDeps(c.MeteredNetworkSensor, deps, b.logger),
+		b.logger,
+	)
 
 	controlSensor, controlSensorKey := captureControlSensorFromDeps(c.CaptureControlSensor, deps, b.logger)
+	preRollTriggerWorkers := b.preRollTriggerWorkers(collectorConfigsByResource, deps, syncConfig)
 
 	b.stopCaptureControlPoller()
+	b.stopPreRollTriggerPoller()
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -222,16 +260,44 @@ func (b *builtIn) Reconfigure(ctx context.Context, deps resource.Dependencies, c
 		return syncConfig.SchedulerEnabled() && datasync.ReadyToSyncDirectories(ctx, syncConfig, b.logger)
 	}
 	b.diskSummaryTracker.reconfigure(syncConfig.SyncPaths(), syncConfig.SyncIntervalMins, shouldSync)
+	if captureConfig.CaptureDir != b.captureDir {
+		b.recoverCaptureFiles(ctx, captureConfig.CaptureDir)
+	}
 	b.capture.Reconfigure(ctx, collectorConfigsByResource, captureConfig)
+	b.captureDir = captureConfig.CaptureDir
 	b.sync.Reconfigure(ctx, syncConfig, cloudConnSvc)
 
 	if controlSensor != nil && !captureConfig.CaptureDisabled {
 		b.startCaptureControlPoller(controlSensor, controlSensorKey)
 	}
+	if len(preRollTriggerWorkers) > 0 && !captureConfig.CaptureDisabled {
+		b.startPreRollTriggerPoller(preRollTriggerWorkers)
+	}
 
 	return nil
 }
 
+// recoverCaptureFiles recovers the in progress capture files of captureDir in the background.
+// Collectors only write to a capture directory once it is configured, so the in progress files it
+// holds before then were left behind when the robot lost power, or was killed, while writing them.
+// They are listed before the collectors start, so that the files the collectors write are left
+// alone.
+func (b *builtIn) recoverCaptureFiles(ctx context.Context, captureDir string) {
+	paths, err := datasync.InProgressCaptureFiles(ctx, captureDir)
+	if err != nil {
+		b.logger.Warnw("failed to find capture files to recover", "dir", captureDir, "error", err)
+		return
+	}
+	if len(paths) == 0 {
+		return
+	}
+	b.recoveryWorkers.Add(func(ctx context.Context) {
+		if _, err := datasync.RecoverCaptureFiles(ctx, captureDir, paths, b.logger); err != nil && !errors.Is(err, context.Canceled) {
+			b.logger.Warnw("failed to recover capture files", "dir", captureDir, "error", err)
+		}
+	})
+}
+
 func (b *builtIn) startCaptureControlPoller(
 	controlSensor sensor.Sensor,
 	controlSensorKey string,
@@ -362,6 +428,44 @@ func syncSensorFromDeps(name string, deps resource.Dependencies, logger logging.
 	return syncSensor, true
 }
 
+// meteredNetworkSensor reports whether the machine is on a metered network from the readings of a
+// sensor.
+type meteredNetworkSensor struct {
+	name   string
+	sensor sensor.Sensor
+}
+
+func (m meteredNetworkSensor) Metered(ctx context.Context) (bool, error) {
+	if m.sensor == nil {
+		return false, fmt.Errorf("metered network sensor %s not found", m.name)
+	}
+	readings, err := m.sensor.Readings(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	metered, ok := readings[datamanager.MeteredNetworkKey]
+	if !ok {
+		return false, fmt.Errorf("value for metered network key %s not present in readings", datamanager.MeteredNetworkKey)
+	}
+	return utils.AssertType[bool](metered)
+}
+
+// meteredNetworkDetectorFromDeps returns nil if no metered network sensor is configured. If the
+// sensor can't be found the network is treated as metered until it is fixed.
+func meteredNetworkDetectorFromDeps(name string, deps resource.Dependencies, logger logging.Logger) datasync.MeteredNetworkDetector {
+	if name == "" {
+		return nil
+	}
+	s, err := sensor.FromProvider(deps, name)
+	if err != nil {
+		logger.Errorw(
+			"unable to initialize metered network sensor; the network is treated as metered until fixed or removed from config",
+			"error", err.Error())
+		return meteredNetworkSensor{name: name}
+	}
+	return meteredNetworkSensor{name: name, sensor: s}
+}
+
 // Lookup the collector configs associated with the data manager service.
 func lookupCollectorConfigsByResource(
 	deps resource.Dependencies,
@@ -393,6 +497,75 @@ func lookupCollectorConfigsByResource(
 	return collectorConfigsByResource, nil
 }
 
+// lookupSyncDestinationsByCaptureMethod returns the sync destinations selected by the capture methods
+// associated with the data manager service. It includes resources that are not in the resource
+// graph, whose data must not be synced to the default destination either.
+func lookupSyncDestinationsByCaptureMethod(resConfig resource.Config) map[string]string {
+	var destinations map[string]string
+	for _, rawAssocCfg := range resConfig.AssociatedAttributes {
+		assocCfg, ok := rawAssocCfg.(*datamanager.AssociatedConfig)
+		if !ok {
+			continue
+		}
+		for _, collectorConfig := range assocCfg.CaptureMethods {
+			if collectorConfig.SyncDestination == "" {
+				continue
+			}
+			if destinations == nil {
+				destinations = map[string]string{}
+			}
+			key := datasync.CaptureMethodKey(
+				collectorConfig.Name.API.String(), collectorConfig.Name.ShortName(), collectorConfig.Method)
+			destinations[key] = collectorConfig.SyncDestination
+		}
+	}
+	return destinations
+}
+
+// lookupRetentionPoliciesByCaptureMethod returns the retention policies of the capture methods
+// associated with the data manager service, and of the raw readings of those that aggregate their
+// readings. Invalid retention configs are logged and ignored.
+func lookupRetentionPoliciesByCaptureMethod(resConfig resource.Config, logger logging.Logger) map[string]datasync.RetentionPolicy {
+	var policies map[string]datasync.RetentionPolicy
+	for _, rawAssocCfg := range resConfig.AssociatedAttributes {
+		assocCfg, ok := rawAssocCfg.(*datamanager.AssociatedConfig)
+		if !ok {
+			continue
+		}
+		for _, collectorConfig := range assocCfg.CaptureMethods {
+			if aggregation := collectorConfig.Aggregation; aggregation != nil && aggregation.RawRetention != nil {
+				if err := aggregation.Validate(); err != nil {
+					logger.Warnw("ignoring invalid raw retention config of capture method",
+						"resource", collectorConfig.Name.String(), "method", collectorConfig.Method, "error", err)
+				} else {
+					if policies == nil {
+						policies = map[string]datasync.RetentionPolicy{}
+					}
+					key := datasync.RawCaptureMethodKey(
+						collectorConfig.Name.API.String(), collectorConfig.Name.ShortName(), collectorConfig.Method)
+					policies[key] = retentionPolicy(*aggregation.RawRetention)
+				}
+			}
+
+			if collectorConfig.Retention == nil {
+				continue
+			}
+			if err := collectorConfig.Retention.Validate(); err != nil {
+				logger.Warnw("ignoring invalid retention config of capture method",
+					"resource", collectorConfig.Name.String(), "method", collectorConfig.Method, "error", err)
+				continue
+			}
+			if policies == nil {
+				policies = map[string]datasync.RetentionPolicy{}
+			}
+			key := datasync.CaptureMethodKey(
+				collectorConfig.Name.API.String(), collectorConfig.Name.ShortName(), collectorConfig.Method)
+			policies[key] = retentionPolicy(*collectorConfig.Retention)
+		}
+	}
+	return policies
+}
+
 // TODO (DATA-4528): Don't ignore the extra field in the UploadBinaryDataToDatasets request.
 func (b *builtIn) UploadBinaryDataToDatasets(ctx context.Context,
 	binaryData []byte,
@@ -430,6 +603,7 @@ type dataManagerStats struct {
 	SyncPaths               syncPathsSummary
 	DiskUsage               diskUsageSummary
 	FilesDeletedToFreeSpace int64
+	FilesDeletedByRetention int64
 	Upload                  datasync.FTDCUploadStats
 }
 
@@ -449,6 +623,7 @@ func (b *builtIn) Stats() any {
 	if b.sync != nil {
 		syncStats := b.sync.GetStats()
 		result.FilesDeletedToFreeSpace = syncStats.FilesDeletedToFreeSpace
+		result.FilesDeletedByRetention = syncStats.FilesDeletedByRetention
 		result.Upload = syncStats.Upload
 	}
 
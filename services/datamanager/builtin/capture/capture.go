@@ -7,18 +7,22 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/benbjohnson/clock"
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	v1 "go.viam.com/api/app/datasync/v1"
 	goutils "go.viam.com/utils"
+	"google.golang.org/protobuf/proto"
 
 	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/protoutils"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/services/datamanager"
+	"go.viam.com/rdk/services/datamanager/builtin/shared"
 )
 
 // TODO: re-determine if queue size is optimal given we now support 10khz+ capture rates
@@ -40,8 +44,9 @@ func generateMetadataKey(component, method string) string {
 }
 
 var metadataToAdditionalParamFields = map[string]string{
-	generateMetadataKey("rdk:component:board", "Analogs"): "reader_name",
-	generateMetadataKey("rdk:component:board", "Gpios"):   "pin_name",
+	generateMetadataKey("rdk:component:board", "Analogs"):    "reader_name",
+	generateMetadataKey("rdk:component:board", "Gpios"):      "pin_name",
+	generateMetadataKey("rdk:service:motion", "PlanHistory"): "component_name",
 }
 
 // Capture polls data sources (resource/method pairs) and writes the responses files.
@@ -62,8 +67,16 @@ type Capture struct {
 	captureDir string
 	// maxCaptureFileSize is only stored on Capture so that we can detect when it changs
 	maxCaptureFileSize int64
+	// captureFileVersion is the format version of the capture files collectors write.
+	captureFileVersion int
 	mongoMU            sync.Mutex
 	mongo              captureMongo
+	sqliteMU           sync.Mutex
+	sqlite             *sqliteStore
+	// groups are the running capture groups by name, and groupsByCaptureMethod the capture groups
+	// by the DataCaptureConfigKey of their members.
+	groups                map[string]*captureGroup
+	groupsByCaptureMethod map[string]*captureGroup
 
 	// defaultCollectorConfigs are the default as specified in the machine config.
 	// These are stored in order to be compared to any capture override readings.
@@ -90,6 +103,10 @@ type collectorAndConfig struct {
 	Resource  resource.Resource
 	Collector data.Collector
 	Config    datamanager.DataCaptureConfig
+	// PreRoll is the target of the collector when the capture method has a pre-roll config.
+	PreRoll *data.PreRollBuffer
+	// FileVersion is the format version of the capture files the collector writes.
+	FileVersion int
 }
 
 // Identifier for a particular collector: component name, component model, component type,
@@ -116,6 +133,7 @@ func (c *Capture) newCollectors(
 	collectorConfigsByResource CollectorConfigsByResource,
 	config Config,
 	collection *mongo.Collection,
+	store data.TabularDataStore,
 ) collectors {
 	// Initialize or add collectors based on changes to the component configurations.
 	newCollectors := make(map[collectorMetadata]*collectorAndConfig)
@@ -125,6 +143,7 @@ func (c *Capture) newCollectors(
 
 			// We only use service-level tags.
 			cfg.Tags = config.Tags
+			cfg = c.withGroup(cfg)
 			if cfg.Disabled {
 				c.logger.Infof("collector disabled due to config `disabled` being true; collector: %s", md)
 				continue
@@ -135,7 +154,7 @@ func (c *Capture) newCollectors(
 				continue
 			}
 
-			newCollectorAndConfig, err := c.initializeOrUpdateCollector(res, md, cfg, config, collection)
+			newCollectorAndConfig, err := c.initializeOrUpdateCollector(res, md, cfg, config, collection, store)
 			if err != nil {
 				c.logger.Warnw("failed to initialize or update collector",
 					"error", err, "resource_name", res.Name(), "metadata", md, "data capture config", format(cfg))
@@ -230,8 +249,16 @@ func (c *Capture) Reconfigure(
 		c.logger.Infof("maximum_capture_file_size_bytes old: %d, new: %d", c.maxCaptureFileSize, config.MaximumCaptureFileSizeBytes)
 	}
 
+	if c.captureFileVersion != config.CaptureFileVersion {
+		c.logger.Infof("capture_file_version old: %d, new: %d", c.captureFileVersion, config.CaptureFileVersion)
+	}
+	// Collectors written with a different version are rebuilt, see `initializeOrUpdateCollector`.
+	c.captureFileVersion = config.CaptureFileVersion
+
 	collection := c.mongoReconfigure(ctx, config.MongoConfig)
-	newCollectors := c.newCollectors(collectorConfigsByResource, config, collection)
+	store := c.sqliteReconfigure(ctx, config.SQLiteConfig, config.CaptureDir)
+	staleGroups := c.groupsReconfigure(config.Groups, collectorConfigsByResource)
+	newCollectors := c.newCollectors(collectorConfigsByResource, config, collection, store)
 	// If a component/method has been removed from the config, close the collector.
 	c.collectorsMu.Lock()
 	for md, collAndConfig := range c.collectors {
@@ -242,6 +269,9 @@ func (c *Capture) Reconfigure(
 	}
 	c.collectors = newCollectors
 	c.collectorsMu.Unlock()
+	for _, group := range staleGroups {
+		group.Close()
+	}
 	c.defaultCollectorConfigs = collectorConfigsByResource
 	c.captureDir = config.CaptureDir
 	c.maxCaptureFileSize = config.MaximumCaptureFileSizeBytes
@@ -251,6 +281,7 @@ func (c *Capture) Reconfigure(
 func (c *Capture) Close(ctx context.Context) {
 	c.FlushCollectors()
 	c.closeCollectors()
+	c.closeGroups()
 	c.mongoMU.Lock()
 	defer c.mongoMU.Unlock()
 	if c.mongo.client != nil {
@@ -258,6 +289,9 @@ func (c *Capture) Close(ctx context.Context) {
 		goutils.UncheckedError(c.mongo.client.Disconnect(ctx))
 		c.mongo = captureMongo{}
 	}
+	c.sqliteMU.Lock()
+	defer c.sqliteMU.Unlock()
+	c.closeSQLiteNoMutex()
 }
 
 // closeNoMongoMutex exists for cases when we need to perform close actions in a function
@@ -280,11 +314,13 @@ func (c *Capture) initializeOrUpdateCollector(
 	collectorConfig datamanager.DataCaptureConfig,
 	config Config,
 	collection *mongo.Collection,
+	store data.TabularDataStore,
 ) (*collectorAndConfig, error) {
 	maxFileSizeChanged := c.maxCaptureFileSize != config.MaximumCaptureFileSizeBytes
 	if storedCollectorAndConfig, ok := c.collectors[md]; ok {
 		if storedCollectorAndConfig.Config.Equals(&collectorConfig) &&
 			res == storedCollectorAndConfig.Resource &&
+			storedCollectorAndConfig.FileVersion == c.captureFileVersion &&
 			!maxFileSizeChanged {
 			// If the attributes have not changed, do nothing and leave the existing collector.
 			return c.collectors[md], nil
@@ -301,6 +337,18 @@ func (c *Capture) initializeOrUpdateCollector(
 		return nil, errors.Errorf("capture_buffer_size can't be less than 0, current value: %d", collectorConfig.CaptureBufferSize)
 	}
 
+	if collectorConfig.PreRoll != nil {
+		if err := collectorConfig.PreRoll.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if collectorConfig.Aggregation != nil {
+		if err := collectorConfig.Aggregation.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	metadataKey := generateMetadataKey(md.MethodMetadata.API.String(), md.MethodMetadata.MethodName)
 	if additionalParamKey, ok := metadataToAdditionalParamFields[metadataKey]; ok {
 		if _, ok := collectorConfig.AdditionalParams[additionalParamKey]; !ok {
@@ -309,7 +357,7 @@ func (c *Capture) initializeOrUpdateCollector(
 		}
 	}
 
-	return c.buildCollector(res, md, collectorConfig, c.maxCaptureFileSize, collection)
+	return c.buildCollector(res, md, collectorConfig, c.maxCaptureFileSize, collection, store)
 }
 
 // buildCollector constructs and starts a new collector, assuming the base config was already validated.
@@ -320,6 +368,7 @@ func (c *Capture) buildCollector(
 	collectorConfig datamanager.DataCaptureConfig,
 	maxCaptureFileSize int64,
 	collection *mongo.Collection,
+	store data.TabularDataStore,
 ) (*collectorAndConfig, error) {
 	// TODO(DATA-451): validate method params
 	methodParams, err := protoutils.ConvertMapToProtoAny(collectorConfig.AdditionalParams)
@@ -347,18 +396,57 @@ func (c *Capture) buildCollector(
 		methodParams,
 		collectorConfig.Tags,
 	)
+	buffer := data.NewCaptureBuffer(targetDir, captureMetadata, maxCaptureFileSize)
+	buffer.FileVersion = c.captureFileVersion
+	var target data.CaptureBufferedWriter = buffer
+	if collectorConfig.Aggregation != nil {
+		if dataType != data.CaptureTypeTabular {
+			return nil, errors.Errorf("aggregation is only supported by tabular capture methods, %s is not", md)
+		}
+		var raw data.CaptureBufferedWriter
+		if collectorConfig.Aggregation.RawRetention != nil {
+			rawDir := rawTargetDir(collectorConfig.CaptureDirectory, collectorConfig)
+			if err := os.MkdirAll(rawDir, 0o700); err != nil {
+				return nil, errors.Wrapf(err, "failed to create raw target directory %s with 700 file permissions", rawDir)
+			}
+			// buffers update their metadata, so the raw buffer gets its own copy.
+			rawMetadata := proto.Clone(captureMetadata).(*v1.DataCaptureMetadata)
+			rawBuffer := data.NewCaptureBuffer(rawDir, rawMetadata, maxCaptureFileSize)
+			rawBuffer.FileVersion = c.captureFileVersion
+			raw = rawBuffer
+		}
+		target = data.NewAggregatingBuffer(
+			target,
+			raw,
+			time.Duration(collectorConfig.Aggregation.WindowSeconds*float64(time.Second)),
+			c.clk,
+		)
+	}
+	var preRoll *data.PreRollBuffer
+	if collectorConfig.PreRoll != nil {
+		preRoll = data.NewPreRollBuffer(
+			target,
+			time.Duration(collectorConfig.PreRoll.PreSeconds*float64(time.Second)),
+			time.Duration(collectorConfig.PreRoll.PostSeconds*float64(time.Second)),
+			defaultIfZeroVal(collectorConfig.PreRoll.MaxBufferedBytes, datamanager.DefaultPreRollMaxBufferedBytes),
+			c.clk,
+		)
+		target = preRoll
+	}
 	// Parameters to initialize collector.
 	queueSize := defaultIfZeroVal(collectorConfig.CaptureQueueSize, defaultCaptureQueueSize)
 	bufferSize := defaultIfZeroVal(collectorConfig.CaptureBufferSize, defaultCaptureBufferSize)
 	collector, err := collectorConstructor(res, data.CollectorParams{
 		MongoCollection: collection,
+		TabularStore:    store,
 		DataType:        dataType,
 		ComponentName:   collectorConfig.Name.ShortName(),
 		ComponentType:   collectorConfig.Name.API.String(),
 		MethodName:      collectorConfig.Method,
 		Interval:        data.GetDurationFromHz(collectorConfig.CaptureFrequencyHz),
+		Group:           c.captureGroup(collectorConfig),
 		MethodParams:    methodParams,
-		Target:          data.NewCaptureBuffer(targetDir, captureMetadata, maxCaptureFileSize),
+		Target:          target,
 		// Set queue size to defaultCaptureQueueSize if it was not set in the config.
 		QueueSize:  queueSize,
 		BufferSize: bufferSize,
@@ -374,7 +462,13 @@ func (c *Capture) buildCollector(
 		md, collectorConfigDescription(collectorConfig, targetDir, maxCaptureFileSize, queueSize, bufferSize))
 	collector.Collect()
 
-	return &collectorAndConfig{res, collector, collectorConfig}, nil
+	return &collectorAndConfig{
+		Resource:    res,
+		Collector:   collector,
+		Config:      collectorConfig,
+		PreRoll:     preRoll,
+		FileVersion: c.captureFileVersion,
+	}, nil
 }
 
 func collectorConfigDescription(
@@ -385,9 +479,9 @@ func collectorConfigDescription(
 	bufferSize int,
 ) string {
 	return fmt.Sprintf("[CaptureFrequencyHz: %f, Tags: %v, MaximumCaptureFileSize: %s, "+
-		"CaptureBufferQueueSize: %d, CaptureBufferSize: %d, TargetDir: %s]",
+		"CaptureBufferQueueSize: %d, CaptureBufferSize: %d, TargetDir: %s, PreRoll: %t, Aggregated: %t]",
 		collectorConfig.CaptureFrequencyHz, collectorConfig.Tags, data.FormatBytesI64(maximumCaptureFileSizeBytes),
-		queueSize, bufferSize, targetDir,
+		queueSize, bufferSize, targetDir, collectorConfig.PreRoll != nil, collectorConfig.Aggregation != nil,
 	)
 }
 
@@ -397,6 +491,12 @@ func targetDir(captureDir string, collectorConfig datamanager.DataCaptureConfig)
 			collectorConfig.Name.ShortName(), collectorConfig.Method))
 }
 
+// rawTargetDir is the directory the raw readings of an aggregated capture method are written to. It
+// is under the capture directory's RawCaptureDir, which is not synced.
+func rawTargetDir(captureDir string, collectorConfig datamanager.DataCaptureConfig) string {
+	return targetDir(filepath.Join(captureDir, shared.RawCaptureDir), collectorConfig)
+}
+
 // closeCollectors closes collectors.
 func (c *Capture) closeCollectors() {
 	var collectorsToClose []data.Collector
@@ -449,6 +549,33 @@ func (c *Capture) FlushCollectors() {
 	wg.Wait()
 }
 
+// TriggerPreRoll triggers the pre-roll capture methods for which `selected` returns true: their
+// buffered data is persisted, as is the data they capture until their post-roll elapses. It
+// returns the number of capture methods triggered.
+func (c *Capture) TriggerPreRoll(selected func(datamanager.DataCaptureConfig) bool) int {
+	// The buffers are written to disk without holding collectorsMu, which would block collectors
+	// from being reconfigured meanwhile. A buffer whose collector is closed meanwhile writes its data
+	// to a new capture file.
+	c.collectorsMu.Lock()
+	preRolls := map[collectorMetadata]*data.PreRollBuffer{}
+	for md, collectorAndConfig := range c.collectors {
+		if collectorAndConfig.PreRoll != nil && selected(collectorAndConfig.Config) {
+			preRolls[md] = collectorAndConfig.PreRoll
+		}
+	}
+	c.collectorsMu.Unlock()
+
+	triggered := 0
+	for md, preRoll := range preRolls {
+		if err := preRoll.Trigger(); err != nil {
+			c.logger.Warnw("failed to persist pre-roll", "error", err, "collector", md)
+			continue
+		}
+		triggered++
+	}
+	return triggered
+}
+
 func defaultIfZeroVal[T comparable](val, defaultVal T) T {
 	var zeroVal T
 	if val == zeroVal {
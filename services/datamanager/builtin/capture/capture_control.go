@@ -41,6 +41,8 @@ func (c *Capture) SetCaptureConfigs(ctx context.Context, captureConfigReadings m
 					effectiveCfg.Tags = override.Tags
 				}
 			}
+			// Members of a capture group are captured at the capture frequency of the group.
+			effectiveCfg = c.withGroup(effectiveCfg)
 
 			md := newCollectorMetadata(effectiveCfg)
 			existing := c.collectors[md]
@@ -61,7 +63,7 @@ func (c *Capture) SetCaptureConfigs(ctx context.Context, captureConfigReadings m
 
 			// Rebuild collectors to reflect override changes.
 			c.logCaptureConfigChange(key, existing, effectiveCfg)
-			coll, err := c.buildCollector(res, md, effectiveCfg, c.maxCaptureFileSize, c.mongo.collection)
+			coll, err := c.buildCollector(res, md, effectiveCfg, c.maxCaptureFileSize, c.mongo.collection, c.tabularStore())
 			if err != nil {
 				c.logger.Warnw("failed to build collector", "error", err, "key", key)
 				continue
@@ -1,11 +1,20 @@
 package capture
 
 import (
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/benbjohnson/clock"
+	v1 "go.viam.com/api/app/datasync/v1"
 	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/services/datamanager"
 )
 
@@ -30,3 +39,109 @@ func TestDefaultIfZeroVal(t *testing.T) {
 	test.That(t, defaultIfZeroVal(nonDefaultF64, defaultValF64), test.ShouldAlmostEqual, nonDefaultF64)
 	test.That(t, defaultIfZeroVal(0, defaultValF64), test.ShouldAlmostEqual, defaultValF64)
 }
+
+func TestTriggerPreRoll(t *testing.T) {
+	captureDir := t.TempDir()
+	preRoll := data.NewPreRollBuffer(
+		data.NewCaptureBuffer(captureDir, &v1.DataCaptureMetadata{}, 1024), time.Minute, 0, 0, clock.NewMock())
+	readings, err := structpb.NewStruct(map[string]interface{}{"a": 1})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, preRoll.WriteTabular(&v1.SensorData{
+		Metadata: &v1.SensorMetadata{},
+		Data:     &v1.SensorData_Struct{Struct: readings},
+	}), test.ShouldBeNil)
+
+	preRollCfg := datamanager.DataCaptureConfig{
+		Name:    resource.NewName(fakeAPI, "fake-1"),
+		Method:  "GetReadings",
+		PreRoll: &datamanager.PreRollConfig{PreSeconds: 60},
+	}
+	cfg := datamanager.DataCaptureConfig{Name: resource.NewName(fakeAPI, "fake-2"), Method: "GetReadings"}
+	c := newTestCapture(t, nil, collectors{
+		newCollectorMetadata(preRollCfg): {Resource: fakeRes, Collector: &mockCollector{}, Config: preRollCfg, PreRoll: preRoll},
+		newCollectorMetadata(cfg):        {Resource: fakeRes, Collector: &mockCollector{}, Config: cfg},
+	})
+
+	test.That(t, c.TriggerPreRoll(func(c datamanager.DataCaptureConfig) bool { return c.Name.Name == "fake-2" }), test.ShouldEqual, 0)
+	test.That(t, c.TriggerPreRoll(func(datamanager.DataCaptureConfig) bool { return true }), test.ShouldEqual, 1)
+	test.That(t, preRoll.Flush(), test.ShouldBeNil)
+	files, err := filepath.Glob(filepath.Join(captureDir, "*"+data.CompletedCaptureFileExt))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, files, test.ShouldHaveLength, 1)
+}
+
+// fakeReadingsTargets receives the targets of the fake/Readings collectors built by the tests.
+var (
+	fakeReadingsTargets               = make(chan data.CaptureBufferedWriter, 1)
+	registerFakeReadingsCollectorOnce sync.Once
+)
+
+func registerFakeReadingsCollector() {
+	registerFakeReadingsCollectorOnce.Do(func() {
+		for _, method := range []string{"Readings", data.GetImages} {
+			data.RegisterCollector(
+				data.MethodMetadata{API: fakeAPI, MethodName: method},
+				func(_ interface{}, params data.CollectorParams) (data.Collector, error) {
+					fakeReadingsTargets <- params.Target
+					return &mockCollector{}, nil
+				},
+			)
+		}
+	})
+}
+
+func TestAggregatedCollector(t *testing.T) {
+	registerFakeReadingsCollector()
+	captureDir := t.TempDir()
+	c := newTestCapture(t, nil, nil)
+	cfg := datamanager.DataCaptureConfig{
+		Name:               resource.NewName(fakeAPI, "fake-1"),
+		Method:             "Readings",
+		CaptureFrequencyHz: 10,
+		CaptureDirectory:   captureDir,
+		Aggregation: &datamanager.AggregationConfig{
+			WindowSeconds: 1,
+			RawRetention:  &datamanager.RetentionConfig{MaxAgeHours: 24},
+		},
+	}
+
+	_, err := c.buildCollector(fakeRes, newCollectorMetadata(cfg), cfg, 1024, nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	target, ok := (<-fakeReadingsTargets).(*data.AggregatingBuffer)
+	test.That(t, ok, test.ShouldBeTrue)
+
+	readings, err := structpb.NewStruct(map[string]interface{}{"a": 1})
+	test.That(t, err, test.ShouldBeNil)
+	requested := timestamppb.New(time.Now().Add(-time.Minute))
+	for i := 0; i < 3; i++ {
+		test.That(t, target.WriteTabular(&v1.SensorData{
+			Metadata: &v1.SensorMetadata{TimeRequested: requested, TimeReceived: requested},
+			Data:     &v1.SensorData_Struct{Struct: readings},
+		}), test.ShouldBeNil)
+	}
+	// The window has ended, so flushing writes it.
+	test.That(t, target.Flush(), test.ShouldBeNil)
+
+	for _, dir := range []string{targetDir(captureDir, cfg), rawTargetDir(captureDir, cfg)} {
+		files, err := filepath.Glob(filepath.Join(dir, "*"+data.CompletedCaptureFileExt))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, files, test.ShouldHaveLength, 1)
+	}
+	test.That(t, rawTargetDir(captureDir, cfg), test.ShouldEqual,
+		filepath.Join(captureDir, "rawCapture", "rdk_component_fake", "fake-1", "Readings"))
+
+	t.Run("is only supported by tabular capture methods", func(t *testing.T) {
+		binaryCfg := cfg
+		binaryCfg.Method = data.GetImages
+		_, err := c.buildCollector(fakeRes, newCollectorMetadata(binaryCfg), binaryCfg, 1024, nil, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "aggregation is only supported by tabular capture methods")
+	})
+
+	t.Run("is validated", func(t *testing.T) {
+		invalidCfg := cfg
+		invalidCfg.Aggregation = &datamanager.AggregationConfig{}
+		_, err := c.initializeOrUpdateCollector(fakeRes, newCollectorMetadata(invalidCfg), invalidCfg, Config{}, nil, nil)
+		test.That(t, err, test.ShouldBeError, "aggregation window_seconds must be greater than zero")
+	})
+}
@@ -1,5 +1,7 @@
 package capture
 
+import "github.com/pkg/errors"
+
 // MongoConfig is the optional data capture mongo config.
 type MongoConfig struct {
 	URI        string `json:"uri"`
@@ -24,6 +26,84 @@ type Config struct {
 	// (.prog) files should be allowed to grow to before they are convered into .capture
 	// files
 	MaximumCaptureFileSizeBytes int64
+	// CaptureFileVersion is the format version of the capture files written, see
+	// data.CaptureFileV1. Zero is data.CaptureFileV1.
+	CaptureFileVersion int
 
 	MongoConfig *MongoConfig
+	// SQLiteConfig when set mirrors tabular readings to an SQLite database
+	SQLiteConfig *SQLiteConfig
+	// Groups defines capture methods which are sampled on one shared tick
+	Groups []GroupConfig
+}
+
+// GroupConfig is a capture group: its members are sampled on one shared tick at the capture
+// frequency of the group, rather than at their own, so that their readings share the time they
+// were requested at. The capture files of the members are tagged with the group's tag, see
+// data.CaptureGroupTag.
+type GroupConfig struct {
+	Name               string              `json:"name"`
+	CaptureFrequencyHz float32             `json:"capture_frequency_hz"`
+	Members            []GroupMemberConfig `json:"members"`
+}
+
+// GroupMemberConfig is a capture method of a capture group, which must also be configured as a
+// capture method of its resource.
+type GroupMemberConfig struct {
+	// Name is the name of the resource.
+	Name   string `json:"name"`
+	Method string `json:"method"`
+}
+
+// Validate returns an error if the capture group is invalid.
+func (gc GroupConfig) Validate() error {
+	if gc.Name == "" {
+		return errors.New("capture group name can't be empty")
+	}
+	if gc.CaptureFrequencyHz <= 0 {
+		return errors.Errorf("capture group %q capture_frequency_hz must be greater than 0", gc.Name)
+	}
+	if len(gc.Members) == 0 {
+		return errors.Errorf("capture group %q has no members", gc.Name)
+	}
+	for _, member := range gc.Members {
+		if member.Name == "" || member.Method == "" {
+			return errors.Errorf("capture group %q members must have a name and a method", gc.Name)
+		}
+	}
+	return nil
+}
+
+// SQLiteConfig is the optional data capture SQLite config. Tabular readings are written to a table
+// of an SQLite database on the machine, for machines which can't run MongoDB.
+type SQLiteConfig struct {
+	// Path is the path of the database file. Defaults to DefaultSQLitePath.
+	Path string `json:"path"`
+	// Table is the name of the table the readings are written to. Defaults to "readings".
+	Table string `json:"table"`
+	// MaxAgeHours deletes the readings requested longer ago than this. Unbounded when zero.
+	MaxAgeHours float64 `json:"max_age_hours,omitempty"`
+	// MaxRows bounds the number of readings kept, the oldest are deleted first. Unbounded when zero.
+	MaxRows int64 `json:"max_rows,omitempty"`
+}
+
+// Validate returns an error if the table name is not a valid SQL identifier, or if the retention
+// is negative.
+func (sc SQLiteConfig) Validate() error {
+	if sc.Table != "" && !sqliteTableNameRegexp.MatchString(sc.Table) {
+		return errors.Errorf("sqlite_capture_config.table %q must only contain letters, digits and underscores, "+
+			"and must not start with a digit", sc.Table)
+	}
+	if sc.MaxAgeHours < 0 {
+		return errors.New("sqlite_capture_config.max_age_hours can't be negative")
+	}
+	if sc.MaxRows < 0 {
+		return errors.New("sqlite_capture_config.max_rows can't be negative")
+	}
+	return nil
+}
+
+// Equal returns true when both SQLiteConfigs are equal.
+func (sc SQLiteConfig) Equal(o SQLiteConfig) bool {
+	return sc.Path == o.Path && sc.Table == o.Table && sc.MaxAgeHours == o.MaxAgeHours && sc.MaxRows == o.MaxRows
 }
@@ -0,0 +1,94 @@
+package capture
+
+import (
+	"slices"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/services/datamanager"
+)
+
+// captureGroup is a running capture group.
+type captureGroup struct {
+	config GroupConfig
+	group  *data.CaptureGroup
+}
+
+// groupsReconfigure starts the capture groups of the new config, and keeps running the ones whose
+// capture frequency is unchanged. It returns the capture groups which are no longer used, which
+// must be closed once the collectors of their members are closed.
+func (c *Capture) groupsReconfigure(
+	configs []GroupConfig,
+	collectorConfigsByResource CollectorConfigsByResource,
+) []*data.CaptureGroup {
+	configured := map[string]bool{}
+	for _, cfgs := range collectorConfigsByResource {
+		for _, cfg := range cfgs {
+			configured[DataCaptureConfigKey(cfg.Name.ShortName(), cfg.Method)] = true
+		}
+	}
+
+	groups := make(map[string]*captureGroup, len(configs))
+	groupsByCaptureMethod := map[string]*captureGroup{}
+	for _, config := range configs {
+		group, ok := c.groups[config.Name]
+		if ok && group.config.CaptureFrequencyHz == config.CaptureFrequencyHz {
+			group = &captureGroup{config: config, group: group.group}
+		} else {
+			c.logger.Infof("starting capture group %s, capture_frequency_hz: %f", config.Name, config.CaptureFrequencyHz)
+			group = &captureGroup{
+				config: config,
+				group:  data.NewCaptureGroup(config.Name, data.GetDurationFromHz(config.CaptureFrequencyHz), c.clk),
+			}
+		}
+		groups[config.Name] = group
+		for _, member := range config.Members {
+			key := DataCaptureConfigKey(member.Name, member.Method)
+			if !configured[key] {
+				c.logger.Warnf("capture group %s member %s is not a capture method of its resource, it is not captured",
+					config.Name, key)
+			}
+			groupsByCaptureMethod[key] = group
+		}
+	}
+
+	var stale []*data.CaptureGroup
+	for name, group := range c.groups {
+		if newGroup, ok := groups[name]; !ok || newGroup.group != group.group {
+			stale = append(stale, group.group)
+		}
+	}
+	c.groups = groups
+	c.groupsByCaptureMethod = groupsByCaptureMethod
+	return stale
+}
+
+// withGroup returns the config of a capture method as a member of its capture group, which is
+// captured at the capture frequency of the group and tagged with the group's tag. Configs of
+// capture methods which aren't in a capture group are returned unchanged.
+func (c *Capture) withGroup(cfg datamanager.DataCaptureConfig) datamanager.DataCaptureConfig {
+	group, ok := c.groupsByCaptureMethod[DataCaptureConfigKey(cfg.Name.ShortName(), cfg.Method)]
+	if !ok {
+		return cfg
+	}
+	cfg.CaptureFrequencyHz = group.config.CaptureFrequencyHz
+	cfg.Tags = append(slices.Clone(cfg.Tags), data.CaptureGroupTag(group.config.Name))
+	return cfg
+}
+
+// captureGroup returns the capture group of a capture method, or nil when it isn't in one.
+func (c *Capture) captureGroup(cfg datamanager.DataCaptureConfig) *data.CaptureGroup {
+	group, ok := c.groupsByCaptureMethod[DataCaptureConfigKey(cfg.Name.ShortName(), cfg.Method)]
+	if !ok {
+		return nil
+	}
+	return group.group
+}
+
+// closeGroups stops the capture groups, the collectors of their members must be closed.
+func (c *Capture) closeGroups() {
+	for _, group := range c.groups {
+		group.group.Close()
+	}
+	c.groups = nil
+	c.groupsByCaptureMethod = nil
+}
@@ -0,0 +1,109 @@
+package capture
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/datamanager"
+)
+
+var (
+	fakeGroupParams                = make(chan data.CollectorParams, 2)
+	registerFakeGroupCollectorOnce sync.Once
+)
+
+func registerFakeGroupCollector() {
+	registerFakeGroupCollectorOnce.Do(func() {
+		for _, method := range []string{"JointPositions", "EndPosition"} {
+			data.RegisterCollector(
+				data.MethodMetadata{API: fakeAPI, MethodName: method},
+				func(_ interface{}, params data.CollectorParams) (data.Collector, error) {
+					fakeGroupParams <- params
+					return &mockCollector{}, nil
+				},
+			)
+		}
+	})
+}
+
+func TestCaptureGroups(t *testing.T) {
+	registerFakeGroupCollector()
+	ctx := context.Background()
+	c := newTestCapture(t, nil, nil)
+	defer c.Close(ctx)
+
+	captureDir := t.TempDir()
+	method := func(name string) datamanager.DataCaptureConfig {
+		return datamanager.DataCaptureConfig{
+			Name:               resource.NewName(fakeAPI, "fake-1"),
+			Method:             name,
+			CaptureFrequencyHz: 1,
+			CaptureDirectory:   captureDir,
+		}
+	}
+	configs := CollectorConfigsByResource{fakeRes: {method("JointPositions"), method("EndPosition")}}
+	config := func(members ...string) Config {
+		group := GroupConfig{Name: "teleop", CaptureFrequencyHz: 10}
+		for _, member := range members {
+			group.Members = append(group.Members, GroupMemberConfig{Name: "fake-1", Method: member})
+		}
+		return Config{
+			CaptureDir:                  captureDir,
+			MaximumCaptureFileSizeBytes: 1024,
+			Tags:                        []string{"a"},
+			Groups:                      []GroupConfig{group},
+		}
+	}
+	receiveParams := func(n int) map[string]data.CollectorParams {
+		t.Helper()
+		params := map[string]data.CollectorParams{}
+		for i := 0; i < n; i++ {
+			select {
+			case p := <-fakeGroupParams:
+				params[p.MethodName] = p
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for collectors to be built")
+			}
+		}
+		select {
+		case p := <-fakeGroupParams:
+			t.Fatalf("unexpected collector built for %s", p.MethodName)
+		default:
+		}
+		return params
+	}
+
+	c.Reconfigure(ctx, configs, config("JointPositions"))
+	params := receiveParams(2)
+	group := params["JointPositions"].Group
+	test.That(t, group, test.ShouldNotBeNil)
+	test.That(t, group.Interval(), test.ShouldEqual, 100*time.Millisecond)
+	test.That(t, params["EndPosition"].Group, test.ShouldBeNil)
+	for md, collector := range c.collectors {
+		if md.MethodMetadata.MethodName == "JointPositions" {
+			test.That(t, collector.Config.CaptureFrequencyHz, test.ShouldEqual, 10)
+			test.That(t, collector.Config.Tags, test.ShouldResemble, []string{"a", "capture_group:teleop"})
+		} else {
+			test.That(t, collector.Config.CaptureFrequencyHz, test.ShouldEqual, 1)
+			test.That(t, collector.Config.Tags, test.ShouldResemble, []string{"a"})
+		}
+	}
+
+	// Adding a member only rebuilds its collector, on the tick of the running group.
+	c.Reconfigure(ctx, configs, config("JointPositions", "EndPosition"))
+	params = receiveParams(1)
+	test.That(t, params["EndPosition"].Group, test.ShouldEqual, group)
+
+	// Removing the group rebuilds the collectors of its members on their own tick.
+	c.Reconfigure(ctx, configs, Config{CaptureDir: captureDir, MaximumCaptureFileSizeBytes: 1024, Tags: []string{"a"}})
+	params = receiveParams(2)
+	test.That(t, params["JointPositions"].Group, test.ShouldBeNil)
+	test.That(t, params["EndPosition"].Group, test.ShouldBeNil)
+	test.That(t, c.groups, test.ShouldBeEmpty)
+}
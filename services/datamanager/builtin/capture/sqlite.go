@@ -0,0 +1,295 @@
+package capture
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+	// Registers the sqlite database/sql driver, which doesn't need cgo.
+	_ "modernc.org/sqlite"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	defaultSQLiteTableName = "readings"
+	// sqliteFlushInterval is how often the readings written by collectors are inserted, in a single
+	// transaction.
+	sqliteFlushInterval = time.Second
+	// sqliteMaxPendingReadings bounds the readings waiting to be inserted when the database can't
+	// keep up. The oldest are dropped first.
+	sqliteMaxPendingReadings = 10000
+	// sqliteRetentionInterval is how often the readings past the retention of the config are
+	// deleted.
+	sqliteRetentionInterval = time.Minute
+)
+
+// DefaultSQLitePath is the default path of the SQLite database tabular readings are written to. It
+// is outside of the capture directory, so that it isn't synced as an arbitrary file.
+var DefaultSQLitePath = filepath.Join(utils.ViamDotDir, "capture.db")
+
+// sqliteTimeFormat is a fixed width ISO 8601 format, so that times sort lexically, which the date
+// and time functions of SQLite understand.
+const sqliteTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+var sqliteTableNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// sqliteStore is a data.TabularDataStore which writes tabular readings to a table of an SQLite
+// database, whose columns are the fields of data.TabularDataBson. Times are UTC ISO 8601 strings,
+// and the data column holds the readings as JSON, which the JSON functions of SQLite can query,
+// e.g:
+//
+//	SELECT time_requested, json_extract(data, '$.readings.temperature') FROM readings
+//	WHERE component_name = 'sensor-1' AND time_requested > '2024-11-18T12:00:00Z';
+//
+// Readings are buffered, and inserted in batches by a background worker, so that collectors don't
+// wait on the database.
+type sqliteStore struct {
+	db        *sql.DB
+	table     string
+	insertSQL string
+	config    SQLiteConfig
+	logger    logging.Logger
+	workers   *goutils.StoppableWorkers
+
+	// flushMu serializes flushes, so that readings are inserted in the order they were written.
+	flushMu       sync.Mutex
+	lastRetention time.Time
+
+	mu      sync.Mutex
+	pending []sqliteRow
+	dropped int
+}
+
+// sqliteRow holds the values of the columns of a reading.
+type sqliteRow struct {
+	timeRequested string
+	timeReceived  string
+	componentName string
+	componentType string
+	methodName    string
+	data          string
+}
+
+func newSQLiteStore(ctx context.Context, config SQLiteConfig, logger logging.Logger) (*sqliteStore, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	path := defaultIfZeroVal(config.Path, DefaultSQLitePath)
+	table := defaultIfZeroVal(config.Table, defaultSQLiteTableName)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, errors.Wrapf(err, "failed to create directory of sqlite database %s", path)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite only supports a single writer.
+	db.SetMaxOpenConns(1)
+
+	schema := fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %[1]s (
+	time_requested TEXT NOT NULL,
+	time_received  TEXT NOT NULL,
+	component_name TEXT NOT NULL,
+	component_type TEXT NOT NULL,
+	method_name    TEXT NOT NULL,
+	data           TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS %[1]s_component_idx ON %[1]s (component_type, component_name, time_requested);
+CREATE INDEX IF NOT EXISTS %[1]s_time_requested_idx ON %[1]s (time_requested);`, table)
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, multierr.Combine(errors.Wrapf(err, "failed to create table %s of sqlite database %s", table, path), db.Close())
+	}
+
+	s := &sqliteStore{
+		db:    db,
+		table: table,
+		insertSQL: fmt.Sprintf(
+			`INSERT INTO %s (time_requested, time_received, component_name, component_type, method_name, data)
+VALUES (?, ?, ?, ?, ?, ?)`, table),
+		config: config,
+		logger: logger,
+	}
+	s.workers = goutils.NewStoppableWorkerWithTicker(sqliteFlushInterval, func(ctx context.Context) {
+		if err := s.flush(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Warnw("failed to write tabular readings to sqlite database", "path", path, "error", err)
+		}
+	})
+	return s, nil
+}
+
+// InsertTabularData buffers the reading, which is inserted into the table by the next flush.
+func (s *sqliteStore) InsertTabularData(ctx context.Context, td data.TabularDataBson) error {
+	readings, err := json.Marshal(td.Data)
+	if err != nil {
+		return errors.Wrap(err, "failed to convert sensor data into json")
+	}
+	row := sqliteRow{
+		timeRequested: td.TimeRequested.UTC().Format(sqliteTimeFormat),
+		timeReceived:  td.TimeReceived.UTC().Format(sqliteTimeFormat),
+		componentName: td.ComponentName,
+		componentType: td.ComponentType,
+		methodName:    td.MethodName,
+		data:          string(readings),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) >= sqliteMaxPendingReadings {
+		s.pending = s.pending[1:]
+		s.dropped++
+	}
+	s.pending = append(s.pending, row)
+	return nil
+}
+
+// flush inserts the buffered readings in a single transaction, and deletes the readings past the
+// retention of the config when it is due.
+func (s *sqliteStore) flush(ctx context.Context) error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	rows, dropped := s.pending, s.dropped
+	s.pending, s.dropped = nil, 0
+	s.mu.Unlock()
+	if dropped > 0 {
+		s.logger.Warnf("dropped %d tabular readings as the sqlite database could not keep up", dropped)
+	}
+
+	if err := s.insert(ctx, rows); err != nil {
+		return errors.Wrapf(err, "failed to insert %d readings", len(rows))
+	}
+
+	now := time.Now()
+	if now.Sub(s.lastRetention) < sqliteRetentionInterval {
+		return nil
+	}
+	s.lastRetention = now
+	return s.enforceRetention(ctx, now)
+}
+
+func (s *sqliteStore) insert(ctx context.Context, rows []sqliteRow) (err error) {
+	if len(rows) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = multierr.Combine(err, tx.Rollback())
+		}
+	}()
+	stmt, err := tx.PrepareContext(ctx, s.insertSQL)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer stmt.Close()
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx,
+			row.timeRequested, row.timeReceived, row.componentName, row.componentType, row.methodName, row.data,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// enforceRetention deletes the readings requested longer than MaxAgeHours ago, and then the oldest
+// readings over MaxRows.
+func (s *sqliteStore) enforceRetention(ctx context.Context, now time.Time) error {
+	if s.config.MaxAgeHours > 0 {
+		cutoff := now.Add(-time.Duration(s.config.MaxAgeHours * float64(time.Hour)))
+		if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE time_requested < ?`, s.table),
+			cutoff.UTC().Format(sqliteTimeFormat)); err != nil {
+			return errors.Wrap(err, "failed to delete readings past max_age_hours")
+		}
+	}
+	if s.config.MaxRows > 0 {
+		// Rows are inserted in the order they were written, so the oldest have the lowest rowids.
+		if _, err := s.db.ExecContext(ctx, fmt.Sprintf(
+			`DELETE FROM %[1]s WHERE rowid IN (SELECT rowid FROM %[1]s ORDER BY rowid DESC LIMIT -1 OFFSET ?)`, s.table),
+			s.config.MaxRows); err != nil {
+			return errors.Wrap(err, "failed to delete readings over max_rows")
+		}
+	}
+	return nil
+}
+
+// Close inserts the buffered readings and closes the database.
+func (s *sqliteStore) Close() error {
+	s.workers.Stop()
+	return multierr.Combine(s.flush(context.Background()), s.db.Close())
+}
+
+// sqliteReconfigure shuts down the collectors and closes the SQLite database when the new config
+// no longer prescribes it, and opens the database the new config prescribes.
+// returns the store when the database is open and nil when it is not.
+func (c *Capture) sqliteReconfigure(ctx context.Context, newConfig *SQLiteConfig, captureDir string) data.TabularDataStore {
+	c.sqliteMU.Lock()
+	defer c.sqliteMU.Unlock()
+	if c.sqlite != nil && newConfig != nil && c.sqlite.config.Equal(*newConfig) {
+		// if we have a database & the configs are equal, reuse the existing store
+		return c.sqlite
+	}
+
+	if c.sqlite != nil {
+		// The collectors write to the store. They will be recreated later during Reconfigure.
+		c.FlushCollectors()
+		c.closeCollectors()
+		c.closeSQLiteNoMutex()
+	}
+	if newConfig == nil {
+		return nil
+	}
+
+	path := defaultIfZeroVal(newConfig.Path, DefaultSQLitePath)
+	if relativePath, err := filepath.Rel(captureDir, path); err == nil && filepath.IsLocal(relativePath) {
+		c.logger.Warnf("sqlite_capture_config.path %s is in the capture directory %s, where it would be synced, "+
+			"not writing tabular readings to it", path, captureDir)
+		return nil
+	}
+	store, err := newSQLiteStore(ctx, *newConfig, c.logger)
+	if err != nil {
+		c.logger.Warnw("failed to open sqlite_capture_config database", "path", path, "error", err)
+		return nil
+	}
+	c.sqlite = store
+	c.logger.Infof("writing tabular readings to sqlite database %s", path)
+	return c.sqlite
+}
+
+// tabularStore returns the store of the SQLite database, or nil when there is none.
+func (c *Capture) tabularStore() data.TabularDataStore {
+	c.sqliteMU.Lock()
+	defer c.sqliteMU.Unlock()
+	if c.sqlite == nil {
+		return nil
+	}
+	return c.sqlite
+}
+
+// closeSQLiteNoMutex closes the SQLite database, its caller must hold the sqliteMU.
+func (c *Capture) closeSQLiteNoMutex() {
+	if c.sqlite != nil {
+		c.logger.Info("closing sqlite database")
+		goutils.UncheckedError(c.sqlite.Close())
+		c.sqlite = nil
+	}
+}
@@ -0,0 +1,145 @@
+package capture
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	ctx := context.Background()
+	config := SQLiteConfig{Path: filepath.Join(t.TempDir(), "capture.db"), Table: "sensor_readings"}
+	store, err := newSQLiteStore(ctx, config, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	requested := time.Date(2024, 11, 18, 12, 0, 0, 0, time.FixedZone("", 3600))
+	for i, name := range []string{"sensor-1", "sensor-2"} {
+		test.That(t, store.InsertTabularData(ctx, data.TabularDataBson{
+			TimeRequested: requested.Add(time.Duration(i) * time.Second),
+			TimeReceived:  requested.Add(time.Duration(i)*time.Second + time.Millisecond),
+			ComponentName: name,
+			ComponentType: "rdk:component:sensor",
+			MethodName:    "Readings",
+			Data:          bson.M{"readings": bson.M{"temperature": 20.5 + float64(i)}},
+		}), test.ShouldBeNil)
+	}
+	test.That(t, store.Close(), test.ShouldBeNil)
+
+	db, err := sql.Open("sqlite", config.Path)
+	test.That(t, err, test.ShouldBeNil)
+	defer db.Close()
+
+	var timeRequested, timeReceived string
+	var temperature float64
+	err = db.QueryRowContext(ctx, `SELECT time_requested, time_received, json_extract(data, '$.readings.temperature')
+FROM sensor_readings WHERE component_type = 'rdk:component:sensor' AND component_name = 'sensor-2' AND method_name = 'Readings'
+AND time_requested > '2024-11-18T11:00:00Z'`).Scan(&timeRequested, &timeReceived, &temperature)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, timeRequested, test.ShouldEqual, "2024-11-18T11:00:01.000000000Z")
+	test.That(t, timeReceived, test.ShouldEqual, "2024-11-18T11:00:01.001000000Z")
+	test.That(t, temperature, test.ShouldEqual, 21.5)
+
+	var indexCount int
+	err = db.QueryRowContext(ctx, `SELECT count(*) FROM sqlite_master WHERE type = 'index' AND tbl_name = 'sensor_readings'`).
+		Scan(&indexCount)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, indexCount, test.ShouldEqual, 2)
+
+	t.Run("validates the table name", func(t *testing.T) {
+		_, err := newSQLiteStore(ctx, SQLiteConfig{Path: config.Path, Table: "readings; DROP TABLE readings"}, logging.NewTestLogger(t))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "must only contain letters, digits and underscores")
+	})
+}
+
+func TestSQLiteRetention(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	count := func(t *testing.T, store *sqliteStore) int {
+		t.Helper()
+		var n int
+		test.That(t, store.db.QueryRowContext(ctx, `SELECT count(*) FROM readings`).Scan(&n), test.ShouldBeNil)
+		return n
+	}
+	insert := func(t *testing.T, store *sqliteStore, requested ...time.Time) {
+		t.Helper()
+		for _, r := range requested {
+			test.That(t, store.InsertTabularData(ctx, data.TabularDataBson{
+				TimeRequested: r,
+				TimeReceived:  r,
+				ComponentName: "sensor-1",
+				ComponentType: "rdk:component:sensor",
+				MethodName:    "Readings",
+				Data:          bson.M{"readings": bson.M{"a": 1}},
+			}), test.ShouldBeNil)
+		}
+	}
+
+	t.Run("readings are inserted in batches", func(t *testing.T) {
+		store, err := newSQLiteStore(ctx, SQLiteConfig{Path: filepath.Join(t.TempDir(), "capture.db")}, logging.NewTestLogger(t))
+		test.That(t, err, test.ShouldBeNil)
+		defer store.Close()
+		store.workers.Stop()
+
+		insert(t, store, now, now)
+		test.That(t, count(t, store), test.ShouldEqual, 0)
+		test.That(t, store.flush(ctx), test.ShouldBeNil)
+		test.That(t, count(t, store), test.ShouldEqual, 2)
+	})
+
+	t.Run("old readings are deleted", func(t *testing.T) {
+		store, err := newSQLiteStore(ctx,
+			SQLiteConfig{Path: filepath.Join(t.TempDir(), "capture.db"), MaxAgeHours: 1}, logging.NewTestLogger(t))
+		test.That(t, err, test.ShouldBeNil)
+		defer store.Close()
+		store.workers.Stop()
+
+		insert(t, store, now.Add(-2*time.Hour), now.Add(-time.Minute))
+		test.That(t, store.flush(ctx), test.ShouldBeNil)
+		test.That(t, count(t, store), test.ShouldEqual, 1)
+	})
+
+	t.Run("readings over max rows are deleted oldest first", func(t *testing.T) {
+		store, err := newSQLiteStore(ctx,
+			SQLiteConfig{Path: filepath.Join(t.TempDir(), "capture.db"), MaxRows: 2}, logging.NewTestLogger(t))
+		test.That(t, err, test.ShouldBeNil)
+		defer store.Close()
+		store.workers.Stop()
+
+		insert(t, store, now.Add(-3*time.Second), now.Add(-2*time.Second), now.Add(-time.Second))
+		test.That(t, store.flush(ctx), test.ShouldBeNil)
+		var oldest string
+		test.That(t, store.db.QueryRowContext(ctx, `SELECT min(time_requested) FROM readings`).Scan(&oldest), test.ShouldBeNil)
+		test.That(t, count(t, store), test.ShouldEqual, 2)
+		test.That(t, oldest, test.ShouldEqual, now.Add(-2*time.Second).UTC().Format(sqliteTimeFormat))
+	})
+}
+
+func TestSQLiteReconfigure(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCapture(t, nil, nil)
+	config := &SQLiteConfig{Path: filepath.Join(t.TempDir(), "capture.db")}
+
+	store := c.sqliteReconfigure(ctx, config, c.captureDir)
+	test.That(t, store, test.ShouldNotBeNil)
+	test.That(t, c.tabularStore(), test.ShouldEqual, store)
+	// An unchanged config reuses the database.
+	test.That(t, c.sqliteReconfigure(ctx, &SQLiteConfig{Path: config.Path}, c.captureDir), test.ShouldEqual, store)
+
+	// Databases in the capture directory would be synced.
+	test.That(t, c.sqliteReconfigure(ctx, &SQLiteConfig{Path: filepath.Join(c.captureDir, "capture.db")}, c.captureDir),
+		test.ShouldBeNil)
+	test.That(t, c.tabularStore(), test.ShouldBeNil)
+
+	test.That(t, c.sqliteReconfigure(ctx, config, c.captureDir), test.ShouldNotBeNil)
+	c.Close(ctx)
+	test.That(t, c.tabularStore(), test.ShouldBeNil)
+}
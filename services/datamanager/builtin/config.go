@@ -2,14 +2,18 @@ package builtin
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/internal/cloud"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/services/datamanager"
 	"go.viam.com/rdk/services/datamanager/builtin/capture"
 	"go.viam.com/rdk/services/datamanager/builtin/shared"
 	datasync "go.viam.com/rdk/services/datamanager/builtin/sync"
@@ -50,6 +54,19 @@ type CaptureControlSensorConfig struct {
 	Key string `json:"key"`
 }
 
+// TagRetentionConfig applies a retention config to the captured data tagged with `Tag`.
+type TagRetentionConfig struct {
+	Tag       string                      `json:"tag"`
+	Retention datamanager.RetentionConfig `json:"retention"`
+}
+
+// SyncWindowConfig is a daily window of local time, e.g: `{"start": "01:00", "end": "05:00"}`. A
+// window that ends before it starts spans midnight.
+type SyncWindowConfig struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
 // Config describes how to configure the service.
 // See sync.Config and capture.Config for docs on what each field does
 // to both sync & capture respectively.
@@ -60,6 +77,16 @@ type Config struct {
 	// Capture
 	CaptureDisabled    bool                 `json:"capture_disabled"`
 	MongoCaptureConfig *capture.MongoConfig `json:"mongo_capture_config"`
+	// SQLiteCaptureConfig mirrors tabular readings to an SQLite database on the machine, for
+	// machines which can't run MongoDB.
+	SQLiteCaptureConfig *capture.SQLiteConfig `json:"sqlite_capture_config,omitempty"`
+	// CaptureGroups sample several capture methods on one shared tick, so that their readings are
+	// aligned, e.g: to capture camera frames along with the joint positions of an arm.
+	CaptureGroups []capture.GroupConfig `json:"capture_groups,omitempty"`
+	// CaptureFileVersion is the format of the capture files written, 1 when unset. Version 2 files
+	// have checksums, so that corrupt readings are skipped, but can't be read by versions of
+	// viam-server from before version 2 was added.
+	CaptureFileVersion int `json:"capture_file_version,omitempty"`
 	// File Deletion Parameters
 	DeleteEveryNthWhenDiskFull  int     `json:"delete_every_nth_when_disk_full"`
 	MaximumCaptureFileSizeBytes int64   `json:"maximum_capture_file_size_bytes"`
@@ -72,6 +99,26 @@ type Config struct {
 	ScheduledSyncDisabled  bool     `json:"sync_disabled"`
 	SelectiveSyncerName    string   `json:"selective_syncer_name"`
 	SyncIntervalMins       float64  `json:"sync_interval_mins"`
+	// SyncDestinations are where data can be synced to instead of the cloud, e.g: for machines that
+	// are air-gapped. Capture methods select one by name with their `sync_destination`.
+	SyncDestinations []datasync.DestinationConfig `json:"sync_destinations,omitempty"`
+	// DefaultSyncDestination names the destination of arbitrary files and of the data of capture
+	// methods without a `sync_destination`. Defaults to the cloud.
+	DefaultSyncDestination string `json:"default_sync_destination,omitempty"`
+	// TagRetentionPolicies apply to the captured data of capture methods without a `retention` of
+	// their own. The first policy whose tag the data is tagged with applies.
+	TagRetentionPolicies []TagRetentionConfig `json:"tag_retention_policies,omitempty"`
+	// SyncWindows are the daily windows of local time during which data is uploaded, e.g: to only
+	// upload at night. Data is uploaded at any time when empty.
+	SyncWindows []SyncWindowConfig `json:"sync_windows,omitempty"`
+	// UploadRateLimitBytesPerSec bounds the upload bandwidth. Unbounded when zero.
+	UploadRateLimitBytesPerSec int64 `json:"upload_rate_limit_bytes_per_sec,omitempty"`
+	// MeteredNetworkSensor names a sensor whose readings report whether the machine is on a metered
+	// network, e.g: a cellular plan, with a boolean `metered` key. Data is not uploaded on metered
+	// networks unless MeteredUploadRateLimitBytesPerSec is set.
+	MeteredNetworkSensor string `json:"metered_network_sensor,omitempty"`
+	// MeteredUploadRateLimitBytesPerSec bounds the upload bandwidth on metered networks.
+	MeteredUploadRateLimitBytesPerSec int64 `json:"metered_upload_rate_limit_bytes_per_sec,omitempty"`
 	// CaptureControlSensor when set specifies a sensor to poll for dynamic
 	// capture configurations.
 	CaptureControlSensor *CaptureControlSensorConfig `json:"capture_control_sensor,omitempty"`
@@ -100,6 +147,64 @@ func (c *Config) Validate(path string) ([]string, []string, error) {
 	if c.CaptureDirDeletionThreshold < 0 {
 		return nil, nil, errors.New("capture_dir_deletion_threshold can't be negative")
 	}
+	if c.CaptureFileVersion != 0 && c.CaptureFileVersion != data.CaptureFileV1 && c.CaptureFileVersion != data.CaptureFileV2 {
+		return nil, nil, fmt.Errorf("capture_file_version must be %d or %d", data.CaptureFileV1, data.CaptureFileV2)
+	}
+	if c.SQLiteCaptureConfig != nil {
+		if err := c.SQLiteCaptureConfig.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+	groupNames := map[string]bool{}
+	groupsByCaptureMethod := map[string]string{}
+	for _, group := range c.CaptureGroups {
+		if err := group.Validate(); err != nil {
+			return nil, nil, err
+		}
+		if groupNames[group.Name] {
+			return nil, nil, fmt.Errorf("capture group name %q is not unique", group.Name)
+		}
+		groupNames[group.Name] = true
+		for _, member := range group.Members {
+			key := capture.DataCaptureConfigKey(member.Name, member.Method)
+			if other, ok := groupsByCaptureMethod[key]; ok {
+				return nil, nil, fmt.Errorf("capture method %s can't be in both capture groups %q and %q", key, other, group.Name)
+			}
+			groupsByCaptureMethod[key] = group.Name
+		}
+	}
+	destinationNames := map[string]bool{datasync.CloudDestination: true}
+	for _, destination := range c.SyncDestinations {
+		if err := destination.Validate(); err != nil {
+			return nil, nil, err
+		}
+		if destinationNames[destination.Name] {
+			return nil, nil, fmt.Errorf("sync destination name %q is not unique", destination.Name)
+		}
+		destinationNames[destination.Name] = true
+	}
+	if c.DefaultSyncDestination != "" && !destinationNames[c.DefaultSyncDestination] {
+		return nil, nil, fmt.Errorf("default_sync_destination %q is not a sync destination", c.DefaultSyncDestination)
+	}
+	for _, policy := range c.TagRetentionPolicies {
+		if policy.Tag == "" {
+			return nil, nil, errors.New("tag_retention_policies tag can't be empty")
+		}
+		if err := policy.Retention.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, window := range c.SyncWindows {
+		if _, err := datasync.ParseSyncWindow(window.Start, window.End); err != nil {
+			return nil, nil, err
+		}
+	}
+	if c.UploadRateLimitBytesPerSec < 0 {
+		return nil, nil, errors.New("upload_rate_limit_bytes_per_sec can't be negative")
+	}
+	if c.MeteredUploadRateLimitBytesPerSec < 0 {
+		return nil, nil, errors.New("metered_upload_rate_limit_bytes_per_sec can't be negative")
+	}
 	return []string{cloud.InternalServiceName.String()}, nil, nil
 }
 
@@ -129,11 +234,21 @@ func (c *Config) captureConfig(logger logging.Logger) capture.Config {
 		CaptureDir:                  c.getCaptureDir(logger),
 		Tags:                        c.Tags,
 		MaximumCaptureFileSizeBytes: maximumCaptureFileSizeBytes,
+		CaptureFileVersion:          c.CaptureFileVersion,
 		MongoConfig:                 c.MongoCaptureConfig,
+		SQLiteConfig:                c.SQLiteCaptureConfig,
+		Groups:                      c.CaptureGroups,
 	}
 }
 
-func (c *Config) syncConfig(syncSensor sensor.Sensor, syncSensorEnabled bool, logger logging.Logger) datasync.Config {
+func (c *Config) syncConfig(
+	syncSensor sensor.Sensor,
+	syncSensorEnabled bool,
+	destinationsByCaptureMethod map[string]string,
+	retentionPolicies map[string]datasync.RetentionPolicy,
+	meteredNetworkDetector datasync.MeteredNetworkDetector,
+	logger logging.Logger,
+) datasync.Config {
 	newMaxSyncThreadValue := runtime.NumCPU() / 2
 	if c.MaximumNumSyncThreads != 0 {
 		newMaxSyncThreadValue = c.MaximumNumSyncThreads
@@ -171,20 +286,63 @@ func (c *Config) syncConfig(syncSensor sensor.Sensor, syncSensorEnabled bool, lo
 			c.SyncIntervalMins, syncIntervalMinsEpsilon, defaultSyncIntervalMins)
 	}
 
+	var tagRetentionPolicies []datasync.TagRetentionPolicy
+	for _, policy := range c.TagRetentionPolicies {
+		tagRetentionPolicies = append(tagRetentionPolicies, datasync.TagRetentionPolicy{
+			Tag:             policy.Tag,
+			RetentionPolicy: retentionPolicy(policy.Retention),
+		})
+	}
+
+	var syncWindows []datasync.SyncWindow
+	for _, window := range c.SyncWindows {
+		syncWindow, err := datasync.ParseSyncWindow(window.Start, window.End)
+		if err != nil {
+			// Validate has already rejected invalid windows.
+			logger.Warnw("ignoring invalid sync window", "error", err)
+			continue
+		}
+		syncWindows = append(syncWindows, syncWindow)
+	}
+
 	return datasync.Config{
-		AdditionalSyncPaths:         c.AdditionalSyncPaths,
-		Tags:                        c.Tags,
-		CaptureDir:                  c.getCaptureDir(logger),
-		CaptureDisabled:             c.CaptureDisabled,
-		DeleteEveryNthWhenDiskFull:  c.DeleteEveryNthWhenDiskFull,
-		DiskUsageDeletionThreshold:  c.DiskUsageDeletionThreshold,
-		CaptureDirDeletionThreshold: c.CaptureDirDeletionThreshold,
-		FileLastModifiedMillis:      c.FileLastModifiedMillis,
-		MaximumNumSyncThreads:       c.MaximumNumSyncThreads,
-		ScheduledSyncDisabled:       c.ScheduledSyncDisabled,
-		SelectiveSyncerName:         c.SelectiveSyncerName,
-		SyncIntervalMins:            syncIntervalMins,
-		SelectiveSyncSensor:         syncSensor,
-		SelectiveSyncSensorEnabled:  syncSensorEnabled,
+		AdditionalSyncPaths:               c.AdditionalSyncPaths,
+		Tags:                              c.Tags,
+		CaptureDir:                        c.getCaptureDir(logger),
+		CaptureDisabled:                   c.CaptureDisabled,
+		DeleteEveryNthWhenDiskFull:        c.DeleteEveryNthWhenDiskFull,
+		DiskUsageDeletionThreshold:        c.DiskUsageDeletionThreshold,
+		CaptureDirDeletionThreshold:       c.CaptureDirDeletionThreshold,
+		FileLastModifiedMillis:            c.FileLastModifiedMillis,
+		MaximumNumSyncThreads:             c.MaximumNumSyncThreads,
+		ScheduledSyncDisabled:             c.ScheduledSyncDisabled,
+		SelectiveSyncerName:               c.SelectiveSyncerName,
+		SyncIntervalMins:                  syncIntervalMins,
+		SelectiveSyncSensor:               syncSensor,
+		SelectiveSyncSensorEnabled:        syncSensorEnabled,
+		Destinations:                      c.SyncDestinations,
+		DefaultDestination:                c.DefaultSyncDestination,
+		DestinationsByCaptureMethod:       destinationsByCaptureMethod,
+		RetentionPolicies:                 retentionPolicies,
+		TagRetentionPolicies:              tagRetentionPolicies,
+		SyncWindows:                       syncWindows,
+		UploadRateLimitBytesPerSec:        c.UploadRateLimitBytesPerSec,
+		MeteredNetworkDetector:            meteredNetworkDetector,
+		MeteredUploadRateLimitBytesPerSec: c.MeteredUploadRateLimitBytesPerSec,
+	}
+}
+
+// retentionPolicy converts a valid retention config into the policy enforced by sync.
+func retentionPolicy(c datamanager.RetentionConfig) datasync.RetentionPolicy {
+	policy := datasync.RetentionPolicy{
+		MaxAge:   time.Duration(c.MaxAgeHours * float64(time.Hour)),
+		MaxBytes: c.MaxBytes,
+	}
+	switch c.Priority {
+	case datamanager.RetentionPriorityLow:
+		policy.Priority = datasync.RetentionPriorityLow
+	case datamanager.RetentionPriorityHigh:
+		policy.Priority = datasync.RetentionPriorityHigh
 	}
+	return policy
 }
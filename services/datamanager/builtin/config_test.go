@@ -6,11 +6,13 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"go.viam.com/test"
 
 	"go.viam.com/rdk/internal/cloud"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/services/datamanager"
 	"go.viam.com/rdk/services/datamanager/builtin/capture"
 	"go.viam.com/rdk/services/datamanager/builtin/shared"
 	"go.viam.com/rdk/services/datamanager/builtin/sync"
@@ -84,6 +86,93 @@ func TestConfig(t *testing.T) {
 				config: Config{CaptureDirDeletionThreshold: -1},
 				err:    errors.New("capture_dir_deletion_threshold can't be negative"),
 			},
+			{
+				name:   "returns an error if CaptureFileVersion is unknown",
+				config: Config{CaptureFileVersion: 3},
+				err:    errors.New("capture_file_version must be 1 or 2"),
+			},
+			{
+				name: "returns the internal cloud service name when sync destinations are valid",
+				config: Config{
+					SyncDestinations: []sync.DestinationConfig{
+						{Name: "usb", Type: sync.DestinationTypeDirectory, Directory: &sync.DirectoryDestinationConfig{Path: "/media/usb"}},
+					},
+					DefaultSyncDestination: "usb",
+				},
+				deps: []string{cloud.InternalServiceName.String()},
+			},
+			{
+				name: "returns an error if a sync destination is missing the attributes of its type",
+				config: Config{
+					SyncDestinations: []sync.DestinationConfig{{Name: "usb", Type: sync.DestinationTypeDirectory}},
+				},
+				err: errors.New(`sync destination "usb" is missing its directory attributes`),
+			},
+			{
+				name: "returns an error if a sync destination is named cloud",
+				config: Config{
+					SyncDestinations: []sync.DestinationConfig{
+						{Name: "cloud", Type: sync.DestinationTypeDirectory, Directory: &sync.DirectoryDestinationConfig{Path: "/media/usb"}},
+					},
+				},
+				err: errors.New(`sync destination name "cloud" is reserved`),
+			},
+			{
+				name: "returns an error if DefaultSyncDestination is not a sync destination",
+				config: Config{
+					DefaultSyncDestination: "usb",
+				},
+				err: errors.New(`default_sync_destination "usb" is not a sync destination`),
+			},
+			{
+				name: "returns an error if a tag retention policy has no tag",
+				config: Config{
+					TagRetentionPolicies: []TagRetentionConfig{{Retention: datamanager.RetentionConfig{MaxBytes: 10}}},
+				},
+				err: errors.New("tag_retention_policies tag can't be empty"),
+			},
+			{
+				name: "returns an error if a tag retention policy has an invalid retention",
+				config: Config{
+					TagRetentionPolicies: []TagRetentionConfig{{Tag: "frames", Retention: datamanager.RetentionConfig{MaxBytes: -1}}},
+				},
+				err: errors.New("retention max_bytes can't be negative"),
+			},
+			{
+				name: "returns an error if a sync window is invalid",
+				config: Config{
+					SyncWindows: []SyncWindowConfig{{Start: "01:00", End: "25:00"}},
+				},
+				err: errors.New(`invalid sync window end "25:00", must be HH:MM`),
+			},
+			{
+				name:   "returns an error if UploadRateLimitBytesPerSec is negative",
+				config: Config{UploadRateLimitBytesPerSec: -1},
+				err:    errors.New("upload_rate_limit_bytes_per_sec can't be negative"),
+			},
+			{
+				name:   "returns an error if the SQLiteCaptureConfig table is not a valid SQL identifier",
+				config: Config{SQLiteCaptureConfig: &capture.SQLiteConfig{Table: "1readings"}},
+				err: errors.New(`sqlite_capture_config.table "1readings" must only contain letters, digits and underscores, ` +
+					"and must not start with a digit"),
+			},
+			{
+				name: "returns an error if a capture group has no members",
+				config: Config{
+					CaptureGroups: []capture.GroupConfig{{Name: "teleop", CaptureFrequencyHz: 10}},
+				},
+				err: errors.New(`capture group "teleop" has no members`),
+			},
+			{
+				name: "returns an error if a capture method is in two capture groups",
+				config: Config{
+					CaptureGroups: []capture.GroupConfig{
+						{Name: "teleop", CaptureFrequencyHz: 10, Members: []capture.GroupMemberConfig{{Name: "arm1", Method: "JointPositions"}}},
+						{Name: "grasp", CaptureFrequencyHz: 5, Members: []capture.GroupMemberConfig{{Name: "arm1", Method: "JointPositions"}}},
+					},
+				},
+				err: errors.New(`capture method arm1/JointPositions can't be in both capture groups "teleop" and "grasp"`),
+			},
 		}
 
 		for _, tc := range tcs {
@@ -140,7 +229,7 @@ func TestConfig(t *testing.T) {
 	t.Run("syncConfig())", func(t *testing.T) {
 		t.Run("returns a sync config with defaults when called on an empty config", func(t *testing.T) {
 			c := &Config{}
-			test.That(t, c.syncConfig(nil, false, logger), test.ShouldResemble, sync.Config{
+			test.That(t, c.syncConfig(nil, false, nil, nil, nil, logger), test.ShouldResemble, sync.Config{
 				CaptureDir:                  shared.ViamCaptureDotDir,
 				DeleteEveryNthWhenDiskFull:  5,
 				FileLastModifiedMillis:      10000,
@@ -153,7 +242,7 @@ func TestConfig(t *testing.T) {
 
 		t.Run("returns a sync config with defaults when called on a config with SyncIntervalMins which is practically 0", func(t *testing.T) {
 			c := &Config{SyncIntervalMins: 0.000000000000000001}
-			test.That(t, c.syncConfig(nil, false, logger), test.ShouldResemble, sync.Config{
+			test.That(t, c.syncConfig(nil, false, nil, nil, nil, logger), test.ShouldResemble, sync.Config{
 				CaptureDir:                  shared.ViamCaptureDotDir,
 				DeleteEveryNthWhenDiskFull:  5,
 				FileLastModifiedMillis:      10000,
@@ -165,7 +254,7 @@ func TestConfig(t *testing.T) {
 		})
 		t.Run("returns a sync config with overridden defaults when called on a full config", func(t *testing.T) {
 			s := &inject.Sensor{}
-			test.That(t, fullConfig.syncConfig(s, true, logger), test.ShouldResemble, sync.Config{
+			test.That(t, fullConfig.syncConfig(s, true, nil, nil, nil, logger), test.ShouldResemble, sync.Config{
 				AdditionalSyncPaths:         []string{"/tmp/a", "/tmp/b"},
 				CaptureDir:                  "/tmp/some/path",
 				CaptureDisabled:             true,
@@ -182,5 +271,16 @@ func TestConfig(t *testing.T) {
 				Tags:                        []string{"a", "b", "c"},
 			})
 		})
+		t.Run("returns a sync config with the sync windows and upload rate limits", func(t *testing.T) {
+			c := Config{
+				SyncWindows:                       []SyncWindowConfig{{Start: "22:00", End: "02:00"}},
+				UploadRateLimitBytesPerSec:        1000,
+				MeteredUploadRateLimitBytesPerSec: 100,
+			}
+			syncConfig := c.syncConfig(nil, false, nil, nil, nil, logger)
+			test.That(t, syncConfig.SyncWindows, test.ShouldResemble, []sync.SyncWindow{{Start: 22 * time.Hour, End: 2 * time.Hour}})
+			test.That(t, syncConfig.UploadRateLimitBytesPerSec, test.ShouldEqual, 1000)
+			test.That(t, syncConfig.MeteredUploadRateLimitBytesPerSec, test.ShouldEqual, 100)
+		})
 	})
 }
@@ -0,0 +1,213 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/datamanager"
+	"go.viam.com/rdk/services/datamanager/builtin/capture"
+	datasync "go.viam.com/rdk/services/datamanager/builtin/sync"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+// DoTriggerCapture is the DoCommand key for triggering pre-roll capture methods.
+const DoTriggerCapture = "trigger_capture"
+
+const (
+	// selectiveSyncTriggerPollInterval is how often the selective syncer is read by the capture
+	// methods it triggers.
+	selectiveSyncTriggerPollInterval    = time.Second
+	defaultVisionTriggerPollFrequencyHz = 1.0
+)
+
+// triggerCaptureRequest is the value of a `DoTriggerCapture` command. All fields are optional, the
+// capture methods matching the fields that are set are triggered.
+type triggerCaptureRequest struct {
+	ComponentType string `mapstructure:"component_type"`
+	ComponentName string `mapstructure:"component_name"`
+	Method        string `mapstructure:"method"`
+}
+
+func (request triggerCaptureRequest) selects(c datamanager.DataCaptureConfig) bool {
+	return (request.ComponentType == "" || request.ComponentType == c.Name.API.String()) &&
+		(request.ComponentName == "" || request.ComponentName == c.Name.ShortName()) &&
+		(request.Method == "" || request.Method == c.Method)
+}
+
+// runTriggerCapture runs a `DoTriggerCapture` command, which persists the buffered data of pre-roll
+// capture methods, and the data they capture until their post-roll elapses.
+//
+//	{"trigger_capture": {"component_name": "front-camera", "method": "GetImages"}}
+//
+// The response holds the number of capture methods triggered under the same key.
+func (b *builtIn) runTriggerCapture(req interface{}) (map[string]interface{}, error) {
+	var request triggerCaptureRequest
+	if err := mapstructure.Decode(req, &request); err != nil {
+		return nil, fmt.Errorf("invalid %s command: %w", DoTriggerCapture, err)
+	}
+	triggered := b.capture.TriggerPreRoll(request.selects)
+	if triggered == 0 {
+		return nil, errors.New("no pre-roll capture methods match the request")
+	}
+	return map[string]interface{}{DoTriggerCapture: triggered}, nil
+}
+
+// selectsCaptureMethod returns a function that selects the capture config of the capture method.
+func selectsCaptureMethod(c datamanager.DataCaptureConfig) func(datamanager.DataCaptureConfig) bool {
+	return func(other datamanager.DataCaptureConfig) bool {
+		return other.Name == c.Name && other.Method == c.Method
+	}
+}
+
+// preRollTriggerWorkers returns the workers that poll the triggers of the pre-roll capture methods.
+// Triggers that can't be resolved are logged and ignored.
+func (b *builtIn) preRollTriggerWorkers(
+	collectorConfigsByResource capture.CollectorConfigsByResource,
+	deps resource.Dependencies,
+	syncConfig datasync.Config,
+) []func(context.Context) {
+	var workers []func(context.Context)
+	var triggeredBySelectiveSync []func(datamanager.DataCaptureConfig) bool
+	for _, collectorConfigs := range collectorConfigsByResource {
+		for _, collectorConfig := range collectorConfigs {
+			if collectorConfig.Disabled || collectorConfig.PreRoll == nil {
+				continue
+			}
+			selected := selectsCaptureMethod(collectorConfig)
+			if collectorConfig.PreRoll.TriggerOnSelectiveSync {
+				triggeredBySelectiveSync = append(triggeredBySelectiveSync, selected)
+			}
+
+			visionTrigger := collectorConfig.PreRoll.VisionTrigger
+			if visionTrigger == nil {
+				continue
+			}
+			visionSvc, err := vision.FromProvider(deps, visionTrigger.VisionService)
+			if err != nil {
+				b.logger.Errorw("unable to initialize the vision trigger of pre-roll capture method; it will not be triggered by vision",
+					"resource", collectorConfig.Name.String(), "method", collectorConfig.Method, "error", err.Error())
+				continue
+			}
+			camera := visionTrigger.Camera
+			if camera == "" {
+				camera = collectorConfig.Name.ShortName()
+			}
+			trigger := *visionTrigger
+			workers = append(workers, func(ctx context.Context) {
+				b.runVisionTrigger(ctx, visionSvc, camera, trigger, selected)
+			})
+		}
+	}
+
+	if len(triggeredBySelectiveSync) > 0 {
+		if syncConfig.SelectiveSyncSensor == nil {
+			b.logger.Warn("pre-roll capture methods are triggered by the selective syncer, but there is no selective syncer")
+		} else {
+			workers = append(workers, func(ctx context.Context) {
+				b.runSelectiveSyncTrigger(ctx, syncConfig, func(c datamanager.DataCaptureConfig) bool {
+					return slices.ContainsFunc(triggeredBySelectiveSync, func(selected func(datamanager.DataCaptureConfig) bool) bool {
+						return selected(c)
+					})
+				})
+			})
+		}
+	}
+	return workers
+}
+
+// runSelectiveSyncTrigger triggers the selected capture methods while the selective syncer returns
+// true for `should_sync`.
+func (b *builtIn) runSelectiveSyncTrigger(
+	ctx context.Context,
+	syncConfig datasync.Config,
+	selected func(datamanager.DataCaptureConfig) bool,
+) {
+	ticker := time.NewTicker(selectiveSyncTriggerPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if datasync.ReadyToSyncDirectories(ctx, syncConfig, b.logger) && ctx.Err() == nil {
+			b.capture.TriggerPreRoll(selected)
+		}
+	}
+}
+
+// runVisionTrigger triggers the selected capture method while the vision service detects objects
+// in the images of the camera.
+func (b *builtIn) runVisionTrigger(
+	ctx context.Context,
+	visionSvc vision.Service,
+	camera string,
+	trigger datamanager.VisionTriggerConfig,
+	selected func(datamanager.DataCaptureConfig) bool,
+) {
+	pollFrequencyHz := trigger.PollFrequencyHz
+	if pollFrequencyHz == 0 {
+		pollFrequencyHz = defaultVisionTriggerPollFrequencyHz
+	}
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / pollFrequencyHz))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		detections, err := visionSvc.DetectionsFromCamera(ctx, camera, nil)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			b.logger.Warnw("error getting detections for pre-roll vision trigger", "camera", camera, "error", err.Error())
+			continue
+		}
+		if visionTriggered(detections, trigger) {
+			b.capture.TriggerPreRoll(selected)
+		}
+	}
+}
+
+// visionTriggered returns true if any of the detections matches the trigger.
+func visionTriggered(detections []objectdetection.Detection, trigger datamanager.VisionTriggerConfig) bool {
+	for _, detection := range detections {
+		if detection.Score() < trigger.MinConfidence {
+			continue
+		}
+		if len(trigger.Labels) == 0 || slices.Contains(trigger.Labels, detection.Label()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *builtIn) startPreRollTriggerPoller(workers []func(context.Context)) {
+	if b.preRollTriggerPoller != nil {
+		b.logger.Warn("pre-roll trigger poller already running")
+		return
+	}
+	b.preRollTriggerPoller = goutils.NewBackgroundStoppableWorkers(workers...)
+}
+
+// stopPreRollTriggerPoller should be called before other calls to acquire b.mu, like
+// stopCaptureControlPoller.
+func (b *builtIn) stopPreRollTriggerPoller() {
+	b.mu.Lock()
+	oldPoller := b.preRollTriggerPoller
+	b.preRollTriggerPoller = nil
+	b.mu.Unlock()
+	if oldPoller != nil {
+		oldPoller.Stop()
+	}
+}
@@ -0,0 +1,47 @@
+package builtin
+
+import (
+	"context"
+	"image"
+	"testing"
+
+	"github.com/benbjohnson/clock"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/services/datamanager"
+	"go.viam.com/rdk/services/datamanager/builtin/capture"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+func TestTriggerCaptureRequest(t *testing.T) {
+	cfg := datamanager.DataCaptureConfig{Name: camera.Named("front"), Method: "GetImages"}
+	test.That(t, triggerCaptureRequest{}.selects(cfg), test.ShouldBeTrue)
+	test.That(t, triggerCaptureRequest{ComponentType: camera.API.String(), ComponentName: "front"}.selects(cfg), test.ShouldBeTrue)
+	test.That(t, triggerCaptureRequest{ComponentName: "back"}.selects(cfg), test.ShouldBeFalse)
+	test.That(t, triggerCaptureRequest{Method: "NextPointCloud"}.selects(cfg), test.ShouldBeFalse)
+
+	logger := logging.NewTestLogger(t)
+	svc := &builtIn{logger: logger, capture: capture.New(clock.New(), logger)}
+	_, err := svc.DoCommand(context.Background(), map[string]interface{}{
+		DoTriggerCapture: map[string]interface{}{"component_name": "front"},
+	})
+	test.That(t, err, test.ShouldBeError, "no pre-roll capture methods match the request")
+}
+
+func TestVisionTriggered(t *testing.T) {
+	bounds := image.Rect(0, 0, 100, 100)
+	detections := []objectdetection.Detection{
+		objectdetection.NewDetection(bounds, image.Rect(0, 0, 10, 10), 0.4, "person"),
+		objectdetection.NewDetection(bounds, image.Rect(0, 0, 10, 10), 0.9, "dog"),
+	}
+	test.That(t, visionTriggered(nil, datamanager.VisionTriggerConfig{}), test.ShouldBeFalse)
+	test.That(t, visionTriggered(detections, datamanager.VisionTriggerConfig{}), test.ShouldBeTrue)
+	test.That(t, visionTriggered(detections, datamanager.VisionTriggerConfig{Labels: []string{"person"}}), test.ShouldBeTrue)
+	test.That(t, visionTriggered(detections, datamanager.VisionTriggerConfig{
+		Labels:        []string{"person"},
+		MinConfidence: 0.5,
+	}), test.ShouldBeFalse)
+	test.That(t, visionTriggered(detections, datamanager.VisionTriggerConfig{Labels: []string{"cat"}}), test.ShouldBeFalse)
+}
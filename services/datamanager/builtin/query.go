@@ -0,0 +1,143 @@
+package builtin
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+
+	"go.viam.com/rdk/services/datamanager/builtin/query"
+)
+
+// DoQuery is the DoCommand key for querying the captured data on the robot's disk.
+const DoQuery = "query"
+
+const (
+	// defaultQueryLimit bounds the number of readings a query returns when it does not set a limit.
+	defaultQueryLimit = 1000
+	// defaultBinaryQueryLimit is the default limit of queries including binary readings, which
+	// can be megabytes each and are returned in a single DoCommand response.
+	defaultBinaryQueryLimit = 10
+)
+
+// queryRequest is the value of a `DoQuery` command. All fields are optional.
+type queryRequest struct {
+	ComponentType string `mapstructure:"component_type"`
+	ComponentName string `mapstructure:"component_name"`
+	Method        string `mapstructure:"method"`
+	// Start and End are RFC3339 times.
+	Start string `mapstructure:"start"`
+	End   string `mapstructure:"end"`
+	// Last is a duration, e.g: `1h`, selecting the readings requested since that long ago. It
+	// cannot be combined with Start.
+	Last          string `mapstructure:"last"`
+	Limit         int    `mapstructure:"limit"`
+	IncludeBinary bool   `mapstructure:"include_binary"`
+	// Raw queries the raw readings kept by aggregated capture methods.
+	Raw bool `mapstructure:"raw"`
+}
+
+// runQuery runs a `DoQuery` command, which returns readings captured to disk that may not have
+// been synced yet. It works while the robot is offline.
+//
+//	{"query": {"component_type": "rdk:component:sensor", "component_name": "my-sensor", "method": "Readings",
+//	           "last": "1h", "limit": 100}}
+//
+// All fields of the request are optional, see `queryRequest`. The response holds a list of
+// readings under the same key, oldest first. Tabular readings have a `data` field. Binary readings
+// have a `binary_size` field, and a base64 encoded `binary` field when `include_binary` is set.
+// Queries without a limit return at most 1000 readings, or 10 when `include_binary` is set.
+func (b *builtIn) runQuery(ctx context.Context, req interface{}) (map[string]interface{}, error) {
+	var request queryRequest
+	if err := mapstructure.Decode(req, &request); err != nil {
+		return nil, fmt.Errorf("invalid %s command: %w", DoQuery, err)
+	}
+	filter, err := request.filter(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s command: %w", DoQuery, err)
+	}
+
+	b.mu.Lock()
+	captureDir := b.captureDir
+	b.mu.Unlock()
+
+	readings, err := query.Query(ctx, captureDir, filter, b.logger)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]interface{}, 0, len(readings))
+	for _, reading := range readings {
+		ret = append(ret, readingToMap(reading))
+	}
+	return map[string]interface{}{DoQuery: ret}, nil
+}
+
+func (request queryRequest) filter(now time.Time) (query.Filter, error) {
+	filter := query.Filter{
+		ComponentType: request.ComponentType,
+		ComponentName: request.ComponentName,
+		MethodName:    request.Method,
+		Limit:         request.Limit,
+		IncludeBinary: request.IncludeBinary,
+		Raw:           request.Raw,
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultQueryLimit
+		if filter.IncludeBinary {
+			filter.Limit = defaultBinaryQueryLimit
+		}
+	}
+
+	var err error
+	if request.Start != "" {
+		if filter.Start, err = time.Parse(time.RFC3339, request.Start); err != nil {
+			return query.Filter{}, fmt.Errorf("invalid start: %w", err)
+		}
+	}
+	if request.End != "" {
+		if filter.End, err = time.Parse(time.RFC3339, request.End); err != nil {
+			return query.Filter{}, fmt.Errorf("invalid end: %w", err)
+		}
+	}
+	if request.Last != "" {
+		if request.Start != "" {
+			return query.Filter{}, errors.New("only one of start and last may be set")
+		}
+		last, err := time.ParseDuration(request.Last)
+		if err != nil {
+			return query.Filter{}, fmt.Errorf("invalid last: %w", err)
+		}
+		filter.Start = now.Add(-last)
+	}
+	return filter, nil
+}
+
+// readingToMap converts a reading into a map that can be returned by DoCommand.
+func readingToMap(reading query.Reading) map[string]interface{} {
+	tags := make([]interface{}, 0, len(reading.Tags))
+	for _, tag := range reading.Tags {
+		tags = append(tags, tag)
+	}
+	ret := map[string]interface{}{
+		"component_type": reading.ComponentType,
+		"component_name": reading.ComponentName,
+		"method":         reading.MethodName,
+		"tags":           tags,
+		"file_extension": reading.FileExtension,
+		"time_requested": reading.TimeRequested.Format(time.RFC3339Nano),
+		"time_received":  reading.TimeReceived.Format(time.RFC3339Nano),
+	}
+	if reading.Tabular != nil {
+		ret["data"] = reading.Tabular
+		return ret
+	}
+	ret["mime_type"] = reading.MimeType.String()
+	ret["binary_size"] = reading.BinarySize
+	if reading.Binary != nil {
+		ret["binary"] = base64.StdEncoding.EncodeToString(reading.Binary)
+	}
+	return ret
+}
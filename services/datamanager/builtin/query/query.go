@@ -0,0 +1,342 @@
+// Package query reads back data captured to the capture directory, including files which are still
+// being written to and files which have not been synced yet.
+package query
+
+import (
+	"cmp"
+	"container/heap"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/services/datamanager/builtin/shared"
+)
+
+// Filter selects the captured readings returned by `Query`. Zero valued fields match everything.
+type Filter struct {
+	// ComponentType is the API of the captured resource, e.g: `rdk:component:sensor`.
+	ComponentType string
+	// ComponentName is the name of the captured resource.
+	ComponentName string
+	// MethodName is the captured method, e.g: `Readings`.
+	MethodName string
+	// Start and End bound the time a reading was requested at. Start is inclusive, End is exclusive.
+	Start time.Time
+	End   time.Time
+	// Limit keeps only the most recent readings.
+	Limit int
+	// IncludeBinary returns the payload of binary readings. Otherwise only their size is returned.
+	IncludeBinary bool
+	// Raw returns the raw readings kept by aggregated capture methods rather than the captured
+	// readings.
+	Raw bool
+}
+
+// Reading is a single captured reading.
+type Reading struct {
+	ComponentType string
+	ComponentName string
+	MethodName    string
+	Tags          []string
+	FileExtension string
+
+	TimeRequested time.Time
+	TimeReceived  time.Time
+
+	// Tabular holds the reading of tabular data.
+	Tabular map[string]interface{}
+	// Binary holds the payload of binary data when `Filter.IncludeBinary` is set.
+	Binary []byte
+	// BinarySize is the size of the binary payload in bytes.
+	BinarySize int
+	MimeType   v1.MimeType
+}
+
+// Query scans the capture directory for readings that match the filter. Readings are returned in
+// the order they were requested in. Readings still buffered in memory by a collector are not
+// returned until they are written to disk.
+func Query(ctx context.Context, captureDir string, filter Filter, logger logging.Logger) ([]Reading, error) {
+	results := &queryResults{limit: filter.Limit}
+	rawCaptureDir := filepath.Join(captureDir, shared.RawCaptureDir)
+	root := captureDir
+	if filter.Raw {
+		root = rawCaptureDir
+	}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			// Files are renamed from .prog to .capture, and deleted once synced, while we walk.
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path == rawCaptureDir && !filter.Raw {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != data.CompletedCaptureFileExt && ext != data.InProgressCaptureFileExt {
+			return nil
+		}
+
+		// A file last written to before the start of the range, or before every reading kept for the
+		// limit, cannot hold a matching reading.
+		if !filter.Start.IsZero() || results.full() {
+			info, err := d.Info()
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if info.ModTime().Before(filter.Start) || !results.wants(info.ModTime()) {
+				return nil
+			}
+		}
+
+		if err := readFile(path, filter, results); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			logger.Warnw("error reading capture file, skipping", "file", path, "error", err)
+			return nil
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results.sorted(), nil
+}
+
+// queryResults holds the readings that matched so far. With a limit, it only holds the most recent
+// readings, in a min-heap ordered by the time they were requested at, so that the memory used by a
+// query is bounded by its limit rather than by the size of the capture directory.
+type queryResults struct {
+	limit    int
+	readings []scannedReading
+	// scanned counts the matching readings, to order readings requested at the same time by when
+	// they were scanned.
+	scanned int
+}
+
+type scannedReading struct {
+	Reading
+	seq int
+}
+
+func (qr *queryResults) full() bool {
+	return qr.limit > 0 && len(qr.readings) >= qr.limit
+}
+
+// wants returns whether a reading requested at timeRequested would be kept.
+func (qr *queryResults) wants(timeRequested time.Time) bool {
+	return !qr.full() || !timeRequested.Before(qr.readings[0].TimeRequested)
+}
+
+func (qr *queryResults) add(reading Reading) {
+	qr.scanned++
+	scanned := scannedReading{Reading: reading, seq: qr.scanned}
+	switch {
+	case qr.limit <= 0:
+		qr.readings = append(qr.readings, scanned)
+	case !qr.full():
+		heap.Push(qr, scanned)
+	default:
+		qr.readings[0] = scanned
+		heap.Fix(qr, 0)
+	}
+}
+
+// sorted returns the readings in the order they were requested in.
+func (qr *queryResults) sorted() []Reading {
+	slices.SortFunc(qr.readings, compareScannedReadings)
+	ret := make([]Reading, len(qr.readings))
+	for idx, scanned := range qr.readings {
+		ret[idx] = scanned.Reading
+	}
+	return ret
+}
+
+func compareScannedReadings(left, right scannedReading) int {
+	if c := left.TimeRequested.Compare(right.TimeRequested); c != 0 {
+		return c
+	}
+	return cmp.Compare(left.seq, right.seq)
+}
+
+// Len, Less, Swap, Push and Pop implement `heap.Interface`.
+func (qr *queryResults) Len() int { return len(qr.readings) }
+
+func (qr *queryResults) Less(i, j int) bool {
+	return compareScannedReadings(qr.readings[i], qr.readings[j]) < 0
+}
+
+func (qr *queryResults) Swap(i, j int) {
+	qr.readings[i], qr.readings[j] = qr.readings[j], qr.readings[i]
+}
+
+func (qr *queryResults) Push(x any) { qr.readings = append(qr.readings, x.(scannedReading)) }
+
+func (qr *queryResults) Pop() any {
+	last := qr.readings[len(qr.readings)-1]
+	qr.readings = qr.readings[:len(qr.readings)-1]
+	return last
+}
+
+// readFile adds the readings of a capture file that match the filter to results.
+func readFile(path string, filter Filter, results *queryResults) error {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	// The file is only read. `CaptureFile.Close` would mark it as completed.
+	//nolint:errcheck
+	defer f.Close()
+
+	captureFile, err := data.ReadCaptureFile(f)
+	if err != nil {
+		return err
+	}
+	md := captureFile.ReadMetadata()
+	if !matches(filter.ComponentType, md.GetComponentType()) ||
+		!matches(filter.ComponentName, md.GetComponentName()) ||
+		!matches(filter.MethodName, md.GetMethodName()) {
+		return nil
+	}
+
+	for {
+		msg, err := captureFile.ReadNextRaw()
+		if err != nil {
+			// A file that is still being written to may end with a partially written reading. It is
+			// skipped, like readings that do not match their checksum.
+			if errors.Is(err, data.ErrCorruptCaptureRecord) {
+				continue
+			}
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return err
+		}
+
+		encoded, err := splitSensorData(msg)
+		if err != nil {
+			return err
+		}
+		// The metadata is decoded first, such that the payload of readings that are filtered out
+		// is never decoded.
+		var sensorMD v1.SensorMetadata
+		if err := proto.Unmarshal(encoded.metadata, &sensorMD); err != nil {
+			return err
+		}
+		timeRequested := sensorMD.GetTimeRequested().AsTime()
+		if !filter.Start.IsZero() && timeRequested.Before(filter.Start) {
+			continue
+		}
+		if !filter.End.IsZero() && !timeRequested.Before(filter.End) {
+			continue
+		}
+		if !results.wants(timeRequested) {
+			continue
+		}
+
+		reading := Reading{
+			ComponentType: md.GetComponentType(),
+			ComponentName: md.GetComponentName(),
+			MethodName:    md.GetMethodName(),
+			Tags:          md.GetTags(),
+			FileExtension: md.GetFileExtension(),
+			TimeRequested: timeRequested,
+			TimeReceived:  sensorMD.GetTimeReceived().AsTime(),
+			MimeType:      sensorMD.GetMimeType(),
+		}
+		if encoded.isTabular {
+			var tabular structpb.Struct
+			if err := proto.Unmarshal(encoded.tabular, &tabular); err != nil {
+				return err
+			}
+			reading.Tabular = tabular.AsMap()
+		} else {
+			reading.BinarySize = len(encoded.binary)
+			if filter.IncludeBinary {
+				reading.Binary = encoded.binary
+			}
+		}
+		results.add(reading)
+	}
+}
+
+// The field numbers of `v1.SensorData`.
+const (
+	sensorDataMetadataField = 1
+	sensorDataStructField   = 2
+	sensorDataBinaryField   = 3
+)
+
+// encodedSensorData holds the still encoded fields of a `v1.SensorData`.
+type encodedSensorData struct {
+	metadata  []byte
+	isTabular bool
+	tabular   []byte
+	binary    []byte
+}
+
+// splitSensorData splits an encoded `v1.SensorData` into its fields without decoding them. The
+// fields reference msg.
+func splitSensorData(msg []byte) (encodedSensorData, error) {
+	var ret encodedSensorData
+	for len(msg) > 0 {
+		num, typ, tagLen := protowire.ConsumeTag(msg)
+		if tagLen < 0 {
+			return ret, protowire.ParseError(tagLen)
+		}
+		msg = msg[tagLen:]
+		if typ != protowire.BytesType {
+			valueLen := protowire.ConsumeFieldValue(num, typ, msg)
+			if valueLen < 0 {
+				return ret, protowire.ParseError(valueLen)
+			}
+			msg = msg[valueLen:]
+			continue
+		}
+		value, valueLen := protowire.ConsumeBytes(msg)
+		if valueLen < 0 {
+			return ret, protowire.ParseError(valueLen)
+		}
+		msg = msg[valueLen:]
+		// A message field may be split over several occurrences, which are merged when decoding.
+		switch num {
+		case sensorDataMetadataField:
+			ret.metadata = append(ret.metadata, value...)
+		case sensorDataStructField:
+			// Only the last field of a oneof is kept when decoding.
+			ret.isTabular = true
+			ret.tabular = append(ret.tabular, value...)
+		case sensorDataBinaryField:
+			ret.isTabular = false
+			ret.binary = value
+		}
+	}
+	return ret, nil
+}
+
+func matches(want, got string) bool {
+	return want == "" || want == got
+}
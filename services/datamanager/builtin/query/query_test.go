@@ -0,0 +1,136 @@
+package query
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/services/datamanager/builtin/shared"
+)
+
+func TestQuery(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	captureDir := t.TempDir()
+	start := time.Date(2024, 9, 24, 18, 0, 0, 0, time.UTC)
+	at := func(seconds int) *timestamppb.Timestamp {
+		return timestamppb.New(start.Add(time.Duration(seconds) * time.Second))
+	}
+
+	writeFile := func(dir string, md *v1.DataCaptureMetadata, readings ...*v1.SensorData) *data.CaptureFile {
+		dir = data.CaptureFilePathWithReplacedReservedChars(dir)
+		test.That(t, os.MkdirAll(dir, 0o700), test.ShouldBeNil)
+		f, err := data.NewCaptureFile(dir, md)
+		test.That(t, err, test.ShouldBeNil)
+		for _, reading := range readings {
+			test.That(t, f.WriteNext(reading), test.ShouldBeNil)
+		}
+		test.That(t, f.Flush(), test.ShouldBeNil)
+		return f
+	}
+	tabular := func(seconds int, value float64) *v1.SensorData {
+		readings, err := structpb.NewStruct(map[string]interface{}{"readings": map[string]interface{}{"temp": value}})
+		test.That(t, err, test.ShouldBeNil)
+		return &v1.SensorData{
+			Metadata: &v1.SensorMetadata{TimeRequested: at(seconds), TimeReceived: at(seconds)},
+			Data:     &v1.SensorData_Struct{Struct: readings},
+		}
+	}
+
+	sensorMD, _ := data.BuildCaptureMetadata(sensor.API, "thermometer", "Readings", nil, nil, []string{"kitchen"})
+	sensorDir := filepath.Join(captureDir, sensor.API.String(), "thermometer", "Readings")
+	// A completed file, and a file that is still being written to.
+	completed := writeFile(sensorDir, sensorMD, tabular(0, 20), tabular(1, 21))
+	test.That(t, completed.Close(), test.ShouldBeNil)
+	inProgress := writeFile(sensorDir, sensorMD, tabular(2, 22), tabular(3, 23))
+	defer inProgress.Close()
+
+	cameraMD, _ := data.BuildCaptureMetadata(camera.API, "cam", "ReadImage", nil, nil, nil)
+	image := writeFile(filepath.Join(captureDir, camera.API.String(), "cam", "ReadImage"), cameraMD, &v1.SensorData{
+		Metadata: &v1.SensorMetadata{TimeRequested: at(1), TimeReceived: at(1), MimeType: v1.MimeType_MIME_TYPE_IMAGE_JPEG},
+		Data:     &v1.SensorData_Binary{Binary: []byte("jpeg")},
+	})
+	defer image.Close()
+
+	// The raw readings of an aggregated capture method.
+	raw := writeFile(filepath.Join(captureDir, shared.RawCaptureDir, sensor.API.String(), "thermometer", "Readings"),
+		sensorMD, tabular(0, 19.5), tabular(0, 20.5), tabular(1, 21))
+	test.That(t, raw.Close(), test.ShouldBeNil)
+
+	t.Run("everything", func(t *testing.T) {
+		readings, err := Query(context.Background(), captureDir, Filter{}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings, test.ShouldHaveLength, 5)
+		for idx := 1; idx < len(readings); idx++ {
+			test.That(t, readings[idx].TimeRequested.Before(readings[idx-1].TimeRequested), test.ShouldBeFalse)
+		}
+	})
+
+	t.Run("by component and time", func(t *testing.T) {
+		readings, err := Query(context.Background(), captureDir, Filter{
+			ComponentName: "thermometer",
+			Start:         start.Add(time.Second),
+			End:           start.Add(3 * time.Second),
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings, test.ShouldHaveLength, 2)
+		test.That(t, readings[0].ComponentType, test.ShouldEqual, sensor.API.String())
+		test.That(t, readings[0].MethodName, test.ShouldEqual, "Readings")
+		test.That(t, readings[0].Tags, test.ShouldResemble, []string{"kitchen"})
+		test.That(t, readings[0].TimeRequested, test.ShouldEqual, start.Add(time.Second))
+		test.That(t, readings[0].Tabular, test.ShouldResemble, map[string]interface{}{"readings": map[string]interface{}{"temp": 21.0}})
+		// The second reading is read from the file still being written to.
+		test.That(t, readings[1].Tabular, test.ShouldResemble, map[string]interface{}{"readings": map[string]interface{}{"temp": 22.0}})
+	})
+
+	t.Run("limit keeps the most recent", func(t *testing.T) {
+		readings, err := Query(context.Background(), captureDir, Filter{MethodName: "Readings", Limit: 1}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings, test.ShouldHaveLength, 1)
+		test.That(t, readings[0].TimeRequested, test.ShouldEqual, start.Add(3*time.Second))
+
+		// The most recent readings are spread over files, and the raw readings requested at the same
+		// time are kept in the order they were written in.
+		readings, err = Query(context.Background(), captureDir, Filter{Limit: 3}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings, test.ShouldHaveLength, 3)
+		test.That(t, readings[0].TimeRequested, test.ShouldEqual, start.Add(time.Second))
+		test.That(t, readings[1].TimeRequested, test.ShouldEqual, start.Add(2*time.Second))
+		test.That(t, readings[2].TimeRequested, test.ShouldEqual, start.Add(3*time.Second))
+
+		readings, err = Query(context.Background(), captureDir, Filter{Raw: true, Limit: 2}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings, test.ShouldHaveLength, 2)
+		test.That(t, readings[0].Tabular["readings"], test.ShouldResemble, map[string]interface{}{"temp": 20.5})
+		test.That(t, readings[1].Tabular["readings"], test.ShouldResemble, map[string]interface{}{"temp": 21.0})
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		readings, err := Query(context.Background(), captureDir, Filter{ComponentType: camera.API.String()}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings, test.ShouldHaveLength, 1)
+		test.That(t, readings[0].BinarySize, test.ShouldEqual, 4)
+		test.That(t, readings[0].Binary, test.ShouldBeNil)
+		test.That(t, readings[0].MimeType, test.ShouldEqual, v1.MimeType_MIME_TYPE_IMAGE_JPEG)
+
+		readings, err = Query(context.Background(), captureDir, Filter{ComponentType: camera.API.String(), IncludeBinary: true}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings[0].Binary, test.ShouldResemble, []byte("jpeg"))
+	})
+	t.Run("raw", func(t *testing.T) {
+		readings, err := Query(context.Background(), captureDir, Filter{Raw: true}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings, test.ShouldHaveLength, 3)
+		test.That(t, readings[0].Tabular, test.ShouldResemble, map[string]interface{}{"readings": map[string]interface{}{"temp": 19.5}})
+	})
+}
@@ -0,0 +1,81 @@
+package builtin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/datamanager/builtin/query"
+)
+
+func TestDoQuery(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	captureDir := t.TempDir()
+	now := time.Now()
+
+	md, _ := data.BuildCaptureMetadata(sensor.API, "thermometer", "Readings", nil, nil, []string{"kitchen"})
+	f, err := data.NewCaptureFile(captureDir, md)
+	test.That(t, err, test.ShouldBeNil)
+	for _, age := range []time.Duration{2 * time.Hour, time.Minute} {
+		readings, err := structpb.NewStruct(map[string]interface{}{"temp": 20})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, f.WriteNext(&v1.SensorData{
+			Metadata: &v1.SensorMetadata{TimeRequested: timestamppb.New(now.Add(-age)), TimeReceived: timestamppb.New(now.Add(-age))},
+			Data:     &v1.SensorData_Struct{Struct: readings},
+		}), test.ShouldBeNil)
+	}
+	test.That(t, f.Close(), test.ShouldBeNil)
+
+	svc := &builtIn{logger: logger, captureDir: captureDir}
+	resp, err := svc.DoCommand(context.Background(), map[string]interface{}{
+		DoQuery: map[string]interface{}{"component_name": "thermometer", "last": "1h", "limit": 10.0},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	// The response must be convertible into a DoCommand response proto.
+	_, err = structpb.NewStruct(resp)
+	test.That(t, err, test.ShouldBeNil)
+	readings := resp[DoQuery].([]interface{})
+	test.That(t, readings, test.ShouldHaveLength, 1)
+	reading := readings[0].(map[string]interface{})
+	test.That(t, reading["component_type"], test.ShouldEqual, sensor.API.String())
+	test.That(t, reading["tags"], test.ShouldResemble, []interface{}{"kitchen"})
+	test.That(t, reading["data"], test.ShouldResemble, map[string]interface{}{"temp": 20.0})
+
+	_, err = svc.DoCommand(context.Background(), map[string]interface{}{"unknown": true})
+	test.That(t, err, test.ShouldEqual, resource.ErrDoUnimplemented)
+}
+
+func TestQueryRequestFilter(t *testing.T) {
+	now := time.Date(2024, 9, 24, 18, 0, 0, 0, time.UTC)
+
+	filter, err := queryRequest{Method: "Readings", End: "2024-09-24T17:30:00Z", Last: "1h"}.filter(now)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, filter, test.ShouldResemble, query.Filter{
+		MethodName: "Readings",
+		Start:      now.Add(-time.Hour),
+		End:        now.Add(-30 * time.Minute),
+		Limit:      defaultQueryLimit,
+	})
+
+	// binary readings are large, so fewer of them are returned by default.
+	filter, err = queryRequest{IncludeBinary: true}.filter(now)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, filter.Limit, test.ShouldEqual, defaultBinaryQueryLimit)
+	filter, err = queryRequest{IncludeBinary: true, Limit: 50}.filter(now)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, filter.Limit, test.ShouldEqual, 50)
+
+	_, err = queryRequest{Start: "2024-09-24T17:00:00Z", Last: "1h"}.filter(now)
+	test.That(t, err, test.ShouldBeError, "only one of start and last may be set")
+	_, err = queryRequest{Start: "yesterday"}.filter(now)
+	test.That(t, err, test.ShouldNotBeNil)
+}
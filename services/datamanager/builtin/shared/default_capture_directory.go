@@ -5,15 +5,19 @@ import (
 	"os"
 	"path/filepath"
 
-	"go.viam.com/rdk/utils"
+	"go.viam.com/rdk/data"
 )
 
 var (
-	// ViamCaptureDotDir is the default directory for capturing and syncing data.
-	ViamCaptureDotDir = filepath.Join(utils.ViamDotDir, "capture")
+	// ViamCaptureDotDir is the default directory for capturing and syncing data, see data.ViamCaptureDotDir.
+	ViamCaptureDotDir = data.ViamCaptureDotDir
 	// OldViamCaptureDotDir is the old default directory for capturing and syncing data.
 	// We will continue syncing data from this directory for backwards compatibility.
 	OldViamCaptureDotDir = filepath.Join(os.Getenv("HOME"), ".viam", "capture")
 	// DefaultCaptureDirChanged is true if the default capture directory has changed since fixing the capture directory location.
 	DefaultCaptureDirChanged = ViamCaptureDotDir != OldViamCaptureDotDir
 )
+
+// RawCaptureDir is a subdirectory of the capture directory that holds the raw readings of the
+// capture methods that aggregate their readings. It is kept on the machine and is never synced.
+const RawCaptureDir = "rawCapture"
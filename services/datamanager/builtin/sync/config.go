@@ -95,6 +95,59 @@ type Config struct {
 	// unil the Readings method of the SelectiveSyncSensor (when called on the SyncIntervalMins interval) returns
 	// the a key of datamanager.ShouldSyncKey and a value of `true`
 	SelectiveSyncSensor sensor.Sensor
+	// Destinations are where data can be synced to instead of the cloud.
+	Destinations []DestinationConfig
+	// DefaultDestination is the name of the destination of files that are not routed by
+	// DestinationsByCaptureMethod, including all arbitrary files. Empty, or `CloudDestination`, is
+	// the cloud.
+	DefaultDestination string
+	// DestinationsByCaptureMethod routes the files of a capture method, keyed by `CaptureMethodKey`,
+	// to the named destination.
+	DestinationsByCaptureMethod map[string]string
+	// RetentionPolicies bound how much captured data of a capture method, keyed by
+	// `CaptureMethodKey`, is kept on disk until it is synced. The raw readings of aggregated capture
+	// methods, which are never synced, are keyed by `RawCaptureMethodKey`. They are enforced while
+	// capture is enabled, along with the deletion of files when the disk is full.
+	RetentionPolicies map[string]RetentionPolicy
+	// TagRetentionPolicies apply to the capture files of capture methods without a retention policy
+	// of their own. The first policy with a tag of a file applies.
+	TagRetentionPolicies []TagRetentionPolicy
+	// SyncWindows are the daily windows of local time during which uploads may run. Uploads may run
+	// at any time when empty.
+	SyncWindows []SyncWindow
+	// UploadRateLimitBytesPerSec bounds the upload bandwidth. Unbounded when zero.
+	UploadRateLimitBytesPerSec int64
+	// MeteredNetworkDetector, when set, reports whether the machine is on a metered network. Uploads
+	// are held back on metered networks unless MeteredUploadRateLimitBytesPerSec is set.
+	MeteredNetworkDetector MeteredNetworkDetector
+	// MeteredUploadRateLimitBytesPerSec bounds the upload bandwidth on metered networks.
+	MeteredUploadRateLimitBytesPerSec int64
+}
+
+// destinationFor returns the name of the destination of the capture files with the given metadata.
+func (c Config) destinationFor(componentType, componentName, methodName string) string {
+	if name, ok := c.DestinationsByCaptureMethod[CaptureMethodKey(componentType, componentName, methodName)]; ok {
+		return name
+	}
+	return c.DefaultDestination
+}
+
+// cloudOnly returns true when all files are synced to the cloud. Otherwise sync runs without a cloud
+// connection, only syncing files to the cloud while connected.
+func (c Config) cloudOnly() bool {
+	if !isCloudDestination(c.DefaultDestination) {
+		return false
+	}
+	for _, name := range c.DestinationsByCaptureMethod {
+		if !isCloudDestination(name) {
+			return false
+		}
+	}
+	return true
+}
+
+func isCloudDestination(name string) bool {
+	return name == "" || name == CloudDestination
 }
 
 // SchedulerEnabled returns true if the sync scheduler should be running.
@@ -121,7 +174,16 @@ func (c Config) Equal(o Config) bool {
 		c.SyncIntervalMins == o.SyncIntervalMins &&
 		reflect.DeepEqual(c.Tags, o.Tags) &&
 		c.SelectiveSyncSensorEnabled == o.SelectiveSyncSensorEnabled &&
-		c.SelectiveSyncSensor == o.SelectiveSyncSensor
+		c.SelectiveSyncSensor == o.SelectiveSyncSensor &&
+		reflect.DeepEqual(c.Destinations, o.Destinations) &&
+		c.DefaultDestination == o.DefaultDestination &&
+		reflect.DeepEqual(c.DestinationsByCaptureMethod, o.DestinationsByCaptureMethod) &&
+		reflect.DeepEqual(c.RetentionPolicies, o.RetentionPolicies) &&
+		reflect.DeepEqual(c.TagRetentionPolicies, o.TagRetentionPolicies) &&
+		reflect.DeepEqual(c.SyncWindows, o.SyncWindows) &&
+		c.UploadRateLimitBytesPerSec == o.UploadRateLimitBytesPerSec &&
+		c.MeteredNetworkDetector == o.MeteredNetworkDetector &&
+		c.MeteredUploadRateLimitBytesPerSec == o.MeteredUploadRateLimitBytesPerSec
 }
 
 func (c *Config) logDiff(o Config, logger logging.Logger) {
@@ -188,6 +250,52 @@ func (c *Config) logDiff(o Config, logger logging.Logger) {
 		}
 		logger.Infof("SelectiveSyncSensor: old: %s, new: %s", oldName, newName)
 	}
+
+	if !reflect.DeepEqual(c.Destinations, o.Destinations) {
+		logger.Infof("sync_destinations: old: %s, new: %s", destinationNames(c.Destinations), destinationNames(o.Destinations))
+	}
+
+	if c.DefaultDestination != o.DefaultDestination {
+		logger.Infof("default_sync_destination: old: %s, new: %s", c.DefaultDestination, o.DefaultDestination)
+	}
+
+	if !reflect.DeepEqual(c.DestinationsByCaptureMethod, o.DestinationsByCaptureMethod) {
+		logger.Infof("sync destinations by capture method: old: %v, new: %v", c.DestinationsByCaptureMethod, o.DestinationsByCaptureMethod)
+	}
+
+	if !reflect.DeepEqual(c.RetentionPolicies, o.RetentionPolicies) {
+		logger.Infof("retention policies by capture method: old: %v, new: %v", c.RetentionPolicies, o.RetentionPolicies)
+	}
+
+	if !reflect.DeepEqual(c.TagRetentionPolicies, o.TagRetentionPolicies) {
+		logger.Infof("tag_retention_policies: old: %v, new: %v", c.TagRetentionPolicies, o.TagRetentionPolicies)
+	}
+
+	if !reflect.DeepEqual(c.SyncWindows, o.SyncWindows) {
+		logger.Infof("sync_windows: old: %v, new: %v", c.SyncWindows, o.SyncWindows)
+	}
+
+	if c.UploadRateLimitBytesPerSec != o.UploadRateLimitBytesPerSec {
+		logger.Infof("upload_rate_limit_bytes_per_sec: old: %d, new: %d", c.UploadRateLimitBytesPerSec, o.UploadRateLimitBytesPerSec)
+	}
+
+	if c.MeteredNetworkDetector != o.MeteredNetworkDetector {
+		logger.Infof("metered network detection: old: %t, new: %t", c.MeteredNetworkDetector != nil, o.MeteredNetworkDetector != nil)
+	}
+
+	if c.MeteredUploadRateLimitBytesPerSec != o.MeteredUploadRateLimitBytesPerSec {
+		logger.Infof("metered_upload_rate_limit_bytes_per_sec: old: %d, new: %d",
+			c.MeteredUploadRateLimitBytesPerSec, o.MeteredUploadRateLimitBytesPerSec)
+	}
+}
+
+// destinationNames does not return the full destination configs, which may hold credentials.
+func destinationNames(destinations []DestinationConfig) string {
+	names := make([]string, 0, len(destinations))
+	for _, destination := range destinations {
+		names = append(names, destination.Name)
+	}
+	return strings.Join(names, " ")
 }
 
 // SyncPaths returns the capture directory and additional sync paths as a slice.
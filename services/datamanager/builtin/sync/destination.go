@@ -0,0 +1,206 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/services/datamanager/builtin/shared"
+)
+
+// CloudDestination is the name of the default sync destination, the Viam app. It may not be used as
+// the name of a configured destination.
+const CloudDestination = "cloud"
+
+// Sync destination types.
+const (
+	// DestinationTypeS3 uploads to an S3 compatible object store, e.g: AWS S3 or MinIO.
+	DestinationTypeS3 = "s3"
+	// DestinationTypeDirectory copies to a directory, e.g: a mounted network share or USB drive.
+	DestinationTypeDirectory = "directory"
+	// DestinationTypeMQTT publishes tabular readings to an MQTT broker.
+	DestinationTypeMQTT = "mqtt"
+)
+
+var (
+	errDestinationUnsupportedBinary    = errors.New("sync destination does not support binary data")
+	errDestinationUnsupportedArbitrary = errors.New("sync destination does not support arbitrary files")
+	// terminalDestinationErrs is the set of errors returned by destinations that will result in
+	// exponential retries stopping to retry and instead moving the file to the failed directory.
+	terminalDestinationErrs = []error{
+		errDestinationUnsupportedBinary,
+		errDestinationUnsupportedArbitrary,
+	}
+)
+
+// Destination is somewhere other than the Viam app that sync hands data off to, e.g: for machines
+// that are air-gapped. A file is deleted once it was uploaded to its destination.
+type Destination interface {
+	// UploadDataCaptureFile uploads the readings of a completed data capture file. It returns the
+	// size of the file.
+	UploadDataCaptureFile(ctx context.Context, f *data.CaptureFile) (uint64, error)
+	// UploadArbitraryFile uploads a file that was not written by data capture. `name` is the path
+	// of the file relative to the sync path it was found in. It returns the size of the file.
+	UploadArbitraryFile(ctx context.Context, f *os.File, name string) (uint64, error)
+	// Close releases the resources held by the destination.
+	Close() error
+}
+
+// DestinationConfig configures a sync destination. Exactly the attributes of its type must be set.
+type DestinationConfig struct {
+	// Name is how capture methods refer to the destination. See `Config.DestinationsByCaptureMethod`.
+	Name string `json:"name"`
+	// Type is one of `s3`, `directory` or `mqtt`.
+	Type      string                      `json:"type"`
+	S3        *S3DestinationConfig        `json:"s3,omitempty"`
+	Directory *DirectoryDestinationConfig `json:"directory,omitempty"`
+	MQTT      *MQTTDestinationConfig      `json:"mqtt,omitempty"`
+}
+
+// Validate returns an error if the destination config is invalid.
+func (c DestinationConfig) Validate() error {
+	if c.Name == "" {
+		return errors.New("sync destination name can't be empty")
+	}
+	if c.Name == CloudDestination {
+		return fmt.Errorf("sync destination name %q is reserved", CloudDestination)
+	}
+	switch c.Type {
+	case DestinationTypeS3:
+		if c.S3 == nil {
+			return fmt.Errorf("sync destination %q is missing its s3 attributes", c.Name)
+		}
+		return c.S3.validate()
+	case DestinationTypeDirectory:
+		if c.Directory == nil {
+			return fmt.Errorf("sync destination %q is missing its directory attributes", c.Name)
+		}
+		return c.Directory.validate()
+	case DestinationTypeMQTT:
+		if c.MQTT == nil {
+			return fmt.Errorf("sync destination %q is missing its mqtt attributes", c.Name)
+		}
+		return c.MQTT.validate()
+	default:
+		return fmt.Errorf("sync destination %q has unknown type %q", c.Name, c.Type)
+	}
+}
+
+// newDestination returns the destination described by the config. It does not connect to the
+// destination, which happens when the first file is uploaded. Destinations on the network throttle
+// the bytes they send with the upload gate.
+func newDestination(c DestinationConfig, gate *uploadGate, logger logging.Logger) (Destination, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	switch c.Type {
+	case DestinationTypeS3:
+		return newS3Destination(*c.S3, gate, logger)
+	case DestinationTypeDirectory:
+		return newDirectoryDestination(*c.Directory, logger), nil
+	default:
+		return newMQTTDestination(*c.MQTT, newPahoPublisher, gate, logger), nil
+	}
+}
+
+// CaptureMethodKey returns the key of a capture method in `Config.DestinationsByCaptureMethod`.
+// The arguments match the component type, component name and method name of the metadata of the
+// capture files written by the capture method.
+func CaptureMethodKey(componentType, componentName, methodName string) string {
+	return path.Join(componentType, componentName, methodName)
+}
+
+// RawCaptureMethodKey returns the key of the raw readings of an aggregated capture method in
+// `Config.RetentionPolicies`.
+func RawCaptureMethodKey(componentType, componentName, methodName string) string {
+	return path.Join(shared.RawCaptureDir, CaptureMethodKey(componentType, componentName, methodName))
+}
+
+// exportedReading is the format readings are exported in by destinations other than the Viam app.
+type exportedReading struct {
+	ComponentType string                 `json:"component_type"`
+	ComponentName string                 `json:"component_name"`
+	Method        string                 `json:"method"`
+	Tags          []string               `json:"tags,omitempty"`
+	TimeRequested time.Time              `json:"time_requested"`
+	TimeReceived  time.Time              `json:"time_received"`
+	Data          map[string]interface{} `json:"data,omitempty"`
+	// File names the binary payload of the reading, relative to the readings file.
+	File     string `json:"file,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+}
+
+// exportedObject is a file written by `exportCaptureFile`.
+type exportedObject struct {
+	name string
+	body []byte
+}
+
+// exportCaptureFile converts a capture file into files that can be read without Viam tooling. The
+// readings are written as newline delimited JSON to a `.jsonl` file. The payload of each binary
+// reading is written to a file of its own, which is referred to by the reading's `file` field.
+// Files are named after the capture file and placed in a `<component type>/<component name>/<method>`
+// directory.
+func exportCaptureFile(f *data.CaptureFile) ([]exportedObject, error) {
+	md := f.ReadMetadata()
+	sensorData, err := data.SensorDataFromCaptureFile(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading sensor data")
+	}
+
+	dir := data.CaptureFilePathWithReplacedReservedChars(
+		path.Join(md.GetComponentType(), md.GetComponentName(), md.GetMethodName()))
+	baseName := strings.TrimSuffix(filepath.Base(f.GetPath()), filepath.Ext(f.GetPath()))
+
+	var objects []exportedObject
+	var readings bytes.Buffer
+	encoder := json.NewEncoder(&readings)
+	for idx, sd := range sensorData {
+		reading := exportedReading{
+			ComponentType: md.GetComponentType(),
+			ComponentName: md.GetComponentName(),
+			Method:        md.GetMethodName(),
+			Tags:          md.GetTags(),
+			TimeRequested: sd.GetMetadata().GetTimeRequested().AsTime(),
+			TimeReceived:  sd.GetMetadata().GetTimeReceived().AsTime(),
+		}
+		if data.IsBinary(sd) {
+			ext := md.GetFileExtension()
+			if mimeType := sd.GetMetadata().GetMimeType(); mimeType != v1.MimeType_MIME_TYPE_UNSPECIFIED {
+				reading.MimeType = mimeType.String()
+				if mimeExt := getFileExtFromMimeType(mimeType); mimeExt != "" {
+					ext = mimeExt
+				}
+			}
+			reading.File = fmt.Sprintf("%s-%d%s", baseName, idx, ext)
+			objects = append(objects, exportedObject{name: path.Join(dir, reading.File), body: sd.GetBinary()})
+		} else {
+			reading.Data = sd.GetStruct().AsMap()
+		}
+		if err := encoder.Encode(reading); err != nil {
+			return nil, err
+		}
+	}
+	return append(objects, exportedObject{name: path.Join(dir, baseName+".jsonl"), body: readings.Bytes()}), nil
+}
+
+// rewind seeks to the start of a file that was not written by data capture. It may have been read
+// from by a previous attempt to upload it.
+func rewind(f *os.File) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return errors.Wrap(err, "error trying to Seek to beginning of file")
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+)
+
+// DirectoryDestinationConfig configures a sync destination that copies files to a directory, e.g: a
+// mounted network share or USB drive.
+type DirectoryDestinationConfig struct {
+	// Path is the directory to copy files to. It must exist, so that sync does not fill up the root
+	// file system if a drive is not mounted.
+	Path string `json:"path"`
+}
+
+func (c DirectoryDestinationConfig) validate() error {
+	if c.Path == "" {
+		return errors.New("directory sync destination path can't be empty")
+	}
+	return nil
+}
+
+type directoryDestination struct {
+	path   string
+	logger logging.Logger
+}
+
+func newDirectoryDestination(c DirectoryDestinationConfig, logger logging.Logger) *directoryDestination {
+	return &directoryDestination{path: c.Path, logger: logger}
+}
+
+func (d *directoryDestination) UploadDataCaptureFile(ctx context.Context, f *data.CaptureFile) (uint64, error) {
+	objects, err := exportCaptureFile(f)
+	if err != nil {
+		return 0, err
+	}
+	for _, object := range objects {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if err := d.writeFile(object.name, func(w io.Writer) error {
+			_, err := w.Write(object.body)
+			return err
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return uint64(f.Size()), nil
+}
+
+func (d *directoryDestination) UploadArbitraryFile(ctx context.Context, f *os.File, name string) (uint64, error) {
+	if err := rewind(f); err != nil {
+		return 0, err
+	}
+	var size int64
+	if err := d.writeFile(name, func(w io.Writer) error {
+		var err error
+		size, err = io.Copy(w, f)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	return uint64(size), nil
+}
+
+func (d *directoryDestination) Close() error {
+	return nil
+}
+
+// writeFile writes a file to the destination directory. The file is written under a temporary name
+// and renamed once it is complete, so that readers of the destination never see a partial file.
+func (d *directoryDestination) writeFile(name string, write func(io.Writer) error) error {
+	if _, err := os.Stat(d.path); err != nil {
+		return errors.Wrapf(err, "directory sync destination %s is not available", d.path)
+	}
+	target := filepath.Join(d.path, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+		return err
+	}
+
+	//nolint:gosec
+	tmp, err := os.OpenFile(target+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := write(tmp); err != nil {
+		//nolint:errcheck,gosec
+		tmp.Close()
+		return err
+	}
+	// Removable drives are often unplugged without being unmounted.
+	if err := tmp.Sync(); err != nil {
+		//nolint:errcheck,gosec
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
@@ -0,0 +1,196 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+)
+
+const (
+	defaultMQTTTopicPrefix = "viam"
+	defaultMQTTQoS         = 1
+	mqttConnectTimeout     = 10 * time.Second
+)
+
+// MQTTDestinationConfig configures a sync destination that publishes tabular readings to an MQTT
+// broker. Each reading is published as a JSON message to the topic
+// `<topic prefix>/<component type>/<component name>/<method>`.
+type MQTTDestinationConfig struct {
+	// Broker is the URL of the broker, e.g: `tcp://broker.local:1883` or `ssl://broker.local:8883`.
+	Broker string `json:"broker"`
+	// TopicPrefix defaults to `viam`.
+	TopicPrefix string `json:"topic_prefix,omitempty"`
+	// ClientID defaults to a random ID.
+	ClientID string `json:"client_id,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// QoS defaults to 1, at least once. Readings may be published more than once if an upload is
+	// retried.
+	QoS *int `json:"qos,omitempty"`
+}
+
+func (c MQTTDestinationConfig) validate() error {
+	if c.Broker == "" {
+		return errors.New("mqtt sync destination broker can't be empty")
+	}
+	if c.QoS != nil && (*c.QoS < 0 || *c.QoS > 2) {
+		return errors.New("mqtt sync destination qos must be 0, 1 or 2")
+	}
+	return nil
+}
+
+// mqttPublisher publishes messages to an MQTT broker.
+type mqttPublisher interface {
+	Publish(ctx context.Context, topic string, qos byte, payload []byte) error
+	Close()
+}
+
+type mqttDestination struct {
+	config       MQTTDestinationConfig
+	newPublisher func(context.Context, MQTTDestinationConfig) (mqttPublisher, error)
+	gate         *uploadGate
+	logger       logging.Logger
+
+	mu        sync.Mutex
+	publisher mqttPublisher
+}
+
+func newMQTTDestination(
+	c MQTTDestinationConfig,
+	newPublisher func(context.Context, MQTTDestinationConfig) (mqttPublisher, error),
+	gate *uploadGate,
+	logger logging.Logger,
+) *mqttDestination {
+	if c.TopicPrefix == "" {
+		c.TopicPrefix = defaultMQTTTopicPrefix
+	}
+	if c.QoS == nil {
+		qos := defaultMQTTQoS
+		c.QoS = &qos
+	}
+	return &mqttDestination{config: c, newPublisher: newPublisher, gate: gate, logger: logger}
+}
+
+// UploadDataCaptureFile publishes each reading of a tabular capture file. Binary capture files are
+// not supported.
+func (d *mqttDestination) UploadDataCaptureFile(ctx context.Context, f *data.CaptureFile) (uint64, error) {
+	md := f.ReadMetadata()
+	sensorData, err := data.SensorDataFromCaptureFile(f)
+	if err != nil {
+		return 0, errors.Wrap(err, "error reading sensor data")
+	}
+	for _, sd := range sensorData {
+		if data.IsBinary(sd) {
+			return 0, errDestinationUnsupportedBinary
+		}
+	}
+	publisher, err := d.connect(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	topic := path.Join(d.config.TopicPrefix, md.GetComponentType(), md.GetComponentName(), md.GetMethodName())
+	for _, sd := range sensorData {
+		payload, err := json.Marshal(exportedReading{
+			ComponentType: md.GetComponentType(),
+			ComponentName: md.GetComponentName(),
+			Method:        md.GetMethodName(),
+			Tags:          md.GetTags(),
+			TimeRequested: sd.GetMetadata().GetTimeRequested().AsTime(),
+			TimeReceived:  sd.GetMetadata().GetTimeReceived().AsTime(),
+			Data:          sd.GetStruct().AsMap(),
+		})
+		if err != nil {
+			return 0, err
+		}
+		if err := d.gate.waitForBandwidth(ctx, len(payload)); err != nil {
+			return 0, err
+		}
+		//nolint:gosec
+		if err := publisher.Publish(ctx, topic, byte(*d.config.QoS), payload); err != nil {
+			return 0, errors.Wrapf(err, "failed to publish to %s", topic)
+		}
+	}
+	return uint64(f.Size()), nil
+}
+
+// UploadArbitraryFile is not supported, MQTT messages are not meant to hold whole files.
+func (d *mqttDestination) UploadArbitraryFile(context.Context, *os.File, string) (uint64, error) {
+	return 0, errDestinationUnsupportedArbitrary
+}
+
+func (d *mqttDestination) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.publisher != nil {
+		d.publisher.Close()
+		d.publisher = nil
+	}
+	return nil
+}
+
+// connect returns the publisher, connecting to the broker on first use.
+func (d *mqttDestination) connect(ctx context.Context) (mqttPublisher, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.publisher != nil {
+		return d.publisher, nil
+	}
+	publisher, err := d.newPublisher(ctx, d.config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to mqtt broker %s", d.config.Broker)
+	}
+	d.publisher = publisher
+	return publisher, nil
+}
+
+// pahoPublisher publishes with the Eclipse Paho client, which reconnects on its own once connected.
+type pahoPublisher struct {
+	client mqtt.Client
+}
+
+func newPahoPublisher(ctx context.Context, c MQTTDestinationConfig) (mqttPublisher, error) {
+	clientID := c.ClientID
+	if clientID == "" {
+		clientID = "viam-datamanager-" + uuid.NewString()
+	}
+	opts := mqtt.NewClientOptions().
+		AddBroker(c.Broker).
+		SetClientID(clientID).
+		SetUsername(c.Username).
+		SetPassword(c.Password).
+		SetConnectTimeout(mqttConnectTimeout).
+		SetAutoReconnect(true)
+	client := mqtt.NewClient(opts)
+	if err := waitForToken(ctx, client.Connect()); err != nil {
+		return nil, err
+	}
+	return &pahoPublisher{client: client}, nil
+}
+
+func (p *pahoPublisher) Publish(ctx context.Context, topic string, qos byte, payload []byte) error {
+	return waitForToken(ctx, p.client.Publish(topic, qos, false, payload))
+}
+
+func (p *pahoPublisher) Close() {
+	p.client.Disconnect(uint(time.Second.Milliseconds()))
+}
+
+func waitForToken(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-token.Done():
+		return token.Error()
+	}
+}
@@ -0,0 +1,135 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+)
+
+// defaultS3Region is used when an S3 sync destination does not set a region. S3 compatible stores,
+// e.g: MinIO, generally accept any region.
+const defaultS3Region = "us-east-1"
+
+// unsignedPayload leaves the bodies of uploads out of their signatures, such that they are only read,
+// and charged to the bandwidth limits of the upload gate, as they are sent.
+var unsignedPayload = s3.WithAPIOptions(v4.SwapComputePayloadSHA256ForUnsignedPayloadMiddleware)
+
+// S3DestinationConfig configures a sync destination that uploads files to an S3 compatible object
+// store.
+type S3DestinationConfig struct {
+	Bucket string `json:"bucket"`
+	// Prefix is prepended to the key of every uploaded object.
+	Prefix string `json:"prefix,omitempty"`
+	// Endpoint is the URL of an S3 compatible store, e.g: `http://minio.local:9000`. AWS S3 is used
+	// when it is empty.
+	Endpoint string `json:"endpoint,omitempty"`
+	// Region defaults to `us-east-1`.
+	Region string `json:"region,omitempty"`
+	// ForcePathStyle addresses buckets as `<endpoint>/<bucket>` rather than `<bucket>.<endpoint>`.
+	// Most S3 compatible stores require it.
+	ForcePathStyle bool `json:"force_path_style,omitempty"`
+	// AccessKeyID and SecretAccessKey are static credentials. When they are not set, credentials are
+	// read from the environment like the AWS CLI does.
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+}
+
+func (c S3DestinationConfig) validate() error {
+	if c.Bucket == "" {
+		return errors.New("s3 sync destination bucket can't be empty")
+	}
+	if (c.AccessKeyID == "") != (c.SecretAccessKey == "") {
+		return errors.New("s3 sync destination must set both or neither of access_key_id and secret_access_key")
+	}
+	return nil
+}
+
+type s3Destination struct {
+	bucket string
+	prefix string
+	client *s3.Client
+	gate   *uploadGate
+	logger logging.Logger
+}
+
+func newS3Destination(c S3DestinationConfig, gate *uploadGate, logger logging.Logger) (*s3Destination, error) {
+	region := c.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+	loadOptions := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if c.AccessKeyID != "" {
+		loadOptions = append(loadOptions, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(c.AccessKeyID, c.SecretAccessKey, "")))
+	}
+	awsConfig, err := config.LoadDefaultConfig(context.Background(), loadOptions...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load s3 config")
+	}
+	client := s3.NewFromConfig(awsConfig, func(o *s3.Options) {
+		o.UsePathStyle = c.ForcePathStyle
+		if c.Endpoint != "" {
+			o.BaseEndpoint = aws.String(c.Endpoint)
+		}
+		// Many S3 compatible stores reject the checksums AWS S3 accepts on every upload.
+		o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+	})
+	return &s3Destination{
+		bucket: c.Bucket,
+		prefix: c.Prefix,
+		client: client,
+		gate:   gate,
+		logger: logger,
+	}, nil
+}
+
+func (d *s3Destination) UploadDataCaptureFile(ctx context.Context, f *data.CaptureFile) (uint64, error) {
+	objects, err := exportCaptureFile(f)
+	if err != nil {
+		return 0, err
+	}
+	for _, object := range objects {
+		if _, err := d.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(d.bucket),
+			Key:    aws.String(path.Join(d.prefix, object.name)),
+			Body:   d.gate.reader(ctx, bytes.NewReader(object.body)),
+		}, unsignedPayload); err != nil {
+			return 0, errors.Wrapf(err, "failed to upload %s to s3", object.name)
+		}
+	}
+	return uint64(f.Size()), nil
+}
+
+func (d *s3Destination) UploadArbitraryFile(ctx context.Context, f *os.File, name string) (uint64, error) {
+	if err := rewind(f); err != nil {
+		return 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return 0, errors.Wrap(err, "stat failed")
+	}
+	if _, err := d.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(d.bucket),
+		Key:           aws.String(path.Join(d.prefix, name)),
+		Body:          d.gate.reader(ctx, f),
+		ContentLength: aws.Int64(info.Size()),
+	}, unsignedPayload); err != nil {
+		return 0, errors.Wrapf(err, "failed to upload %s to s3", name)
+	}
+	return uint64(info.Size()), nil
+}
+
+func (d *s3Destination) Close() error {
+	return nil
+}
@@ -0,0 +1,426 @@
+package sync
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var destinationTestTime = time.Date(2024, 11, 18, 20, 37, 0, 0, time.UTC)
+
+// writeTestCaptureFile writes a completed capture file to dir and returns its path.
+func writeTestCaptureFile(t *testing.T, dir string, api resource.API, method string, sensorData ...*v1.SensorData) string {
+	t.Helper()
+	md, _ := data.BuildCaptureMetadata(api, "my-resource", method, nil, nil, []string{"tag"})
+	w, err := data.NewCaptureFile(dir, md)
+	test.That(t, err, test.ShouldBeNil)
+	for _, sd := range sensorData {
+		test.That(t, w.WriteNext(sd), test.ShouldBeNil)
+	}
+	test.That(t, w.Close(), test.ShouldBeNil)
+	return strings.TrimSuffix(w.GetPath(), data.InProgressCaptureFileExt) + data.CompletedCaptureFileExt
+}
+
+func tabularSensorData(t *testing.T, value float64) *v1.SensorData {
+	t.Helper()
+	readings, err := structpb.NewStruct(map[string]interface{}{"readings": map[string]interface{}{"a": value}})
+	test.That(t, err, test.ShouldBeNil)
+	return &v1.SensorData{
+		Metadata: &v1.SensorMetadata{
+			TimeRequested: timestamppb.New(destinationTestTime),
+			TimeReceived:  timestamppb.New(destinationTestTime.Add(time.Millisecond)),
+		},
+		Data: &v1.SensorData_Struct{Struct: readings},
+	}
+}
+
+func binarySensorData(payload []byte) *v1.SensorData {
+	return &v1.SensorData{
+		Metadata: &v1.SensorMetadata{
+			TimeRequested: timestamppb.New(destinationTestTime),
+			TimeReceived:  timestamppb.New(destinationTestTime),
+			MimeType:      v1.MimeType_MIME_TYPE_IMAGE_JPEG,
+		},
+		Data: &v1.SensorData_Binary{Binary: payload},
+	}
+}
+
+func openCaptureFile(t *testing.T, path string) *data.CaptureFile {
+	t.Helper()
+	//nolint:gosec
+	f, err := os.Open(path)
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(func() { f.Close() })
+	captureFile, err := data.ReadCaptureFile(f)
+	test.That(t, err, test.ShouldBeNil)
+	return captureFile
+}
+
+func readExportedReadings(t *testing.T, r io.Reader) []exportedReading {
+	t.Helper()
+	var readings []exportedReading
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var reading exportedReading
+		test.That(t, json.Unmarshal(scanner.Bytes(), &reading), test.ShouldBeNil)
+		readings = append(readings, reading)
+	}
+	test.That(t, scanner.Err(), test.ShouldBeNil)
+	return readings
+}
+
+func TestDirectoryDestination(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	captureDir := t.TempDir()
+	destinationDir := t.TempDir()
+	destination := newDirectoryDestination(DirectoryDestinationConfig{Path: destinationDir}, logger)
+
+	t.Run("tabular capture files are written as json lines", func(t *testing.T) {
+		path := writeTestCaptureFile(t, captureDir, sensor.API, "Readings", tabularSensorData(t, 1), tabularSensorData(t, 2))
+		_, err := destination.UploadDataCaptureFile(ctx, openCaptureFile(t, path))
+		test.That(t, err, test.ShouldBeNil)
+
+		baseName := strings.TrimSuffix(filepath.Base(path), data.CompletedCaptureFileExt)
+		//nolint:gosec
+		f, err := os.Open(filepath.Join(destinationDir, "rdk_component_sensor", "my-resource", "Readings", baseName+".jsonl"))
+		test.That(t, err, test.ShouldBeNil)
+		defer f.Close()
+		readings := readExportedReadings(t, f)
+		test.That(t, readings, test.ShouldHaveLength, 2)
+		test.That(t, readings[1].ComponentType, test.ShouldEqual, "rdk:component:sensor")
+		test.That(t, readings[1].Tags, test.ShouldResemble, []string{"tag"})
+		test.That(t, readings[1].TimeRequested.Equal(destinationTestTime), test.ShouldBeTrue)
+		test.That(t, readings[1].Data, test.ShouldResemble, map[string]interface{}{"readings": map[string]interface{}{"a": 2.0}})
+	})
+
+	t.Run("binary payloads are written to files of their own", func(t *testing.T) {
+		path := writeTestCaptureFile(t, captureDir, camera.API, "ReadImage", binarySensorData([]byte("jpeg bytes")))
+		_, err := destination.UploadDataCaptureFile(ctx, openCaptureFile(t, path))
+		test.That(t, err, test.ShouldBeNil)
+
+		dir := filepath.Join(destinationDir, "rdk_component_camera", "my-resource", "ReadImage")
+		baseName := strings.TrimSuffix(filepath.Base(path), data.CompletedCaptureFileExt)
+		//nolint:gosec
+		f, err := os.Open(filepath.Join(dir, baseName+".jsonl"))
+		test.That(t, err, test.ShouldBeNil)
+		defer f.Close()
+		readings := readExportedReadings(t, f)
+		test.That(t, readings, test.ShouldHaveLength, 1)
+		test.That(t, readings[0].File, test.ShouldEqual, baseName+"-0.jpeg")
+		test.That(t, readings[0].MimeType, test.ShouldEqual, v1.MimeType_MIME_TYPE_IMAGE_JPEG.String())
+
+		payload, err := os.ReadFile(filepath.Join(dir, readings[0].File))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(payload), test.ShouldEqual, "jpeg bytes")
+	})
+
+	t.Run("arbitrary files are copied", func(t *testing.T) {
+		path := filepath.Join(captureDir, "logs.txt")
+		test.That(t, os.WriteFile(path, []byte("some logs"), 0o600), test.ShouldBeNil)
+		//nolint:gosec
+		f, err := os.Open(path)
+		test.That(t, err, test.ShouldBeNil)
+		defer f.Close()
+
+		// A previous attempt may have read part of the file.
+		_, err = f.Read(make([]byte, 4))
+		test.That(t, err, test.ShouldBeNil)
+		size, err := destination.UploadArbitraryFile(ctx, f, "some/dir/logs.txt")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, size, test.ShouldEqual, 9)
+
+		contents, err := os.ReadFile(filepath.Join(destinationDir, "some", "dir", "logs.txt"))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(contents), test.ShouldEqual, "some logs")
+	})
+
+	t.Run("a missing directory is retried", func(t *testing.T) {
+		unmounted := newDirectoryDestination(DirectoryDestinationConfig{Path: filepath.Join(destinationDir, "unmounted")}, logger)
+		path := writeTestCaptureFile(t, captureDir, sensor.API, "Readings", tabularSensorData(t, 1))
+		_, err := unmounted.UploadDataCaptureFile(ctx, openCaptureFile(t, path))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, terminalError(err), test.ShouldBeFalse)
+	})
+}
+
+func TestS3Destination(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	// A stand-in for an S3 compatible object store which stores the objects that are put into it.
+	var mu sync.Mutex
+	objects := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		if !strings.Contains(r.Header.Get("Authorization"), "Credential=access-key/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		objects[r.URL.Path] = string(body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	destinationConfig := DestinationConfig{
+		Name: "minio",
+		Type: DestinationTypeS3,
+		S3: &S3DestinationConfig{
+			Bucket:          "my-bucket",
+			Prefix:          "site-a",
+			Endpoint:        server.URL,
+			ForcePathStyle:  true,
+			AccessKeyID:     "access-key",
+			SecretAccessKey: "secret-key",
+		},
+	}
+	captureDir := t.TempDir()
+	upload := func(t *testing.T, ctx context.Context, destination Destination, logs string) {
+		t.Helper()
+		path := writeTestCaptureFile(t, captureDir, sensor.API, "Readings", tabularSensorData(t, 1))
+		_, err := destination.UploadDataCaptureFile(ctx, openCaptureFile(t, path))
+		test.That(t, err, test.ShouldBeNil)
+
+		arbitraryPath := filepath.Join(captureDir, "logs.txt")
+		test.That(t, os.WriteFile(arbitraryPath, []byte(logs), 0o600), test.ShouldBeNil)
+		//nolint:gosec
+		f, err := os.Open(arbitraryPath)
+		test.That(t, err, test.ShouldBeNil)
+		defer f.Close()
+		_, err = destination.UploadArbitraryFile(ctx, f, "logs.txt")
+		test.That(t, err, test.ShouldBeNil)
+
+		mu.Lock()
+		defer mu.Unlock()
+		baseName := strings.TrimSuffix(filepath.Base(path), data.CompletedCaptureFileExt)
+		readings := readExportedReadings(t,
+			strings.NewReader(objects["/my-bucket/site-a/rdk_component_sensor/my-resource/Readings/"+baseName+".jsonl"]))
+		test.That(t, readings, test.ShouldHaveLength, 1)
+		test.That(t, readings[0].Data, test.ShouldResemble, map[string]interface{}{"readings": map[string]interface{}{"a": 1.0}})
+		test.That(t, objects["/my-bucket/site-a/logs.txt"], test.ShouldEqual, logs)
+	}
+
+	t.Run("uploads objects", func(t *testing.T) {
+		destination, err := newDestination(destinationConfig, nil, logger)
+		test.That(t, err, test.ShouldBeNil)
+		defer destination.Close()
+		upload(t, ctx, destination, "some logs")
+		test.That(t, objects, test.ShouldHaveLength, 2)
+	})
+
+	t.Run("uploads are throttled by the bytes sent", func(t *testing.T) {
+		// The clock never advances, so the uploads only succeed within the first second of bandwidth,
+		// which is not enough to read their bodies twice.
+		gate := newUploadGate(Config{UploadRateLimitBytesPerSec: int64(UploadChunkSize)}, clock.NewMock(), logger)
+		destination, err := newDestination(destinationConfig, gate, logger)
+		test.That(t, err, test.ShouldBeNil)
+		defer destination.Close()
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		upload(t, ctx, destination, strings.Repeat("l", UploadChunkSize*3/4))
+	})
+}
+
+type publishedMessage struct {
+	topic   string
+	qos     byte
+	payload []byte
+}
+
+type fakeMQTTPublisher struct {
+	messages []publishedMessage
+	closed   bool
+}
+
+func (p *fakeMQTTPublisher) Publish(_ context.Context, topic string, qos byte, payload []byte) error {
+	p.messages = append(p.messages, publishedMessage{topic: topic, qos: qos, payload: payload})
+	return nil
+}
+
+func (p *fakeMQTTPublisher) Close() {
+	p.closed = true
+}
+
+func TestMQTTDestination(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	captureDir := t.TempDir()
+
+	publisher := &fakeMQTTPublisher{}
+	var connects int
+	destination := newMQTTDestination(MQTTDestinationConfig{Broker: "tcp://broker:1883"},
+		func(context.Context, MQTTDestinationConfig) (mqttPublisher, error) {
+			connects++
+			return publisher, nil
+		}, nil, logger)
+
+	t.Run("tabular readings are published", func(t *testing.T) {
+		path := writeTestCaptureFile(t, captureDir, sensor.API, "Readings", tabularSensorData(t, 1), tabularSensorData(t, 2))
+		_, err := destination.UploadDataCaptureFile(ctx, openCaptureFile(t, path))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, connects, test.ShouldEqual, 1)
+		test.That(t, publisher.messages, test.ShouldHaveLength, 2)
+		test.That(t, publisher.messages[1].topic, test.ShouldEqual, "viam/rdk:component:sensor/my-resource/Readings")
+		test.That(t, publisher.messages[1].qos, test.ShouldEqual, 1)
+
+		var reading exportedReading
+		test.That(t, json.Unmarshal(publisher.messages[1].payload, &reading), test.ShouldBeNil)
+		test.That(t, reading.Data, test.ShouldResemble, map[string]interface{}{"readings": map[string]interface{}{"a": 2.0}})
+	})
+
+	t.Run("binary readings and arbitrary files are not supported", func(t *testing.T) {
+		path := writeTestCaptureFile(t, captureDir, camera.API, "ReadImage", binarySensorData([]byte("jpeg bytes")))
+		_, err := destination.UploadDataCaptureFile(ctx, openCaptureFile(t, path))
+		test.That(t, terminalError(err), test.ShouldBeTrue)
+
+		_, err = destination.UploadArbitraryFile(ctx, nil, "logs.txt")
+		test.That(t, terminalError(err), test.ShouldBeTrue)
+		test.That(t, publisher.messages, test.ShouldHaveLength, 2)
+	})
+
+	test.That(t, destination.Close(), test.ShouldBeNil)
+	test.That(t, publisher.closed, test.ShouldBeTrue)
+}
+
+func TestSyncToDestination(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	captureDir := t.TempDir()
+	destinationDir := t.TempDir()
+
+	s := New(NoOpCloudClientConstructor, func() {}, clock.New(), logger)
+	defer s.Close()
+	config := Config{
+		CaptureDir:   captureDir,
+		Destinations: []DestinationConfig{{Name: "usb", Type: DestinationTypeDirectory, Directory: &DirectoryDestinationConfig{Path: destinationDir}}},
+		DestinationsByCaptureMethod: map[string]string{
+			CaptureMethodKey(sensor.API.String(), "my-resource", "Readings"): "usb",
+		},
+	}
+	test.That(t, config.cloudOnly(), test.ShouldBeFalse)
+	s.destinations = newDestinations(config, nil, logger)
+
+	// The capture method routed to the destination is synced without a cloud connection.
+	routedPath := writeTestCaptureFile(t, captureDir, sensor.API, "Readings", tabularSensorData(t, 1))
+	s.syncFile(config, routedPath)
+	_, err := os.Stat(routedPath)
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+	exported, err := os.ReadDir(filepath.Join(destinationDir, "rdk_component_sensor", "my-resource", "Readings"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, exported, test.ShouldHaveLength, 1)
+	test.That(t, s.GetStats().Upload.TabularSensorUploadedFileCount, test.ShouldEqual, 1)
+
+	// Other capture methods go to the cloud, and are kept until there is a cloud connection.
+	cloudPath := writeTestCaptureFile(t, captureDir, camera.API, "ReadImage", binarySensorData([]byte("jpeg bytes")))
+	s.syncFile(config, cloudPath)
+	_, err = os.Stat(cloudPath)
+	test.That(t, err, test.ShouldBeNil)
+
+	// Arbitrary files go to the default destination.
+	config.DefaultDestination = "usb"
+	arbitraryPath := filepath.Join(captureDir, "some", "dir", "logs.txt")
+	test.That(t, os.MkdirAll(filepath.Dir(arbitraryPath), 0o700), test.ShouldBeNil)
+	test.That(t, os.WriteFile(arbitraryPath, []byte("some logs"), 0o600), test.ShouldBeNil)
+	s.syncFile(config, arbitraryPath)
+	_, err = os.Stat(arbitraryPath)
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+	contents, err := os.ReadFile(filepath.Join(destinationDir, "some", "dir", "logs.txt"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(contents), test.ShouldEqual, "some logs")
+}
+
+func TestSyncWhileUploadsAreHeldBack(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	captureDir := t.TempDir()
+	destinationDir := t.TempDir()
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Outside of the sync window, uploads to the network are held back.
+	clk := clock.NewMock()
+	clk.Set(time.Date(2024, 11, 18, 12, 0, 0, 0, time.Local))
+	window, err := ParseSyncWindow("01:00", "05:00")
+	test.That(t, err, test.ShouldBeNil)
+	config := Config{
+		CaptureDir:            captureDir,
+		MaximumNumSyncThreads: 1,
+		SyncWindows:           []SyncWindow{window},
+		Destinations: []DestinationConfig{
+			{Name: "usb", Type: DestinationTypeDirectory, Directory: &DirectoryDestinationConfig{Path: destinationDir}},
+			{Name: "minio", Type: DestinationTypeS3, S3: &S3DestinationConfig{
+				Bucket:          "my-bucket",
+				Endpoint:        server.URL,
+				ForcePathStyle:  true,
+				AccessKeyID:     "access-key",
+				SecretAccessKey: "secret-key",
+			}},
+		},
+		DestinationsByCaptureMethod: map[string]string{
+			CaptureMethodKey(sensor.API.String(), "my-resource", "Readings"):  "usb",
+			CaptureMethodKey(camera.API.String(), "my-resource", "ReadImage"): "minio",
+		},
+	}
+	s := New(NoOpCloudClientConstructor, func() {}, clk, logger)
+	defer s.Close()
+	s.uploadGate = newUploadGate(config, clk, logger)
+	s.destinations = newDestinations(config, s.uploadGate, logger)
+	s.startWorkers(config)
+
+	networkPath := writeTestCaptureFile(t, captureDir, camera.API, "ReadImage", binarySensorData([]byte("jpeg bytes")))
+	directoryPath := writeTestCaptureFile(t, captureDir, sensor.API, "Readings", tabularSensorData(t, 1))
+
+	// The single worker is handed the file routed to the network first, and must still take the file
+	// routed to the directory.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.sendToSync(ctx, networkPath)
+	s.sendToSync(ctx, directoryPath)
+	test.That(t, ctx.Err(), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		_, err := os.Stat(directoryPath)
+		test.That(tb, os.IsNotExist(err), test.ShouldBeTrue)
+	})
+
+	// The file routed to the network is kept until uploads are allowed.
+	_, err = os.Stat(networkPath)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, requests.Load(), test.ShouldEqual, 0)
+}
@@ -3,6 +3,7 @@ package sync
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -28,6 +29,10 @@ const (
 	maxRetryInterval       = time.Hour
 )
 
+// errUploadHeldBack is returned by retries which stop as the upload gate holds back uploads. The
+// file is kept, and synced once uploads are allowed again.
+var errUploadHeldBack = errors.New("upload held back")
+
 type uploadFunc func(context.Context) (uint64, error)
 
 func newExponentialRetry(
@@ -35,6 +40,7 @@ func newExponentialRetry(
 	clock clock.Clock,
 	logger logging.Logger,
 	name string,
+	gate *uploadGate,
 	fun uploadFunc,
 ) exponentialRetry {
 	return exponentialRetry{
@@ -42,6 +48,7 @@ func newExponentialRetry(
 		clock:  clock,
 		logger: logger,
 		name:   name,
+		gate:   gate,
 		fun:    fun,
 	}
 }
@@ -51,7 +58,9 @@ type exponentialRetry struct {
 	clock  clock.Clock
 	logger logging.Logger
 	name   string
-	fun    uploadFunc
+	// gate stops the retry when it holds back uploads.
+	gate *uploadGate
+	fun  uploadFunc
 }
 
 // run calls fn and retries with exponentially increasing waits from initialWait to a
@@ -60,7 +69,7 @@ type exponentialRetry struct {
 // returns context.Cancelled if ctx is cancelled
 // all other errors are due to an unrecoverable error.
 func (e exponentialRetry) run() (uint64, error) {
-	bytesUploaded, err := e.fun(e.ctx)
+	bytesUploaded, err := e.attempt()
 
 	// If no error, return nil for success
 	if err == nil {
@@ -74,9 +83,9 @@ func (e exponentialRetry) run() (uint64, error) {
 		return 0, err
 	}
 
-	// If the context was cancelled
+	// If the context was cancelled, or uploads are held back,
 	// return the error without logging to not spam
-	if errors.Is(err, context.Canceled) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, errUploadHeldBack) {
 		return 0, err
 	}
 
@@ -98,7 +107,7 @@ func (e exponentialRetry) run() (uint64, error) {
 		case <-e.ctx.Done():
 			return 0, e.ctx.Err()
 		case <-ticker.C:
-			bytesUploaded, err := e.fun(e.ctx)
+			bytesUploaded, err := e.attempt()
 
 			// If no error, return nil for success
 			if err == nil {
@@ -106,9 +115,9 @@ func (e exponentialRetry) run() (uint64, error) {
 				return bytesUploaded, nil
 			}
 
-			// If the context was cancelled
+			// If the context was cancelled, or uploads are held back,
 			// return the error without logging to not spam
-			if errors.Is(err, context.Canceled) {
+			if errors.Is(err, context.Canceled) || errors.Is(err, errUploadHeldBack) {
 				return 0, err
 			}
 
@@ -144,6 +153,15 @@ func (e exponentialRetry) run() (uint64, error) {
 	}
 }
 
+// attempt calls fun, unless the upload gate holds back uploads.
+func (e exponentialRetry) attempt() (uint64, error) {
+	if reason := e.gate.closedReason(e.ctx); reason != "" {
+		e.logger.Debugf("not uploading %s as the machine is %s", e.name, reason)
+		return 0, fmt.Errorf("%w: the machine is %s", errUploadHeldBack, reason)
+	}
+	return e.fun(e.ctx)
+}
+
 func isOfflineGRPCError(err error) bool {
 	errStatus := status.Convert(err)
 	return errStatus.Code() == codes.Unavailable
@@ -177,5 +195,10 @@ func terminalError(err error) bool {
 			return true
 		}
 	}
+	for _, e := range terminalDestinationErrs {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
 	return false
 }
@@ -27,9 +27,11 @@ func deleteExcessFilesOnSchedule(
 	deleteEveryNth int,
 	diskUsageThreshold float64,
 	captureDirThreshold float64,
+	policies retentionPolicies,
 	clock clock.Clock,
 	logger logging.Logger,
 	deletedFileCount *atomic.Int64,
+	retentionDeletedFileCount *atomic.Int64,
 ) {
 	if runtime.GOOS == "android" {
 		logger.Debug("file deletion if disk is full is not currently supported on Android")
@@ -46,9 +48,16 @@ func deleteExcessFilesOnSchedule(
 		case <-ctx.Done():
 			return
 		case <-t.C:
+			count, err := enforceRetention(ctx, fileTracker, captureDir, policies, clock.Now(), logger)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				logger.Errorw("error enforcing capture retention policies", "error", err)
+			}
+			retentionDeletedFileCount.Add(int64(count))
 			maybeDeleteExcessFiles(
-				ctx, fileTracker, captureDir, deleteEveryNth, diskUsageThreshold, captureDirThreshold, clock, logger, deletedFileCount,
+				ctx, fileTracker, captureDir, deleteEveryNth, diskUsageThreshold, captureDirThreshold, policies, clock, logger, deletedFileCount,
 			)
+			// Every file whose policy matters was looked up by the passes above.
+			policies.tags.prune()
 		}
 	}
 }
@@ -60,6 +69,7 @@ func maybeDeleteExcessFiles(
 	deleteEveryNth int,
 	diskUsageThreshold float64,
 	captureDirThreshold float64,
+	policies retentionPolicies,
 	clock clock.Clock,
 	logger logging.Logger,
 	deletedFileCount *atomic.Int64,
@@ -75,6 +85,23 @@ func maybeDeleteExcessFiles(
 		logger.Error("captureDir partition has size zero")
 		return
 	}
+
+	// Other components, e.g: FTDC, may keep files on the same disk that are less valuable than
+	// captured data. Have them free up space before deciding whether to delete captured data.
+	if 1.0-usage.AvailablePercent() >= diskUsageThreshold {
+		freedBytes, err := diskusage.Reclaim(ctx, captureDir)
+		if err != nil {
+			logger.Warnw("error reclaiming disk space from other components", "error", err)
+		}
+		if freedBytes > 0 {
+			logger.Infof("reclaimed %d bytes of disk space from other components", freedBytes)
+			if usage, err = diskusage.Statfs(captureDir); err != nil {
+				logger.Error(errors.Wrap(err, "error checking file system stats"))
+				return
+			}
+		}
+	}
+
 	count, err := deleteExcessFiles(
 		ctx,
 		fileTracker,
@@ -83,6 +110,7 @@ func maybeDeleteExcessFiles(
 		deleteEveryNth,
 		diskUsageThreshold,
 		captureDirThreshold,
+		policies,
 		logger)
 
 	duration := clock.Since(start)
@@ -105,6 +133,7 @@ func deleteExcessFiles(
 	deleteEveryNth int,
 	diskUsageThreshold float64,
 	captureDirToFSThreshold float64,
+	policies retentionPolicies,
 	logger logging.Logger,
 ) (int, error) {
 	shouldDelete, err := shouldDeleteBasedOnDiskUsage(
@@ -123,7 +152,7 @@ func deleteExcessFiles(
 	}
 
 	logger.Warnf("current disk usage of the data capture directory exceeds threshold (%f)", captureDirToFSThreshold)
-	return deleteFiles(ctx, fileTracker, deleteEveryNth, captureDir, logger)
+	return deleteFiles(ctx, fileTracker, deleteEveryNth, captureDir, policies, logger)
 }
 
 func shouldDeleteBasedOnDiskUsage(
@@ -205,16 +234,29 @@ func exceedsDeletionThreshold(
 	return false, nil
 }
 
+// deleteFiles deletes every nth completed capture file. If capture methods have retention
+// priorities, only the files of the lowest priority are considered.
 func deleteFiles(
 	ctx context.Context,
 	fileTracker *fileTracker,
 	deleteEveryNth int,
 	captureDirPath string,
+	policies retentionPolicies,
 	logger logging.Logger,
 ) (int, error) {
+	lowestPriority := RetentionPriorityNormal
+	if policies.hasPriorities() {
+		var err error
+		if lowestPriority, err = lowestPriorityOfFiles(ctx, captureDirPath, policies); err != nil {
+			return 0, err
+		}
+		logger.Infof("Deleting every %dth file of retention priority %d", deleteEveryNth, lowestPriority)
+	} else {
+		logger.Infof("Deleting every %dth file", deleteEveryNth)
+	}
+
 	index := 0
 	deletedFileCount := 0
-	logger.Infof("Deleting every %dth file", deleteEveryNth)
 	fileDeletion := func(path string, d fs.DirEntry, err error) error {
 		if ctx.Err() != nil {
 			return ctx.Err()
@@ -240,7 +282,8 @@ func deleteFiles(
 			}
 			return err
 		}
-		isCompletedDataCaptureFile := strings.Contains(fileInfo.Name(), data.CompletedCaptureFileExt)
+		isCompletedDataCaptureFile := strings.Contains(fileInfo.Name(), data.CompletedCaptureFileExt) &&
+			policies.forFile(path).Priority == lowestPriority
 		// if at nth file and the file is not currently being written, mark as in progress if possible
 		if isCompletedDataCaptureFile && index%deleteEveryNth == 0 {
 			if !fileTracker.markInProgress(path) {
@@ -264,3 +307,28 @@ func deleteFiles(
 	err := filepath.WalkDir(captureDirPath, fileDeletion)
 	return deletedFileCount, err
 }
+
+// lowestPriorityOfFiles returns the lowest retention priority of the completed capture files.
+func lowestPriorityOfFiles(ctx context.Context, captureDirPath string, policies retentionPolicies) (RetentionPriority, error) {
+	lowestPriority := RetentionPriorityHigh
+	err := filepath.WalkDir(captureDirPath, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.Contains(d.Name(), data.CompletedCaptureFileExt) {
+			return nil
+		}
+		lowestPriority = min(lowestPriority, policies.forFile(path).Priority)
+		if lowestPriority == RetentionPriorityLow {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return lowestPriority, err
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"sync/atomic"
 	"testing"
 
+	"github.com/benbjohnson/clock"
 	"go.viam.com/test"
 
 	"go.viam.com/rdk/logging"
@@ -73,7 +75,7 @@ func TestFileDeletion(t *testing.T) {
 			if tc.shouldCancelContext {
 				cancelFunc()
 			}
-			deletedFileCount, err := deleteFiles(ctx, ft, 5, tempCaptureDir, logger)
+			deletedFileCount, err := deleteFiles(ctx, ft, 5, tempCaptureDir, retentionPolicies{}, logger)
 			if tc.shouldCancelContext {
 				test.That(t, err, test.ShouldBeError, context.Canceled)
 			} else {
@@ -134,6 +136,45 @@ func TestFileDeletionUsageCheck(t *testing.T) {
 	}
 }
 
+type fakeReclaimer struct {
+	dir        string
+	reclaimCnt int
+	freedBytes int64
+}
+
+func (fr *fakeReclaimer) ReclaimDir() string {
+	return fr.dir
+}
+
+func (fr *fakeReclaimer) Reclaim(ctx context.Context) (int64, error) {
+	fr.reclaimCnt++
+	return fr.freedBytes, nil
+}
+
+func TestFileDeletionReclaimsFirst(t *testing.T) {
+	tempCaptureDir := t.TempDir()
+	writeFiles(t, tempCaptureDir, []string{"0.capture", "1.capture"})
+	logger := logging.NewTestLogger(t)
+
+	reclaimer := &fakeReclaimer{dir: t.TempDir(), freedBytes: 100}
+	diskusage.RegisterReclaimer(reclaimer)
+	defer diskusage.UnregisterReclaimer(reclaimer)
+
+	var deletedFileCount atomic.Int64
+	// The disk is always "full". Captured data is never the cause, so only the reclaimer is asked
+	// to free up space.
+	maybeDeleteExcessFiles(context.Background(), newFileTracker(), tempCaptureDir, 1,
+		math.SmallestNonzeroFloat64, 1.0, retentionPolicies{}, clock.NewMock(), logger, &deletedFileCount)
+	test.That(t, reclaimer.reclaimCnt, test.ShouldEqual, 1)
+	test.That(t, deletedFileCount.Load(), test.ShouldEqual, 0)
+	test.That(t, getFileNames(t, tempCaptureDir), test.ShouldHaveLength, 2)
+
+	// The disk is not full. The reclaimer is left alone.
+	maybeDeleteExcessFiles(context.Background(), newFileTracker(), tempCaptureDir, 1,
+		1.0, 1.0, retentionPolicies{}, clock.NewMock(), logger, &deletedFileCount)
+	test.That(t, reclaimer.reclaimCnt, test.ShouldEqual, 1)
+}
+
 func writeFiles(t *testing.T, dir string, filenames []string) map[string]string {
 	t.Helper()
 	fileContents := []byte("never gonna let you down")
@@ -0,0 +1,83 @@
+package sync
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+)
+
+// RecoverCaptureDir salvages the in progress capture files left in captureDir when the robot lost
+// power, or was killed, while writing them. Each of them is replaced by a completed file holding its
+// intact readings, so that they are synced. Files that can't be repaired are moved to the failed
+// directory. It must only be called when no collector is writing to captureDir. It returns the
+// number of files that were recovered.
+func RecoverCaptureDir(ctx context.Context, captureDir string, logger logging.Logger) (int, error) {
+	paths, err := InProgressCaptureFiles(ctx, captureDir)
+	if err != nil {
+		return 0, err
+	}
+	return RecoverCaptureFiles(ctx, captureDir, paths, logger)
+}
+
+// InProgressCaptureFiles returns the in progress capture files in captureDir. When called before
+// collectors write to captureDir, they are the files left behind by a crash, which can be recovered
+// with RecoverCaptureFiles while the collectors run.
+func InProgressCaptureFiles(ctx context.Context, captureDir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(captureDir, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == FailedDir || d.Name() == DatasetDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) == data.InProgressCaptureFileExt {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// RecoverCaptureFiles salvages the given in progress capture files of captureDir, see
+// RecoverCaptureDir. It returns the number of files that were recovered.
+func RecoverCaptureFiles(ctx context.Context, captureDir string, paths []string, logger logging.Logger) (int, error) {
+	recoveredFileCount := 0
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			return recoveredFileCount, ctx.Err()
+		}
+		report, err := data.RepairCaptureFile(path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err := moveFailedData(path, captureDir, err, logger); err != nil {
+				logger.Error(err)
+			}
+			continue
+		}
+		recoveredFileCount++
+		if report.Damaged() {
+			logger.Warnf("recovered capture file %s: %d intact readings, %d corrupt readings, %d truncated bytes",
+				path, report.Readings, report.CorruptReadings, report.TruncatedBytes)
+		} else {
+			logger.Infof("recovered capture file %s: %d intact readings", path, report.Readings)
+		}
+	}
+	return recoveredFileCount, nil
+}
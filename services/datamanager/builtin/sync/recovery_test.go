@@ -0,0 +1,56 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+)
+
+func TestRecoverCaptureDir(t *testing.T) {
+	captureDir := t.TempDir()
+	methodDir := filepath.Join(captureDir, "rdk_component_sensor", "sensor-1", "Readings")
+	failedDir := filepath.Join(captureDir, FailedDir)
+	test.That(t, os.MkdirAll(methodDir, 0o700), test.ShouldBeNil)
+	test.That(t, os.MkdirAll(failedDir, 0o700), test.ShouldBeNil)
+
+	// An in progress file which lost its last reading to a power cut.
+	md, _ := data.BuildCaptureMetadata(sensor.API, "sensor-1", "Readings", nil, nil, nil)
+	f, err := data.NewCaptureFile(methodDir, md)
+	test.That(t, err, test.ShouldBeNil)
+	readings, err := structpb.NewStruct(map[string]interface{}{"a": 1})
+	test.That(t, err, test.ShouldBeNil)
+	for i := 0; i < 3; i++ {
+		test.That(t, f.WriteNext(&v1.SensorData{Metadata: &v1.SensorMetadata{}, Data: &v1.SensorData_Struct{Struct: readings}}),
+			test.ShouldBeNil)
+	}
+	test.That(t, f.Flush(), test.ShouldBeNil)
+	truncatedPath := f.GetPath()
+	test.That(t, os.Truncate(truncatedPath, f.Size()-1), test.ShouldBeNil)
+
+	// Files which aren't capture files can't be repaired.
+	writeFiles(t, methodDir, []string{"unreadable.prog"})
+	// Files which failed to sync are left alone.
+	writeFiles(t, failedDir, []string{"failed.prog"})
+
+	recoveredFileCount, err := RecoverCaptureDir(context.Background(), captureDir, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, recoveredFileCount, test.ShouldEqual, 1)
+
+	completedPath := truncatedPath[:len(truncatedPath)-len(data.InProgressCaptureFileExt)] + data.CompletedCaptureFileExt
+	test.That(t, getFileNames(t, methodDir), test.ShouldResemble, []string{filepath.Base(completedPath)})
+	recovered, err := data.SensorDataFromCaptureFilePath(completedPath)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, recovered, test.ShouldHaveLength, 2)
+	test.That(t, getFileNames(t, failedDir), test.ShouldResemble, []string{"failed.prog", "rdk_component_sensor"})
+	test.That(t, getFileNames(t, filepath.Join(failedDir, "rdk_component_sensor", "sensor-1", "Readings")),
+		test.ShouldResemble, []string{"unreadable.prog"})
+}
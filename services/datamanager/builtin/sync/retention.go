@@ -0,0 +1,273 @@
+package sync
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+)
+
+// RetentionPriority orders captured data by how valuable it is. When the disk is full, data of lower
+// priority is deleted first. The zero value is the normal priority.
+type RetentionPriority int
+
+// Retention priorities.
+const (
+	RetentionPriorityLow    RetentionPriority = -1
+	RetentionPriorityNormal RetentionPriority = 0
+	RetentionPriorityHigh   RetentionPriority = 1
+)
+
+// RetentionPolicy bounds how much captured data is kept on disk until it is synced. Zero valued
+// fields are unbounded.
+type RetentionPolicy struct {
+	// MaxAge deletes completed capture files once they were last written to this long ago.
+	MaxAge time.Duration
+	// MaxBytes bounds the total size of the completed capture files of a capture method. The
+	// oldest files are deleted first.
+	MaxBytes int64
+	// Priority orders the deletion of capture files when the disk is full.
+	Priority RetentionPriority
+}
+
+// TagRetentionPolicy applies a retention policy to the capture files tagged with `Tag`.
+type TagRetentionPolicy struct {
+	Tag string
+	RetentionPolicy
+}
+
+// retentionPolicies resolves the retention policy of capture files.
+type retentionPolicies struct {
+	// byDir maps the directories capture methods write to, to their retention policy.
+	byDir map[string]RetentionPolicy
+	// byTag applies to files written by capture methods without a retention policy of their own. The
+	// first policy with a tag of the file applies.
+	byTag []TagRetentionPolicy
+	// tags caches the tags of files for byTag. Without it, the tags are read each time.
+	tags *fileTagCache
+}
+
+// newRetentionPolicies returns the retention policies of the config.
+func newRetentionPolicies(config Config) retentionPolicies {
+	policies := retentionPolicies{byTag: config.TagRetentionPolicies, tags: newFileTagCache()}
+	if len(config.RetentionPolicies) > 0 {
+		policies.byDir = map[string]RetentionPolicy{}
+	}
+	for key, policy := range config.RetentionPolicies {
+		// Matches the directory data capture writes to, see `capture.targetDir`.
+		dir := data.CaptureFilePathWithReplacedReservedChars(filepath.Join(config.CaptureDir, filepath.FromSlash(key)))
+		policies.byDir[dir] = policy
+	}
+	return policies
+}
+
+func (rp retentionPolicies) empty() bool {
+	return len(rp.byDir) == 0 && len(rp.byTag) == 0
+}
+
+// hasPriorities returns true if any policy has a priority other than the normal priority.
+func (rp retentionPolicies) hasPriorities() bool {
+	for _, policy := range rp.byDir {
+		if policy.Priority != RetentionPriorityNormal {
+			return true
+		}
+	}
+	for _, policy := range rp.byTag {
+		if policy.Priority != RetentionPriorityNormal {
+			return true
+		}
+	}
+	return false
+}
+
+// forFile returns the retention policy of a completed capture file. Tag policies require the
+// metadata of the file, which is only read the first time.
+func (rp retentionPolicies) forFile(path string) RetentionPolicy {
+	if policy, ok := rp.byDir[filepath.Dir(path)]; ok {
+		return policy
+	}
+	if len(rp.byTag) == 0 {
+		return RetentionPolicy{}
+	}
+	var tags []string
+	var err error
+	if rp.tags != nil {
+		tags, err = rp.tags.get(path)
+	} else {
+		tags, err = captureFileTags(path)
+	}
+	if err != nil {
+		return RetentionPolicy{}
+	}
+	for _, policy := range rp.byTag {
+		if slices.Contains(tags, policy.Tag) {
+			return policy.RetentionPolicy
+		}
+	}
+	return RetentionPolicy{}
+}
+
+func captureFileTags(path string) ([]string, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	// The file is only read. `CaptureFile.Close` would rename it.
+	//nolint:errcheck
+	defer f.Close()
+	captureFile, err := data.ReadCaptureFile(f)
+	if err != nil {
+		return nil, err
+	}
+	return captureFile.ReadMetadata().GetTags(), nil
+}
+
+// fileTagCache caches the tags of completed capture files, which never change once the files are
+// completed, such that retention does not read the metadata of every file each time it runs.
+type fileTagCache struct {
+	mu   sync.Mutex
+	tags map[string][]string
+	// used holds the files whose tags were looked up since the last prune.
+	used map[string]struct{}
+}
+
+func newFileTagCache() *fileTagCache {
+	return &fileTagCache{tags: map[string][]string{}, used: map[string]struct{}{}}
+}
+
+func (c *fileTagCache) get(path string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.used[path] = struct{}{}
+	if tags, ok := c.tags[path]; ok {
+		return tags, nil
+	}
+	tags, err := captureFileTags(path)
+	if err != nil {
+		return nil, err
+	}
+	c.tags[path] = tags
+	return tags, nil
+}
+
+// prune drops the files whose tags were not looked up since the last prune, e.g: because they were
+// synced and deleted.
+func (c *fileTagCache) prune() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for path := range c.tags {
+		if _, ok := c.used[path]; !ok {
+			delete(c.tags, path)
+		}
+	}
+	clear(c.used)
+}
+
+// retentionGroup is the capture files of a capture method that share a retention policy.
+type retentionGroup struct {
+	dir    string
+	policy RetentionPolicy
+}
+
+// enforceRetention deletes the completed capture files that are older, or over the size budget,
+// allowed by their retention policy. Files that are being synced, and files that failed to sync,
+// are left alone. It returns the number of files deleted.
+func enforceRetention(
+	ctx context.Context,
+	fileTracker *fileTracker,
+	captureDir string,
+	policies retentionPolicies,
+	now time.Time,
+	logger logging.Logger,
+) (int, error) {
+	if policies.empty() {
+		return 0, nil
+	}
+
+	groups := map[retentionGroup][]fs.FileInfo{}
+	err := filepath.WalkDir(captureDir, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == FailedDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isCompletedCaptureFile(path) {
+			return nil
+		}
+		policy := policies.forFile(path)
+		if policy.MaxAge <= 0 && policy.MaxBytes <= 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			// The file was synced, and deleted, while walking.
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		group := retentionGroup{dir: filepath.Dir(path), policy: policy}
+		groups[group] = append(groups[group], info)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	deletedFileCount := 0
+	for group, files := range groups {
+		// Newest first, so that the oldest files are over the size budget.
+		slices.SortFunc(files, func(left, right fs.FileInfo) int {
+			return right.ModTime().Compare(left.ModTime())
+		})
+		var totalBytes int64
+		for _, file := range files {
+			totalBytes += file.Size()
+			tooOld := group.policy.MaxAge > 0 && now.Sub(file.ModTime()) > group.policy.MaxAge
+			overMaxBytes := group.policy.MaxBytes > 0 && totalBytes > group.policy.MaxBytes
+			if !tooOld && !overMaxBytes {
+				continue
+			}
+
+			path := filepath.Join(group.dir, file.Name())
+			if !fileTracker.markInProgress(path) {
+				logger.Debugw("Tried to mark file as in progress but lock already held", "file", file.Name())
+				continue
+			}
+			err := os.Remove(path)
+			fileTracker.unmarkInProgress(path)
+			if err != nil {
+				if !errors.Is(err, fs.ErrNotExist) {
+					logger.Warnw("error deleting file", "error", err)
+				}
+				continue
+			}
+			logger.Infow("deleted capture file due to its retention policy",
+				"file", path, "too_old", tooOld, "over_max_bytes", overMaxBytes)
+			deletedFileCount++
+		}
+	}
+	return deletedFileCount, nil
+}
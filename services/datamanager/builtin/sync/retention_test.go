@@ -0,0 +1,158 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+)
+
+func TestNewRetentionPolicies(t *testing.T) {
+	policy := RetentionPolicy{MaxBytes: 10, Priority: RetentionPriorityLow}
+	policies := newRetentionPolicies(Config{
+		CaptureDir:        "/tmp/capture",
+		RetentionPolicies: map[string]RetentionPolicy{CaptureMethodKey("rdk:component:camera", "cam", "ReadImage"): policy},
+	})
+	test.That(t, policies.forFile("/tmp/capture/rdk_component_camera/cam/ReadImage/a.capture"), test.ShouldResemble, policy)
+	test.That(t, policies.forFile("/tmp/capture/rdk_component_arm/arm/JointPositions/a.capture"),
+		test.ShouldResemble, RetentionPolicy{})
+	test.That(t, policies.hasPriorities(), test.ShouldBeTrue)
+	test.That(t, retentionPolicies{}.empty(), test.ShouldBeTrue)
+}
+
+func TestRetentionTagCache(t *testing.T) {
+	captureDir := t.TempDir()
+	policy := RetentionPolicy{MaxAge: time.Minute}
+	policies := newRetentionPolicies(Config{
+		CaptureDir:           captureDir,
+		TagRetentionPolicies: []TagRetentionPolicy{{Tag: "tag", RetentionPolicy: policy}},
+	})
+	tagged := writeTestCaptureFile(t, captureDir, sensor.API, "Readings", tabularSensorData(t, 1))
+	test.That(t, policies.forFile(tagged), test.ShouldResemble, policy)
+
+	// The tags are not read again, even though the file can't be read anymore.
+	test.That(t, os.WriteFile(tagged, []byte("not a capture file"), 0o600), test.ShouldBeNil)
+	test.That(t, policies.forFile(tagged), test.ShouldResemble, policy)
+
+	// Files which were not looked up since the last prune are dropped from the cache.
+	policies.tags.prune()
+	test.That(t, policies.tags.tags, test.ShouldContainKey, tagged)
+	policies.tags.prune()
+	test.That(t, policies.tags.tags, test.ShouldBeEmpty)
+	test.That(t, policies.forFile(tagged), test.ShouldResemble, RetentionPolicy{})
+}
+
+func TestEnforceRetention(t *testing.T) {
+	now := time.Now()
+	logger := logging.NewTestLogger(t)
+
+	// writeAgedFiles writes the files, the first being the oldest, each an hour apart.
+	writeAgedFiles := func(t *testing.T, dir string, filenames []string) {
+		t.Helper()
+		filePaths := writeFiles(t, dir, filenames)
+		for i, filename := range filenames {
+			modTime := now.Add(-time.Duration(len(filenames)-i) * time.Hour)
+			test.That(t, os.Chtimes(filePaths[filename], modTime, modTime), test.ShouldBeNil)
+		}
+	}
+
+	t.Run("deletes files older than the max age", func(t *testing.T) {
+		captureDir := t.TempDir()
+		methodDir := filepath.Join(captureDir, "method")
+		test.That(t, os.Mkdir(methodDir, 0o700), test.ShouldBeNil)
+		writeAgedFiles(t, methodDir, []string{"0.capture", "1.capture", "2.capture", "3.prog"})
+
+		policies := retentionPolicies{byDir: map[string]RetentionPolicy{methodDir: {MaxAge: 2*time.Hour + time.Minute}}}
+		deletedFileCount, err := enforceRetention(context.Background(), newFileTracker(), captureDir, policies, now, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, deletedFileCount, test.ShouldEqual, 2)
+		test.That(t, getFileNames(t, methodDir), test.ShouldResemble, []string{"2.capture", "3.prog"})
+	})
+
+	t.Run("deletes the oldest files over the max bytes", func(t *testing.T) {
+		captureDir := t.TempDir()
+		methodDir := filepath.Join(captureDir, "method")
+		otherDir := filepath.Join(captureDir, "other")
+		test.That(t, os.Mkdir(methodDir, 0o700), test.ShouldBeNil)
+		test.That(t, os.Mkdir(otherDir, 0o700), test.ShouldBeNil)
+		writeAgedFiles(t, methodDir, []string{"0.capture", "1.capture", "2.capture"})
+		writeAgedFiles(t, otherDir, []string{"0.capture", "1.capture", "2.capture"})
+
+		fileSize := int64(len("never gonna let you down"))
+		policies := retentionPolicies{byDir: map[string]RetentionPolicy{methodDir: {MaxBytes: 2 * fileSize}}}
+		deletedFileCount, err := enforceRetention(context.Background(), newFileTracker(), captureDir, policies, now, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, deletedFileCount, test.ShouldEqual, 1)
+		test.That(t, getFileNames(t, methodDir), test.ShouldResemble, []string{"1.capture", "2.capture"})
+		test.That(t, getFileNames(t, otherDir), test.ShouldHaveLength, 3)
+	})
+
+	t.Run("leaves files in progress and failed files alone", func(t *testing.T) {
+		captureDir := t.TempDir()
+		failedDir := filepath.Join(captureDir, FailedDir)
+		test.That(t, os.Mkdir(failedDir, 0o700), test.ShouldBeNil)
+		writeAgedFiles(t, captureDir, []string{"0.capture", "1.capture"})
+		writeAgedFiles(t, failedDir, []string{"0.capture"})
+
+		ft := newFileTracker()
+		ft.markInProgress(filepath.Join(captureDir, "0.capture"))
+		policies := retentionPolicies{byDir: map[string]RetentionPolicy{
+			captureDir: {MaxAge: time.Minute},
+			failedDir:  {MaxAge: time.Minute},
+		}}
+		deletedFileCount, err := enforceRetention(context.Background(), ft, captureDir, policies, now, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, deletedFileCount, test.ShouldEqual, 1)
+		test.That(t, getFileNames(t, failedDir), test.ShouldHaveLength, 1)
+	})
+
+	t.Run("applies tag policies to files with the tag", func(t *testing.T) {
+		captureDir := t.TempDir()
+		tagged := writeTestCaptureFile(t, captureDir, sensor.API, "Readings", tabularSensorData(t, 1))
+		writeAgedFiles(t, captureDir, []string{"untagged.capture"})
+		test.That(t, os.Chtimes(tagged, now.Add(-time.Hour), now.Add(-time.Hour)), test.ShouldBeNil)
+
+		policies := retentionPolicies{byTag: []TagRetentionPolicy{
+			{Tag: "other", RetentionPolicy: RetentionPolicy{MaxAge: 2 * time.Hour}},
+			{Tag: "tag", RetentionPolicy: RetentionPolicy{MaxAge: time.Minute}},
+		}}
+		deletedFileCount, err := enforceRetention(context.Background(), newFileTracker(), captureDir, policies, now, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, deletedFileCount, test.ShouldEqual, 1)
+		test.That(t, getFileNames(t, captureDir), test.ShouldResemble, []string{"untagged.capture"})
+	})
+}
+
+func TestFileDeletionByPriority(t *testing.T) {
+	captureDir := t.TempDir()
+	lowDir := filepath.Join(captureDir, "low")
+	highDir := filepath.Join(captureDir, "high")
+	test.That(t, os.Mkdir(lowDir, 0o700), test.ShouldBeNil)
+	test.That(t, os.Mkdir(highDir, 0o700), test.ShouldBeNil)
+	writeFiles(t, lowDir, []string{"0.capture", "1.capture", "2.capture", "3.capture"})
+	writeFiles(t, highDir, []string{"0.capture", "1.capture", "2.capture", "3.capture"})
+	logger := logging.NewTestLogger(t)
+
+	policies := retentionPolicies{byDir: map[string]RetentionPolicy{
+		lowDir:  {Priority: RetentionPriorityLow},
+		highDir: {Priority: RetentionPriorityHigh},
+	}}
+	deletedFileCount, err := deleteFiles(context.Background(), newFileTracker(), 2, captureDir, policies, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deletedFileCount, test.ShouldEqual, 2)
+	test.That(t, getFileNames(t, lowDir), test.ShouldResemble, []string{"1.capture", "3.capture"})
+	test.That(t, getFileNames(t, highDir), test.ShouldHaveLength, 4)
+
+	// Once the low priority files are gone, the high priority files are thinned.
+	test.That(t, os.RemoveAll(lowDir), test.ShouldBeNil)
+	deletedFileCount, err = deleteFiles(context.Background(), newFileTracker(), 2, captureDir, policies, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deletedFileCount, test.ShouldEqual, 2)
+	test.That(t, getFileNames(t, highDir), test.ShouldHaveLength, 2)
+}
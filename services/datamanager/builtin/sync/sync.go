@@ -27,6 +27,7 @@ import (
 	"go.viam.com/rdk/internal/cloud"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/services/datamanager"
+	"go.viam.com/rdk/services/datamanager/builtin/shared"
 	"go.viam.com/rdk/utils"
 )
 
@@ -64,6 +65,7 @@ type dataTypeUploadStats struct {
 // FTDCStats represents upload and deleted file metric values for a given moment. Returned by Sync.GetStats().
 type FTDCStats struct {
 	FilesDeletedToFreeSpace int64
+	FilesDeletedByRetention int64
 	Upload                  FTDCUploadStats
 }
 
@@ -107,6 +109,8 @@ type Sync struct {
 	clock             clock.Clock
 	uploadStats       *uploadStats
 	deletedFileCount  atomic.Int64
+	// retentionDeletedFileCount counts the files deleted by retention policies.
+	retentionDeletedFileCount atomic.Int64
 
 	configMu sync.Mutex
 	config   Config
@@ -115,6 +119,12 @@ type Sync struct {
 	configCancelFunc func()
 
 	cloudConn cloudConn
+	// destinations are the configured destinations other than the cloud, by name. They are only
+	// replaced while the workers are stopped.
+	destinations map[string]Destination
+	// uploadGate holds back uploads to the network outside of the sync windows, and on metered
+	// networks. It is only replaced while the workers are stopped, under configMu.
+	uploadGate *uploadGate
 
 	Scheduler        *goutils.StoppableWorkers
 	cloudConnManager *goutils.StoppableWorkers
@@ -187,7 +197,10 @@ func (s *Sync) Reconfigure(_ context.Context, config Config, cloudConnSvc cloud.
 	// update config
 	s.configMu.Lock()
 	s.config = config
+	s.uploadGate = newUploadGate(config, s.clock, s.logger)
 	s.configMu.Unlock()
+	s.closeDestinations()
+	s.destinations = newDestinations(config, s.uploadGate, s.logger)
 	// reset config context
 	s.configCtx, s.configCancelFunc = context.WithCancel(context.Background())
 
@@ -220,9 +233,11 @@ func (s *Sync) Reconfigure(_ context.Context, config Config, cloudConnSvc cloud.
 				config.DeleteEveryNthWhenDiskFull,
 				config.DiskUsageDeletionThreshold,
 				config.CaptureDirDeletionThreshold,
+				newRetentionPolicies(config),
 				s.clock,
 				s.logger,
 				&s.deletedFileCount,
+				&s.retentionDeletedFileCount,
 			)
 		})
 	}
@@ -233,6 +248,7 @@ func (s *Sync) GetStats() FTDCStats {
 	return FTDCStats{
 		// File deletion metric.
 		FilesDeletedToFreeSpace: s.deletedFileCount.Load(),
+		FilesDeletedByRetention: s.retentionDeletedFileCount.Load(),
 
 		Upload: FTDCUploadStats{
 			// Upload metrics - arbitrary files.
@@ -261,6 +277,7 @@ func (s *Sync) Close() {
 	s.FileDeletingWorkers.Stop()
 	s.Scheduler.Stop()
 	s.workersWg.Wait()
+	s.closeDestinations()
 	if s.cloudConnManager != nil {
 		s.cloudConnManager.Stop()
 	}
@@ -278,17 +295,83 @@ func (s *Sync) CloudConnReady() chan struct{} {
 // If automated sync is also enabled, calling Sync will upload the files,
 // regardless of whether or not is the scheduled time.
 func (s *Sync) Sync(ctx context.Context, _ map[string]interface{}) error {
-	select {
-	case <-s.cloudConn.ready:
-	default:
-		return errors.New("not connected to the cloud")
-	}
 	s.configMu.Lock()
 	config := s.config
+	gate := s.uploadGate
 	s.configMu.Unlock()
+	if config.cloudOnly() {
+		select {
+		case <-s.cloudConn.ready:
+		default:
+			return errors.New("not connected to the cloud")
+		}
+		if reason := gate.closedReason(ctx); reason != "" {
+			return errors.Errorf("not syncing as the machine is %s", reason)
+		}
+	}
 	return s.walkDirsAndSendFilesToSync(ctx, config)
 }
 
+// cloudReady returns true if files can be synced to the cloud.
+func (s *Sync) cloudReady() bool {
+	select {
+	case <-s.cloudConn.ready:
+		return s.cloudConn.conn.GetState() == connectivity.Ready
+	default:
+		return false
+	}
+}
+
+// newDestinations returns the destinations of the config. Destinations with an invalid config are
+// left out, the files routed to them are kept until the config is fixed.
+func newDestinations(config Config, gate *uploadGate, logger logging.Logger) map[string]Destination {
+	destinations := map[string]Destination{}
+	for _, destinationConfig := range config.Destinations {
+		destination, err := newDestination(destinationConfig, gate, logger.Sublogger(destinationConfig.Name))
+		if err != nil {
+			logger.Errorw("invalid sync destination, files routed to it will not be synced", "error", err)
+			continue
+		}
+		destinations[destinationConfig.Name] = destination
+	}
+	return destinations
+}
+
+func (s *Sync) closeDestinations() {
+	for name, destination := range s.destinations {
+		if err := destination.Close(); err != nil {
+			s.logger.Warnw("error closing sync destination", "destination", name, "error", err)
+		}
+	}
+	s.destinations = nil
+}
+
+// destination returns the destination with the given name, or nil for the cloud. It returns false
+// if files may not be synced to the destination at this time.
+func (s *Sync) destination(config Config, name, filePath string) (Destination, bool) {
+	var destination Destination
+	if isCloudDestination(name) {
+		// When all files go to the cloud the scheduler only runs while connected.
+		if !config.cloudOnly() && !s.cloudReady() {
+			return nil, false
+		}
+	} else {
+		var ok bool
+		destination, ok = s.destinations[name]
+		if !ok {
+			s.logger.Warnw("not syncing file routed to unknown sync destination", "file", filePath, "destination", name)
+			return nil, false
+		}
+	}
+	// Files routed to the network are skipped while uploads are held back, instead of tying up a
+	// sync worker until the gate opens, and are synced on a later tick.
+	if reason := s.gateFor(destination).closedReason(s.configCtx); reason != "" {
+		s.logger.Debugw("not syncing file as the machine is "+reason, "file", filePath, "destination", name)
+		return destination, false
+	}
+	return destination, true
+}
+
 type cloudConn struct {
 	// closed by cloud conn manager
 	ready  chan struct{}
@@ -435,13 +518,37 @@ func (s *Sync) syncFile(config Config, filePath string) {
 	}
 
 	if data.IsDataCaptureFile(f) {
-		s.syncDataCaptureFile(f, config.CaptureDir, s.logger)
-	} else {
-		s.syncArbitraryFile(f, config.Tags, []string{}, config.FileLastModifiedMillis, s.logger)
+		s.syncDataCaptureFile(f, config, s.logger)
+		return
+	}
+
+	destination, ok := s.destination(config, config.DefaultDestination, filePath)
+	if !ok {
+		if err := f.Close(); err != nil {
+			s.logger.Error(errors.Wrapf(err, "failed to close file %s", f.Name()).Error())
+		}
+		return
+	}
+	if destination != nil {
+		s.syncArbitraryFileToDestination(f, destination, arbitraryFileName(config, filePath), s.logger)
+		return
 	}
+	s.syncArbitraryFile(f, config.Tags, []string{}, config.FileLastModifiedMillis, s.logger)
 }
 
-func (s *Sync) syncDataCaptureFile(f *os.File, captureDir string, logger logging.Logger) {
+// arbitraryFileName returns the path of an arbitrary file relative to the sync path it is in.
+func arbitraryFileName(config Config, filePath string) string {
+	for _, dir := range config.SyncPaths() {
+		relativePath, err := filepath.Rel(dir, filePath)
+		if err == nil && filepath.IsLocal(relativePath) {
+			return filepath.ToSlash(relativePath)
+		}
+	}
+	return filepath.Base(filePath)
+}
+
+func (s *Sync) syncDataCaptureFile(f *os.File, config Config, logger logging.Logger) {
+	captureDir := config.CaptureDir
 	captureFile, err := data.ReadCaptureFile(f)
 	// if you can't read the capture file's metadata field, close & move it to the failed directory
 	if err != nil {
@@ -457,7 +564,17 @@ func (s *Sync) syncDataCaptureFile(f *os.File, captureDir string, logger logging
 		s.uploadStats.tabular.uploadFailedFileCount.Add(1)
 		return
 	}
-	isBinary := captureFile.ReadMetadata().GetType() == v1.DataType_DATA_TYPE_BINARY_SENSOR
+	md := captureFile.ReadMetadata()
+	isBinary := md.GetType() == v1.DataType_DATA_TYPE_BINARY_SENSOR
+
+	destinationName := config.destinationFor(md.GetComponentType(), md.GetComponentName(), md.GetMethodName())
+	destination, ok := s.destination(config, destinationName, captureFile.GetPath())
+	if !ok {
+		if err := captureFile.Close(); err != nil {
+			logger.Error(errors.Wrap(err, "error closing data capture file").Error())
+		}
+		return
+	}
 
 	// Include counter for binary sensor data because larger binary data files are uploaded via our streaming API, so updating
 	// a counter during the upload provides a more granular rate metric.
@@ -467,10 +584,17 @@ func (s *Sync) syncDataCaptureFile(f *os.File, captureDir string, logger logging
 	}
 
 	// setup a retry struct that will try to upload the capture file
-	retry := newExponentialRetry(s.configCtx, s.clock, s.logger, f.Name(), func(ctx context.Context) (uint64, error) {
+	gate := s.gateFor(destination)
+	retry := newExponentialRetry(s.configCtx, s.clock, s.logger, f.Name(), gate, func(ctx context.Context) (uint64, error) {
 		msg := "error uploading data capture file %s, size: %s, md: %s"
 		errMetadata := fmt.Sprintf(msg, captureFile.GetPath(), data.FormatBytesI64(captureFile.Size()), captureFile.ReadMetadata())
-		bytesUploaded, err := uploadDataCaptureFile(ctx, captureFile, s.cloudConn, logger, uploadingBytesCounter)
+		var bytesUploaded uint64
+		var err error
+		if destination != nil {
+			bytesUploaded, err = destination.UploadDataCaptureFile(ctx, captureFile)
+		} else {
+			bytesUploaded, err = uploadDataCaptureFile(ctx, captureFile, s.cloudConn, gate, logger, uploadingBytesCounter)
+		}
 		if err != nil {
 			return 0, errors.Wrap(err, errMetadata)
 		}
@@ -485,12 +609,23 @@ func (s *Sync) syncDataCaptureFile(f *os.File, captureDir string, logger logging
 			logger.Error(errors.Wrap(closeErr, "error closing data capture file").Error())
 		}
 
-		// if we stopped due to a cancelled context,
+		// if we stopped due to a cancelled context, or uploads being held back,
 		// return without deleting the file or moving it to the failed directory
-		if errors.Is(err, context.Canceled) {
+		if errors.Is(err, context.Canceled) || errors.Is(err, errUploadHeldBack) {
 			return
 		}
 
+		// if the file has corrupt readings, replace it with its intact readings, which are synced next time
+		if errors.Is(err, data.ErrCorruptCaptureRecord) {
+			report, repairErr := data.RepairCaptureFile(captureFile.GetPath())
+			if repairErr == nil {
+				logger.Warnf("repaired data capture file %s, dropping %d corrupt readings: %v",
+					captureFile.GetPath(), report.CorruptReadings, err)
+				return
+			}
+			logger.Error(errors.Wrapf(repairErr, "failed to repair data capture file %s", captureFile.GetPath()).Error())
+		}
+
 		// otherwise we hit a terminal error, and we should move the file to the failed directory
 		if err := moveFailedData(captureFile.GetPath(), captureDir, err, logger); err != nil {
 			logger.Error(err)
@@ -517,10 +652,10 @@ func (s *Sync) syncDataCaptureFile(f *os.File, captureDir string, logger logging
 }
 
 func (s *Sync) syncArbitraryFile(f *os.File, tags, datasetIDs []string, fileLastModifiedMillis int, logger logging.Logger) {
-	retry := newExponentialRetry(s.configCtx, s.clock, s.logger, f.Name(), func(ctx context.Context) (uint64, error) {
+	retry := newExponentialRetry(s.configCtx, s.clock, s.logger, f.Name(), s.uploadGate, func(ctx context.Context) (uint64, error) {
 		errMetadata := fmt.Sprintf("error uploading arbitrary file %s", f.Name())
 		bytesUploaded, err := uploadArbitraryFile(
-			ctx, f, s.cloudConn, tags, datasetIDs, fileLastModifiedMillis, s.clock, logger, &s.uploadStats.arbitrary.uploadingBytes,
+			ctx, f, s.cloudConn, tags, datasetIDs, fileLastModifiedMillis, s.uploadGate, s.clock, logger, &s.uploadStats.arbitrary.uploadingBytes,
 		)
 		if err != nil {
 			return 0, errors.Wrap(err, errMetadata)
@@ -535,9 +670,9 @@ func (s *Sync) syncArbitraryFile(f *os.File, tags, datasetIDs []string, fileLast
 			logger.Error(errors.Wrap(closeErr, "error closing data capture file").Error())
 		}
 
-		// if we stopped due to a cancelled context,
+		// if we stopped due to a cancelled context, or uploads being held back,
 		// return without deleting the file or moving it to the failed directory
-		if errors.Is(err, context.Canceled) {
+		if errors.Is(err, context.Canceled) || errors.Is(err, errUploadHeldBack) {
 			return
 		}
 
@@ -560,6 +695,49 @@ func (s *Sync) syncArbitraryFile(f *os.File, tags, datasetIDs []string, fileLast
 	s.uploadStats.arbitrary.completedUploadBytes.Add(bytesUploaded)
 }
 
+// syncArbitraryFileToDestination uploads an arbitrary file to a destination other than the cloud and
+// deletes it once uploaded.
+func (s *Sync) syncArbitraryFileToDestination(f *os.File, destination Destination, name string, logger logging.Logger) {
+	gate := s.gateFor(destination)
+	retry := newExponentialRetry(s.configCtx, s.clock, s.logger, f.Name(), gate, func(ctx context.Context) (uint64, error) {
+		bytesUploaded, err := destination.UploadArbitraryFile(ctx, f, name)
+		if err != nil {
+			return 0, errors.Wrap(err, fmt.Sprintf("error uploading arbitrary file %s", f.Name()))
+		}
+		return bytesUploaded, nil
+	})
+
+	bytesUploaded, err := retry.run()
+	if closeErr := f.Close(); closeErr != nil {
+		logger.Error(errors.Wrap(closeErr, "error closing arbitrary file").Error())
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, errUploadHeldBack) {
+			return
+		}
+		if err := moveFailedData(f.Name(), path.Dir(f.Name()), err, logger); err != nil {
+			logger.Error(err.Error())
+		}
+		s.uploadStats.arbitrary.uploadFailedFileCount.Add(1)
+		return
+	}
+
+	if err := os.Remove(f.Name()); err != nil {
+		logger.Error(errors.Wrap(err, fmt.Sprintf("error deleting file %s", f.Name())).Error())
+	}
+	s.uploadStats.arbitrary.uploadedFileCount.Add(1)
+	s.uploadStats.arbitrary.completedUploadBytes.Add(bytesUploaded)
+}
+
+// gateFor returns the upload gate of uploads to the destination, nil for destinations on the
+// machine, which don't use the network. A nil destination is the cloud.
+func (s *Sync) gateFor(destination Destination) *uploadGate {
+	if _, ok := destination.(*directoryDestination); ok {
+		return nil
+	}
+	return s.uploadGate
+}
+
 // UploadBinaryDataToDatasets simultaneously uploads binary data and adds it to a dataset.
 func (s *Sync) UploadBinaryDataToDatasets(ctx context.Context, binaryData []byte, datasetIDs, tags []string, mimeType v1.MimeType) error {
 	errChan := make(chan error, 1)
@@ -624,6 +802,10 @@ func moveFailedData(path, parentDir string, cause error, logger logging.Logger)
 // BEGIN sync scheudler.
 func (s *Sync) runScheduler(ctx context.Context, tkr *clock.Ticker, config Config) {
 	defer tkr.Stop()
+	if !config.cloudOnly() {
+		s.runSchedulerWithDestinations(ctx, tkr, config)
+		return
+	}
 	var readyLogged bool
 
 	for {
@@ -646,6 +828,10 @@ func (s *Sync) runScheduler(ctx context.Context, tkr *clock.Ticker, config Confi
 		case <-ctx.Done():
 			return
 		case <-tkr.C:
+			if reason := s.uploadGate.closedReason(ctx); reason != "" {
+				s.logger.Debugf("data manager: NOT syncing data to the cloud as the machine is %s", reason)
+				continue
+			}
 			shouldSync := ReadyToSyncDirectories(ctx, config, s.logger)
 			state := s.cloudConn.conn.GetState()
 			if state != connectivity.Ready {
@@ -669,11 +855,38 @@ func (s *Sync) runScheduler(ctx context.Context, tkr *clock.Ticker, config Confi
 	}
 }
 
+// runSchedulerWithDestinations syncs on every tick, whether or not there is a cloud connection. Files
+// routed to the cloud are skipped while there is no cloud connection, and files routed to the network
+// while uploads are held back by the upload gate, so that files routed to directories keep being
+// synced.
+func (s *Sync) runSchedulerWithDestinations(ctx context.Context, tkr *clock.Ticker, config Config) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tkr.C:
+			if !ReadyToSyncDirectories(ctx, config, s.logger) {
+				s.logger.Info("data manager: NOT syncing data as it's selective sync sensor is not ready to sync")
+				continue
+			}
+
+			if err := s.walkDirsAndSendFilesToSync(ctx, config); err != nil && !errors.Is(err, context.Canceled) {
+				goutils.UncheckedError(err)
+			}
+		}
+	}
+}
+
 // returns early with an error if either ctx is cancelled or if the reconfigure is called
 // while walkDirsAndSendFilesToSync.
 func (s *Sync) walkDirsAndSendFilesToSync(ctx context.Context, config Config) error {
 	s.flushCollectors()
 	var errs []error
+	rawCaptureDir := filepath.Join(config.CaptureDir, shared.RawCaptureDir)
 	for _, dir := range config.SyncPaths() {
 		s.logger.Debugf("syncing from: %s", dir)
 		loggedDirPaths := map[string]bool{}
@@ -699,6 +912,11 @@ func (s *Sync) walkDirsAndSendFilesToSync(ctx context.Context, config Config) er
 				return filepath.SkipDir
 			}
 
+			// The raw readings of aggregated capture methods are kept on the machine.
+			if info.IsDir() && path == rawCaptureDir {
+				return filepath.SkipDir
+			}
+
 			if info.IsDir() {
 				return nil
 			}
@@ -31,6 +31,7 @@ var (
 // They are frequently files written by 3rd party programs such as images, videos, logs, written to
 // the capture directory or a subdirectory or to additional sync paths (or their sub directories).
 // Note: the bytes size returned is the size of the input file. It only returns a non 0 value in the success case.
+// The chunks sent are throttled by the upload gate.
 // If bytesUploadingCounter is provided, it will be updated as each chunk is successfully uploaded.
 func uploadArbitraryFile(
 	ctx context.Context,
@@ -38,6 +39,7 @@ func uploadArbitraryFile(
 	conn cloudConn,
 	tags, datasetIDs []string,
 	fileLastModifiedMillis int,
+	gate *uploadGate,
 	clock clock.Clock,
 	logger logging.Logger,
 	bytesUploadingCounter *atomic.Uint64,
@@ -126,7 +128,7 @@ func uploadArbitraryFile(
 		return 0, errors.Wrap(err, "FileUpload failed sending metadata")
 	}
 
-	if err := sendFileUploadRequests(ctx, stream, f, path, logger, bytesUploadingCounter); err != nil {
+	if err := sendFileUploadRequests(ctx, stream, f, path, gate, logger, bytesUploadingCounter); err != nil {
 		return 0, errors.Wrap(err, "FileUpload failed to sync")
 	}
 
@@ -142,6 +144,7 @@ func sendFileUploadRequests(
 	stream v1.DataSyncService_FileUploadClient,
 	f *os.File,
 	path string,
+	gate *uploadGate,
 	logger logging.Logger,
 	bytesUploadingCounter *atomic.Uint64,
 ) error {
@@ -163,6 +166,10 @@ func sendFileUploadRequests(
 			return err
 		}
 
+		if err := gate.waitForBandwidth(ctx, len(uploadReq.GetFileContents().GetData())); err != nil {
+			return err
+		}
+
 		logger.Debugf("datasync.FileUpload sending chunk %d for file: %s", i, path)
 		if err = stream.Send(uploadReq); err != nil {
 			return err
@@ -30,6 +30,7 @@ var (
 		errMultipleReadingTypes,
 		errSensorDataTypesDontMatchUploadMetadata,
 		errInvalidCaptureFileType,
+		data.ErrCorruptCaptureRecord,
 	}
 )
 
@@ -38,9 +39,10 @@ var (
 // If f is of type BINARY_SENSOR and its size is over MaxUnaryFileSize,
 // uses StreamingDataCaptureUpload API so as to not exceed the unary response size.
 // Otherwise, uploads data over DataCaptureUpload API.
+// The bytes sent are throttled by the upload gate.
 // Note: the bytes size returned is the size of the input file. It only returns a non 0 value in the success case.
 func uploadDataCaptureFile(
-	ctx context.Context, f *data.CaptureFile, conn cloudConn, logger logging.Logger, bytesUploadingCounter *atomic.Uint64,
+	ctx context.Context, f *data.CaptureFile, conn cloudConn, gate *uploadGate, logger logging.Logger, bytesUploadingCounter *atomic.Uint64,
 ) (uint64, error) {
 	logger.Debugf("preparing to upload data capture file: %s, size: %d", f.GetPath(), f.Size())
 
@@ -65,7 +67,7 @@ func uploadDataCaptureFile(
 	_, isTabular := sensorDataTypeSet[data.CaptureTypeTabular]
 	if isLegacyGetImagesCaptureFile(md, isTabular) {
 		logger.Debugf("attemping to upload legacy camera.GetImages data: %s", f.GetPath())
-		return uint64(f.Size()), legacyUploadGetImages(ctx, conn, md, sensorData[0], f.Size(), f.GetPath(), logger, bytesUploadingCounter)
+		return uint64(f.Size()), legacyUploadGetImages(ctx, conn, gate, md, sensorData[0], f.Size(), f.GetPath(), logger, bytesUploadingCounter)
 	}
 
 	if err := checkUploadMetadaTypeMatchesSensorDataType(md, sensorDataTypeSet); err != nil {
@@ -73,7 +75,7 @@ func uploadDataCaptureFile(
 	}
 
 	metaData := uploadMetadata(conn.partID, md)
-	return uint64(f.Size()), uploadSensorData(ctx, conn.client, metaData, sensorData, f.Size(), f.GetPath(), logger, bytesUploadingCounter)
+	return uint64(f.Size()), uploadSensorData(ctx, conn.client, gate, metaData, sensorData, f.Size(), f.GetPath(), logger, bytesUploadingCounter)
 }
 
 func checkUploadMetadaTypeMatchesSensorDataType(md *datasyncPB.DataCaptureMetadata, sensorDataTypeSet map[data.CaptureType]struct{}) error {
@@ -130,6 +132,7 @@ func uploadMetadata(partID string, md *datasyncPB.DataCaptureMetadata) *datasync
 func legacyUploadGetImages(
 	ctx context.Context,
 	conn cloudConn,
+	gate *uploadGate,
 	md *datasyncPB.DataCaptureMetadata,
 	sd *datasyncPB.SensorData,
 	size int64,
@@ -160,7 +163,7 @@ func legacyUploadGetImages(
 		metadata.FileExtension = getFileExtFromImageMimeType(img.GetMimeType())
 		// TODO: This is wrong as the size describes the size of the entire GetImages response, but we are only
 		// uploading one of the 2 images in that response here.
-		if err := uploadSensorData(ctx, conn.client, metadata, newSensorData, size, path, logger, bytesUploadingCounter); err != nil {
+		if err := uploadSensorData(ctx, conn.client, gate, metadata, newSensorData, size, path, logger, bytesUploadingCounter); err != nil {
 			return errors.Wrapf(err, "failed uploading GetImages image index: %d", i)
 		}
 	}
@@ -189,6 +192,7 @@ func getImagesTimestamps(
 func uploadSensorData(
 	ctx context.Context,
 	client datasyncPB.DataSyncServiceClient,
+	gate *uploadGate,
 	uploadMD *datasyncPB.UploadMetadata,
 	sensorData []*datasyncPB.SensorData,
 	fileSize int64,
@@ -201,16 +205,20 @@ func uploadSensorData(
 	case datasyncPB.DataType_DATA_TYPE_BINARY_SENSOR:
 		// If it's a large binary file, we need to upload it in chunks.
 		if uploadMD.GetType() == datasyncPB.DataType_DATA_TYPE_BINARY_SENSOR && fileSize > MaxUnaryFileSize {
-			return uploadMultipleLargeBinarySensorData(ctx, client, uploadMD, sensorData, path, logger, bytesUploadingCounter)
+			return uploadMultipleLargeBinarySensorData(ctx, client, gate, uploadMD, sensorData, path, logger, bytesUploadingCounter)
 		}
-		return uploadMultipleBinarySensorData(ctx, client, uploadMD, sensorData, path, logger, bytesUploadingCounter)
+		return uploadMultipleBinarySensorData(ctx, client, gate, uploadMD, sensorData, path, logger, bytesUploadingCounter)
 	case datasyncPB.DataType_DATA_TYPE_TABULAR_SENSOR:
 		// Otherwise use the unary endpoint
 		logger.Debugf("attempting to upload small binary file using DataCaptureUpload, file: %s", path)
-		_, err := client.DataCaptureUpload(ctx, &datasyncPB.DataCaptureUploadRequest{
+		req := &datasyncPB.DataCaptureUploadRequest{
 			Metadata:       uploadMD,
 			SensorContents: sensorData,
-		})
+		}
+		if err := gate.waitForBandwidth(ctx, proto.Size(req)); err != nil {
+			return err
+		}
+		_, err := client.DataCaptureUpload(ctx, req)
 		return errors.Wrap(err, "DataCaptureUpload failed")
 	case datasyncPB.DataType_DATA_TYPE_FILE:
 		fallthrough
@@ -225,6 +233,7 @@ func uploadSensorData(
 func uploadBinarySensorData(
 	ctx context.Context,
 	client datasyncPB.DataSyncServiceClient,
+	gate *uploadGate,
 	md *datasyncPB.UploadMetadata,
 	sd *datasyncPB.SensorData,
 	bytesUploadingCounter *atomic.Uint64,
@@ -235,10 +244,14 @@ func uploadBinarySensorData(
 	if fileExtensionFromMimeType != "" {
 		md.FileExtension = fileExtensionFromMimeType
 	}
-	if _, err := client.DataCaptureUpload(ctx, &datasyncPB.DataCaptureUploadRequest{
+	req := &datasyncPB.DataCaptureUploadRequest{
 		Metadata:       md,
 		SensorContents: []*datasyncPB.SensorData{sd},
-	}); err != nil {
+	}
+	if err := gate.waitForBandwidth(ctx, proto.Size(req)); err != nil {
+		return err
+	}
+	if _, err := client.DataCaptureUpload(ctx, req); err != nil {
 		return errors.Wrap(err, "DataCaptureUpload failed")
 	}
 
@@ -253,6 +266,7 @@ func uploadBinarySensorData(
 func uploadMultipleBinarySensorData(
 	ctx context.Context,
 	client datasyncPB.DataSyncServiceClient,
+	gate *uploadGate,
 	uploadMD *datasyncPB.UploadMetadata,
 	sensorData []*datasyncPB.SensorData,
 	path string,
@@ -262,7 +276,7 @@ func uploadMultipleBinarySensorData(
 	// this is the common case
 	if len(sensorData) == 1 {
 		logger.Debugf("attempting to upload small binary file using DataCaptureUpload, sensor data, file: %s", path)
-		return uploadBinarySensorData(ctx, client, uploadMD, sensorData[0], bytesUploadingCounter)
+		return uploadBinarySensorData(ctx, client, gate, uploadMD, sensorData[0], bytesUploadingCounter)
 	}
 
 	// we only go down this path if the capture method returned multiple binary
@@ -273,7 +287,7 @@ func uploadMultipleBinarySensorData(
 		// and I'm not confident that it is safe to reuse grpc request structs
 		// between calls if the data in the request struct changes
 		clonedMD := proto.Clone(uploadMD).(*datasyncPB.UploadMetadata)
-		if err := uploadBinarySensorData(ctx, client, clonedMD, sd, bytesUploadingCounter); err != nil {
+		if err := uploadBinarySensorData(ctx, client, gate, clonedMD, sd, bytesUploadingCounter); err != nil {
 			return err
 		}
 	}
@@ -283,6 +297,7 @@ func uploadMultipleBinarySensorData(
 func uploadMultipleLargeBinarySensorData(
 	ctx context.Context,
 	client datasyncPB.DataSyncServiceClient,
+	gate *uploadGate,
 	uploadMD *datasyncPB.UploadMetadata,
 	sensorData []*datasyncPB.SensorData,
 	path string,
@@ -291,7 +306,7 @@ func uploadMultipleLargeBinarySensorData(
 ) error {
 	if len(sensorData) == 1 {
 		logger.Debugf("attempting to upload large binary file using StreamingDataCaptureUpload, sensor data file: %s", path)
-		return uploadLargeBinarySensorData(ctx, client, uploadMD, sensorData[0], path, logger, bytesUploadingCounter)
+		return uploadLargeBinarySensorData(ctx, client, gate, uploadMD, sensorData[0], path, logger, bytesUploadingCounter)
 	}
 
 	for i, sd := range sensorData {
@@ -300,7 +315,7 @@ func uploadMultipleLargeBinarySensorData(
 		// and I'm not confident that it is safe to reuse grpc request structs
 		// between calls if the data in the request struct changes
 		clonedMD := proto.Clone(uploadMD).(*datasyncPB.UploadMetadata)
-		if err := uploadLargeBinarySensorData(ctx, client, clonedMD, sd, path, logger, bytesUploadingCounter); err != nil {
+		if err := uploadLargeBinarySensorData(ctx, client, gate, clonedMD, sd, path, logger, bytesUploadingCounter); err != nil {
 			return err
 		}
 	}
@@ -310,6 +325,7 @@ func uploadMultipleLargeBinarySensorData(
 func uploadLargeBinarySensorData(
 	ctx context.Context,
 	client datasyncPB.DataSyncServiceClient,
+	gate *uploadGate,
 	md *datasyncPB.UploadMetadata,
 	sd *datasyncPB.SensorData,
 	path string,
@@ -338,7 +354,7 @@ func uploadLargeBinarySensorData(
 	}
 
 	// Then call the function to send the rest.
-	if err := sendStreamingDCRequests(ctx, c, sd.GetBinary(), path, logger, bytesUploadingCounter); err != nil {
+	if err := sendStreamingDCRequests(ctx, c, gate, sd.GetBinary(), path, logger, bytesUploadingCounter); err != nil {
 		return errors.Wrap(err, "StreamingDataCaptureUpload failed to sync")
 	}
 
@@ -352,6 +368,7 @@ func uploadLargeBinarySensorData(
 func sendStreamingDCRequests(
 	ctx context.Context,
 	stream datasyncPB.DataSyncService_StreamingDataCaptureUploadClient,
+	gate *uploadGate,
 	contents []byte,
 	path string,
 	logger logging.Logger,
@@ -370,6 +387,9 @@ func sendStreamingDCRequests(
 				end = len(contents)
 			}
 			chunk := contents[i:end]
+			if err := gate.waitForBandwidth(ctx, len(chunk)); err != nil {
+				return err
+			}
 
 			// Build request with contents.
 			uploadReq := &datasyncPB.StreamingDataCaptureUploadRequest{
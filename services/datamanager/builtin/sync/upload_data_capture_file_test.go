@@ -835,7 +835,7 @@ func TestUploadDataCaptureFile(t *testing.T) {
 				cf, err := data.ReadCaptureFile(f)
 				test.That(t, err, test.ShouldBeNil)
 				cc := cloudConn{partID: partID, client: tc.client}
-				bytesUploaded, err := uploadDataCaptureFile(testCtx, cf, cc, logger, nil)
+				bytesUploaded, err := uploadDataCaptureFile(testCtx, cf, cc, nil, logger, nil)
 				test.That(t, err, test.ShouldBeNil)
 				test.That(t, bytesUploaded, test.ShouldEqual, stat.Size())
 			}
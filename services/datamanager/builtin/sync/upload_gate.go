@@ -0,0 +1,257 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"golang.org/x/time/rate"
+
+	"go.viam.com/rdk/logging"
+)
+
+const (
+	// meteredNetworkCheckInterval is how long whether the network is metered is cached for.
+	meteredNetworkCheckInterval = 10 * time.Second
+	syncWindowTimeLayout        = "15:04"
+)
+
+// SyncWindow is a daily window of local time during which uploads may run. A window that ends before
+// it starts spans midnight, e.g: 22:00 to 02:00.
+type SyncWindow struct {
+	// Start and End are offsets from midnight.
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseSyncWindow parses a sync window from `HH:MM` local times.
+func ParseSyncWindow(start, end string) (SyncWindow, error) {
+	startTime, err := time.Parse(syncWindowTimeLayout, start)
+	if err != nil {
+		return SyncWindow{}, fmt.Errorf("invalid sync window start %q, must be HH:MM", start)
+	}
+	endTime, err := time.Parse(syncWindowTimeLayout, end)
+	if err != nil {
+		return SyncWindow{}, fmt.Errorf("invalid sync window end %q, must be HH:MM", end)
+	}
+	window := SyncWindow{Start: sinceMidnight(startTime), End: sinceMidnight(endTime)}
+	if window.Start == window.End {
+		return SyncWindow{}, fmt.Errorf("sync window from %s to %s is empty", start, end)
+	}
+	return window, nil
+}
+
+func (w SyncWindow) contains(t time.Time) bool {
+	offset := sinceMidnight(t)
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// untilOpen returns how long after t the window opens, zero if t is in the window.
+func (w SyncWindow) untilOpen(t time.Time) time.Duration {
+	if w.contains(t) {
+		return 0
+	}
+	untilOpen := w.Start - sinceMidnight(t)
+	if untilOpen < 0 {
+		untilOpen += 24 * time.Hour
+	}
+	return untilOpen
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	hour, minute, second := t.Clock()
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute +
+		time.Duration(second)*time.Second + time.Duration(t.Nanosecond())
+}
+
+// MeteredNetworkDetector reports whether the machine is on a metered network, e.g: a cellular plan.
+type MeteredNetworkDetector interface {
+	Metered(ctx context.Context) (bool, error)
+}
+
+// uploadGate holds back uploads outside of the sync windows, and on metered networks, and bounds the
+// upload bandwidth. A nil uploadGate lets all uploads through.
+type uploadGate struct {
+	clock           clock.Clock
+	logger          logging.Logger
+	windows         []SyncWindow
+	meteredDetector MeteredNetworkDetector
+	// limiter bounds the bandwidth, it is nil when unbounded.
+	limiter *rate.Limiter
+	// meteredLimiter bounds the bandwidth on metered networks. Uploads are held back on metered
+	// networks when it is nil.
+	meteredLimiter *rate.Limiter
+
+	mu               sync.Mutex
+	meteredCheckedAt time.Time
+	metered          bool
+}
+
+// newUploadGate returns the upload gate of the config, nil if the config does not hold back uploads.
+func newUploadGate(config Config, clk clock.Clock, logger logging.Logger) *uploadGate {
+	if len(config.SyncWindows) == 0 && config.MeteredNetworkDetector == nil && config.UploadRateLimitBytesPerSec <= 0 {
+		return nil
+	}
+	return &uploadGate{
+		clock:           clk,
+		logger:          logger,
+		windows:         config.SyncWindows,
+		meteredDetector: config.MeteredNetworkDetector,
+		limiter:         newBandwidthLimiter(config.UploadRateLimitBytesPerSec),
+		meteredLimiter:  newBandwidthLimiter(config.MeteredUploadRateLimitBytesPerSec),
+	}
+}
+
+func newBandwidthLimiter(bytesPerSec int64) *rate.Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	// A burst of a second of bandwidth, and at least one upload chunk.
+	return rate.NewLimiter(rate.Limit(bytesPerSec), int(max(bytesPerSec, int64(UploadChunkSize))))
+}
+
+// closedReason returns why uploads are held back, the empty string if they are not.
+func (g *uploadGate) closedReason(ctx context.Context) string {
+	if g == nil {
+		return ""
+	}
+	now := g.clock.Now()
+	if g.untilWindowOpens(now) > 0 {
+		return "outside of the sync windows"
+	}
+	if g.meteredLimiter == nil && g.isMetered(ctx, now) {
+		return "on a metered network"
+	}
+	return ""
+}
+
+// untilWindowOpens returns how long after t the first sync window opens, zero if t is in a window or
+// there are no windows.
+func (g *uploadGate) untilWindowOpens(t time.Time) time.Duration {
+	var untilOpen time.Duration
+	for i, window := range g.windows {
+		windowUntilOpen := window.untilOpen(t)
+		if i == 0 || windowUntilOpen < untilOpen {
+			untilOpen = windowUntilOpen
+		}
+	}
+	return untilOpen
+}
+
+func (g *uploadGate) isMetered(ctx context.Context, now time.Time) bool {
+	if g.meteredDetector == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.meteredCheckedAt.IsZero() && now.Sub(g.meteredCheckedAt) < meteredNetworkCheckInterval {
+		return g.metered
+	}
+	metered, err := g.meteredDetector.Metered(ctx)
+	if err != nil {
+		// Like the selective syncer, err on the side of not uploading.
+		g.logger.CWarnw(ctx, "error detecting whether the network is metered, treating it as metered", "error", err.Error())
+		metered = true
+	}
+	if metered != g.metered {
+		g.logger.CInfof(ctx, "network metered: %t", metered)
+	}
+	g.metered = metered
+	g.meteredCheckedAt = now
+	return metered
+}
+
+// waitForBandwidth waits until there is bandwidth to send the given number of bytes. The bandwidth
+// is given back if ctx is cancelled.
+func (g *uploadGate) waitForBandwidth(ctx context.Context, bytes int) error {
+	if g == nil {
+		return nil
+	}
+	limiter := g.limiter
+	if g.isMetered(ctx, g.clock.Now()) {
+		limiter = g.meteredLimiter
+	}
+	if limiter == nil {
+		return nil
+	}
+	now := g.clock.Now()
+	var reservations []*rate.Reservation
+	var delay time.Duration
+	// A reservation can't exceed the burst, which is at least one upload chunk.
+	for bytes > 0 {
+		n := min(bytes, limiter.Burst())
+		reservation := limiter.ReserveN(now, n)
+		reservations = append(reservations, reservation)
+		delay = reservation.DelayFrom(now)
+		bytes -= n
+	}
+	if err := sleepWithClock(ctx, g.clock, delay); err != nil {
+		for _, reservation := range reservations {
+			reservation.CancelAt(g.clock.Now())
+		}
+		return err
+	}
+	return nil
+}
+
+// reader returns a reader of r that waits for the bandwidth of the bytes it reads. It keeps r
+// seekable, which uploaders use to retry requests.
+func (g *uploadGate) reader(ctx context.Context, r io.Reader) io.Reader {
+	if g == nil {
+		return r
+	}
+	throttled := &throttledReader{ctx: ctx, gate: g, r: r}
+	if seeker, ok := r.(io.Seeker); ok {
+		return throttledReadSeeker{throttledReader: throttled, seeker: seeker}
+	}
+	return throttled
+}
+
+type throttledReader struct {
+	ctx  context.Context
+	gate *uploadGate
+	r    io.Reader
+}
+
+func (tr *throttledReader) Read(p []byte) (int, error) {
+	// Never read more than a chunk at a time, so that reads don't wait for more than the burst.
+	if len(p) > UploadChunkSize {
+		p = p[:UploadChunkSize]
+	}
+	n, err := tr.r.Read(p)
+	if n > 0 {
+		if waitErr := tr.gate.waitForBandwidth(tr.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+type throttledReadSeeker struct {
+	*throttledReader
+	seeker io.Seeker
+}
+
+func (trs throttledReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	return trs.seeker.Seek(offset, whence)
+}
+
+func sleepWithClock(ctx context.Context, clk clock.Clock, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := clk.Timer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,177 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestSyncWindow(t *testing.T) {
+	_, err := ParseSyncWindow("1am", "05:00")
+	test.That(t, err, test.ShouldBeError, `invalid sync window start "1am", must be HH:MM`)
+	_, err = ParseSyncWindow("01:00", "25:00")
+	test.That(t, err, test.ShouldBeError, `invalid sync window end "25:00", must be HH:MM`)
+	_, err = ParseSyncWindow("01:00", "01:00")
+	test.That(t, err, test.ShouldBeError, "sync window from 01:00 to 01:00 is empty")
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 11, 18, hour, minute, 0, 0, time.Local)
+	}
+
+	night, err := ParseSyncWindow("01:00", "05:00")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, night, test.ShouldResemble, SyncWindow{Start: time.Hour, End: 5 * time.Hour})
+	test.That(t, night.contains(at(1, 0)), test.ShouldBeTrue)
+	test.That(t, night.contains(at(4, 59)), test.ShouldBeTrue)
+	test.That(t, night.contains(at(5, 0)), test.ShouldBeFalse)
+	test.That(t, night.untilOpen(at(3, 0)), test.ShouldEqual, 0)
+	test.That(t, night.untilOpen(at(0, 30)), test.ShouldEqual, 30*time.Minute)
+	test.That(t, night.untilOpen(at(13, 0)), test.ShouldEqual, 12*time.Hour)
+
+	overMidnight, err := ParseSyncWindow("22:00", "02:00")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, overMidnight.contains(at(23, 0)), test.ShouldBeTrue)
+	test.That(t, overMidnight.contains(at(1, 0)), test.ShouldBeTrue)
+	test.That(t, overMidnight.contains(at(12, 0)), test.ShouldBeFalse)
+	test.That(t, overMidnight.untilOpen(at(21, 0)), test.ShouldEqual, time.Hour)
+}
+
+type fakeMeteredNetworkDetector struct {
+	metered bool
+	err     error
+	calls   int
+}
+
+func (d *fakeMeteredNetworkDetector) Metered(context.Context) (bool, error) {
+	d.calls++
+	return d.metered, d.err
+}
+
+func TestUploadGate(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	t.Run("is nil when uploads are not held back", func(t *testing.T) {
+		gate := newUploadGate(Config{}, clock.NewMock(), logger)
+		test.That(t, gate, test.ShouldBeNil)
+		test.That(t, gate.closedReason(ctx), test.ShouldBeEmpty)
+		test.That(t, gate.waitForBandwidth(ctx, 1<<30), test.ShouldBeNil)
+	})
+
+	t.Run("holds back uploads outside of the sync windows", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(time.Date(2024, 11, 18, 12, 0, 0, 0, time.Local))
+		window, err := ParseSyncWindow("01:00", "05:00")
+		test.That(t, err, test.ShouldBeNil)
+		gate := newUploadGate(Config{SyncWindows: []SyncWindow{window}}, clk, logger)
+		test.That(t, gate.closedReason(ctx), test.ShouldEqual, "outside of the sync windows")
+		test.That(t, gate.untilWindowOpens(clk.Now()), test.ShouldEqual, 13*time.Hour)
+
+		clk.Add(14 * time.Hour)
+		test.That(t, gate.closedReason(ctx), test.ShouldBeEmpty)
+	})
+
+	t.Run("holds back uploads on metered networks", func(t *testing.T) {
+		clk := clock.NewMock()
+		detector := &fakeMeteredNetworkDetector{metered: true}
+		gate := newUploadGate(Config{MeteredNetworkDetector: detector}, clk, logger)
+		test.That(t, gate.closedReason(ctx), test.ShouldEqual, "on a metered network")
+
+		// Detection is cached.
+		detector.metered = false
+		test.That(t, gate.closedReason(ctx), test.ShouldEqual, "on a metered network")
+		test.That(t, detector.calls, test.ShouldEqual, 1)
+		clk.Add(meteredNetworkCheckInterval)
+		test.That(t, gate.closedReason(ctx), test.ShouldBeEmpty)
+
+		// Errors are treated as metered networks.
+		detector.err = errors.New("no modem")
+		clk.Add(meteredNetworkCheckInterval)
+		test.That(t, gate.closedReason(ctx), test.ShouldEqual, "on a metered network")
+	})
+
+	t.Run("lets uploads through on metered networks with a metered rate limit", func(t *testing.T) {
+		gate := newUploadGate(Config{
+			MeteredNetworkDetector:            &fakeMeteredNetworkDetector{metered: true},
+			MeteredUploadRateLimitBytesPerSec: 1000,
+		}, clock.NewMock(), logger)
+		test.That(t, gate.closedReason(ctx), test.ShouldBeEmpty)
+	})
+
+	t.Run("waits for the bandwidth to upload", func(t *testing.T) {
+		clk := clock.NewMock()
+		chunkSize := UploadChunkSize
+		gate := newUploadGate(Config{UploadRateLimitBytesPerSec: int64(chunkSize)}, clk, logger)
+
+		// The first second of bandwidth is available right away.
+		test.That(t, gate.waitForBandwidth(ctx, chunkSize), test.ShouldBeNil)
+
+		waited := make(chan error, 1)
+		go func() {
+			waited <- gate.waitForBandwidth(ctx, 2*chunkSize)
+		}()
+		// Give the upload time to set its timer on the mock clock.
+		time.Sleep(50 * time.Millisecond)
+		clk.Add(time.Second)
+		select {
+		case <-waited:
+			t.Fatal("upload did not wait for the bandwidth")
+		case <-time.After(10 * time.Millisecond):
+		}
+		clk.Add(time.Second)
+		test.That(t, <-waited, test.ShouldBeNil)
+	})
+
+	t.Run("retries stop while uploads are held back", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(time.Date(2024, 11, 18, 12, 0, 0, 0, time.Local))
+		window, err := ParseSyncWindow("01:00", "05:00")
+		test.That(t, err, test.ShouldBeNil)
+		gate := newUploadGate(Config{SyncWindows: []SyncWindow{window}}, clk, logger)
+		var attempts int
+		retry := newExponentialRetry(ctx, clk, logger, "file", gate, func(context.Context) (uint64, error) {
+			attempts++
+			return 1, nil
+		})
+		_, err = retry.run()
+		test.That(t, errors.Is(err, errUploadHeldBack), test.ShouldBeTrue)
+		test.That(t, terminalError(err), test.ShouldBeFalse)
+		test.That(t, attempts, test.ShouldEqual, 0)
+
+		clk.Add(14 * time.Hour)
+		uploaded, err := retry.run()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, uploaded, test.ShouldEqual, 1)
+		test.That(t, attempts, test.ShouldEqual, 1)
+	})
+	t.Run("readers wait for the bandwidth of the bytes read", func(t *testing.T) {
+		clk := clock.NewMock()
+		gate := newUploadGate(Config{UploadRateLimitBytesPerSec: int64(UploadChunkSize)}, clk, logger)
+		reader := gate.reader(ctx, bytes.NewReader(make([]byte, 2*UploadChunkSize)))
+		_, seekable := reader.(io.Seeker)
+		test.That(t, seekable, test.ShouldBeTrue)
+
+		read := make(chan error, 1)
+		go func() {
+			_, err := io.ReadAll(reader)
+			read <- err
+		}()
+		// Give the reader time to set its timer on the mock clock.
+		time.Sleep(50 * time.Millisecond)
+		select {
+		case <-read:
+			t.Fatal("reader did not wait for the bandwidth")
+		case <-time.After(10 * time.Millisecond):
+		}
+		clk.Add(time.Second)
+		test.That(t, <-read, test.ShouldBeNil)
+	})
+}
@@ -7,6 +7,8 @@ package datamanager
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"image"
 	"reflect"
 	"slices"
@@ -169,6 +171,15 @@ type DataCaptureConfig struct {
 	Disabled           bool                   `json:"disabled"`
 	Tags               []string               `json:"tags,omitempty"`
 	CaptureDirectory   string                 `json:"capture_directory"`
+	// SyncDestination names the data manager sync destination the captured data is synced to. The
+	// data manager's default sync destination is used when it is empty.
+	SyncDestination string `json:"sync_destination,omitempty"`
+	// Retention bounds how much of the captured data is kept on disk until it is synced.
+	Retention *RetentionConfig `json:"retention,omitempty"`
+	// PreRoll, when set, keeps the captured data in memory and only persists it when triggered.
+	PreRoll *PreRollConfig `json:"pre_roll,omitempty"`
+	// Aggregation, when set, rolls the captured tabular readings up into windows.
+	Aggregation *AggregationConfig `json:"aggregation,omitempty"`
 }
 
 // Equals checks if one capture config is equal to another.
@@ -181,9 +192,140 @@ func (c *DataCaptureConfig) Equals(other *DataCaptureConfig) bool {
 		c.Disabled == other.Disabled &&
 		slices.Compare(c.Tags, other.Tags) == 0 &&
 		reflect.DeepEqual(c.AdditionalParams, other.AdditionalParams) &&
-		c.CaptureDirectory == other.CaptureDirectory
+		c.CaptureDirectory == other.CaptureDirectory &&
+		c.SyncDestination == other.SyncDestination &&
+		reflect.DeepEqual(c.Retention, other.Retention) &&
+		reflect.DeepEqual(c.PreRoll, other.PreRoll) &&
+		reflect.DeepEqual(c.Aggregation, other.Aggregation)
 }
 
+// Retention priorities. When the disk is full, captured data of lower priority is deleted first.
+const (
+	RetentionPriorityLow    = "low"
+	RetentionPriorityNormal = "normal"
+	RetentionPriorityHigh   = "high"
+)
+
+// RetentionConfig bounds how much captured data is kept on disk until it is synced. Data that is
+// deleted is never synced. Zero valued fields are unbounded.
+type RetentionConfig struct {
+	// MaxAgeHours deletes captured data once it is older than this many hours.
+	MaxAgeHours float64 `json:"max_age_hours,omitempty"`
+	// MaxBytes bounds the disk space used by the captured data of a capture method. The oldest data
+	// is deleted first.
+	MaxBytes int64 `json:"max_bytes,omitempty"`
+	// Priority is one of `low`, `normal` or `high`. Defaults to `normal`.
+	Priority string `json:"priority,omitempty"`
+}
+
+// Validate returns an error if the retention config is invalid.
+func (c RetentionConfig) Validate() error {
+	if c.MaxAgeHours < 0 {
+		return errors.New("retention max_age_hours can't be negative")
+	}
+	if c.MaxBytes < 0 {
+		return errors.New("retention max_bytes can't be negative")
+	}
+	switch c.Priority {
+	case "", RetentionPriorityLow, RetentionPriorityNormal, RetentionPriorityHigh:
+		return nil
+	default:
+		return fmt.Errorf("retention priority must be one of %s, %s or %s, got %q",
+			RetentionPriorityLow, RetentionPriorityNormal, RetentionPriorityHigh, c.Priority)
+	}
+}
+
+// DefaultPreRollMaxBufferedBytes bounds the memory used by the buffered data of a pre-roll capture
+// method that does not set `max_buffered_bytes`.
+const DefaultPreRollMaxBufferedBytes = 64 * 1024 * 1024
+
+// PreRollConfig makes a capture method keep the data it captured during the last `pre_seconds` in
+// memory rather than writing it to disk. When triggered, the buffered data is persisted, as is the
+// data captured during the following `post_seconds`. Capture methods can be triggered with the
+// data manager's `trigger_capture` DoCommand, or by the triggers below.
+type PreRollConfig struct {
+	PreSeconds  float64 `json:"pre_seconds"`
+	PostSeconds float64 `json:"post_seconds,omitempty"`
+	// MaxBufferedBytes bounds the memory used by the buffered data. The oldest data is dropped
+	// first. Defaults to 64MiB.
+	MaxBufferedBytes int `json:"max_buffered_bytes,omitempty"`
+	// TriggerOnSelectiveSync triggers the capture method while the data manager's selective syncer
+	// returns true for `should_sync`.
+	TriggerOnSelectiveSync bool `json:"trigger_on_selective_sync,omitempty"`
+	// VisionTrigger triggers the capture method while a vision service detects objects.
+	VisionTrigger *VisionTriggerConfig `json:"vision_trigger,omitempty"`
+}
+
+// VisionTriggerConfig triggers a pre-roll capture method when a vision service detects objects in
+// the images of a camera.
+type VisionTriggerConfig struct {
+	VisionService string `json:"vision_service"`
+	// Camera defaults to the name of the captured resource.
+	Camera string `json:"camera,omitempty"`
+	// Labels restricts the detections that trigger to those with one of the labels.
+	Labels []string `json:"labels,omitempty"`
+	// MinConfidence restricts the detections that trigger to those with at least this score.
+	MinConfidence float64 `json:"min_confidence,omitempty"`
+	// PollFrequencyHz is how often the vision service is asked for detections. Defaults to 1.
+	PollFrequencyHz float64 `json:"poll_frequency_hz,omitempty"`
+}
+
+// Validate returns an error if the pre-roll config is invalid.
+func (c PreRollConfig) Validate() error {
+	if c.PreSeconds <= 0 {
+		return errors.New("pre_roll pre_seconds must be greater than zero")
+	}
+	if c.PostSeconds < 0 {
+		return errors.New("pre_roll post_seconds can't be negative")
+	}
+	if c.MaxBufferedBytes < 0 {
+		return errors.New("pre_roll max_buffered_bytes can't be negative")
+	}
+	if c.VisionTrigger != nil {
+		if c.VisionTrigger.VisionService == "" {
+			return errors.New("pre_roll vision_trigger vision_service can't be empty")
+		}
+		if c.VisionTrigger.MinConfidence < 0 || c.VisionTrigger.MinConfidence > 1 {
+			return errors.New("pre_roll vision_trigger min_confidence must be between 0 and 1")
+		}
+		if c.VisionTrigger.PollFrequencyHz < 0 {
+			return errors.New("pre_roll vision_trigger poll_frequency_hz can't be negative")
+		}
+	}
+	return nil
+}
+
+// AggregationConfig makes a tabular capture method capture a reading per window of `window_seconds`
+// rather than every reading. The reading of a window has the shape of the readings it aggregates,
+// with each numeric field replaced by its `min`, `max`, `mean` and `count` over the window. Fields
+// which are not numeric are dropped.
+type AggregationConfig struct {
+	WindowSeconds float64 `json:"window_seconds"`
+	// RawRetention, when set, keeps the raw readings on the machine, without syncing them, for as
+	// long as the retention allows. It must bound the age or the size of the raw readings.
+	RawRetention *RetentionConfig `json:"raw_retention,omitempty"`
+}
+
+// Validate returns an error if the aggregation config is invalid.
+func (c AggregationConfig) Validate() error {
+	if c.WindowSeconds <= 0 {
+		return errors.New("aggregation window_seconds must be greater than zero")
+	}
+	if c.RawRetention != nil {
+		if err := c.RawRetention.Validate(); err != nil {
+			return err
+		}
+		if c.RawRetention.MaxAgeHours == 0 && c.RawRetention.MaxBytes == 0 {
+			return errors.New("aggregation raw_retention must set max_age_hours or max_bytes")
+		}
+	}
+	return nil
+}
+
+// MeteredNetworkKey is the key of the boolean reading of the data manager's metered network sensor
+// that reports whether the machine is on a metered network.
+const MeteredNetworkKey = "metered"
+
 // ShouldSyncKey is a special key we use within a modular sensor to pass a boolean
 // that indicates to the datamanager whether or not we want to sync.
 var ShouldSyncKey = "should_sync"
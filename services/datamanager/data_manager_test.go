@@ -165,6 +165,26 @@ func TestDataCaptureConfig(t *testing.T) {
 			},
 			equal: false,
 		},
+		{
+			name: "different PreRoll are not equal",
+			a: &DataCaptureConfig{
+				PreRoll: &PreRollConfig{PreSeconds: 10},
+			},
+			b: &DataCaptureConfig{
+				PreRoll: &PreRollConfig{PreSeconds: 10, PostSeconds: 5},
+			},
+			equal: false,
+		},
+		{
+			name: "different Aggregation are not equal",
+			a: &DataCaptureConfig{
+				Aggregation: &AggregationConfig{WindowSeconds: 1},
+			},
+			b: &DataCaptureConfig{
+				Aggregation: &AggregationConfig{WindowSeconds: 1, RawRetention: &RetentionConfig{MaxAgeHours: 24}},
+			},
+			equal: false,
+		},
 	}
 
 	for _, tc := range tcs {
@@ -57,6 +57,8 @@ const (
 	DoPlan              = "plan"
 	DoExecute           = "execute"
 	DoExecuteCheckStart = "executeCheckStart"
+	DoBuildRoadmap      = "buildRoadmap"
+	DoRoadmapOptions    = "roadmapOptions"
 )
 
 const (
@@ -90,6 +92,14 @@ type Config struct {
 	LogPlannerErrors            bool   `json:"log_planner_errors"`
 	LogSlowPlanThresholdMS      int    `json:"log_slow_plan_threshold_ms"`
 
+	// If set, up to this many plans are cached and reused for identical requests, see armplanning.PlanCache.
+	PlanCacheSize int `json:"plan_cache_size"`
+	// Width of the buckets start configurations are rounded to when keying cached plans, in radians or mm.
+	PlanCacheStartBucketSize float64 `json:"plan_cache_start_bucket_size"`
+	// If set, the roadmap in this file, see armplanning.Roadmap, plans motions which name the prm planner algorithm.
+	// DoBuildRoadmap writes the roadmap it builds to this file.
+	RoadmapFilePath string `json:"roadmap_file_path"`
+
 	// example { "arm" : { "3" : { "min" : 0, "max" : 2 } } }
 	InputRangeOverride map[string]map[string]referenceframe.Limit `json:"input_range_override"`
 }
@@ -121,6 +131,14 @@ func (c *Config) Validate(path string) ([]string, []string, error) {
 		return nil, nil, fmt.Errorf("need a plan_file_path if you sent LogSlowPlanThresholdMS to %v", c.LogSlowPlanThresholdMS)
 	}
 
+	if c.PlanCacheSize < 0 {
+		return nil, nil, fmt.Errorf("plan_cache_size can't be negative, got %d", c.PlanCacheSize)
+	}
+
+	if c.PlanCacheStartBucketSize < 0 {
+		return nil, nil, fmt.Errorf("plan_cache_start_bucket_size can't be negative, got %v", c.PlanCacheStartBucketSize)
+	}
+
 	return []string{framesystem.InternalServiceName.String()}, nil, nil
 }
 
@@ -135,6 +153,8 @@ type builtIn struct {
 	components              map[string]resource.Resource
 	logger                  logging.Logger
 	configuredDefaultExtras map[string]any
+	planCache               *armplanning.PlanCache
+	roadmap                 *armplanning.Roadmap
 }
 
 // NewBuiltIn returns a new move and grab service for the given robot.
@@ -175,6 +195,21 @@ func (ms *builtIn) Reconfigure(
 	if config.NumThreads > 0 {
 		ms.configuredDefaultExtras["num_threads"] = config.NumThreads
 	}
+	ms.planCache = nil
+	if config.PlanCacheSize > 0 {
+		ms.planCache, err = armplanning.NewPlanCache(config.PlanCacheSize, config.PlanCacheStartBucketSize)
+		if err != nil {
+			return err
+		}
+	}
+	ms.roadmap = nil
+	if config.RoadmapFilePath != "" {
+		ms.roadmap, err = armplanning.ReadRoadmapFromFile(config.RoadmapFilePath)
+		// A missing file means no roadmap was built yet, DoBuildRoadmap creates it.
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("cannot read roadmap: %w", err)
+		}
+	}
 
 	movementSensors := make(map[string]movementsensor.MovementSensor)
 	slamServices := make(map[string]slam.Service)
@@ -260,10 +295,10 @@ func (ms *builtIn) PlanHistory(
 	ctx context.Context,
 	req motion.PlanHistoryReq,
 ) ([]motion.PlanWithStatus, error) {
-	return nil, fmt.Errorf("PlanHistory not supported by builtin")
+	return nil, errors.Wrap(motion.ErrPlanHistoryNotSupported, "builtin motion service")
 }
 
-// DoCommand supports two commands which are specified through the command map
+// DoCommand supports three commands which are specified through the command map
 //   - DoPlan generates and returns a Trajectory for a given motionpb.MoveRequest without executing it
 //     required key: DoPlan
 //     input value: a motionpb.MoveRequest which will be used to create a Trajectory
@@ -272,37 +307,43 @@ func (ms *builtIn) PlanHistory(
 //     required key: DoExecute
 //     input value: a motionplan.Trajectory
 //     output value: a bool
+//   - DoBuildRoadmap builds a roadmap for the frames moved by a motionpb.MoveRequest, writes it to the roadmap_file_path
+//     of the config and plans with it from then on
+//     required key: DoBuildRoadmap
+//     input value: a motionpb.MoveRequest whose component and start state the roadmap is built for
+//     optional key: DoRoadmapOptions, whose value is an armplanning.RoadmapOptions
+//     output value: the file the roadmap was written to
 func (ms *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
-	ms.mu.RLock()
-	defer ms.mu.RUnlock()
 	resp := make(map[string]interface{}, 0)
-	if req, ok := cmd[DoPlan]; ok {
-		s, err := utils.AssertType[string](req)
+	// Building a roadmap replaces the roadmap of the service, so it manages the lock itself.
+	if req, ok := cmd[DoBuildRoadmap]; ok {
+		moveReq, err := moveReqFromDoCommand(req)
 		if err != nil {
 			return nil, err
 		}
-		var moveReqProto pb.MoveRequest
-		err = protojson.Unmarshal([]byte(s), &moveReqProto)
+		var opts armplanning.RoadmapOptions
+		if err := mapstructure.Decode(cmd[DoRoadmapOptions], &opts); err != nil {
+			return nil, err
+		}
+		fileName, err := ms.buildRoadmap(ctx, moveReq, &opts)
 		if err != nil {
 			return nil, err
 		}
-		fields := moveReqProto.Extra.AsMap()
-		if extra, err := utils.AssertType[map[string]interface{}](fields["fields"]); err == nil {
-			v, err := structpb.NewStruct(extra)
-			if err != nil {
-				return nil, err
-			}
-			moveReqProto.Extra = v
+		resp[DoBuildRoadmap] = fileName
+	}
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	if req, ok := cmd[DoPlan]; ok {
+		moveReq, err := moveReqFromDoCommand(req)
+		if err != nil {
+			return nil, err
 		}
 		// Special handling: we want to observe the logs just for the DoCommand
 		obsLogger := ms.logger.Sublogger("observed-" + uuid.New().String())
 		observerCore, observedLogs := observer.New(zap.LevelEnablerFunc(zapcore.InfoLevel.Enabled))
 		obsLogger.AddAppender(observerCore)
 
-		moveReq, err := motion.MoveReqFromProto(&moveReqProto)
-		if err != nil {
-			return nil, err
-		}
 		plan, err := ms.plan(ctx, moveReq, obsLogger)
 		if err != nil {
 			return nil, err
@@ -357,6 +398,56 @@ func (ms *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (m
 	return resp, nil
 }
 
+// moveReqFromDoCommand parses the motionpb.MoveRequest, as json, of a DoCommand.
+func moveReqFromDoCommand(req interface{}) (motion.MoveReq, error) {
+	s, err := utils.AssertType[string](req)
+	if err != nil {
+		return motion.MoveReq{}, err
+	}
+	var moveReqProto pb.MoveRequest
+	if err := protojson.Unmarshal([]byte(s), &moveReqProto); err != nil {
+		return motion.MoveReq{}, err
+	}
+	fields := moveReqProto.Extra.AsMap()
+	if extra, err := utils.AssertType[map[string]interface{}](fields["fields"]); err == nil {
+		v, err := structpb.NewStruct(extra)
+		if err != nil {
+			return motion.MoveReq{}, err
+		}
+		moveReqProto.Extra = v
+	}
+	return motion.MoveReqFromProto(&moveReqProto)
+}
+
+// buildRoadmap builds a roadmap for the frames moved by req, writes it to the roadmap file of the config and replaces
+// the roadmap of the service with it. It returns the file the roadmap was written to.
+func (ms *builtIn) buildRoadmap(ctx context.Context, req motion.MoveReq, opts *armplanning.RoadmapOptions) (string, error) {
+	ms.mu.RLock()
+	fileName := ms.conf.RoadmapFilePath
+	if fileName == "" {
+		ms.mu.RUnlock()
+		return "", errors.New("roadmap_file_path must be configured to build a roadmap")
+	}
+	planRequest, err := ms.newPlanRequest(ctx, req, ms.logger)
+	ms.mu.RUnlock()
+	if err != nil {
+		return "", err
+	}
+
+	rm, err := armplanning.BuildRoadmap(ctx, ms.logger, planRequest, opts)
+	if err != nil {
+		return "", err
+	}
+	if err := rm.WriteToFile(fileName); err != nil {
+		return "", err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.roadmap = rm
+	return fileName, nil
+}
+
 func (ms *builtIn) getFrameSystem(ctx context.Context, transforms []*referenceframe.LinkInFrame) (*referenceframe.FrameSystem, error) {
 	frameSys, err := framesystem.NewFromService(ctx, ms.fsService, transforms)
 	if err != nil {
@@ -408,6 +499,43 @@ func (ms *builtIn) getFrameSystem(ctx context.Context, transforms []*referencefr
 }
 
 func (ms *builtIn) plan(ctx context.Context, req motion.MoveReq, logger logging.Logger) (motionplan.Plan, error) {
+	planRequest, err := ms.newPlanRequest(ctx, req, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	var plan motionplan.Plan
+	if ms.planCache != nil {
+		plan, _, err = ms.planCache.PlanMotion(ctx, logger, planRequest)
+	} else {
+		plan, _, err = armplanning.PlanMotion(ctx, logger, planRequest)
+	}
+	if ms.conf.shouldWritePlan(start, err) {
+		var traceID string
+		if span := trace.FromContext(ctx); span != nil {
+			traceID = span.SpanContext().TraceID().String()
+		}
+
+		// Extract plan tag from extra if provided
+		var planTag string
+		if req.Extra != nil {
+			if tag, ok := req.Extra["plan_tag"].(string); ok {
+				planTag = tag
+			}
+		}
+
+		err := ms.writePlanRequest(planRequest, plan, start, traceID, planTag, err)
+		if err != nil {
+			ms.logger.Warnf("couldn't write plan: %v", err)
+		}
+	}
+	return plan, err
+}
+
+// newPlanRequest returns the armplanning.PlanRequest of a motion.MoveReq, starting from the current inputs of the frame
+// system.
+func (ms *builtIn) newPlanRequest(ctx context.Context, req motion.MoveReq, logger logging.Logger) (*armplanning.PlanRequest, error) {
 	frameSys, err := ms.getFrameSystem(ctx, req.WorldState.Transforms())
 	if err != nil {
 		return nil, err
@@ -471,37 +599,15 @@ func (ms *builtIn) plan(ctx context.Context, req motion.MoveReq, logger logging.
 
 	// the goal is to move the component to goalPose which is specified in coordinates of goalFrameName
 
-	planRequest := &armplanning.PlanRequest{
+	return &armplanning.PlanRequest{
 		FrameSystem:    frameSys,
 		Goals:          worldWaypoints,
 		StartState:     startState,
 		WorldState:     req.WorldState,
 		Constraints:    req.Constraints,
 		PlannerOptions: planOpts,
-	}
-
-	start := time.Now()
-	plan, _, err := armplanning.PlanMotion(ctx, logger, planRequest)
-	if ms.conf.shouldWritePlan(start, err) {
-		var traceID string
-		if span := trace.FromContext(ctx); span != nil {
-			traceID = span.SpanContext().TraceID().String()
-		}
-
-		// Extract plan tag from extra if provided
-		var planTag string
-		if req.Extra != nil {
-			if tag, ok := req.Extra["plan_tag"].(string); ok {
-				planTag = tag
-			}
-		}
-
-		err := ms.writePlanRequest(planRequest, plan, start, traceID, planTag, err)
-		if err != nil {
-			ms.logger.Warnf("couldn't write plan: %v", err)
-		}
-	}
-	return plan, err
+		Roadmap:        ms.roadmap,
+	}, nil
 }
 
 func (ms *builtIn) execute(ctx context.Context, trajectory motionplan.Trajectory, epsilon float64) error {
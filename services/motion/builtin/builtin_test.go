@@ -45,6 +45,27 @@ func setupMotionServiceFromConfig(t *testing.T, configFilename string) (motion.S
 	}
 }
 
+// setupMotionServiceWithConfig is setupMotionServiceFromConfig with the builtin motion service configured by msConfig.
+func setupMotionServiceWithConfig(t *testing.T, configFilename string, msConfig *Config) (motion.Service, func()) {
+	t.Helper()
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	cfg, err := config.Read(ctx, configFilename, logger, nil)
+	test.That(t, err, test.ShouldBeNil)
+	for i, svcCfg := range cfg.Services {
+		if svcCfg.API == motion.API && svcCfg.Name == "builtin" {
+			cfg.Services[i].ConvertedAttributes = msConfig
+		}
+	}
+	myRobot, err := robotimpl.New(ctx, cfg, nil, logger)
+	test.That(t, err, test.ShouldBeNil)
+	svc, err := motion.FromProvider(myRobot, "builtin")
+	test.That(t, err, test.ShouldBeNil)
+	return svc, func() {
+		myRobot.Close(context.Background())
+	}
+}
+
 func TestMoveFailures(t *testing.T) {
 	var err error
 	ms, teardown := setupMotionServiceFromConfig(t, "../data/arm_gantry.json")
@@ -593,6 +614,102 @@ func TestConfiguredDefaultExtras(t *testing.T) {
 	})
 }
 
+func TestConfiguredPlanCache(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	t.Run("plan cache not configured", func(t *testing.T) {
+		ms, err := NewBuiltIn(ctx, nil, resource.Config{ConvertedAttributes: &Config{}}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		defer test.That(t, ms.Close(ctx), test.ShouldBeNil)
+		test.That(t, ms.(*builtIn).planCache, test.ShouldBeNil)
+	})
+
+	t.Run("configure plan cache", func(t *testing.T) {
+		ms, err := NewBuiltIn(ctx, nil, resource.Config{ConvertedAttributes: &Config{PlanCacheSize: 10}}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		defer test.That(t, ms.Close(ctx), test.ShouldBeNil)
+		test.That(t, ms.(*builtIn).planCache, test.ShouldNotBeNil)
+	})
+
+	t.Run("plan cache configured poorly", func(t *testing.T) {
+		_, _, err := (&Config{PlanCacheSize: -1}).Validate("")
+		test.That(t, err, test.ShouldNotBeNil)
+		_, _, err = (&Config{PlanCacheSize: 10, PlanCacheStartBucketSize: -1}).Validate("")
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+func TestConfiguredRoadmap(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	t.Run("configure roadmap", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "roadmap.json")
+		test.That(t, (&armplanning.Roadmap{}).WriteToFile(fileName), test.ShouldBeNil)
+		ms, err := NewBuiltIn(ctx, nil, resource.Config{ConvertedAttributes: &Config{RoadmapFilePath: fileName}}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		defer test.That(t, ms.Close(ctx), test.ShouldBeNil)
+		test.That(t, ms.(*builtIn).roadmap, test.ShouldNotBeNil)
+	})
+
+	t.Run("missing roadmap file", func(t *testing.T) {
+		ms, err := NewBuiltIn(ctx, nil, resource.Config{
+			ConvertedAttributes: &Config{RoadmapFilePath: filepath.Join(t.TempDir(), "missing.json")},
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		defer test.That(t, ms.Close(ctx), test.ShouldBeNil)
+		test.That(t, ms.(*builtIn).roadmap, test.ShouldBeNil)
+	})
+
+	t.Run("unreadable roadmap file", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "roadmap.json")
+		test.That(t, os.WriteFile(fileName, []byte("not a roadmap"), 0o600), test.ShouldBeNil)
+		_, err := NewBuiltIn(ctx, nil, resource.Config{ConvertedAttributes: &Config{RoadmapFilePath: fileName}}, logger)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "cannot read roadmap")
+	})
+
+	buildRoadmapCmd := func(ms motion.Service) map[string]interface{} {
+		moveReq := motion.MoveReq{
+			ComponentName: "pieceGripper",
+			Destination:   referenceframe.NewPoseInFrame("c", spatialmath.NewPoseFromPoint(r3.Vector{X: 0, Y: -30, Z: -50})),
+		}
+		proto, err := moveReq.ToProto(ms.Name().Name)
+		test.That(t, err, test.ShouldBeNil)
+		bytes, err := protojson.Marshal(proto)
+		test.That(t, err, test.ShouldBeNil)
+		return map[string]interface{}{
+			DoBuildRoadmap:   string(bytes),
+			DoRoadmapOptions: map[string]interface{}{"nodes": 20, "neighbors": 4},
+		}
+	}
+
+	t.Run("build roadmap", func(t *testing.T) {
+		// The roadmap file doesn't exist until the roadmap is built.
+		fileName := filepath.Join(t.TempDir(), "roadmap.json")
+		ms, teardown := setupMotionServiceWithConfig(t, "../data/moving_arm.json", &Config{RoadmapFilePath: fileName})
+		defer teardown()
+		test.That(t, ms.(*builtIn).roadmap, test.ShouldBeNil)
+
+		resp, err := ms.DoCommand(ctx, buildRoadmapCmd(ms))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp[DoBuildRoadmap], test.ShouldEqual, fileName)
+		rm, err := armplanning.ReadRoadmapFromFile(fileName)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(rm.Nodes), test.ShouldEqual, 20)
+		test.That(t, ms.(*builtIn).roadmap, test.ShouldResemble, rm)
+	})
+
+	t.Run("build roadmap without a roadmap file", func(t *testing.T) {
+		ms, teardown := setupMotionServiceFromConfig(t, "../data/moving_arm.json")
+		defer teardown()
+		_, err := ms.DoCommand(ctx, buildRoadmapCmd(ms))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "roadmap_file_path must be configured")
+	})
+}
+
 func TestConfigureJointLimits(t *testing.T) {
 	ctx := context.Background()
 
@@ -1,6 +1,15 @@
 package motion
 
 import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
 	"go.viam.com/rdk/data"
 )
 
@@ -8,11 +17,18 @@ type method int64
 
 const (
 	doCommand method = iota
+	planHistory
 )
 
+// componentNameKey is the additional param naming the component whose plans are captured.
+const componentNameKey = "component_name"
+
 func (m method) String() string {
-	if m == doCommand {
+	switch m {
+	case doCommand:
 		return "DoCommand"
+	case planHistory:
+		return "PlanHistory"
 	}
 	return "Unknown"
 }
@@ -29,6 +45,187 @@ func newDoCommandCollector(resource interface{}, params data.CollectorParams) (d
 	return data.NewCollector(cFunc, params)
 }
 
+// executedPlans is the tabular reading of the plans which reached a terminal state since the
+// previous capture, in the order they ended.
+type executedPlans struct {
+	Plans []executedPlan `json:"plans"`
+}
+
+// executedPlan is a plan which reached a terminal state.
+type executedPlan struct {
+	PlanID          string              `json:"plan_id"`
+	ExecutionID     string              `json:"execution_id"`
+	ComponentName   string              `json:"component_name"`
+	State           string              `json:"state"`
+	Reason          string              `json:"reason"`
+	StartTime       string              `json:"start_time"`
+	EndTime         string              `json:"end_time"`
+	DurationSeconds float64             `json:"duration_seconds"`
+	Steps           int                 `json:"steps"`
+	StatusHistory   []executedPlanState `json:"status_history"`
+}
+
+type executedPlanState struct {
+	State     string `json:"state"`
+	Timestamp string `json:"timestamp"`
+	Reason    string `json:"reason"`
+}
+
+// planHistoryRecorder remembers the plans which were already captured, so that each executed plan
+// is captured exactly once, no matter the capture frequency.
+type planHistoryRecorder struct {
+	mu sync.Mutex
+	// plans which ended before the collector was created aren't captured.
+	start    time.Time
+	recorded map[PlanID]struct{}
+}
+
+type endedPlan struct {
+	plan executedPlan
+	end  time.Time
+}
+
+// next returns the plans of the history which reached a terminal state and weren't captured yet,
+// in the order they ended.
+func (r *planHistoryRecorder) next(history []PlanWithStatus) []executedPlan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inHistory := make(map[PlanID]struct{}, len(history))
+	var ended []endedPlan
+	for _, p := range history {
+		inHistory[p.Plan.ID] = struct{}{}
+		if _, ok := r.recorded[p.Plan.ID]; ok {
+			continue
+		}
+		plan, end, ok := newExecutedPlan(p)
+		if !ok {
+			continue
+		}
+		r.recorded[p.Plan.ID] = struct{}{}
+		if end.Before(r.start) {
+			continue
+		}
+		ended = append(ended, endedPlan{plan: plan, end: end})
+	}
+	// Forget plans which are no longer in the history, they won't be returned again.
+	for id := range r.recorded {
+		if _, ok := inHistory[id]; !ok {
+			delete(r.recorded, id)
+		}
+	}
+	slices.SortStableFunc(ended, func(a, b endedPlan) int { return a.end.Compare(b.end) })
+
+	plans := make([]executedPlan, 0, len(ended))
+	for _, p := range ended {
+		plans = append(plans, p.plan)
+	}
+	return plans
+}
+
+// newExecutedPlan returns the reading of a plan and the time it ended, and false when the plan hasn't
+// reached a terminal state.
+func newExecutedPlan(p PlanWithStatus) (executedPlan, time.Time, bool) {
+	if len(p.StatusHistory) == 0 {
+		return executedPlan{}, time.Time{}, false
+	}
+	statuses := slices.Clone(p.StatusHistory)
+	slices.SortStableFunc(statuses, func(a, b PlanStatus) int { return a.Timestamp.Compare(b.Timestamp) })
+	first, last := statuses[0], statuses[len(statuses)-1]
+	if _, ok := TerminalStateSet[last.State]; !ok {
+		return executedPlan{}, time.Time{}, false
+	}
+
+	plan := executedPlan{
+		PlanID:          p.Plan.ID.String(),
+		ExecutionID:     p.Plan.ExecutionID.String(),
+		ComponentName:   p.Plan.ComponentName,
+		State:           last.State.String(),
+		Reason:          reasonOrEmpty(last.Reason),
+		StartTime:       first.Timestamp.UTC().Format(time.RFC3339Nano),
+		EndTime:         last.Timestamp.UTC().Format(time.RFC3339Nano),
+		DurationSeconds: last.Timestamp.Sub(first.Timestamp).Seconds(),
+		StatusHistory:   make([]executedPlanState, 0, len(statuses)),
+	}
+	if p.Plan.Plan != nil && p.Plan.Trajectory() != nil {
+		plan.Steps = len(p.Plan.Trajectory())
+	}
+	for _, s := range statuses {
+		plan.StatusHistory = append(plan.StatusHistory, executedPlanState{
+			State:     s.State.String(),
+			Timestamp: s.Timestamp.UTC().Format(time.RFC3339Nano),
+			Reason:    reasonOrEmpty(s.Reason),
+		})
+	}
+	return plan, last.Timestamp, true
+}
+
+func reasonOrEmpty(reason *string) string {
+	if reason == nil {
+		return ""
+	}
+	return *reason
+}
+
+// newPlanHistoryCollector returns a collector which captures the plans the motion service executed
+// for the component named by the component_name additional param. Each capture records every plan
+// which reached a terminal state since the previous capture, with its timing and status history.
+// Captures when no plan has ended are not stored. The builtin motion service does not keep a plan
+// history, so the collector needs a motion service which implements PlanHistory, like a modular one.
+func newPlanHistoryCollector(resource interface{}, params data.CollectorParams) (data.Collector, error) {
+	motion, err := assertMotion(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := &planHistoryRecorder{start: time.Now(), recorded: map[PlanID]struct{}{}}
+	cFunc := data.CaptureFunc(func(ctx context.Context, arg map[string]*anypb.Any) (data.CaptureResult, error) {
+		timeRequested := time.Now()
+		var res data.CaptureResult
+		componentName, err := componentNameFromParams(arg)
+		if err != nil {
+			return res, data.NewFailedToReadError(params.ComponentName, planHistory.String(), err)
+		}
+
+		history, err := motion.PlanHistory(ctx, PlanHistoryReq{ComponentName: componentName, Extra: data.FromDMExtraMap})
+		if err != nil {
+			// A modular filter component can be created to filter the readings from a component. The error ErrNoCaptureToStore
+			// is used in the datamanager to exclude readings from being captured and stored.
+			if errors.Is(err, data.ErrNoCaptureToStore) {
+				return res, err
+			}
+			if errors.Is(err, ErrPlanHistoryNotSupported) {
+				err = errors.Wrap(err, "plan history capture needs a motion service which implements PlanHistory")
+			}
+			return res, data.NewFailedToReadError(params.ComponentName, planHistory.String(), err)
+		}
+
+		plans := recorder.next(history)
+		if len(plans) == 0 {
+			return res, data.ErrNoCaptureToStore
+		}
+		ts := data.Timestamps{TimeRequested: timeRequested, TimeReceived: time.Now()}
+		return data.NewTabularCaptureResult(ts, executedPlans{Plans: plans})
+	})
+	return data.NewCollector(cFunc, params)
+}
+
+func componentNameFromParams(arg map[string]*anypb.Any) (string, error) {
+	nameMarshaled, ok := arg[componentNameKey]
+	if !ok {
+		return "", errors.New("must supply component_name in additional_params for plan history collector")
+	}
+	var structVal structpb.Value
+	if err := nameMarshaled.UnmarshalTo(&structVal); err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal component_name")
+	}
+	name, ok := structVal.Kind.(*structpb.Value_StringValue)
+	if !ok {
+		return "", errors.New("component_name must be a string")
+	}
+	return name.StringValue, nil
+}
+
 func assertMotion(resource interface{}) (Service, error) {
 	motion, ok := resource.(Service)
 	if !ok {
@@ -2,11 +2,24 @@ package motion_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/benbjohnson/clock"
+	"github.com/google/uuid"
+	datasyncpb "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/data"
 	datatu "go.viam.com/rdk/data/testutils"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
 	motion "go.viam.com/rdk/services/motion"
+	tu "go.viam.com/rdk/testutils"
 	inject "go.viam.com/rdk/testutils/inject/motion"
 )
 
@@ -27,10 +40,116 @@ func TestDoCommandCollector(t *testing.T) {
 	})
 }
 
-func newMotion() motion.Service {
+func TestPlanHistoryCollector(t *testing.T) {
+	start := time.Now()
+	reason := "obstacle detected"
+	executionID := uuid.New()
+	plan := func(id uuid.UUID, statuses ...motion.PlanStatus) motion.PlanWithStatus {
+		// The status history is ordered most recent first.
+		for i, j := 0, len(statuses)-1; i < j; i, j = i+1, j-1 {
+			statuses[i], statuses[j] = statuses[j], statuses[i]
+		}
+		return motion.PlanWithStatus{
+			Plan: motion.PlanWithMetadata{
+				ID:            id,
+				ComponentName: "arm",
+				ExecutionID:   executionID,
+				Plan:          motionplan.NewSimplePlan(nil, motionplan.Trajectory{referenceframe.FrameSystemInputs{}, {}}),
+			},
+			StatusHistory: statuses,
+		}
+	}
+	succeededID, failedID := uuid.New(), uuid.New()
+	history := []motion.PlanWithStatus{
+		// ended before the collector was created
+		plan(uuid.New(),
+			motion.PlanStatus{State: motion.PlanStateInProgress, Timestamp: start.Add(-time.Hour)},
+			motion.PlanStatus{State: motion.PlanStateSucceeded, Timestamp: start.Add(-time.Minute)}),
+		// still in progress
+		plan(uuid.New(), motion.PlanStatus{State: motion.PlanStateInProgress, Timestamp: start}),
+		plan(failedID,
+			motion.PlanStatus{State: motion.PlanStateInProgress, Timestamp: start.Add(time.Second)},
+			motion.PlanStatus{State: motion.PlanStateFailed, Timestamp: start.Add(3 * time.Second), Reason: &reason}),
+		plan(succeededID,
+			motion.PlanStatus{State: motion.PlanStateInProgress, Timestamp: start.Add(-time.Second)},
+			motion.PlanStatus{State: motion.PlanStateSucceeded, Timestamp: start.Add(time.Second)}),
+	}
+
+	m := newMotion()
+	m.PlanHistoryFunc = func(ctx context.Context, req motion.PlanHistoryReq) ([]motion.PlanWithStatus, error) {
+		if req.ComponentName != "arm" {
+			return nil, errors.New("unexpected component name")
+		}
+		return history, nil
+	}
+
+	timestamp := func(d time.Duration) string { return start.Add(d).UTC().Format(time.RFC3339Nano) }
+	executed := func(id uuid.UUID, state, reason string, begin, end time.Duration) map[string]any {
+		return map[string]any{
+			"plan_id":          id.String(),
+			"execution_id":     executionID.String(),
+			"component_name":   "arm",
+			"state":            state,
+			"reason":           reason,
+			"start_time":       timestamp(begin),
+			"end_time":         timestamp(end),
+			"duration_seconds": (end - begin).Seconds(),
+			"steps":            2,
+			"status_history": []any{
+				map[string]any{"state": "in progress", "timestamp": timestamp(begin), "reason": ""},
+				map[string]any{"state": state, "timestamp": timestamp(end), "reason": reason},
+			},
+		}
+	}
+	reading := func(plans ...any) *datasyncpb.SensorData {
+		return &datasyncpb.SensorData{
+			Metadata: &datasyncpb.SensorMetadata{},
+			Data:     &datasyncpb.SensorData_Struct{Struct: tu.ToStructPBStruct(t, map[string]any{"plans": plans})},
+		}
+	}
+
+	buf := tu.NewMockBuffer(t)
+	col, err := motion.NewPlanHistoryCollector(m, data.CollectorParams{
+		DataType:      data.CaptureTypeTabular,
+		ComponentName: componentName,
+		Interval:      captureInterval,
+		Logger:        logging.NewTestLogger(t),
+		Clock:         clock.New(),
+		Target:        buf,
+		MethodParams:  map[string]*anypb.Any{"component_name": convertStringToAny(t, "arm")},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	defer col.Close()
+	col.Collect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// The plans which ended are captured together, once, in the order they ended.
+	tu.CheckMockBufferWrites(t, ctx, start, buf.Writes, []*datasyncpb.SensorData{
+		reading(
+			executed(succeededID, "succeeded", "", -time.Second, time.Second),
+			executed(failedID, "failed", reason, time.Second, 3*time.Second),
+		),
+	})
+	select {
+	case <-buf.Writes:
+		t.Fatal("expected the plans to be captured once")
+	case <-time.After(50 * time.Millisecond):
+	}
+	buf.Close()
+}
+
+func newMotion() *inject.MotionService {
 	m := &inject.MotionService{}
 	m.DoCommandFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
 		return doCommandMap, nil
 	}
 	return m
 }
+
+func convertStringToAny(t *testing.T, str string) *anypb.Any {
+	t.Helper()
+	anyValue, err := anypb.New(structpb.NewStringValue(str))
+	test.That(t, err, test.ShouldBeNil)
+	return anyValue
+}
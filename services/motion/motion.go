@@ -11,6 +11,7 @@ import (
 
 	"github.com/google/uuid"
 	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
 	pb "go.viam.com/api/service/motion/v1"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -33,8 +34,16 @@ func init() {
 		API:        API,
 		MethodName: doCommand.String(),
 	}, newDoCommandCollector)
+	data.RegisterCollector(data.MethodMetadata{
+		API:        API,
+		MethodName: planHistory.String(),
+	}, newPlanHistoryCollector)
 }
 
+// ErrPlanHistoryNotSupported is returned by motion services which don't keep a plan history, like
+// the builtin motion service.
+var ErrPlanHistoryNotSupported = errors.New("PlanHistory not supported")
+
 // PlanHistoryReq describes the request to PlanHistory().
 type PlanHistoryReq struct {
 	// ComponentName the returned plans should be associated with.
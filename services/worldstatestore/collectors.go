@@ -0,0 +1,250 @@
+package worldstatestore
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	commonpb "go.viam.com/api/common/v1"
+	goutils "go.viam.com/utils"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"go.viam.com/rdk/data"
+)
+
+type method int64
+
+const (
+	doCommand method = iota
+	transforms
+	transformChanges
+)
+
+func (m method) String() string {
+	switch m {
+	case doCommand:
+		return "DoCommand"
+	case transforms:
+		return "Transforms"
+	case transformChanges:
+		return "TransformChanges"
+	}
+	return "Unknown"
+}
+
+// maxBufferedTransformChanges is the number of transform changes buffered between captures, the
+// oldest changes are dropped when captures can't keep up with the stream.
+const maxBufferedTransformChanges = 1000
+
+// newDoCommandCollector returns a collector to register a doCommand action. If one is already registered
+// with the same MethodMetadata it will panic.
+func newDoCommandCollector(resource interface{}, params data.CollectorParams) (data.Collector, error) {
+	store, err := assertWorldStateStore(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	cFunc := data.NewDoCommandCaptureFunc(store, params)
+	return data.NewCollector(cFunc, params)
+}
+
+// newTransformsCollector returns a collector which captures a snapshot of all the transforms in
+// the world state store.
+func newTransformsCollector(resource interface{}, params data.CollectorParams) (data.Collector, error) {
+	store, err := assertWorldStateStore(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	cFunc := data.CaptureFunc(func(ctx context.Context, _ map[string]*anypb.Any) (data.CaptureResult, error) {
+		timeRequested := time.Now()
+		var res data.CaptureResult
+		uuids, err := store.ListUUIDs(ctx, data.FromDMExtraMap)
+		if err != nil {
+			// A modular filter component can be created to filter the readings from a component. The error ErrNoCaptureToStore
+			// is used in the datamanager to exclude readings from being captured and stored.
+			if errors.Is(err, data.ErrNoCaptureToStore) {
+				return res, err
+			}
+			return res, data.NewFailedToReadError(params.ComponentName, transforms.String(), err)
+		}
+
+		readings := make([]interface{}, 0, len(uuids))
+		for _, uuid := range uuids {
+			transform, err := store.GetTransform(ctx, uuid, data.FromDMExtraMap)
+			if err != nil {
+				return res, data.NewFailedToReadError(params.ComponentName, transforms.String(), err)
+			}
+			reading, err := transformToReading(transform)
+			if err != nil {
+				return res, data.NewFailedToReadError(params.ComponentName, transforms.String(), err)
+			}
+			readings = append(readings, reading)
+		}
+		ts := data.Timestamps{TimeRequested: timeRequested, TimeReceived: time.Now()}
+		return data.NewTabularCaptureResult(ts, map[string]interface{}{"transforms": readings})
+	})
+	return data.NewCollector(cFunc, params)
+}
+
+// transformChangeRecorder buffers the changes of a transform change stream between captures.
+type transformChangeRecorder struct {
+	mu        sync.Mutex
+	streaming bool
+	changes   []interface{}
+	err       error
+
+	// cancelStream ends the current stream, and receivers tracks the goroutine receiving it.
+	cancelStream context.CancelFunc
+	receivers    sync.WaitGroup
+}
+
+// close ends the stream and waits for its changes to stop being received.
+func (r *transformChangeRecorder) close() {
+	r.mu.Lock()
+	if r.cancelStream != nil {
+		r.cancelStream()
+	}
+	r.mu.Unlock()
+	r.receivers.Wait()
+}
+
+// transformChangesCollector is a collector which stops streaming transform changes when closed.
+type transformChangesCollector struct {
+	data.Collector
+	recorder *transformChangeRecorder
+}
+
+func (c *transformChangesCollector) Close() {
+	c.Collector.Close()
+	c.recorder.close()
+}
+
+// receive buffers the changes of the stream until it ends.
+func (r *transformChangeRecorder) receive(stream *TransformChangeStream) {
+	for {
+		change, err := stream.Next()
+		if err != nil {
+			r.mu.Lock()
+			r.streaming = false
+			if !errors.Is(err, io.EOF) && !errors.Is(err, context.Canceled) {
+				r.err = err
+			}
+			r.mu.Unlock()
+			return
+		}
+		reading, err := transformChangeToReading(change)
+
+		r.mu.Lock()
+		if err != nil {
+			r.err = err
+		} else {
+			r.changes = append(r.changes, reading)
+			if len(r.changes) > maxBufferedTransformChanges {
+				r.changes = r.changes[len(r.changes)-maxBufferedTransformChanges:]
+			}
+		}
+		r.mu.Unlock()
+	}
+}
+
+// newTransformChangesCollector returns a collector which captures the changes to the transforms of
+// the world state store. The changes are streamed as they happen, and each capture stores the changes
+// received since the previous capture. Captures when nothing changed are not stored.
+func newTransformChangesCollector(resource interface{}, params data.CollectorParams) (data.Collector, error) {
+	store, err := assertWorldStateStore(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := &transformChangeRecorder{}
+	cFunc := data.CaptureFunc(func(ctx context.Context, _ map[string]*anypb.Any) (data.CaptureResult, error) {
+		timeRequested := time.Now()
+		var res data.CaptureResult
+
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		// The capture context lives as long as the collector, so does the stream. It is reopened on
+		// the next capture when it ends.
+		if !recorder.streaming {
+			if recorder.cancelStream != nil {
+				recorder.cancelStream()
+			}
+			streamCtx, cancel := context.WithCancel(ctx)
+			stream, err := store.StreamTransformChanges(streamCtx, data.FromDMExtraMap)
+			if err != nil {
+				cancel()
+				return res, data.NewFailedToReadError(params.ComponentName, transformChanges.String(), err)
+			}
+			recorder.streaming = true
+			recorder.cancelStream = cancel
+			recorder.receivers.Add(1)
+			goutils.PanicCapturingGo(func() {
+				defer recorder.receivers.Done()
+				recorder.receive(stream)
+			})
+		}
+		if recorder.err != nil {
+			err := recorder.err
+			recorder.err = nil
+			return res, data.NewFailedToReadError(params.ComponentName, transformChanges.String(), err)
+		}
+		if len(recorder.changes) == 0 {
+			return res, data.ErrNoCaptureToStore
+		}
+
+		changes := recorder.changes
+		recorder.changes = nil
+		ts := data.Timestamps{TimeRequested: timeRequested, TimeReceived: time.Now()}
+		return data.NewTabularCaptureResult(ts, map[string]interface{}{"changes": changes})
+	})
+	col, err := data.NewCollector(cFunc, params)
+	if err != nil {
+		return nil, err
+	}
+	return &transformChangesCollector{Collector: col, recorder: recorder}, nil
+}
+
+func transformChangeToReading(change TransformChange) (map[string]interface{}, error) {
+	transform, err := transformToReading(change.Transform)
+	if err != nil {
+		return nil, err
+	}
+	updatedFields := make([]interface{}, 0, len(change.UpdatedFields))
+	for _, field := range change.UpdatedFields {
+		updatedFields = append(updatedFields, field)
+	}
+	return map[string]interface{}{
+		"change_type":    change.ChangeType.String(),
+		"transform":      transform,
+		"updated_fields": updatedFields,
+	}, nil
+}
+
+// transformToReading converts a transform to the JSON representation of its proto.
+func transformToReading(transform *commonpb.Transform) (map[string]interface{}, error) {
+	if transform == nil {
+		return nil, ErrNilResponse
+	}
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(transform)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert transform into json")
+	}
+	var reading map[string]interface{}
+	if err := json.Unmarshal(b, &reading); err != nil {
+		return nil, errors.Wrap(err, "failed to convert transform into json")
+	}
+	return reading, nil
+}
+
+func assertWorldStateStore(resource interface{}) (Service, error) {
+	store, ok := resource.(Service)
+	if !ok {
+		return nil, data.InvalidInterfaceErr(API)
+	}
+	return store, nil
+}
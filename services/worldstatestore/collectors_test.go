@@ -0,0 +1,163 @@
+package worldstatestore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	datasyncpb "go.viam.com/api/app/datasync/v1"
+	commonpb "go.viam.com/api/common/v1"
+	pb "go.viam.com/api/service/worldstatestore/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/data"
+	datatu "go.viam.com/rdk/data/testutils"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/services/worldstatestore"
+	tu "go.viam.com/rdk/testutils"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+const (
+	serviceName     = "world_state_store"
+	captureInterval = time.Millisecond
+)
+
+var doCommandMap = map[string]any{"readings": "random-test"}
+
+func TestTransformsCollector(t *testing.T) {
+	transforms := map[string]*commonpb.Transform{
+		"a": newTransform("box", 1),
+		"b": newTransform("sphere", 2),
+	}
+	store := inject.NewWorldStateStoreService(serviceName)
+	store.ListUUIDsFunc = func(ctx context.Context, extra map[string]any) ([][]byte, error) {
+		return [][]byte{[]byte("a"), []byte("b")}, nil
+	}
+	store.GetTransformFunc = func(ctx context.Context, uuid []byte, extra map[string]any) (*commonpb.Transform, error) {
+		return transforms[string(uuid)], nil
+	}
+
+	start := time.Now()
+	buf := tu.NewMockBuffer(t)
+	col, err := worldstatestore.NewTransformsCollector(store, newCollectorParams(t, buf))
+	test.That(t, err, test.ShouldBeNil)
+	defer col.Close()
+	col.Collect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	tu.CheckMockBufferWrites(t, ctx, start, buf.Writes, []*datasyncpb.SensorData{{
+		Metadata: &datasyncpb.SensorMetadata{},
+		Data: &datasyncpb.SensorData_Struct{Struct: tu.ToStructPBStruct(t, map[string]any{
+			"transforms": []any{transformReading("box", 1), transformReading("sphere", 2)},
+		})},
+	}})
+	buf.Close()
+}
+
+func TestTransformChangesCollector(t *testing.T) {
+	changes := make(chan worldstatestore.TransformChange, 1)
+	streamCtxs := make(chan context.Context, 1)
+	store := inject.NewWorldStateStoreService(serviceName)
+	store.StreamTransformChangesFunc = func(ctx context.Context, extra map[string]any) (*worldstatestore.TransformChangeStream, error) {
+		streamCtxs <- ctx
+		return worldstatestore.NewTransformChangeStreamFromChannel(ctx, changes), nil
+	}
+	changes <- worldstatestore.TransformChange{
+		ChangeType: pb.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED,
+		Transform:  newTransform("box", 1),
+	}
+
+	start := time.Now()
+	buf := tu.NewMockBuffer(t)
+	col, err := worldstatestore.NewTransformChangesCollector(store, newCollectorParams(t, buf))
+	test.That(t, err, test.ShouldBeNil)
+	defer col.Close()
+	col.Collect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	tu.CheckMockBufferWrites(t, ctx, start, buf.Writes, []*datasyncpb.SensorData{{
+		Metadata: &datasyncpb.SensorMetadata{},
+		Data: &datasyncpb.SensorData_Struct{Struct: tu.ToStructPBStruct(t, map[string]any{
+			"changes": []any{map[string]any{
+				"change_type":    "TRANSFORM_CHANGE_TYPE_ADDED",
+				"transform":      transformReading("box", 1),
+				"updated_fields": []any{},
+			}},
+		})},
+	}})
+
+	// Captures only store the changes since the previous capture.
+	changes <- worldstatestore.TransformChange{
+		ChangeType:    pb.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED,
+		Transform:     newTransform("box", 2),
+		UpdatedFields: []string{"pose_in_observer_frame.pose.x"},
+	}
+	tu.CheckMockBufferWrites(t, ctx, start, buf.Writes, []*datasyncpb.SensorData{{
+		Metadata: &datasyncpb.SensorMetadata{},
+		Data: &datasyncpb.SensorData_Struct{Struct: tu.ToStructPBStruct(t, map[string]any{
+			"changes": []any{map[string]any{
+				"change_type":    "TRANSFORM_CHANGE_TYPE_UPDATED",
+				"transform":      transformReading("box", 2),
+				"updated_fields": []any{"pose_in_observer_frame.pose.x"},
+			}},
+		})},
+	}})
+
+	// Closing the collector ends the stream.
+	col.Close()
+	streamCtx := <-streamCtxs
+	test.That(t, streamCtx.Err(), test.ShouldNotBeNil)
+	buf.Close()
+}
+
+func TestDoCommandCollector(t *testing.T) {
+	datatu.TestDoCommandCollector(t, datatu.DoCommandTestConfig{
+		ComponentName:   serviceName,
+		CaptureInterval: captureInterval,
+		DoCommandMap:    doCommandMap,
+		Collector:       worldstatestore.NewDoCommandCollector,
+		ResourceFactory: func() interface{} {
+			store := inject.NewWorldStateStoreService(serviceName)
+			store.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+				return doCommandMap, nil
+			}
+			return store
+		},
+	})
+}
+
+func newCollectorParams(t *testing.T, buf *tu.MockBuffer) data.CollectorParams {
+	t.Helper()
+	return data.CollectorParams{
+		DataType:      data.CaptureTypeTabular,
+		ComponentName: serviceName,
+		Interval:      captureInterval,
+		Logger:        logging.NewTestLogger(t),
+		Clock:         clock.New(),
+		Target:        buf,
+	}
+}
+
+func newTransform(name string, x float64) *commonpb.Transform {
+	return &commonpb.Transform{
+		ReferenceFrame: name,
+		PoseInObserverFrame: &commonpb.PoseInFrame{
+			ReferenceFrame: "world",
+			Pose:           &commonpb.Pose{X: x, OZ: 1},
+		},
+	}
+}
+
+func transformReading(name string, x float64) map[string]any {
+	return map[string]any{
+		"reference_frame": name,
+		"pose_in_observer_frame": map[string]any{
+			"reference_frame": "world",
+			"pose":            map[string]any{"x": x, "o_z": 1},
+		},
+	}
+}
@@ -0,0 +1,9 @@
+// export_collectors_test.go adds functionality to the package that we only want to use and expose during testing.
+package worldstatestore
+
+// Exported variables for testing collectors, see unexported collectors for implementation details.
+var (
+	NewDoCommandCollector        = newDoCommandCollector
+	NewTransformsCollector       = newTransformsCollector
+	NewTransformChangesCollector = newTransformChangesCollector
+)
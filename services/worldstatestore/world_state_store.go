@@ -13,6 +13,7 @@ import (
 	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/service/worldstatestore/v1"
 
+	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
 )
@@ -24,6 +25,18 @@ func init() {
 		RPCServiceDesc:              &pb.WorldStateStoreService_ServiceDesc,
 		RPCClient:                   NewClientFromConn,
 	})
+	data.RegisterCollector(data.MethodMetadata{
+		API:        API,
+		MethodName: doCommand.String(),
+	}, newDoCommandCollector)
+	data.RegisterCollector(data.MethodMetadata{
+		API:        API,
+		MethodName: transforms.String(),
+	}, newTransformsCollector)
+	data.RegisterCollector(data.MethodMetadata{
+		API:        API,
+		MethodName: transformChanges.String(),
+	}, newTransformChangesCollector)
 }
 
 const (
@@ -145,10 +145,11 @@ func (q *DualQuaternion) SetZ(z float64) {
 func (q *DualQuaternion) Transformation(by dualquat.Number) dualquat.Number {
 	var newReal quat.Number
 
-	if q.Real.Real == 1 {
-		// Since we're working with unit quaternions, if either Real is 1, then that quat is an identity quat
+	// Rotations small enough that their real part rounds to 1 still have nonzero imaginary parts, so the whole quat is
+	// compared against the identity.
+	if q.Real == (quat.Number{Real: 1}) {
 		newReal = by.Real
-	} else if by.Real.Real == 1 {
+	} else if by.Real == (quat.Number{Real: 1}) {
 		newReal = q.Real
 	} else {
 		newReal = quat.Mul(q.Real, by.Real)
@@ -86,6 +86,12 @@ func TestDualQuatTransform(t *testing.T) {
 	test.That(t, transformedPoint.X, test.ShouldAlmostEqual, expectedPoint.X)
 	test.That(t, transformedPoint.Y, test.ShouldAlmostEqual, expectedPoint.Y)
 	test.That(t, transformedPoint.Z, test.ShouldAlmostEqual, expectedPoint.Z)
+
+	// A rotation small enough that its real part rounds to 1 still moves the point.
+	tiny := &DualQuaternion{dualquat.Number{Real: (&R4AA{1e-8, 0, 0, 1}).ToQuat()}}
+	test.That(t, tiny.Real.Real, test.ShouldEqual, 1)
+	rotatedPoint := Compose(tiny, NewPoseFromPoint(r3.Vector{X: 100})).Point()
+	test.That(t, rotatedPoint.Y, test.ShouldAlmostEqual, 1e-6, 1e-12)
 }
 
 func TestPoseInterpolation(t *testing.T) {
@@ -220,3 +226,23 @@ func TestInterpolateSand1(t *testing.T) {
 
 	test.That(t, x.Point().Distance(y.Point()), test.ShouldAlmostEqual, y.Point().Distance(b.Point()), .00001)
 }
+
+func BenchmarkDualQuatTransformation(b *testing.B) {
+	rotated := &DualQuaternion{dualquat.Number{Real: (&R4AA{0.5, 0, 0, 1}).ToQuat()}}
+	identity := &DualQuaternion{dualquat.Number{Real: quat.Number{Real: 1}}}
+	moved := NewPoseFromPoint(r3.Vector{X: 100}).(*DualQuaternion)
+	for _, tc := range []struct {
+		name string
+		q    *DualQuaternion
+		by   *DualQuaternion
+	}{
+		{"identity", identity, moved},
+		{"rotation", rotated, rotated},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				tc.q.Transformation(tc.by.Number)
+			}
+		})
+	}
+}
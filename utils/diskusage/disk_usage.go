@@ -18,3 +18,12 @@ func Statfs(volumePath string) (DiskUsage, error) {
 		SizeBytes:      stat.Blocks * uint64(stat.Bsize),
 	}, nil
 }
+
+// sameVolume returns whether both paths exist and are on the same file system.
+func sameVolume(left, right string) bool {
+	var leftStat, rightStat syscall.Stat_t
+	if syscall.Stat(left, &leftStat) != nil || syscall.Stat(right, &rightStat) != nil {
+		return false
+	}
+	return leftStat.Dev == rightStat.Dev
+}
@@ -1,6 +1,8 @@
 package diskusage
 
 import (
+	"context"
+	"path/filepath"
 	"testing"
 
 	"go.viam.com/test"
@@ -45,3 +47,35 @@ func TestDiskUsage(t *testing.T) {
 		}
 	})
 }
+
+type testReclaimer struct {
+	dir        string
+	freedBytes int64
+}
+
+func (tr *testReclaimer) ReclaimDir() string {
+	return tr.dir
+}
+
+func (tr *testReclaimer) Reclaim(ctx context.Context) (int64, error) {
+	return tr.freedBytes, nil
+}
+
+func TestReclaim(t *testing.T) {
+	volumeDir := t.TempDir()
+	sameVolume := &testReclaimer{dir: t.TempDir(), freedBytes: 10}
+	// A directory that does not exist has nothing to reclaim.
+	missing := &testReclaimer{dir: filepath.Join(volumeDir, "missing"), freedBytes: 100}
+	RegisterReclaimer(sameVolume)
+	RegisterReclaimer(missing)
+
+	freedBytes, err := Reclaim(context.Background(), volumeDir)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, freedBytes, test.ShouldEqual, 10)
+
+	UnregisterReclaimer(sameVolume)
+	UnregisterReclaimer(missing)
+	freedBytes, err = Reclaim(context.Background(), volumeDir)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, freedBytes, test.ShouldEqual, 0)
+}
@@ -1,6 +1,9 @@
 package diskusage
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"unsafe"
 )
@@ -14,6 +17,25 @@ func Statfs(volumePath string) (DiskUsage, error) {
 	}, nil
 }
 
+// sameVolume returns whether both paths exist and are on the same volume.
+func sameVolume(left, right string) bool {
+	if _, err := os.Stat(left); err != nil {
+		return false
+	}
+	if _, err := os.Stat(right); err != nil {
+		return false
+	}
+	leftAbs, err := filepath.Abs(left)
+	if err != nil {
+		return false
+	}
+	rightAbs, err := filepath.Abs(right)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(filepath.VolumeName(leftAbs), filepath.VolumeName(rightAbs))
+}
+
 type windowsDiskUsage struct {
 	freeBytes  int64
 	totalBytes int64
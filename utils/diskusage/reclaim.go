@@ -0,0 +1,62 @@
+package diskusage
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/multierr"
+)
+
+// Reclaimer is implemented by components that keep files on disk which may be deleted when the disk
+// is full, e.g: FTDC diagnostics. Components that delete their own data to free up disk space, such
+// as the data manager, call `Reclaim` first.
+type Reclaimer interface {
+	// ReclaimDir returns the directory the reclaimable files are kept in.
+	ReclaimDir() string
+	// Reclaim deletes files to free up disk space and returns the number of bytes freed.
+	Reclaim(ctx context.Context) (int64, error)
+}
+
+var (
+	reclaimersMu sync.Mutex
+	reclaimers   = map[Reclaimer]struct{}{}
+)
+
+// RegisterReclaimer adds a `Reclaimer` that is asked to free up disk space by `Reclaim`.
+func RegisterReclaimer(reclaimer Reclaimer) {
+	reclaimersMu.Lock()
+	defer reclaimersMu.Unlock()
+	reclaimers[reclaimer] = struct{}{}
+}
+
+// UnregisterReclaimer removes a `Reclaimer` added by `RegisterReclaimer`.
+func UnregisterReclaimer(reclaimer Reclaimer) {
+	reclaimersMu.Lock()
+	defer reclaimersMu.Unlock()
+	delete(reclaimers, reclaimer)
+}
+
+// Reclaim asks every registered `Reclaimer` keeping its files on the same file system as
+// `volumePath` to free up disk space. It returns the total number of bytes freed.
+func Reclaim(ctx context.Context, volumePath string) (int64, error) {
+	reclaimersMu.Lock()
+	toAsk := make([]Reclaimer, 0, len(reclaimers))
+	for reclaimer := range reclaimers {
+		if sameVolume(volumePath, reclaimer.ReclaimDir()) {
+			toAsk = append(toAsk, reclaimer)
+		}
+	}
+	reclaimersMu.Unlock()
+
+	var freedBytes int64
+	var errs error
+	for _, reclaimer := range toAsk {
+		if ctx.Err() != nil {
+			return freedBytes, multierr.Combine(errs, ctx.Err())
+		}
+		freed, err := reclaimer.Reclaim(ctx)
+		freedBytes += freed
+		errs = multierr.Combine(errs, err)
+	}
+	return freedBytes, errs
+}
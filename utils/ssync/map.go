@@ -35,6 +35,16 @@ func (m *Map[K, V]) Load(key K) (V, bool) {
 	return v.(V), ok
 }
 
+// LoadAndDelete is an alias to [sync.Map.LoadAndDelete].
+func (m *Map[K, V]) LoadAndDelete(key K) (V, bool) {
+	v, loaded := (*sync.Map)(m).LoadAndDelete(key)
+	if !loaded {
+		var zero V
+		return zero, loaded
+	}
+	return v.(V), loaded
+}
+
 // LoadOrStore is an alias to [sync.Map.LoadOrStore].
 func (m *Map[K, V]) LoadOrStore(key K, value V) (V, bool) {
 	v, stored := (*sync.Map)(m).LoadOrStore(key, value)
@@ -43,6 +43,7 @@ type Arguments struct {
 	Debug                      bool   `flag:"debug"`
 	Version                    bool   `flag:"version,usage=print version"`
 	WebProfile                 bool   `flag:"webprofile,usage=include profiler in http server"`
+	WebMetrics                 bool   `flag:"webmetrics,usage=serve diagnostics in the OpenMetrics format at /metrics in http server"`
 	WebRTC                     bool   `flag:"webrtc,default=true,usage=force webrtc connections instead of direct"`
 	RevealSensitiveConfigDiffs bool   `flag:"reveal-sensitive-config-diffs,usage=show config diffs"`
 	UntrustedEnv               bool   `flag:"untrusted-env,usage=disable processes and shell from running in a untrusted environment"`
@@ -329,6 +330,7 @@ func (s *robotServer) createWebOptions(cfg *config.Config) (weboptions.Options,
 		return weboptions.Options{}, err
 	}
 	options.Pprof = s.args.WebProfile || cfg.EnableWebProfile
+	options.Metrics = s.args.WebMetrics || cfg.EnableWebMetrics
 	options.Debug = s.args.Debug || cfg.Debug
 	options.PreferWebRTC = s.args.WebRTC
 	options.DisableMulticastDNS = s.args.DisableMulticastDNS
@@ -363,6 +365,7 @@ func (s *robotServer) processConfig(in *config.Config) (*config.Config, error) {
 	}
 	out.Debug = s.args.Debug || in.Debug
 	out.EnableWebProfile = s.args.WebProfile || in.EnableWebProfile
+	out.EnableWebMetrics = s.args.WebMetrics || in.EnableWebMetrics
 	out.FromCommand = true
 	out.AllowInsecureCreds = s.args.AllowInsecureCreds
 	out.UntrustedEnv = s.args.UntrustedEnv